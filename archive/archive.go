@@ -0,0 +1,373 @@
+// Package archive lists and extracts entries of ZIP, TAR and gzip-compressed TAR archives
+// with limits against decompression bombs.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+// Archive formats
+const (
+	FormatZip   = "zip"
+	FormatTar   = "tar"
+	FormatTarGz = "tar.gz"
+)
+
+// Entry types
+const (
+	EntryTypeFile  = "file"
+	EntryTypeDir   = "dir"
+	EntryTypeLink  = "link" // Symbolic or hard link
+	EntryTypeOther = "other"
+)
+
+// minRatioSize expanded sizes below this are not subject to the compression ratio limit
+// Small, highly compressible files (text, zero padding) are harmless.
+const minRatioSize = 1 << 20
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported archive format (zip/tar/tar.gz)")
+	ErrTooManyEntries    = errors.New("archive has too many entries")
+	ErrTooLarge          = errors.New("archive expands beyond the size limit")
+	ErrCompressionRatio  = errors.New("archive compression ratio exceeds the limit")
+	ErrEntryNotFound     = errors.New("archive entry not found")
+	ErrNotRegularFile    = errors.New("archive entry is not a regular file")
+)
+
+// Limits decompression bomb limits (0 = no limit)
+type Limits struct {
+	MaxEntries   int     // Maximum number of entries
+	MaxTotalSize int64   // Maximum total expanded size in bytes
+	MaxRatio     float64 // Maximum expanded/compressed size ratio
+}
+
+// Entry archive entry
+type Entry struct {
+	Path           string    `json:"path"` // Cleaned slash-separated path, without leading or trailing slash
+	Type           string    `json:"type"` // file/dir/link/other
+	Size           int64     `json:"size"`
+	CompressedSize int64     `json:"compressed_size"` // ZIP only, equal to size for TAR
+	ContentType    string    `json:"content_type"`    // Guessed from the extension (empty = unknown)
+	LinkTarget     string    `json:"link_target,omitempty"`
+	ModTime        time.Time `json:"mod_time"`
+}
+
+// Index entries of an archive
+type Index struct {
+	Format    string  `json:"format"`
+	TotalSize int64   `json:"total_size"` // Total expanded size of all entries
+	Entries   []Entry `json:"entries"`
+}
+
+// DetectFormat detect archive format of content (empty = unsupported)
+func DetectFormat(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, []byte("PK\x03\x04")), bytes.HasPrefix(content, []byte("PK\x05\x06")):
+		return FormatZip
+	case isTarHeader(content):
+		return FormatTar
+	case bytes.HasPrefix(content, []byte{0x1F, 0x8B}):
+		zr, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return ""
+		}
+		header := make([]byte, 512)
+		if _, err := io.ReadFull(zr, header); err != nil {
+			return ""
+		}
+		if isTarHeader(header) {
+			return FormatTarGz
+		}
+	}
+	return ""
+}
+
+// isTarHeader check for the POSIX/GNU "ustar" magic of the first header block
+func isTarHeader(block []byte) bool {
+	return len(block) >= 262 && bytes.Equal(block[257:262], []byte("ustar"))
+}
+
+// List list the entries of an archive
+func List(content []byte, limits Limits) (*Index, error) {
+	format := DetectFormat(content)
+	index := &Index{Format: format, Entries: []Entry{}}
+
+	switch format {
+	case FormatZip:
+		files, err := openZip(content, limits)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			entry, ok := zipEntry(f)
+			if !ok {
+				continue
+			}
+			index.Entries = append(index.Entries, entry)
+			index.TotalSize += entry.Size
+		}
+	case FormatTar, FormatTarGz:
+		err := walkTar(content, format == FormatTarGz, limits, func(hdr *tar.Header, _ io.Reader) (bool, error) {
+			entry, ok := tarEntry(hdr)
+			if ok {
+				index.Entries = append(index.Entries, entry)
+				index.TotalSize += entry.Size
+			}
+			return false, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	return index, nil
+}
+
+// Open open a regular file entry of an archive for reading
+// The reader enforces the limits while streaming, so the whole entry is never held in memory.
+func Open(content []byte, name string, limits Limits) (io.ReadCloser, *Entry, error) {
+	name = cleanPath(name)
+	if name == "" {
+		return nil, nil, ErrEntryNotFound
+	}
+
+	switch format := DetectFormat(content); format {
+	case FormatZip:
+		files, err := openZip(content, limits)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, f := range files {
+			entry, ok := zipEntry(f)
+			if !ok || entry.Path != name {
+				continue
+			}
+			if entry.Type != EntryTypeFile {
+				return nil, nil, ErrNotRegularFile
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to open zip entry: %w", err)
+			}
+			return rc, &entry, nil
+		}
+	case FormatTar, FormatTarGz:
+		var (
+			reader io.Reader
+			found  *Entry
+		)
+		err := walkTar(content, format == FormatTarGz, limits, func(hdr *tar.Header, r io.Reader) (bool, error) {
+			entry, ok := tarEntry(hdr)
+			if !ok || entry.Path != name {
+				return false, nil
+			}
+			if entry.Type != EntryTypeFile {
+				return true, ErrNotRegularFile
+			}
+			reader, found = r, &entry
+			return true, nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if found != nil {
+			return io.NopCloser(reader), found, nil
+		}
+	default:
+		return nil, nil, ErrUnsupportedFormat
+	}
+
+	return nil, nil, ErrEntryNotFound
+}
+
+// openZip read the ZIP central directory and check it against the limits
+// Sizes come from the central directory; archive/zip rejects entries that expand beyond their declared size.
+func openZip(content []byte, limits Limits) ([]*zip.File, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if limits.MaxEntries > 0 && len(zr.File) > limits.MaxEntries {
+		return nil, ErrTooManyEntries
+	}
+
+	var total uint64
+	for _, f := range zr.File {
+		total += f.UncompressedSize64
+		if total < f.UncompressedSize64 || (limits.MaxTotalSize > 0 && total > uint64(limits.MaxTotalSize)) {
+			return nil, ErrTooLarge
+		}
+		if exceedsRatio(f.UncompressedSize64, f.CompressedSize64, limits.MaxRatio) {
+			return nil, ErrCompressionRatio
+		}
+	}
+	if exceedsRatio(total, uint64(len(content)), limits.MaxRatio) {
+		return nil, ErrCompressionRatio
+	}
+
+	return zr.File, nil
+}
+
+// exceedsRatio check if expanded/compressed exceeds the maximum ratio
+func exceedsRatio(expanded, compressed uint64, maxRatio float64) bool {
+	if maxRatio <= 0 || expanded < minRatioSize {
+		return false
+	}
+	return compressed == 0 || float64(expanded)/float64(compressed) > maxRatio
+}
+
+// zipEntry convert ZIP file header to entry (false = entry has no usable path)
+func zipEntry(f *zip.File) (Entry, bool) {
+	entryPath := cleanPath(f.Name)
+	if entryPath == "" {
+		return Entry{}, false
+	}
+
+	entry := Entry{
+		Path:           entryPath,
+		Type:           modeEntryType(f.Mode()),
+		Size:           int64(f.UncompressedSize64),
+		CompressedSize: int64(f.CompressedSize64),
+		ModTime:        f.Modified.UTC(),
+	}
+	if entry.Type == EntryTypeFile {
+		entry.ContentType = guessContentType(entryPath)
+	}
+	return entry, true
+}
+
+// walkTar iterate the headers of a TAR archive, enforcing the limits
+// fn receives each header with a reader of its content and returns true to stop.
+// The reader stays valid after walkTar returns, it streams from the same decompressor.
+func walkTar(content []byte, gzipped bool, limits Limits, fn func(hdr *tar.Header, r io.Reader) (bool, error)) error {
+	var r io.Reader = bytes.NewReader(content)
+	if gzipped {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+		}
+		r = &boundedReader{r: zr, compressedSize: int64(len(content)), limits: limits}
+	}
+
+	tr := tar.NewReader(r)
+	entries := 0
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if errors.Is(err, ErrTooLarge) || errors.Is(err, ErrCompressionRatio) {
+				return err
+			}
+			return fmt.Errorf("failed to read tar: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		entries++
+		if limits.MaxEntries > 0 && entries > limits.MaxEntries {
+			return ErrTooManyEntries
+		}
+		total += hdr.Size
+		if limits.MaxTotalSize > 0 && total > limits.MaxTotalSize {
+			return ErrTooLarge
+		}
+
+		stop, err := fn(hdr, io.LimitReader(tr, hdr.Size))
+		if err != nil || stop {
+			return err
+		}
+	}
+}
+
+// boundedReader decompressed stream that fails once it expands beyond the limits
+// Headers and padding are counted as well, which also covers bombs hidden outside entry content.
+type boundedReader struct {
+	r              io.Reader
+	n              int64
+	compressedSize int64
+	limits         Limits
+}
+
+func (b *boundedReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+	// Headers and padding take at most a few blocks per entry on top of the content
+	if b.limits.MaxTotalSize > 0 && b.n > b.limits.MaxTotalSize+int64(b.limits.MaxEntries+2)*2048 {
+		return n, ErrTooLarge
+	}
+	if exceedsRatio(uint64(b.n), uint64(b.compressedSize), b.limits.MaxRatio) {
+		return n, ErrCompressionRatio
+	}
+	return n, err
+}
+
+// tarEntry convert TAR header to entry (false = entry has no usable path)
+func tarEntry(hdr *tar.Header) (Entry, bool) {
+	entryPath := cleanPath(hdr.Name)
+	if entryPath == "" {
+		return Entry{}, false
+	}
+
+	entry := Entry{
+		Path:           entryPath,
+		Type:           modeEntryType(hdr.FileInfo().Mode()),
+		Size:           hdr.Size,
+		CompressedSize: hdr.Size,
+		ModTime:        hdr.ModTime.UTC(),
+	}
+	switch hdr.Typeflag {
+	case tar.TypeLink, tar.TypeSymlink:
+		entry.Type = EntryTypeLink
+		entry.LinkTarget = hdr.Linkname
+	}
+	if entry.Type == EntryTypeFile {
+		entry.ContentType = guessContentType(entryPath)
+	}
+	return entry, true
+}
+
+// modeEntryType entry type of a file mode
+func modeEntryType(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return EntryTypeDir
+	case mode&fs.ModeSymlink != 0:
+		return EntryTypeLink
+	case mode.IsRegular():
+		return EntryTypeFile
+	}
+	return EntryTypeOther
+}
+
+// cleanPath normalize an entry name to a slash-separated relative path
+// Backslashes (written by some Windows tools) become slashes and ".." cannot climb above the root.
+func cleanPath(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// guessContentType content type of an entry from its extension
+func guessContentType(entryPath string) string {
+	ext := strings.ToLower(path.Ext(entryPath))
+	if ext == "" {
+		return ""
+	}
+	return mime.TypeByExtension(ext)
+}
@@ -0,0 +1,145 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+)
+
+var testLimits = Limits{MaxEntries: 100, MaxTotalSize: 64 << 20, MaxRatio: 100}
+
+func zipArchive(t *testing.T, method uint16, entries ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(entries); i += 2 {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: entries[i], Method: method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(entries[i+1]))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarArchive(t *testing.T, gzipped bool, entries ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	var zw *gzip.Writer
+	if gzipped {
+		zw = gzip.NewWriter(&buf)
+		out = zw
+	}
+	w := tar.NewWriter(out)
+	for i := 0; i+1 < len(entries); i += 2 {
+		hdr := &tar.Header{Name: entries[i], Mode: 0644, Size: int64(len(entries[i+1])), Typeflag: tar.TypeReg}
+		if entries[i][len(entries[i])-1] == '/' {
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0755
+		}
+		if err := w.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(entries[i+1]))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if zw != nil {
+		zw.Close()
+	}
+	return buf.Bytes()
+}
+
+func TestListAndOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		format  string
+	}{
+		{"zip", zipArchive(t, zip.Deflate, "docs/", "", "docs/readme.txt", "hello", "..\\evil.png", "png"), FormatZip},
+		{"tar", tarArchive(t, false, "docs/", "", "docs/readme.txt", "hello", "../evil.png", "png"), FormatTar},
+		{"tar.gz", tarArchive(t, true, "docs/", "", "docs/readme.txt", "hello", "../evil.png", "png"), FormatTarGz},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := List(tt.content, testLimits)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if index.Format != tt.format || len(index.Entries) != 3 || index.TotalSize != 8 {
+				t.Fatalf("index = %+v", index)
+			}
+			if e := index.Entries[0]; e.Path != "docs" || e.Type != EntryTypeDir {
+				t.Errorf("entry 0 = %+v", e)
+			}
+			if e := index.Entries[1]; e.Path != "docs/readme.txt" || e.Type != EntryTypeFile || e.Size != 5 {
+				t.Errorf("entry 1 = %+v", e)
+			}
+			if e := index.Entries[2]; e.Path != "evil.png" || e.ContentType != "image/png" {
+				t.Errorf("entry 2 = %+v", e)
+			}
+
+			rc, entry, err := Open(tt.content, "/docs/readme.txt", testLimits)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil || string(data) != "hello" || entry.Size != 5 {
+				t.Errorf("Open = %q, %+v, %v", data, entry, err)
+			}
+
+			if _, _, err := Open(tt.content, "docs", testLimits); !errors.Is(err, ErrNotRegularFile) {
+				t.Errorf("Open(dir) error = %v, want ErrNotRegularFile", err)
+			}
+			if _, _, err := Open(tt.content, "missing.txt", testLimits); !errors.Is(err, ErrEntryNotFound) {
+				t.Errorf("Open(missing) error = %v, want ErrEntryNotFound", err)
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	many := make([]string, 0, 20)
+	for i := 0; i < 10; i++ {
+		many = append(many, string(rune('a'+i))+".txt", "x")
+	}
+	zeros := string(make([]byte, 8<<20))
+
+	tests := []struct {
+		name    string
+		content []byte
+		limits  Limits
+		want    error
+	}{
+		{"zip entries", zipArchive(t, zip.Store, many...), Limits{MaxEntries: 5}, ErrTooManyEntries},
+		{"tar entries", tarArchive(t, false, many...), Limits{MaxEntries: 5}, ErrTooManyEntries},
+		{"zip total size", zipArchive(t, zip.Store, "a.bin", zeros), Limits{MaxTotalSize: 1 << 20}, ErrTooLarge},
+		{"tar.gz total size", tarArchive(t, true, "a.bin", zeros), Limits{MaxEntries: 10, MaxTotalSize: 1 << 20}, ErrTooLarge},
+		{"zip ratio", zipArchive(t, zip.Deflate, "a.bin", zeros), testLimits, ErrCompressionRatio},
+		{"tar.gz ratio", tarArchive(t, true, "a.bin", zeros), testLimits, ErrCompressionRatio},
+		{"unsupported", []byte("not an archive"), testLimits, ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := List(tt.content, tt.limits); !errors.Is(err, tt.want) {
+				t.Errorf("List error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// Small, highly compressible entries are not bombs
+	if _, err := List(zipArchive(t, zip.Deflate, "a.txt", zeros[:64<<10]), testLimits); err != nil {
+		t.Errorf("List(small compressible) error = %v", err)
+	}
+}
@@ -39,6 +39,11 @@ func init() {
 // @host      localhost:7281
 // @BasePath  /api/v1
 
+// @securityDefinitions.apikey  AdminToken
+// @in                          header
+// @name                        Authorization
+// @description                 Admin API token, sent as "Bearer <token>"
+
 // @schemes http https
 
 func main() {
@@ -50,6 +55,12 @@ func main() {
 	go indexerService.Start()
 	log.Println("Indexer service started successfully")
 
+	// Start storage integrity scrubber (in goroutine)
+	if conf.Cfg.Indexer.ScrubInterval > 0 {
+		scrubService := indexer_service.NewScrubService(indexerService.GetStorage(), indexerService.GetScanner())
+		go scrubService.Start(time.Duration(conf.Cfg.Indexer.ScrubInterval)*time.Hour, conf.Cfg.Indexer.ScrubRepair)
+	}
+
 	// Start HTTP API service (in goroutine)
 	go startServer(srv)
 	log.Println("Indexer API service started successfully")
@@ -104,6 +115,9 @@ func initAll() (*indexer_service.IndexerService, *http.Server, func()) {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
 	log.Printf("Storage initialized: type=%s", conf.Cfg.Storage.Type)
+	if _, ok := stor.(*storage.CacheStorage); ok {
+		log.Printf("Storage cache enabled: dir=%s, max_size=%dMB", conf.Cfg.Storage.Cache.Dir, conf.Cfg.Storage.Cache.MaxSize)
+	}
 
 	// Create indexer service
 	indexerService, err := indexer_service.NewIndexerService(stor)
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"meta-media-service/conf"
+	"meta-media-service/database"
+	"meta-media-service/indexer"
+	"meta-media-service/service/indexer_service"
+	"meta-media-service/storage"
+)
+
+var (
+	ENV    string
+	CMD    string
+	DryRun bool
+	Repair bool
+)
+
+func init() {
+	flag.StringVar(&ENV, "env", "mainnet", "Environment: loc/mainnet/testnet")
+	flag.StringVar(&CMD, "cmd", "", "Command: compact/scrub/backfill")
+	flag.BoolVar(&DryRun, "dry-run", false, "Only report, do not modify storage or database (compact/backfill)")
+	flag.BoolVar(&Repair, "repair", false, "Re-fetch missing/corrupt objects from chain (scrub)")
+}
+
+// storagectl indexer storage maintenance tool
+// Stop the indexer before running it when using PebbleDB (the database is locked by the running process).
+//
+//	storagectl -env=mainnet -cmd=compact [-dry-run]   migrate existing objects to the content-addressed layout
+//	storagectl -env=mainnet -cmd=scrub [-repair]      verify stored objects against recorded hashes
+//	storagectl -env=mainnet -cmd=backfill [-dry-run]  fill content type detection, image placeholders and perceptual hashes of older records
+func main() {
+	cleanup := initAll()
+
+	exitCode := 0
+	switch CMD {
+	case "compact":
+		runCompact()
+	case "scrub":
+		exitCode = runScrub()
+	case "backfill":
+		exitCode = runBackfill()
+	default:
+		flag.Usage()
+		exitCode = 2
+	}
+
+	cleanup()
+	os.Exit(exitCode)
+}
+
+// runCompact migrate existing per-PIN objects to content-addressed blobs
+func runCompact() {
+	if conf.Cfg.Storage.Layout != storage.LayoutContentAddressed {
+		log.Fatalf("storage.layout is %q, set it to %q before compacting so new PINs use the same layout",
+			conf.Cfg.Storage.Layout, storage.LayoutContentAddressed)
+	}
+
+	stor, err := storage.NewStorage()
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	result, err := indexer_service.NewCompactionService(stor).Compact(DryRun)
+	if err != nil {
+		log.Printf("Compaction stopped: %v", err)
+	}
+	if result != nil {
+		log.Printf("Compaction finished (dry run: %v): scanned=%d, migrated=%d, deduplicated=%d, skipped=%d, failed=%d, bytes_freed=%d",
+			DryRun, result.Scanned, result.Migrated, result.Deduplicated, result.Skipped, result.Failed, result.BytesFreed)
+	}
+}
+
+// runScrub verify stored objects and report missing, corrupt and orphaned ones
+// Returns non-zero exit code when unrepaired problems remain, for use in cron/monitoring
+func runScrub() int {
+	stor, err := storage.NewStorage()
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	// Node connections are only needed to repair objects
+	var scanners []*indexer.BlockScanner
+	if Repair {
+		scanners = chainScanners()
+	}
+
+	result, err := indexer_service.NewScrubService(stor, scanners...).Scrub(Repair)
+	if err != nil {
+		log.Printf("Scrub stopped: %v", err)
+	}
+	if result == nil {
+		return 1
+	}
+
+	for _, issue := range result.Issues {
+		fmt.Printf("%-8s %-6s %-70s %s (repaired: %v) %s\n",
+			issue.Kind, issue.Record, issue.PinID, issue.StoragePath, issue.Repaired, issue.Detail)
+	}
+	if result.Unlisted > 0 {
+		fmt.Printf("... %d more issues, see the log\n", result.Unlisted)
+	}
+	log.Printf("Scrub finished: scanned=%d, ok=%d, skipped=%d, missing=%d, corrupt=%d, orphaned=%d, repaired=%d, repair_failed=%d",
+		result.Scanned, result.OK, result.Skipped, result.Missing, result.Corrupt, result.Orphaned, result.Repaired, result.RepairFailed)
+
+	if result.Missing+result.Corrupt > result.Repaired || result.Orphaned > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runBackfill compute derived fields missing from existing records
+func runBackfill() int {
+	stor, err := storage.NewStorage()
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	// Serve-from-chain files without a cached copy are read from the node
+	result, err := indexer_service.NewBackfillService(stor, chainScanners()...).Backfill(DryRun)
+	if result != nil {
+		log.Printf("Backfill finished (dry run: %v): scanned=%d, pending=%d, updated=%d, undecodable=%d, skipped=%d, failed=%d",
+			DryRun, result.Scanned, result.Pending, result.Updated, result.Undecodable, result.Skipped, result.Failed)
+	}
+	if err != nil {
+		log.Printf("Backfill stopped: %v", err)
+		return 1
+	}
+	if result.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// chainScanners node connections to fetch transactions of indexed records, one per chain
+// Records are read from the node of their ChainName: MVC from chain, BTC from uploader.btc when it is configured.
+func chainScanners() []*indexer.BlockScanner {
+	scanners := []*indexer.BlockScanner{indexer.NewBlockScannerWithChain(
+		conf.Cfg.Chain.RpcUrl,
+		conf.Cfg.Chain.RpcUser,
+		conf.Cfg.Chain.RpcPass,
+		0,
+		conf.Cfg.Indexer.ScanInterval,
+		indexer.ChainTypeMVC,
+	)}
+	if conf.Cfg.Uploader.Btc.RpcUrl != "" {
+		scanners = append(scanners, indexer.NewBlockScannerWithChain(
+			conf.Cfg.Uploader.Btc.RpcUrl,
+			conf.Cfg.Uploader.Btc.RpcUser,
+			conf.Cfg.Uploader.Btc.RpcPass,
+			0,
+			conf.Cfg.Indexer.ScanInterval,
+			indexer.ChainTypeBTC,
+		))
+	}
+	return scanners
+}
+
+// initEnv initialize environment
+func initEnv() {
+	if ENV == "loc" {
+		conf.SystemEnvironmentEnum = conf.LocalEnvironmentEnum
+	} else if ENV == "mainnet" {
+		conf.SystemEnvironmentEnum = conf.MainnetEnvironmentEnum
+	} else if ENV == "testnet" {
+		conf.SystemEnvironmentEnum = conf.TestnetEnvironmentEnum
+	} else if ENV == "example" {
+		conf.SystemEnvironmentEnum = conf.ExampleEnvironmentEnum
+	}
+	fmt.Printf("Environment: %s\n", ENV)
+}
+
+// initAll initialize configuration and database
+func initAll() func() {
+	// Parse command line parameters
+	flag.Parse()
+
+	// Set environment
+	initEnv()
+
+	// Initialize configuration
+	if err := conf.InitConfig(); err != nil {
+		log.Fatalf("Failed to initialize config: %v", err)
+	}
+
+	// Initialize database
+	if err := initDatabase(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	return func() {
+		if database.DB != nil {
+			database.DB.Close()
+		}
+	}
+}
+
+// initDatabase initialize indexer database based on configuration
+func initDatabase() error {
+	dbType := database.DBType(conf.Cfg.Database.IndexerType)
+
+	switch dbType {
+	case database.DBTypePebble:
+		config := &database.PebbleConfig{
+			DataDir: conf.Cfg.Database.DataDir,
+		}
+		return database.InitDatabase(database.DBTypePebble, config)
+
+	default:
+		config := &database.MySQLConfig{
+			DSN:          conf.Cfg.Database.Dsn,
+			MaxOpenConns: conf.Cfg.Database.MaxOpenConns,
+			MaxIdleConns: conf.Cfg.Database.MaxIdleConns,
+		}
+		return database.InitDatabase(database.DBTypeMySQL, config)
+	}
+}
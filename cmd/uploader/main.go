@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"flag"
 	"fmt"
@@ -8,19 +9,25 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"meta-media-service/conf"
 	"meta-media-service/controller"
 	"meta-media-service/database"
+	"meta-media-service/service/upload_service"
 	"meta-media-service/storage"
 )
 
-var ENV string
+var (
+	ENV        string
+	EncryptKey bool
+)
 
 func init() {
 	flag.StringVar(&ENV, "env", "loc", "Environment: loc/mainnet/testnet")
+	flag.BoolVar(&EncryptKey, "encrypt-key", false, "Read a private key (hex) from stdin, print it encrypted with uploader.assistant.master_key and exit")
 }
 
 // @title           Meta Media Uploader API
@@ -38,13 +45,30 @@ func init() {
 // @host      localhost:7282
 // @BasePath  /api/v1
 
+// @securityDefinitions.apikey  AdminToken
+// @in                          header
+// @name                        Authorization
+// @description                 Admin API token, sent as "Bearer <token>"
+
 // @schemes http https
 
 func main() {
+	flag.Parse()
+	if EncryptKey {
+		runEncryptKey()
+		return
+	}
+
 	// Initialize all components
-	srv, cleanup := initAll()
+	tusService, srv, cleanup := initAll()
 	defer cleanup()
 
+	// Start expired resumable upload cleanup (in goroutine)
+	go tusService.Start(time.Hour)
+
+	// Start broadcast transaction tracker (in goroutine)
+	go upload_service.NewTxTracker().Start(time.Duration(conf.Cfg.Uploader.TxTracker.Interval) * time.Second)
+
 	// Start server (in goroutine)
 	go startServer(srv)
 
@@ -76,10 +100,7 @@ func initEnv() {
 }
 
 // initAll initialize all components
-func initAll() (*http.Server, func()) {
-	// Parse command line parameters
-	flag.Parse()
-
+func initAll() (*upload_service.TusService, *http.Server, func()) {
 	// Set environment
 	initEnv()
 
@@ -88,6 +109,9 @@ func initAll() (*http.Server, func()) {
 		log.Fatalf("Failed to initialize config: %v", err)
 	}
 	log.Printf("Configuration loaded: env=%s, net=%s, port=%s", ENV, conf.Cfg.Net, conf.Cfg.UploaderPort)
+	if conf.Cfg.Uploader.Btc.Enabled && conf.Cfg.Uploader.Btc.RevealKeySecret == "" {
+		log.Fatalf("uploader.btc.reveal_key_secret must be set when uploader.btc.enabled is true")
+	}
 
 	// Initialize database (Uploader always uses MySQL)
 	if err := database.InitUploaderDB(); err != nil {
@@ -100,9 +124,15 @@ func initAll() (*http.Server, func()) {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
 	log.Printf("Storage initialized: type=%s", conf.Cfg.Storage.Type)
+	if _, ok := stor.(*storage.CacheStorage); ok {
+		log.Printf("Storage cache enabled: dir=%s, max_size=%dMB", conf.Cfg.Storage.Cache.Dir, conf.Cfg.Storage.Cache.MaxSize)
+	}
+
+	// Create resumable upload service
+	tusService := upload_service.NewTusService(stor)
 
 	// Setup upload service router
-	router := controller.SetupUploadRouter(stor)
+	router := controller.SetupUploadRouter(stor, tusService)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -115,7 +145,27 @@ func initAll() (*http.Server, func()) {
 		database.CloseUploaderDB()
 	}
 
-	return srv, cleanup
+	return tusService, srv, cleanup
+}
+
+// runEncryptKey encrypt a private key for the config, e.g. uploader.sponsor.private_key
+func runEncryptKey() {
+	initEnv()
+	if err := conf.InitConfig(); err != nil {
+		log.Fatalf("Failed to initialize config: %v", err)
+	}
+	if conf.Cfg.Uploader.Assistant.MasterKey == "" {
+		log.Fatalf("uploader.assistant.master_key is not set")
+	}
+	priHex, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && priHex == "" {
+		log.Fatalf("Failed to read private key: %v", err)
+	}
+	encrypted, err := upload_service.EncryptPrivateKey(strings.TrimSpace(priHex))
+	if err != nil {
+		log.Fatalf("Failed to encrypt private key: %v", err)
+	}
+	fmt.Println(encrypted)
 }
 
 // startServer start HTTP server
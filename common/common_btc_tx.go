@@ -0,0 +1,463 @@
+package common
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	btcMaxStandardTxWeight = 400000 // Largest transaction weight relayed by default
+	btcDustLimit           = 546    // Smaller change is left to the fee
+	btcP2wpkhWitnessSize   = 108    // Witness items count 1, signature 1+72, public key 1+33
+	btcP2trWitnessSize     = 66     // Witness items count 1, Schnorr signature 1+64
+	btcMaxDataPush         = 520    // Largest script element
+)
+
+var (
+	ErrBtcNonSegwitInput      = errors.New("commit inputs must be P2WPKH or P2TR")
+	ErrBtcInsufficientFunds   = errors.New("insufficient funds")
+	ErrBtcInscriptionTooLarge = errors.New("inscription exceeds the standard transaction weight")
+)
+
+// BtcUtxo spendable output of the payer
+type BtcUtxo struct {
+	TxId     string
+	Vout     uint32
+	Amount   int64
+	PkScript []byte
+}
+
+// BtcInscription commit and reveal transactions of a MetaID witness inscription
+// The commit transaction pays to a taproot output whose only script leaf carries the MetaID
+// envelope. The reveal transaction spends it to the owner address with a one-time key. The reveal
+// is signed as soon as the commit transaction is built, the key is kept to sign it again when the
+// commit is rebuilt.
+type BtcInscription struct {
+	CommitTx       *wire.MsgTx       // Commit transaction, inputs unsigned
+	CommitPrevOuts []*wire.TxOut     // Outputs spent by the commit inputs, in input order
+	RevealTx       *wire.MsgTx       // Signed reveal transaction, the PIN is at vout 0
+	RevealKey      *btcec.PrivateKey // One-time key of the script path
+	CommitFee      int64             // Commit fee (satoshis)
+	RevealFee      int64             // Reveal fee (satoshis)
+	CommitVsize    int64             // Commit virtual size once signed (estimated)
+	RevealVsize    int64             // Reveal virtual size
+}
+
+// BuildBtcMetaIdInscription build the commit and reveal transactions of a MetaID PIN on BTC
+// Inputs are selected from utxos (largest first) and must be segwit, the commit txid does not
+// change when they are signed. postage goes to ownerAddress with the PIN, change to changeAddress.
+func BuildBtcMetaIdInscription(netParam *chaincfg.Params, utxos []*BtcUtxo, ownerAddress, changeAddress, operation, path, contentType string, content []byte, postage, feeRate int64) (*BtcInscription, error) {
+	// One-time key of the script path, it is only used to sign the reveal transaction
+	revealKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return buildBtcMetaIdInscription(netParam, utxos, ownerAddress, changeAddress, operation, path, contentType, content, postage, feeRate, revealKey)
+}
+
+// buildBtcMetaIdInscription build the inscription transactions with the given reveal key
+func buildBtcMetaIdInscription(netParam *chaincfg.Params, utxos []*BtcUtxo, ownerAddress, changeAddress, operation, path, contentType string, content []byte, postage, feeRate int64, revealKey *btcec.PrivateKey) (*BtcInscription, error) {
+	pins := []*BtcInscriptionContent{{Operation: operation, Path: path, ContentType: contentType, Content: content}}
+	batch, err := buildBtcMetaIdInscriptions(netParam, utxos, ownerAddress, changeAddress, pins, postage, feeRate, []*btcec.PrivateKey{revealKey})
+	if err != nil {
+		return nil, err
+	}
+	return &BtcInscription{
+		CommitTx:       batch.CommitTx,
+		CommitPrevOuts: batch.CommitPrevOuts,
+		RevealTx:       batch.Reveals[0].Tx,
+		RevealKey:      revealKey,
+		CommitFee:      batch.CommitFee,
+		RevealFee:      batch.Reveals[0].Fee,
+		CommitVsize:    batch.CommitVsize,
+		RevealVsize:    batch.Reveals[0].Vsize,
+	}, nil
+}
+
+// BtcInscriptionContent MetaID PIN carried by an inscription
+type BtcInscriptionContent struct {
+	Operation   string // create/modify/revoke, defaults to create
+	Path        string // MetaID path
+	ContentType string // Content type
+	Content     []byte // Content
+}
+
+// BtcReveal reveal transaction of one PIN of a batch
+type BtcReveal struct {
+	Tx    *wire.MsgTx       // Signed reveal transaction, the PIN is at vout 0
+	Key   *btcec.PrivateKey // One-time key of the script path
+	Fee   int64             // Reveal fee (satoshis)
+	Vsize int64             // Reveal virtual size
+}
+
+// BtcInscriptionBatch reveal transactions of several PINs funded by one commit transaction
+// The commit pays one taproot output per PIN, in order, and each reveal spends its own output. The
+// MetaID indexer gives every PIN of a reveal the ID i0, so PINs are not packed into one reveal.
+type BtcInscriptionBatch struct {
+	CommitTx       *wire.MsgTx   // Commit transaction, inputs unsigned
+	CommitPrevOuts []*wire.TxOut // Outputs spent by the commit inputs, in input order
+	Reveals        []*BtcReveal  // Reveal transactions in PIN order, reveal i spends commit output i
+	CommitFee      int64         // Commit fee (satoshis)
+	CommitVsize    int64         // Commit virtual size once signed (estimated)
+}
+
+// BuildBtcMetaIdInscriptions build one commit transaction and the reveal transactions of several MetaID PINs
+// Every PIN gets its own one-time key and postage output to ownerAddress, inputs are selected as for
+// BuildBtcMetaIdInscription.
+func BuildBtcMetaIdInscriptions(netParam *chaincfg.Params, utxos []*BtcUtxo, ownerAddress, changeAddress string, pins []*BtcInscriptionContent, postage, feeRate int64) (*BtcInscriptionBatch, error) {
+	revealKeys := make([]*btcec.PrivateKey, len(pins))
+	for i := range pins {
+		key, err := btcec.NewPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		revealKeys[i] = key
+	}
+	return buildBtcMetaIdInscriptions(netParam, utxos, ownerAddress, changeAddress, pins, postage, feeRate, revealKeys)
+}
+
+// buildBtcMetaIdInscriptions build the inscription transactions of several PINs with the given reveal keys
+func buildBtcMetaIdInscriptions(netParam *chaincfg.Params, utxos []*BtcUtxo, ownerAddress, changeAddress string, pins []*BtcInscriptionContent, postage, feeRate int64, revealKeys []*btcec.PrivateKey) (*BtcInscriptionBatch, error) {
+	if len(pins) == 0 || len(pins) != len(revealKeys) {
+		return nil, errors.New("every PIN needs one reveal key")
+	}
+	ownerScript, err := BtcAddressScript(netParam, ownerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner address: %w", err)
+	}
+	changeScript, err := BtcAddressScript(netParam, changeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid change address: %w", err)
+	}
+	for _, utxo := range utxos {
+		if btcInputWitnessSize(utxo.PkScript) == 0 {
+			return nil, fmt.Errorf("%w: %s:%d", ErrBtcNonSegwitInput, utxo.TxId, utxo.Vout)
+		}
+	}
+
+	reveals := make([]*BtcReveal, len(pins))
+	commitOuts := make([]*wire.TxOut, len(pins))
+	for i, pin := range pins {
+		operation := pin.Operation
+		if operation == "" {
+			operation = "create"
+		}
+		revealKey := revealKeys[i]
+		script, err := buildBtcInscriptionScript(revealKey.PubKey(), operation, pin.Path, pin.ContentType, pin.Content)
+		if err != nil {
+			return nil, err
+		}
+		commitScript, controlBlockBytes, err := btcInscriptionTaproot(revealKey.PubKey(), script)
+		if err != nil {
+			return nil, err
+		}
+
+		// Reveal size with a placeholder signature, Schnorr signatures have a fixed size
+		reveal := wire.NewMsgTx(2)
+		reveal.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0), nil, nil))
+		reveal.AddTxOut(wire.NewTxOut(postage, ownerScript))
+		reveal.TxIn[0].Witness = wire.TxWitness{make([]byte, schnorr.SignatureSize), script, controlBlockBytes}
+		if weight := btcTxWeight(reveal); weight > btcMaxStandardTxWeight {
+			return nil, fmt.Errorf("%w: weight %d, max %d", ErrBtcInscriptionTooLarge, weight, btcMaxStandardTxWeight)
+		}
+		revealVsize := BtcTxVsize(reveal)
+		reveals[i] = &BtcReveal{Tx: reveal, Key: revealKey, Fee: revealVsize * feeRate, Vsize: revealVsize}
+		commitOuts[i] = wire.NewTxOut(postage+reveals[i].Fee, commitScript)
+	}
+
+	commit, prevOuts, commitFee, commitVsize, err := fundBtcCommit(utxos, commitOuts, changeScript, feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign the reveals now, the commit txid is fixed
+	for _, reveal := range reveals {
+		if err := SignBtcReveal(reveal.Tx, commit, reveal.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	return &BtcInscriptionBatch{
+		CommitTx:       commit,
+		CommitPrevOuts: prevOuts,
+		Reveals:        reveals,
+		CommitFee:      commitFee,
+		CommitVsize:    commitVsize,
+	}, nil
+}
+
+// SignBtcReveal point the reveal transaction at the inscription output of a commit transaction and sign it
+// The tapscript leaf and control block are taken from the reveal witness, so a reveal can be signed again
+// for a commit rebuilt with other inputs.
+func SignBtcReveal(reveal, commit *wire.MsgTx, revealKey *btcec.PrivateKey) error {
+	if len(reveal.TxIn) != 1 || len(reveal.TxIn[0].Witness) != 3 {
+		return errors.New("reveal transaction has no script path witness")
+	}
+	script := reveal.TxIn[0].Witness[1]
+	commitScript, controlBlockBytes, err := btcInscriptionTaproot(revealKey.PubKey(), script)
+	if err != nil {
+		return err
+	}
+	vout := -1
+	for i, out := range commit.TxOut {
+		if bytes.Equal(out.PkScript, commitScript) {
+			vout = i
+			break
+		}
+	}
+	if vout < 0 {
+		return errors.New("commit transaction does not pay the inscription output")
+	}
+	commitValue := commit.TxOut[vout].Value
+
+	commitHash := commit.TxHash()
+	reveal.TxIn[0].PreviousOutPoint = *wire.NewOutPoint(&commitHash, uint32(vout))
+	fetcher := txscript.NewCannedPrevOutputFetcher(commitScript, commitValue)
+	sig, err := txscript.RawTxInTapscriptSignature(reveal, txscript.NewTxSigHashes(reveal, fetcher), 0,
+		commitValue, commitScript, txscript.NewBaseTapLeaf(script), txscript.SigHashDefault, revealKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign reveal transaction: %w", err)
+	}
+	reveal.TxIn[0].Witness = wire.TxWitness{sig, script, controlBlockBytes}
+	return nil
+}
+
+// btcInscriptionTaproot commit output script and control block of an inscription script under the reveal key
+func btcInscriptionTaproot(pubKey *btcec.PublicKey, script []byte) ([]byte, []byte, error) {
+	tree := txscript.AssembleTaprootScriptTree(txscript.NewBaseTapLeaf(script))
+	rootHash := tree.RootNode.TapHash()
+	commitScript, err := txscript.PayToTaprootScript(txscript.ComputeTaprootOutputKey(pubKey, rootHash[:]))
+	if err != nil {
+		return nil, nil, err
+	}
+	controlBlock := tree.LeafMerkleProofs[0].ToControlBlock(pubKey)
+	controlBlockBytes, err := controlBlock.ToBytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	return commitScript, controlBlockBytes, nil
+}
+
+// fundBtcCommit build the commit transaction paying the inscription outputs from utxos
+// Returns the transaction, the spent outputs, the fee and the estimated signed virtual size.
+func fundBtcCommit(utxos []*BtcUtxo, commitOuts []*wire.TxOut, changeScript []byte, feeRate int64) (*wire.MsgTx, []*wire.TxOut, int64, int64, error) {
+	pool := append([]*BtcUtxo(nil), utxos...)
+	sort.SliceStable(pool, func(i, j int) bool {
+		return pool[i].Amount > pool[j].Amount
+	})
+
+	commit := wire.NewMsgTx(2)
+	var commitValue int64
+	for _, out := range commitOuts {
+		commit.AddTxOut(out)
+		commitValue += out.Value
+	}
+	var (
+		prevOuts    []*wire.TxOut
+		total       int64
+		witnessSize int64
+	)
+	for _, utxo := range pool {
+		hash, err := chainhash.NewHashFromStr(utxo.TxId)
+		if err != nil {
+			return nil, nil, 0, 0, fmt.Errorf("invalid UTXO txid %s: %w", utxo.TxId, err)
+		}
+		commit.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, utxo.Vout), nil, nil))
+		prevOuts = append(prevOuts, wire.NewTxOut(utxo.Amount, utxo.PkScript))
+		total += utxo.Amount
+		witnessSize += btcInputWitnessSize(utxo.PkScript)
+
+		vsize := btcEstimateVsize(commit, witnessSize)
+		if total < commitValue+vsize*feeRate {
+			continue
+		}
+		change := wire.NewTxOut(0, changeScript)
+		vsizeWithChange := vsize + int64(change.SerializeSize())
+		if change.Value = total - commitValue - vsizeWithChange*feeRate; change.Value >= btcDustLimit {
+			commit.AddTxOut(change)
+			return commit, prevOuts, vsizeWithChange * feeRate, vsizeWithChange, nil
+		}
+		return commit, prevOuts, total - commitValue, vsize, nil
+	}
+	return nil, nil, 0, 0, fmt.Errorf("%w: commit needs %d satoshis plus fee, inputs have %d", ErrBtcInsufficientFunds, commitValue, total)
+}
+
+// buildBtcInscriptionScript tapscript leaf: <pubkey> OP_CHECKSIG OP_FALSE OP_IF "metaid" <operation> <path>
+// <encryption> <version> <content-type> <payload>... OP_ENDIF
+func buildBtcInscriptionScript(pubKey *btcec.PublicKey, operation, path, contentType string, content []byte) ([]byte, error) {
+	header, err := txscript.NewScriptBuilder().
+		AddData(schnorr.SerializePubKey(pubKey)).
+		AddOp(txscript.OP_CHECKSIG).
+		AddOp(txscript.OP_FALSE).
+		AddOp(txscript.OP_IF).
+		AddData([]byte("metaid")).    // <metaid_flag>
+		AddData([]byte(operation)).   // <operation>
+		AddData([]byte(path)).        // <path>
+		AddData([]byte("0")).         // <Encryption>
+		AddData([]byte("1.0.0")).     // <version>
+		AddData([]byte(contentType)). // <content-type>
+		Script()
+	if err != nil {
+		return nil, err
+	}
+
+	// The builder caps scripts at 10000 bytes, tapscript has no such limit, so the payload is appended
+	script := header
+	for i := 0; i < len(content); i += btcMaxDataPush {
+		end := i + btcMaxDataPush
+		if end > len(content) {
+			end = len(content)
+		}
+		push, err := txscript.NewScriptBuilder().AddFullData(content[i:end]).Script() // <payload>
+		if err != nil {
+			return nil, err
+		}
+		script = append(script, push...)
+	}
+	return append(script, txscript.OP_ENDIF), nil
+}
+
+// BtcCommitPsbt PSBT (base64) of a commit transaction for the payer wallet to sign
+func BtcCommitPsbt(commit *wire.MsgTx, prevOuts []*wire.TxOut) (string, error) {
+	packet, err := psbt.NewFromUnsignedTx(commit)
+	if err != nil {
+		return "", err
+	}
+	for i, prevOut := range prevOuts {
+		packet.Inputs[i].WitnessUtxo = prevOut
+		packet.Inputs[i].SighashType = txscript.SigHashAll
+		if txscript.IsPayToTaproot(prevOut.PkScript) {
+			packet.Inputs[i].SighashType = txscript.SigHashDefault
+		}
+	}
+	return packet.B64Encode()
+}
+
+// ParseBtcPsbt parse a PSBT given as base64 or hex
+func ParseBtcPsbt(encoded string) (*psbt.Packet, error) {
+	encoded = strings.TrimSpace(encoded)
+	raw, err := hex.DecodeString(encoded)
+	if err != nil {
+		if raw, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, fmt.Errorf("PSBT is neither hex nor base64")
+		}
+	}
+	return psbt.NewFromRawBytes(bytes.NewReader(raw), false)
+}
+
+// ExtractBtcSignedTx final transaction from a signed PSBT (base64 or hex) or a signed raw transaction (hex)
+// Returns the outputs the PSBT declares as spent, in input order, nil for a raw transaction.
+func ExtractBtcSignedTx(signed string) (*wire.MsgTx, []*wire.TxOut, error) {
+	signed = strings.TrimSpace(signed)
+	if packet, err := ParseBtcPsbt(signed); err == nil {
+		var prevOuts []*wire.TxOut
+		for _, in := range packet.Inputs {
+			if in.WitnessUtxo == nil {
+				prevOuts = nil
+				break
+			}
+			prevOuts = append(prevOuts, in.WitnessUtxo)
+		}
+		if err := psbt.MaybeFinalizeAll(packet); err != nil {
+			return nil, nil, fmt.Errorf("failed to finalize PSBT: %w", err)
+		}
+		tx, err := psbt.Extract(packet)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tx, prevOuts, nil
+	}
+	raw, err := hex.DecodeString(signed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("signed transaction is neither a PSBT nor a raw transaction")
+	}
+	tx := wire.NewMsgTx(2)
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, nil, fmt.Errorf("failed to deserialize transaction: %w", err)
+	}
+	return tx, nil, nil
+}
+
+// VerifyBtcInputs run the scripts of every input of a signed transaction against the outputs it spends
+func VerifyBtcInputs(tx *wire.MsgTx, prevOuts []*wire.TxOut) error {
+	if len(prevOuts) != len(tx.TxIn) {
+		return fmt.Errorf("transaction has %d inputs, expected %d", len(tx.TxIn), len(prevOuts))
+	}
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, in := range tx.TxIn {
+		fetcher.AddPrevOut(in.PreviousOutPoint, prevOuts[i])
+	}
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+	for i, prevOut := range prevOuts {
+		vm, err := txscript.NewEngine(prevOut.PkScript, tx, i, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, fetcher)
+		if err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+		if err := vm.Execute(); err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// BtcAddressScript locking script of a BTC address (P2PKH, P2SH, P2WPKH, P2WSH or P2TR)
+func BtcAddressScript(netParam *chaincfg.Params, address string) ([]byte, error) {
+	addr, err := btcutil.DecodeAddress(address, netParam)
+	if err != nil {
+		return nil, err
+	}
+	if !addr.IsForNet(netParam) {
+		return nil, fmt.Errorf("address %s is not for %s", address, netParam.Name)
+	}
+	return txscript.PayToAddrScript(addr)
+}
+
+// BtcTxToHex serialize a BTC transaction (with witness) to hex
+func BtcTxToHex(tx *wire.MsgTx) (string, error) {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// BtcTxVsize virtual size of a transaction with its witness
+func BtcTxVsize(tx *wire.MsgTx) int64 {
+	return (btcTxWeight(tx) + 3) / 4
+}
+
+// btcTxWeight weight of a transaction with its witness
+func btcTxWeight(tx *wire.MsgTx) int64 {
+	return int64(tx.SerializeSizeStripped()*3 + tx.SerializeSize())
+}
+
+// btcEstimateVsize virtual size of an unsigned segwit transaction once its inputs carry witnessSize bytes
+func btcEstimateVsize(tx *wire.MsgTx, witnessSize int64) int64 {
+	weight := int64(tx.SerializeSizeStripped())*4 + 2 + witnessSize // Marker and flag
+	return (weight + 3) / 4
+}
+
+// btcInputWitnessSize witness size of a signed input spending pkScript, 0 if it is not segwit
+func btcInputWitnessSize(pkScript []byte) int64 {
+	switch {
+	case txscript.IsPayToWitnessPubKeyHash(pkScript):
+		return btcP2wpkhWitnessSize
+	case txscript.IsPayToTaproot(pkScript):
+		return btcP2trWitnessSize
+	}
+	return 0
+}
@@ -0,0 +1,320 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// testBtcKey fixed private key, every byte set to b
+func testBtcKey(b byte) *btcec.PrivateKey {
+	key, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{b}, 32))
+	return key
+}
+
+// testBtcAddress P2WPKH testnet address and script of a key
+func testBtcAddress(t *testing.T, key *btcec.PrivateKey) (string, []byte) {
+	t.Helper()
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(key.PubKey().SerializeCompressed()), &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewAddressWitnessPubKeyHash() failed, err: %v", err)
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript() failed, err: %v", err)
+	}
+	return addr.EncodeAddress(), script
+}
+
+// testBtcUtxo payer output with a fixed txid
+func testBtcUtxo(b byte, amount int64, pkScript []byte) *BtcUtxo {
+	return &BtcUtxo{TxId: strings.Repeat(string("0123456789abcdef"[b%16]), 64), Vout: uint32(b), Amount: amount, PkScript: pkScript}
+}
+
+func buildTestInscription(t *testing.T, content []byte) (*BtcInscription, []byte) {
+	t.Helper()
+	address, script := testBtcAddress(t, testBtcKey(1))
+	utxos := []*BtcUtxo{testBtcUtxo(1, 100000, script)}
+	inscription, err := buildBtcMetaIdInscription(&chaincfg.TestNet3Params, utxos, address, address,
+		"create", "/file", "image/png;binary", content, 546, 2, testBtcKey(2))
+	if err != nil {
+		t.Fatalf("buildBtcMetaIdInscription() failed, err: %v", err)
+	}
+	return inscription, script
+}
+
+func TestBuildBtcMetaIdInscription(t *testing.T) {
+	content := []byte("hello metaid")
+	inscription, ownerScript := buildTestInscription(t, content)
+	again, _ := buildTestInscription(t, content)
+
+	commit, reveal := inscription.CommitTx, inscription.RevealTx
+	if commit.TxHash() != again.CommitTx.TxHash() || reveal.TxHash() != again.RevealTx.TxHash() {
+		t.Error("building with the same key and inputs should give the same transactions")
+	}
+	if !bytes.Equal(reveal.TxIn[0].Witness[0], again.RevealTx.TxIn[0].Witness[0]) {
+		t.Error("reveal signature should be deterministic")
+	}
+
+	if got, want := inscription.RevealFee, inscription.RevealVsize*2; got != want {
+		t.Errorf("RevealFee = %d, want %d", got, want)
+	}
+	if got, want := commit.TxOut[0].Value, int64(546)+inscription.RevealFee; got != want {
+		t.Errorf("commit output = %d, want postage plus reveal fee %d", got, want)
+	}
+	if got := BtcTxVsize(reveal); got != inscription.RevealVsize {
+		t.Errorf("signed reveal vsize = %d, estimated %d", got, inscription.RevealVsize)
+	}
+	if len(reveal.TxOut) != 1 || reveal.TxOut[0].Value != 546 || !bytes.Equal(reveal.TxOut[0].PkScript, ownerScript) {
+		t.Errorf("reveal should pay the postage to the owner, got %+v", reveal.TxOut)
+	}
+	commitHash := commit.TxHash()
+	if got := reveal.TxIn[0].PreviousOutPoint; got != *wire.NewOutPoint(&commitHash, 0) {
+		t.Errorf("reveal spends %v, want the commit output", got)
+	}
+	if err := VerifyBtcInputs(reveal, []*wire.TxOut{commit.TxOut[0]}); err != nil {
+		t.Errorf("reveal script path should verify, err: %v", err)
+	}
+	if len(inscription.CommitPrevOuts) != len(commit.TxIn) {
+		t.Errorf("commit has %d inputs and %d spent outputs", len(commit.TxIn), len(inscription.CommitPrevOuts))
+	}
+	if !inscription.RevealKey.Key.Equals(&testBtcKey(2).Key) {
+		t.Error("inscription should keep the reveal key")
+	}
+}
+
+func TestBtcRevealWitness(t *testing.T) {
+	content := bytes.Repeat([]byte{0xab}, 2*btcMaxDataPush+100)
+	inscription, _ := buildTestInscription(t, content)
+	witness := inscription.RevealTx.TxIn[0].Witness
+	if len(witness) != 3 || len(witness[0]) != schnorr.SignatureSize {
+		t.Fatalf("witness should be <signature> <script> <control block>, got %d items", len(witness))
+	}
+
+	revealPubKey := testBtcKey(2).PubKey()
+	want, err := buildBtcInscriptionScript(revealPubKey, "create", "/file", "image/png;binary", content)
+	if err != nil {
+		t.Fatalf("buildBtcInscriptionScript() failed, err: %v", err)
+	}
+	if !bytes.Equal(witness[1], want) {
+		t.Error("witness script is not the inscription script")
+	}
+
+	// <pubkey> OP_CHECKSIG OP_FALSE OP_IF "metaid" <operation> <path> <encryption> <version> <content-type> <payload>... OP_ENDIF
+	var (
+		ops     []byte
+		pushes  [][]byte
+		payload []byte
+	)
+	tokenizer := txscript.MakeScriptTokenizer(0, witness[1])
+	for tokenizer.Next() {
+		ops = append(ops, tokenizer.Opcode())
+		if data := tokenizer.Data(); data != nil {
+			pushes = append(pushes, data)
+		}
+	}
+	if err := tokenizer.Err(); err != nil {
+		t.Fatalf("failed to parse witness script, err: %v", err)
+	}
+	if ops[1] != txscript.OP_CHECKSIG || ops[2] != txscript.OP_FALSE || ops[3] != txscript.OP_IF || ops[len(ops)-1] != txscript.OP_ENDIF {
+		t.Errorf("unexpected envelope opcodes %v", ops)
+	}
+	if !bytes.Equal(pushes[0], schnorr.SerializePubKey(revealPubKey)) {
+		t.Error("script should be locked to the reveal key")
+	}
+	fields := []string{"metaid", "create", "/file", "0", "1.0.0", "image/png;binary"}
+	for i, field := range fields {
+		if string(pushes[i+1]) != field {
+			t.Errorf("field %d = %q, want %q", i, pushes[i+1], field)
+		}
+	}
+	for _, push := range pushes[len(fields)+1:] {
+		if len(push) > btcMaxDataPush {
+			t.Errorf("payload push of %d bytes exceeds %d", len(push), btcMaxDataPush)
+		}
+		payload = append(payload, push...)
+	}
+	if !bytes.Equal(payload, content) {
+		t.Error("payload pushes do not add up to the content")
+	}
+
+	controlBlock, err := txscript.ParseControlBlock(witness[2])
+	if err != nil {
+		t.Fatalf("ParseControlBlock() failed, err: %v", err)
+	}
+	if !controlBlock.InternalKey.IsEqual(revealPubKey) || controlBlock.LeafVersion != txscript.BaseLeafVersion {
+		t.Error("control block should carry the reveal key and the base leaf version")
+	}
+}
+
+func TestFundBtcCommit(t *testing.T) {
+	_, script := testBtcAddress(t, testBtcKey(1))
+	commitScript := bytes.Repeat([]byte{0x51}, 34) // P2TR sized output
+	const commitValue, feeRate = 10000, 2
+	// One P2WPKH input: 122 vbytes, 153 with a P2WPKH change output. Two inputs: 190 and 221.
+	tests := []struct {
+		name       string
+		amounts    []int64
+		wantInputs int
+		wantFee    int64
+		wantChange int64 // 0 when there is no change output
+		wantErr    error
+	}{
+		{"change", []int64{100000}, 1, 306, 100000 - commitValue - 306, nil},
+		{"smallest change", []int64{commitValue + 306 + btcDustLimit}, 1, 306, btcDustLimit, nil},
+		{"dust change left to fee", []int64{commitValue + 306 + btcDustLimit - 1}, 1, 306 + btcDustLimit - 1, 0, nil},
+		{"no change needed", []int64{commitValue + 244}, 1, 244, 0, nil},
+		{"insufficient funds", []int64{commitValue + 243}, 0, 0, 0, ErrBtcInsufficientFunds},
+		{"largest first", []int64{5000, 6000}, 2, 442, 11000 - commitValue - 442, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var utxos []*BtcUtxo
+			for i, amount := range tt.amounts {
+				utxos = append(utxos, testBtcUtxo(byte(i), amount, script))
+			}
+			commitOuts := []*wire.TxOut{wire.NewTxOut(commitValue, commitScript)}
+			commit, prevOuts, fee, vsize, err := fundBtcCommit(utxos, commitOuts, script, feeRate)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("fundBtcCommit() err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fundBtcCommit() failed, err: %v", err)
+			}
+			if len(commit.TxIn) != tt.wantInputs || len(prevOuts) != tt.wantInputs {
+				t.Errorf("inputs = %d, want %d", len(commit.TxIn), tt.wantInputs)
+			}
+			if fee != tt.wantFee {
+				t.Errorf("fee = %d, want %d", fee, tt.wantFee)
+			}
+			if fee < vsize*feeRate {
+				t.Errorf("fee %d is below %d vbytes at %d sat/vB", fee, vsize, feeRate)
+			}
+			var change int64
+			if len(commit.TxOut) == 2 {
+				change = commit.TxOut[1].Value
+			}
+			if change != tt.wantChange {
+				t.Errorf("change = %d, want %d", change, tt.wantChange)
+			}
+			var total int64
+			for _, prevOut := range prevOuts {
+				total += prevOut.Value
+			}
+			if total != commitValue+change+fee {
+				t.Errorf("inputs %d != commit %d + change %d + fee %d", total, commitValue, change, fee)
+			}
+		})
+	}
+}
+
+func TestBuildBtcMetaIdInscriptionRejectsLegacyInput(t *testing.T) {
+	address, _ := testBtcAddress(t, testBtcKey(1))
+	legacy, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).
+		AddData(make([]byte, 20)).AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).Script()
+	_, err := buildBtcMetaIdInscription(&chaincfg.TestNet3Params, []*BtcUtxo{testBtcUtxo(1, 100000, legacy)}, address, address,
+		"create", "/file", "text/plain", []byte("x"), 546, 2, testBtcKey(2))
+	if !errors.Is(err, ErrBtcNonSegwitInput) {
+		t.Errorf("buildBtcMetaIdInscription() err = %v, want %v", err, ErrBtcNonSegwitInput)
+	}
+}
+
+func TestSignBtcRevealRebuiltCommit(t *testing.T) {
+	inscription, script := buildTestInscription(t, []byte("hello metaid"))
+	built := inscription.CommitTx
+
+	// The wallet rebuilt the commit with another input and put the inscription output second
+	rebuilt := wire.NewMsgTx(2)
+	rebuilt.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{9}, 3), nil, nil))
+	rebuilt.AddTxOut(wire.NewTxOut(5000, script))
+	rebuilt.AddTxOut(built.TxOut[0])
+
+	reveal := inscription.RevealTx.Copy()
+	if err := SignBtcReveal(reveal, rebuilt, testBtcKey(2)); err != nil {
+		t.Fatalf("SignBtcReveal() failed, err: %v", err)
+	}
+	rebuiltHash := rebuilt.TxHash()
+	if got := reveal.TxIn[0].PreviousOutPoint; got != *wire.NewOutPoint(&rebuiltHash, 1) {
+		t.Errorf("reveal spends %v, want the inscription output of the rebuilt commit", got)
+	}
+	if reveal.TxHash() == inscription.RevealTx.TxHash() {
+		t.Error("reveal txid should follow the commit")
+	}
+	if err := VerifyBtcInputs(reveal, []*wire.TxOut{built.TxOut[0]}); err != nil {
+		t.Errorf("signed reveal should verify, err: %v", err)
+	}
+
+	other := wire.NewMsgTx(2)
+	other.AddTxOut(wire.NewTxOut(built.TxOut[0].Value, script))
+	if err := SignBtcReveal(inscription.RevealTx.Copy(), other, testBtcKey(2)); err == nil {
+		t.Error("SignBtcReveal() should fail when the commit does not pay the inscription output")
+	}
+	if err := SignBtcReveal(inscription.RevealTx.Copy(), rebuilt, testBtcKey(3)); err == nil {
+		t.Error("SignBtcReveal() should fail with another key")
+	}
+}
+
+func TestBuildBtcMetaIdInscriptions(t *testing.T) {
+	address, script := testBtcAddress(t, testBtcKey(1))
+	utxos := []*BtcUtxo{testBtcUtxo(1, 100000, script)}
+	pins := []*BtcInscriptionContent{
+		{Path: "/file", ContentType: "image/png;binary", Content: []byte("first")},
+		{Path: "/file", ContentType: "image/png;binary", Content: []byte("second")},
+		{Operation: "modify", Path: "/file", ContentType: "text/plain", Content: []byte("third")},
+	}
+	keys := []*btcec.PrivateKey{testBtcKey(2), testBtcKey(3), testBtcKey(4)}
+	batch, err := buildBtcMetaIdInscriptions(&chaincfg.TestNet3Params, utxos, address, address, pins, 546, 2, keys)
+	if err != nil {
+		t.Fatalf("buildBtcMetaIdInscriptions() failed, err: %v", err)
+	}
+
+	commit := batch.CommitTx
+	if len(commit.TxOut) != len(pins)+1 {
+		t.Fatalf("commit has %d outputs, want one per PIN plus change", len(commit.TxOut))
+	}
+	commitHash := commit.TxHash()
+	var inscribed int64
+	seen := make(map[chainhash.Hash]bool)
+	for i, reveal := range batch.Reveals {
+		if got := reveal.Tx.TxIn[0].PreviousOutPoint; got != *wire.NewOutPoint(&commitHash, uint32(i)) {
+			t.Errorf("reveal %d spends %v, want commit output %d", i, got, i)
+		}
+		if got, want := commit.TxOut[i].Value, 546+reveal.Fee; got != want {
+			t.Errorf("commit output %d = %d, want postage plus reveal fee %d", i, got, want)
+		}
+		if err := VerifyBtcInputs(reveal.Tx, []*wire.TxOut{commit.TxOut[i]}); err != nil {
+			t.Errorf("reveal %d should verify, err: %v", i, err)
+		}
+		if seen[reveal.Tx.TxHash()] {
+			t.Errorf("reveal %d repeats an earlier reveal", i)
+		}
+		seen[reveal.Tx.TxHash()] = true
+		inscribed += commit.TxOut[i].Value
+	}
+	if got := string(batch.Reveals[2].Tx.TxIn[0].Witness[1]); !strings.Contains(got, "modify") {
+		t.Error("reveal should carry the operation of its PIN")
+	}
+	change := commit.TxOut[len(pins)].Value
+	if total := utxos[0].Amount; total != inscribed+change+batch.CommitFee {
+		t.Errorf("inputs %d != inscriptions %d + change %d + fee %d", total, inscribed, change, batch.CommitFee)
+	}
+	if batch.CommitFee != batch.CommitVsize*2 {
+		t.Errorf("commit fee = %d, want %d vbytes at 2 sat/vB", batch.CommitFee, batch.CommitVsize)
+	}
+
+	if _, err := buildBtcMetaIdInscriptions(&chaincfg.TestNet3Params, utxos, address, address, pins, 546, 2, keys[:2]); err == nil {
+		t.Error("buildBtcMetaIdInscriptions() should fail without a key per PIN")
+	}
+}
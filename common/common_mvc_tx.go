@@ -405,3 +405,85 @@ func getTxNewRawByte(tx *wire2.MsgTx) []byte {
 	newRawTxByte = append(newRawTxByte, tool.SHA256(newOutputsByte)...)
 	return newRawTxByte
 }
+
+// CalcMvcSigHashPreimage build the signature hash preimage of a transaction input (BIP143 with fork ID)
+// The wallet signs double SHA256 of the preimage, hashType is SigHashAll|SigHashForkID for P2PKH inputs.
+func CalcMvcSigHashPreimage(tx *wire2.MsgTx, idx int, subScript []byte, amount int64, hashType txscript2.SigHashType) ([]byte, error) {
+	if idx < 0 || idx > len(tx.TxIn)-1 {
+		return nil, fmt.Errorf("idx %d but %d txins", idx, len(tx.TxIn))
+	}
+	if hashType&txscript2.SigHashForkID != txscript2.SigHashForkID {
+		return nil, errors.New("sighash type without fork id is not supported")
+	}
+	if hashType&0x1f != txscript2.SigHashAll || hashType&txscript2.SigHashAnyOneCanPay != 0 {
+		return nil, errors.New("only SIGHASH_ALL is supported")
+	}
+	sigHashes := txscript2.NewTxSigHashes(tx)
+
+	var preimage bytes.Buffer
+	var buf4 [4]byte
+	var buf8 [8]byte
+
+	binary.LittleEndian.PutUint32(buf4[:], uint32(tx.Version))
+	preimage.Write(buf4[:])
+	preimage.Write(sigHashes.HashPrevOuts[:])
+	preimage.Write(sigHashes.HashSequence[:])
+
+	preimage.Write(tx.TxIn[idx].PreviousOutPoint.Hash[:])
+	binary.LittleEndian.PutUint32(buf4[:], tx.TxIn[idx].PreviousOutPoint.Index)
+	preimage.Write(buf4[:])
+	if err := wire2.WriteVarBytes(&preimage, 0, subScript); err != nil {
+		return nil, err
+	}
+	binary.LittleEndian.PutUint64(buf8[:], uint64(amount))
+	preimage.Write(buf8[:])
+	binary.LittleEndian.PutUint32(buf4[:], tx.TxIn[idx].Sequence)
+	preimage.Write(buf4[:])
+
+	preimage.Write(sigHashes.HashOutputs[:])
+	binary.LittleEndian.PutUint32(buf4[:], tx.LockTime)
+	preimage.Write(buf4[:])
+	binary.LittleEndian.PutUint32(buf4[:], uint32(hashType))
+	preimage.Write(buf4[:])
+
+	return preimage.Bytes(), nil
+}
+
+// SignMvcP2PKHInputs sign the P2PKH inputs of a transaction with their private keys
+// ins are matched to tx.TxIn by index, inputs after len(ins) are left unsigned. Signatures use
+// SIGHASH_ALL|FORKID, the sighash of CalcMvcSigHashPreimage.
+func SignMvcP2PKHInputs(tx *wire2.MsgTx, ins []*TxInputUtxo) error {
+	if len(ins) > len(tx.TxIn) {
+		return fmt.Errorf("%d inputs to sign but %d txins", len(ins), len(tx.TxIn))
+	}
+	for i, in := range ins {
+		if in.SignMode != "" && in.SignMode != SignModeLegacy {
+			return fmt.Errorf("input %d: sign mode %s is not supported for MVC", i, in.SignMode)
+		}
+		hash, err := chainhash2.NewHashFromStr(in.TxId)
+		if err != nil {
+			return err
+		}
+		prevOut := tx.TxIn[i].PreviousOutPoint
+		if !prevOut.Hash.IsEqual(hash) || prevOut.Index != uint32(in.TxIndex) {
+			return fmt.Errorf("input %d: outpoint %s does not match %s:%d", i, prevOut.String(), in.TxId, in.TxIndex)
+		}
+
+		privateKeyBytes, err := hex.DecodeString(in.PriHex)
+		if err != nil {
+			return err
+		}
+		privateKey, _ := bsvec2.PrivKeyFromBytes(bsvec2.S256(), privateKeyBytes)
+		pkScriptByte, err := hex.DecodeString(in.PkScript)
+		if err != nil {
+			return err
+		}
+
+		sigScript, err := txscript2.SignatureScript(tx, i, int64(in.Amount), pkScriptByte, txscript2.SigHashAll|txscript2.SigHashForkID, privateKey, true)
+		if err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+		tx.TxIn[i].SignatureScript = sigScript
+	}
+	return nil
+}
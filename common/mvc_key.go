@@ -0,0 +1,80 @@
+package common
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	bsvec2 "github.com/bitcoinsv/bsvd/bsvec"
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	chainhash2 "github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+	bsvutil2 "github.com/bitcoinsv/bsvutil"
+)
+
+// signedMessageMagic prefix of messages signed by wallets (signMessage)
+const signedMessageMagic = "Bitcoin Signed Message:\n"
+
+// GenerateMvcKey generate a private key and its P2PKH address
+// Returns: private key (hex), address
+func GenerateMvcKey(netParam *chaincfg2.Params) (string, string, error) {
+	privateKey, err := bsvec2.NewPrivateKey(bsvec2.S256())
+	if err != nil {
+		return "", "", err
+	}
+	priHex := hex.EncodeToString(privateKey.Serialize())
+	address, err := MvcAddressFromPriHex(netParam, priHex)
+	if err != nil {
+		return "", "", err
+	}
+	return priHex, address, nil
+}
+
+// MvcAddressFromPriHex P2PKH address (compressed public key) of a private key
+func MvcAddressFromPriHex(netParam *chaincfg2.Params, priHex string) (string, error) {
+	privateKeyBytes, err := hex.DecodeString(priHex)
+	if err != nil {
+		return "", err
+	}
+	_, publicKey := bsvec2.PrivKeyFromBytes(bsvec2.S256(), privateKeyBytes)
+	addr, err := bsvutil2.NewAddressPubKeyHash(bsvutil2.Hash160(publicKey.SerializeCompressed()), netParam)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
+
+// VerifyMessageSignature check a wallet message signature (base64 compact signature) against an address
+func VerifyMessageSignature(netParam *chaincfg2.Params, address, message, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("signature is not base64: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := wire2.WriteVarString(&buf, 0, signedMessageMagic); err != nil {
+		return err
+	}
+	if err := wire2.WriteVarString(&buf, 0, message); err != nil {
+		return err
+	}
+	publicKey, compressed, err := bsvec2.RecoverCompact(bsvec2.S256(), sig, chainhash2.DoubleHashB(buf.Bytes()))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	serialized := publicKey.SerializeUncompressed()
+	if compressed {
+		serialized = publicKey.SerializeCompressed()
+	}
+	addr, err := bsvutil2.NewAddressPubKeyHash(bsvutil2.Hash160(serialized), netParam)
+	if err != nil {
+		return err
+	}
+	if addr.EncodeAddress() != address {
+		return errors.New("signature does not match address")
+	}
+	return nil
+}
@@ -49,9 +49,12 @@ type ChainConfig struct {
 
 // StorageConfig storage configuration
 type StorageConfig struct {
-	Type  string
-	Local LocalStorageConfig
-	OSS   OSSStorageConfig
+	Type   string
+	Layout string // Storage layout: path (one object per PIN), cas (content-addressed, deduplicated by SHA256)
+	Local  LocalStorageConfig
+	OSS    OSSStorageConfig
+	S3     S3StorageConfig
+	Cache  CacheStorageConfig
 }
 
 // LocalStorageConfig local storage configuration
@@ -67,6 +70,25 @@ type OSSStorageConfig struct {
 	Bucket    string
 }
 
+// S3StorageConfig S3-compatible storage configuration (AWS S3, MinIO, Ceph, R2)
+type S3StorageConfig struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	PathStyle bool
+	PartSize  int64 // Multipart part size in MB
+}
+
+// CacheStorageConfig local disk cache in front of remote storage (oss/s3)
+type CacheStorageConfig struct {
+	Enabled bool
+	Dir     string // Cache directory
+	MaxSize int64  // Cache size budget in MB
+}
+
 // IndexerConfig indexer configuration
 type IndexerConfig struct {
 	ScanInterval       int
@@ -77,6 +99,53 @@ type IndexerConfig struct {
 	SwaggerBaseUrl     string // Swagger API base URL (e.g., "example.com:7281")
 	ZmqEnabled         bool   // Enable ZMQ real-time monitoring
 	ZmqAddress         string // ZMQ server address (e.g., "tcp://127.0.0.1:28332")
+	ScrubInterval      int    // Storage integrity scrub interval in hours (0 = disabled)
+	ScrubRepair        bool   // Repair missing/corrupt objects from chain during scrub
+	LazyContent        LazyContentConfig
+	Thumbnail          ThumbnailConfig
+	ContentServing     ContentServingConfig
+	Archive            ArchiveConfig
+	Preview            PreviewConfig
+	Admin              AdminConfig
+}
+
+// AdminConfig admin API configuration
+type AdminConfig struct {
+	Token string // Bearer token of the admin API (empty = admin API disabled)
+}
+
+// PreviewConfig text preview configuration
+type PreviewConfig struct {
+	MaxSize int // Largest text rendered in bytes, longer files are truncated with a "view full" link
+}
+
+// ArchiveConfig archive inspection limits
+type ArchiveConfig struct {
+	MaxEntries   int     // Maximum number of entries
+	MaxTotalSize int64   // Maximum total expanded size in bytes
+	MaxRatio     float64 // Maximum expanded/compressed size ratio
+}
+
+// ContentServingConfig raw content serving policy
+type ContentServingConfig struct {
+	ContentOrigin string // Separate origin for raw content, e.g. "https://content.example.com" (empty = serve from API origin)
+	SVGMode       string // SVG handling: sanitize (serve sanitized copy inline) / attachment (force download)
+}
+
+// ThumbnailConfig image thumbnail configuration
+type ThumbnailConfig struct {
+	Quality      int      // JPEG quality (1-100)
+	MaxDimension int      // Largest allowed thumbnail width/height
+	Pregenerate  []string // Sizes generated at index time, e.g. "200x200" (cover fit, default format)
+}
+
+// LazyContentConfig serve-from-chain configuration
+// Matching files are indexed as metadata only and their content is extracted from the transaction on read.
+type LazyContentConfig struct {
+	Enabled     bool
+	FileTypes   []string // File types served from chain: image/video/audio/text/document/archive/data/other (empty = all)
+	MinSize     int64    // Only files of at least this size (KB) are served from chain (0 = any size)
+	CacheOnRead bool     // Save content to storage after the first read
 }
 
 // UploaderConfig uploader configuration
@@ -84,6 +153,55 @@ type UploaderConfig struct {
 	MaxFileSize    int64
 	FeeRate        int64
 	SwaggerBaseUrl string // Swagger API base URL (e.g., "example.com:7282")
+	ChunkThreshold int64  // Files larger than this (bytes) are uploaded as chunk PINs plus an index PIN
+	ChunkSize      int64  // Chunk PIN content size (bytes)
+
+	StagingExpiration  int64 // Hours a resumable upload is kept after its last write
+	UtxoReservationTTL int64 // Minutes a payer UTXO selected for a pending upload stays reserved
+	MaxBatchFiles      int64 // Most files one batch pre-upload accepts
+	Assistant          AssistantConfig
+	Sponsor            SponsorConfig
+	Admin              AdminConfig
+	Btc                BtcConfig
+	TxTracker          TxTrackerConfig
+}
+
+// TxTrackerConfig broadcast transaction tracking configuration
+type TxTrackerConfig struct {
+	Interval        int64 // Seconds between polls of the node
+	MaxRebroadcasts int64 // Times a dropped transaction is broadcast again before it is failed
+	ConfirmDepth    int64 // Recent blocks searched for transactions the node does not return, and re-checked for reorgs
+}
+
+// BtcConfig BTC inscription upload configuration
+// Uploads with chain btc are inscribed with a taproot commit transaction and a reveal transaction.
+type BtcConfig struct {
+	Enabled bool
+	RpcUrl  string // BTC node RPC URL, used to broadcast (and by storagectl to re-fetch BTC records)
+	RpcUser string
+	RpcPass string
+	FeeRate int64 // Default fee rate (satoshis per virtual byte)
+	Postage int64 // Satoshis of the reveal output that carries the PIN to the owner
+
+	RevealKeySecret string // Secret one-time reveal keys are encrypted with on the file record (required when enabled)
+}
+
+// SponsorConfig fee sponsorship configuration
+// Eligible uploads are funded and signed by the sponsor wallet, the user only signs an authorization message.
+type SponsorConfig struct {
+	Enabled             bool
+	PrivateKey          string   // Sponsor wallet private key, AES-GCM encrypted with the assistant master key (base64)
+	OwnerOutputAmount   int64    // Satoshis sent to the user address, which makes it the PIN owner
+	DailyByteBudget     int64    // File bytes sponsored per MetaID per day (UTC)
+	DailyFeeBudget      int64    // Satoshis spent per MetaID per day (UTC), fees and owner outputs
+	AllowedContentTypes []string // Content type prefixes eligible for sponsorship, e.g. "image/" (empty = all)
+	Allowlist           []string // Addresses or MetaIDs eligible for sponsorship (empty = all)
+}
+
+// AssistantConfig custodial assistant key configuration
+type AssistantConfig struct {
+	MasterKey     string // Secret assistant and sponsor private keys are encrypted with (empty = assistants and sponsorship disabled)
+	MaxSpendLimit int64  // Largest spend limit an assistant can be given (satoshis)
 }
 
 // RpcConfig RPC configuration
@@ -129,7 +247,8 @@ func InitConfig() error {
 		},
 
 		Storage: StorageConfig{
-			Type: viper.GetString("storage.type"),
+			Type:   viper.GetString("storage.type"),
+			Layout: viper.GetString("storage.layout"),
 			Local: LocalStorageConfig{
 				BasePath: viper.GetString("storage.local.base_path"),
 			},
@@ -139,6 +258,21 @@ func InitConfig() error {
 				SecretKey: viper.GetString("storage.oss.secret_key"),
 				Bucket:    viper.GetString("storage.oss.bucket"),
 			},
+			S3: S3StorageConfig{
+				Endpoint:  viper.GetString("storage.s3.endpoint"),
+				Region:    viper.GetString("storage.s3.region"),
+				Bucket:    viper.GetString("storage.s3.bucket"),
+				AccessKey: viper.GetString("storage.s3.access_key"),
+				SecretKey: viper.GetString("storage.s3.secret_key"),
+				UseSSL:    viper.GetBool("storage.s3.use_ssl"),
+				PathStyle: viper.GetBool("storage.s3.path_style"),
+				PartSize:  viper.GetInt64("storage.s3.part_size"),
+			},
+			Cache: CacheStorageConfig{
+				Enabled: viper.GetBool("storage.cache.enabled"),
+				Dir:     viper.GetString("storage.cache.dir"),
+				MaxSize: viper.GetInt64("storage.cache.max_size"),
+			},
 		},
 
 		Indexer: IndexerConfig{
@@ -150,12 +284,77 @@ func InitConfig() error {
 			SwaggerBaseUrl:     viper.GetString("indexer.swagger_base_url"),
 			ZmqEnabled:         viper.GetBool("indexer.zmq_enabled"),
 			ZmqAddress:         viper.GetString("indexer.zmq_address"),
+			ScrubInterval:      viper.GetInt("indexer.scrub_interval"),
+			ScrubRepair:        viper.GetBool("indexer.scrub_repair"),
+			LazyContent: LazyContentConfig{
+				Enabled:     viper.GetBool("indexer.lazy_content.enabled"),
+				FileTypes:   viper.GetStringSlice("indexer.lazy_content.file_types"),
+				MinSize:     viper.GetInt64("indexer.lazy_content.min_size"),
+				CacheOnRead: viper.GetBool("indexer.lazy_content.cache_on_read"),
+			},
+			Thumbnail: ThumbnailConfig{
+				Quality:      viper.GetInt("indexer.thumbnail.quality"),
+				MaxDimension: viper.GetInt("indexer.thumbnail.max_dimension"),
+				Pregenerate:  viper.GetStringSlice("indexer.thumbnail.pregenerate"),
+			},
+			ContentServing: ContentServingConfig{
+				ContentOrigin: viper.GetString("indexer.content_serving.content_origin"),
+				SVGMode:       viper.GetString("indexer.content_serving.svg_mode"),
+			},
+			Archive: ArchiveConfig{
+				MaxEntries:   viper.GetInt("indexer.archive.max_entries"),
+				MaxTotalSize: viper.GetInt64("indexer.archive.max_total_size") * 1024 * 1024, // MB to bytes
+				MaxRatio:     viper.GetFloat64("indexer.archive.max_ratio"),
+			},
+			Preview: PreviewConfig{
+				MaxSize: viper.GetInt("indexer.preview.max_size") * 1024, // KB to bytes
+			},
+			Admin: AdminConfig{
+				Token: viper.GetString("indexer.admin.token"),
+			},
 		},
 
 		Uploader: UploaderConfig{
 			MaxFileSize:    viper.GetInt64("uploader.max_file_size") * 1024 * 1024, // MB to bytes
 			FeeRate:        viper.GetInt64("uploader.fee_rate"),
 			SwaggerBaseUrl: viper.GetString("uploader.swagger_base_url"),
+			ChunkThreshold: viper.GetInt64("uploader.chunk_threshold") * 1024 * 1024, // MB to bytes
+			ChunkSize:      viper.GetInt64("uploader.chunk_size") * 1024,             // KB to bytes
+
+			StagingExpiration:  viper.GetInt64("uploader.staging_expiration"),
+			UtxoReservationTTL: viper.GetInt64("uploader.utxo_reservation_ttl"),
+			MaxBatchFiles:      viper.GetInt64("uploader.max_batch_files"),
+			Assistant: AssistantConfig{
+				MasterKey:     viper.GetString("uploader.assistant.master_key"),
+				MaxSpendLimit: viper.GetInt64("uploader.assistant.max_spend_limit"),
+			},
+			Sponsor: SponsorConfig{
+				Enabled:             viper.GetBool("uploader.sponsor.enabled"),
+				PrivateKey:          viper.GetString("uploader.sponsor.private_key"),
+				OwnerOutputAmount:   viper.GetInt64("uploader.sponsor.owner_output_amount"),
+				DailyByteBudget:     viper.GetInt64("uploader.sponsor.daily_byte_budget") * 1024, // KB to bytes
+				DailyFeeBudget:      viper.GetInt64("uploader.sponsor.daily_fee_budget"),
+				AllowedContentTypes: viper.GetStringSlice("uploader.sponsor.allowed_content_types"),
+				Allowlist:           viper.GetStringSlice("uploader.sponsor.allowlist"),
+			},
+			Admin: AdminConfig{
+				Token: viper.GetString("uploader.admin.token"),
+			},
+			Btc: BtcConfig{
+				Enabled: viper.GetBool("uploader.btc.enabled"),
+				RpcUrl:  viper.GetString("uploader.btc.rpc_url"),
+				RpcUser: viper.GetString("uploader.btc.rpc_user"),
+				RpcPass: viper.GetString("uploader.btc.rpc_pass"),
+				FeeRate: viper.GetInt64("uploader.btc.fee_rate"),
+				Postage: viper.GetInt64("uploader.btc.postage"),
+
+				RevealKeySecret: viper.GetString("uploader.btc.reveal_key_secret"),
+			},
+			TxTracker: TxTrackerConfig{
+				Interval:        viper.GetInt64("uploader.tx_tracker.interval"),
+				MaxRebroadcasts: viper.GetInt64("uploader.tx_tracker.max_rebroadcasts"),
+				ConfirmDepth:    viper.GetInt64("uploader.tx_tracker.confirm_depth"),
+			},
 		},
 	}
 
@@ -173,9 +372,39 @@ func InitConfig() error {
 	if Cfg.Storage.Type == "" {
 		Cfg.Storage.Type = "local"
 	}
+	if Cfg.Storage.Layout == "" {
+		Cfg.Storage.Layout = "path"
+	}
 	if Cfg.Storage.Local.BasePath == "" {
 		Cfg.Storage.Local.BasePath = "./data/files"
 	}
+	if Cfg.Storage.Cache.Dir == "" {
+		Cfg.Storage.Cache.Dir = "./data/cache"
+	}
+	if Cfg.Storage.Cache.MaxSize == 0 {
+		Cfg.Storage.Cache.MaxSize = 1024
+	}
+	if Cfg.Indexer.Thumbnail.Quality == 0 {
+		Cfg.Indexer.Thumbnail.Quality = 85
+	}
+	if Cfg.Indexer.Thumbnail.MaxDimension == 0 {
+		Cfg.Indexer.Thumbnail.MaxDimension = 2048
+	}
+	if Cfg.Indexer.ContentServing.SVGMode == "" {
+		Cfg.Indexer.ContentServing.SVGMode = "sanitize"
+	}
+	if Cfg.Indexer.Archive.MaxEntries == 0 {
+		Cfg.Indexer.Archive.MaxEntries = 10000
+	}
+	if Cfg.Indexer.Archive.MaxTotalSize == 0 {
+		Cfg.Indexer.Archive.MaxTotalSize = 1024 * 1024 * 1024
+	}
+	if Cfg.Indexer.Archive.MaxRatio == 0 {
+		Cfg.Indexer.Archive.MaxRatio = 100
+	}
+	if Cfg.Indexer.Preview.MaxSize == 0 {
+		Cfg.Indexer.Preview.MaxSize = 512 * 1024
+	}
 	if Cfg.Indexer.ScanInterval == 0 {
 		Cfg.Indexer.ScanInterval = 10
 	}
@@ -188,6 +417,48 @@ func InitConfig() error {
 	if Cfg.Uploader.FeeRate == 0 {
 		Cfg.Uploader.FeeRate = 1
 	}
+	if Cfg.Uploader.ChunkThreshold == 0 {
+		Cfg.Uploader.ChunkThreshold = 5242880 // 5MB
+	}
+	if Cfg.Uploader.ChunkSize == 0 {
+		Cfg.Uploader.ChunkSize = 1048576 // 1MB
+	}
+	if Cfg.Uploader.StagingExpiration == 0 {
+		Cfg.Uploader.StagingExpiration = 24
+	}
+	if Cfg.Uploader.UtxoReservationTTL == 0 {
+		Cfg.Uploader.UtxoReservationTTL = 60
+	}
+	if Cfg.Uploader.MaxBatchFiles == 0 {
+		Cfg.Uploader.MaxBatchFiles = 50
+	}
+	if Cfg.Uploader.Assistant.MaxSpendLimit == 0 {
+		Cfg.Uploader.Assistant.MaxSpendLimit = 100000000
+	}
+	if Cfg.Uploader.Sponsor.OwnerOutputAmount == 0 {
+		Cfg.Uploader.Sponsor.OwnerOutputAmount = 1
+	}
+	if Cfg.Uploader.Sponsor.DailyByteBudget == 0 {
+		Cfg.Uploader.Sponsor.DailyByteBudget = 1024 * 1024
+	}
+	if Cfg.Uploader.Sponsor.DailyFeeBudget == 0 {
+		Cfg.Uploader.Sponsor.DailyFeeBudget = 10000
+	}
+	if Cfg.Uploader.Btc.FeeRate == 0 {
+		Cfg.Uploader.Btc.FeeRate = 2
+	}
+	if Cfg.Uploader.Btc.Postage == 0 {
+		Cfg.Uploader.Btc.Postage = 546
+	}
+	if Cfg.Uploader.TxTracker.Interval == 0 {
+		Cfg.Uploader.TxTracker.Interval = 30
+	}
+	if Cfg.Uploader.TxTracker.MaxRebroadcasts == 0 {
+		Cfg.Uploader.TxTracker.MaxRebroadcasts = 10
+	}
+	if Cfg.Uploader.TxTracker.ConfirmDepth == 0 {
+		Cfg.Uploader.TxTracker.ConfirmDepth = 6
+	}
 	if Cfg.Database.MaxOpenConns == 0 {
 		Cfg.Database.MaxOpenConns = 100
 	}
@@ -207,6 +478,13 @@ func InitConfig() error {
 		Username: Cfg.Chain.RpcUser,
 		Password: Cfg.Chain.RpcPass,
 	}
+	if Cfg.Uploader.Btc.Enabled {
+		RpcConfigMap["btc"] = RpcConfig{
+			Url:      Cfg.Uploader.Btc.RpcUrl,
+			Username: Cfg.Uploader.Btc.RpcUser,
+			Password: Cfg.Uploader.Btc.RpcPass,
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"meta-media-service/controller/respond"
+	"meta-media-service/service/indexer_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler indexer admin handler
+type AdminHandler struct {
+	moderationService *indexer_service.ModerationService
+}
+
+// NewAdminHandler create indexer admin handler instance
+func NewAdminHandler(moderationService *indexer_service.ModerationService) *AdminHandler {
+	return &AdminHandler{
+		moderationService: moderationService,
+	}
+}
+
+// CreateBlockRequest create blocklist entry request
+type CreateBlockRequest struct {
+	Type     string `json:"type" binding:"required" example:"pin"` // pin/hash/address/metaid
+	Value    string `json:"value" binding:"required" example:"abc123def456i0"`
+	Reason   string `json:"reason" binding:"required" example:"DMCA notice #2024-017"`
+	Operator string `json:"operator" binding:"required" example:"legal@example.com"`
+}
+
+// DeleteBlockRequest delete blocklist entry request
+type DeleteBlockRequest struct {
+	Reason   string `json:"reason" example:"Counter-notice accepted"`
+	Operator string `json:"operator" binding:"required" example:"legal@example.com"`
+}
+
+// CreateBlock add a target to the moderation blocklist
+// @Summary      Block content
+// @Description  Block a PIN, a content hash (SHA256, also applied to PINs indexed later), a creator address or a creator MetaID. Blocked content returns 451 from content, thumbnail and preview endpoints and is hidden from lists.
+// @Tags         Indexer Admin
+// @Accept       json
+// @Produce      json
+// @Security     AdminToken
+// @Param        request  body      CreateBlockRequest  true  "Block target"
+// @Success      200      {object}  respond.Response{data=respond.IndexerBlockResponse}
+// @Failure      400      {object}  respond.Response
+// @Failure      401      {object}  respond.Response
+// @Router       /admin/blocks [post]
+func (h *AdminHandler) CreateBlock(c *gin.Context) {
+	var req CreateBlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	block, err := h.moderationService.Block(req.Type, req.Value, req.Reason, req.Operator)
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrInvalidBlock) || errors.Is(err, indexer_service.ErrBlockExists) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, respond.ToIndexerBlockResponse(block))
+}
+
+// DeleteBlock remove an entry from the moderation blocklist
+// @Summary      Unblock content
+// @Description  Remove a blocklist entry by ID. The entry stays in the audit log.
+// @Tags         Indexer Admin
+// @Accept       json
+// @Produce      json
+// @Security     AdminToken
+// @Param        id       path      int                 true  "Block ID"
+// @Param        request  body      DeleteBlockRequest  true  "Unblock reason and operator"
+// @Success      200      {object}  respond.Response{data=respond.IndexerBlockResponse}
+// @Failure      400      {object}  respond.Response
+// @Failure      401      {object}  respond.Response
+// @Failure      404      {object}  respond.Response
+// @Router       /admin/blocks/{id} [delete]
+func (h *AdminHandler) DeleteBlock(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		respond.InvalidParam(c, "id must be a positive integer")
+		return
+	}
+
+	var req DeleteBlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	block, err := h.moderationService.Unblock(id, req.Reason, req.Operator)
+	if err != nil {
+		switch {
+		case errors.Is(err, indexer_service.ErrInvalidBlock):
+			respond.InvalidParam(c, err.Error())
+		case errors.Is(err, indexer_service.ErrBlockNotFound):
+			respond.NotFound(c, err.Error())
+		default:
+			respond.ServerError(c, err.Error())
+		}
+		return
+	}
+
+	respond.Success(c, respond.ToIndexerBlockResponse(block))
+}
+
+// ListBlocks get moderation blocklist with cursor pagination
+// @Summary      Query blocklist
+// @Description  Query blocklist entries with cursor pagination, newest first
+// @Tags         Indexer Admin
+// @Accept       json
+// @Produce      json
+// @Security     AdminToken
+// @Param        type    query  string  false  "Block type: pin/hash/address/metaid (empty for all)"
+// @Param        cursor  query  int     false  "Cursor (last block ID)" default(0)
+// @Param        size    query  int     false  "Page size"              default(20)
+// @Success      200     {object}  respond.Response{data=respond.IndexerBlockListResponse}
+// @Failure      400     {object}  respond.Response
+// @Failure      401     {object}  respond.Response
+// @Router       /admin/blocks [get]
+func (h *AdminHandler) ListBlocks(c *gin.Context) {
+	cursor, _ := strconv.ParseInt(c.DefaultQuery("cursor", "0"), 10, 64)
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	blocks, nextCursor, hasMore, err := h.moderationService.ListBlocks(c.Query("type"), cursor, size)
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrInvalidBlock) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, respond.ToIndexerBlockListResponse(blocks, nextCursor, hasMore))
+}
+
+// ListAudits get moderation audit log with cursor pagination
+// @Summary      Query moderation audit log
+// @Description  Query block, unblock and hash match events with cursor pagination, newest first
+// @Tags         Indexer Admin
+// @Accept       json
+// @Produce      json
+// @Security     AdminToken
+// @Param        cursor  query  int  false  "Cursor (last audit entry ID)" default(0)
+// @Param        size    query  int  false  "Page size"                    default(20)
+// @Success      200     {object}  respond.Response{data=respond.IndexerBlockAuditListResponse}
+// @Failure      401     {object}  respond.Response
+// @Router       /admin/audit [get]
+func (h *AdminHandler) ListAudits(c *gin.Context) {
+	cursor, _ := strconv.ParseInt(c.DefaultQuery("cursor", "0"), 10, 64)
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	audits, nextCursor, hasMore, err := h.moderationService.ListAudits(cursor, size)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, respond.ToIndexerBlockAuditListResponse(audits, nextCursor, hasMore))
+}
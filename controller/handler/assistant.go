@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"meta-media-service/controller/respond"
+	"meta-media-service/service/upload_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AssistantHandler custodial assistant handler
+type AssistantHandler struct {
+	assistantService *upload_service.AssistantService
+}
+
+// NewAssistantHandler create assistant handler instance
+func NewAssistantHandler(assistantService *upload_service.AssistantService) *AssistantHandler {
+	return &AssistantHandler{
+		assistantService: assistantService,
+	}
+}
+
+// AssistantAuthRequest owner authorization of an assistant action
+// signature is the wallet message signature (base64) of:
+// "meta-media assistant\naction: {action}\nmetaId: {sha256(address)}\nspendLimit: {spendLimit}\ntimestamp: {timestamp}"
+type AssistantAuthRequest struct {
+	Address    string `json:"address" binding:"required" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"`            // Owner address
+	SpendLimit int64  `json:"spendLimit" example:"1000000"`                                                       // Spend limit (satoshis), 0 for rotate and revoke
+	Timestamp  int64  `json:"timestamp" binding:"required" example:"1735689600"`                                  // Unix seconds
+	Signature  string `json:"signature" binding:"required" example:"H6Xh2uM4eQ0K7Vtq1Yc3m1QmR8nOa9r0s5w9gRrJZ0="` // Base64 message signature
+}
+
+// CreateAssistant create an assistant
+// @Summary      Create assistant
+// @Description  Authorize a server-held assistant key to sign and pay for uploads of the owner, up to spendLimit satoshis. Fund assistantAddress, then upload with /files/assistant-upload and the returned accessToken. Creating again after a revoke issues a new key.
+// @Tags         Assistant
+// @Accept       json
+// @Produce      json
+// @Param        request  body      AssistantAuthRequest  true  "Owner authorization (action: create)"
+// @Success      200      {object}  respond.Response{data=upload_service.AssistantResponse}  "accessToken is only returned here and on rotate"
+// @Failure      400      {object}  respond.Response  "Parameter error, invalid spend limit or assistant exists"
+// @Failure      401      {object}  respond.Response  "Invalid signature or assistants disabled"
+// @Router       /assistants [post]
+func (h *AssistantHandler) CreateAssistant(c *gin.Context) {
+	auth, ok := bindAssistantAuth(c)
+	if !ok {
+		return
+	}
+	resp, err := h.assistantService.Create(auth)
+	if err != nil {
+		respondAssistantError(c, err)
+		return
+	}
+	respond.Success(c, resp)
+}
+
+// GetAssistant get assistant info
+// @Summary      Get assistant
+// @Description  Get the assistant address, spend limit and spent amount of an owner MetaID
+// @Tags         Assistant
+// @Produce      json
+// @Param        metaId  path      string  true  "Owner MetaID"
+// @Success      200     {object}  respond.Response{data=upload_service.AssistantResponse}
+// @Failure      404     {object}  respond.Response  "Assistant not found"
+// @Router       /assistants/{metaId} [get]
+func (h *AssistantHandler) GetAssistant(c *gin.Context) {
+	resp, err := h.assistantService.Get(c.Param("metaId"))
+	if err != nil {
+		respondAssistantError(c, err)
+		return
+	}
+	respond.Success(c, resp)
+}
+
+// RotateAssistant replace the assistant key
+// @Summary      Rotate assistant key
+// @Description  Replace the assistant key and access token. The balance of the old key is moved to the new assistantAddress (sweepTxId).
+// @Tags         Assistant
+// @Accept       json
+// @Produce      json
+// @Param        metaId   path      string                true  "Owner MetaID"
+// @Param        request  body      AssistantAuthRequest  true  "Owner authorization (action: rotate)"
+// @Success      200      {object}  respond.Response{data=upload_service.AssistantResponse}
+// @Failure      401      {object}  respond.Response  "Invalid signature"
+// @Failure      404      {object}  respond.Response  "Assistant not found"
+// @Router       /assistants/{metaId}/rotate [post]
+func (h *AssistantHandler) RotateAssistant(c *gin.Context) {
+	auth, ok := bindAssistantAuth(c)
+	if !ok {
+		return
+	}
+	resp, err := h.assistantService.Rotate(c.Param("metaId"), auth)
+	if err != nil {
+		respondAssistantError(c, err)
+		return
+	}
+	respond.Success(c, resp)
+}
+
+// SetAssistantLimit change the spend limit
+// @Summary      Set assistant spend limit
+// @Description  Change how many satoshis the assistant may spend in total (fees and outputs). A limit below spentAmount stops further uploads.
+// @Tags         Assistant
+// @Accept       json
+// @Produce      json
+// @Param        metaId   path      string                true  "Owner MetaID"
+// @Param        request  body      AssistantAuthRequest  true  "Owner authorization (action: limit)"
+// @Success      200      {object}  respond.Response{data=upload_service.AssistantResponse}
+// @Failure      400      {object}  respond.Response  "Invalid spend limit"
+// @Failure      401      {object}  respond.Response  "Invalid signature"
+// @Failure      404      {object}  respond.Response  "Assistant not found"
+// @Router       /assistants/{metaId}/limit [post]
+func (h *AssistantHandler) SetAssistantLimit(c *gin.Context) {
+	auth, ok := bindAssistantAuth(c)
+	if !ok {
+		return
+	}
+	resp, err := h.assistantService.SetLimit(c.Param("metaId"), auth)
+	if err != nil {
+		respondAssistantError(c, err)
+		return
+	}
+	respond.Success(c, resp)
+}
+
+// RevokeAssistant revoke the assistant
+// @Summary      Revoke assistant
+// @Description  Stop the assistant from signing and invalidate its access token. The remaining balance is returned to the owner address (sweepTxId).
+// @Tags         Assistant
+// @Accept       json
+// @Produce      json
+// @Param        metaId   path      string                true  "Owner MetaID"
+// @Param        request  body      AssistantAuthRequest  true  "Owner authorization (action: revoke)"
+// @Success      200      {object}  respond.Response{data=upload_service.AssistantResponse}
+// @Failure      401      {object}  respond.Response  "Invalid signature"
+// @Failure      404      {object}  respond.Response  "Assistant not found"
+// @Router       /assistants/{metaId}/revoke [post]
+func (h *AssistantHandler) RevokeAssistant(c *gin.Context) {
+	auth, ok := bindAssistantAuth(c)
+	if !ok {
+		return
+	}
+	resp, err := h.assistantService.Revoke(c.Param("metaId"), auth)
+	if err != nil {
+		respondAssistantError(c, err)
+		return
+	}
+	respond.Success(c, resp)
+}
+
+// AssistantUpload upload a file signed and paid by the assistant
+// @Summary      Assistant upload
+// @Description  Upload a file in one request: the assistant of metaId funds, signs and broadcasts all transactions (chunks and index for large files). Requires "Authorization: Bearer <accessToken>". The cost (fees and outputs) counts against the spend limit.
+// @Tags         Assistant
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer <accessToken>"
+// @Param        metaId         formData  string  true   "Owner MetaID"
+// @Param        file           formData  file    false  "File to upload (required unless uploadId is given)"
+// @Param        uploadId       formData  string  false  "ID of a completed resumable upload, used instead of file"
+// @Param        path           formData  string  true   "File path"
+// @Param        operation      formData  string  false  "Operation type"        default(create)
+// @Param        contentType    formData  string  false  "Content type"
+// @Param        feeRate        formData  int     false  "Fee rate (satoshis per byte, defaults to config, MVC rates below the configured one are raised to it)"
+// @Param        outputs        formData  string  false  "Output list json, paid by the assistant"
+// @Success      200  {object}  respond.Response{data=CommitUploadResponseData}  "Upload successful, return transaction ID and Pin ID"
+// @Failure      400  {object}  respond.Response  "Parameter error, insufficient funds or spend limit exceeded"
+// @Failure      401  {object}  respond.Response  "Invalid access token"
+// @Failure      500  {object}  respond.Response  "Server error or broadcast failed"
+// @Router       /files/assistant-upload [post]
+func (h *UploadHandler) AssistantUpload(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		respond.Unauthorized(c, "assistant access token is required")
+		return
+	}
+	metaId := c.PostForm("metaId")
+	if metaId == "" {
+		respond.InvalidParam(c, "metaId is required")
+		return
+	}
+
+	content, fileName, fileContentType, ok := h.readUploadContent(c)
+	if !ok {
+		return
+	}
+	path := c.PostForm("path")
+	if path == "" {
+		respond.InvalidParam(c, "path is required")
+		return
+	}
+	contentType := c.PostForm("contentType")
+	if contentType == "" {
+		contentType = fileContentType
+	}
+	feeRate := int64(0) // Defaults to config
+	if rate, err := strconv.ParseInt(c.PostForm("feeRate"), 10, 64); err == nil {
+		feeRate = rate
+	}
+
+	req := &upload_service.UploadRequest{
+		FileName:    fileName,
+		Content:     content,
+		Path:        path,
+		Operation:   c.PostForm("operation"),
+		ContentType: contentType,
+		Outputs:     parseTxOutputs(c.PostForm("outputs")),
+		FeeRate:     feeRate,
+	}
+	resp, err := h.assistantService.Upload(metaId, token, req)
+	if err != nil {
+		respondAssistantError(c, err)
+		return
+	}
+	respond.Success(c, resp)
+}
+
+// bindAssistantAuth bind an owner authorization request
+func bindAssistantAuth(c *gin.Context) (*upload_service.AssistantAuth, bool) {
+	var req AssistantAuthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return nil, false
+	}
+	return &upload_service.AssistantAuth{
+		Address:    req.Address,
+		SpendLimit: req.SpendLimit,
+		Timestamp:  req.Timestamp,
+		Signature:  req.Signature,
+	}, true
+}
+
+// respondAssistantError map assistant errors to responses
+func respondAssistantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, upload_service.ErrAssistantDisabled), errors.Is(err, upload_service.ErrAssistantUnauthorized):
+		respond.Unauthorized(c, err.Error())
+	case errors.Is(err, upload_service.ErrAssistantNotFound):
+		respond.NotFound(c, err.Error())
+	case errors.Is(err, upload_service.ErrAssistantExists),
+		errors.Is(err, upload_service.ErrInvalidSpendLimit),
+		errors.Is(err, upload_service.ErrSpendLimitExceeded),
+		errors.Is(err, upload_service.ErrInsufficientFunds):
+		respond.InvalidParam(c, err.Error())
+	default:
+		respond.ServerError(c, err.Error())
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"errors"
+	"io/ioutil"
+	"strconv"
+
+	"meta-media-service/controller/respond"
+	"meta-media-service/service/upload_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchPreUpload pre-upload several files at once
+// @Summary      Batch pre-upload files
+// @Description  Pre-upload every files part like pre-upload with the shared parameters, in upload order. The MetaID indexer reads only the first MetaID output of an MVC transaction and numbers every PIN of a BTC reveal i0, so each file keeps its own PIN transaction. An MVC batch holds one file, since every MVC file would still need its own transaction and signature; several MVC files are rejected. On BTC one commit transaction (commitPsbt) funds a signed reveal per file; sign the commit once. Submit with /files/batch-commit-upload. Files above the chunk threshold and identical files are rejected. If one file fails, the records and UTXO reservations of the batch are rolled back.
+// @Tags         File Upload
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        files          formData  file    true   "Files to upload (repeat the field for each file)"
+// @Param        path           formData  string  true   "File path, shared by all files"
+// @Param        operation      formData  string  false  "Operation type"        default(create)
+// @Param        changeAddress  formData  string  false  "Change address"
+// @Param        metaId         formData  string  false  "MetaID"
+// @Param        address        formData  string  false  "Address"
+// @Param        feeRate        formData  int     false  "Fee rate (satoshis per byte, defaults to config, MVC rates below the configured one are raised to it)"
+// @Param        payerAddress   formData  string  false  "Payer address, its UTXOs are selected as inputs (change goes to changeAddress, defaults to payerAddress)"
+// @Param        chain          formData  string  false  "Chain to inscribe on: mvc, btc"  default(mvc)
+// @Param        inputs         formData  string  false  "BTC commit input list json: [{txId, vout, amount, scriptPubKey}] (btc only)"
+// @Success      200  {object}  respond.Response{data=upload_service.BatchPreUploadResponse}  "Pre-upload successful, return the transactions of every file"
+// @Failure      400  {object}  respond.Response  "Parameter error"
+// @Failure      500  {object}  respond.Response  "Server error"
+// @Router       /files/batch-pre-upload [post]
+func (h *UploadHandler) BatchPreUpload(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["files"]) == 0 {
+		respond.InvalidParam(c, "files is required")
+		return
+	}
+
+	path := c.PostForm("path")
+	if path == "" {
+		respond.InvalidParam(c, "path is required")
+		return
+	}
+
+	operation := c.PostForm("operation")
+	if operation == "" {
+		operation = "create"
+	}
+
+	feeRate := int64(0) // Defaults to config
+	if rate, err := strconv.ParseInt(c.PostForm("feeRate"), 10, 64); err == nil {
+		feeRate = rate
+	}
+
+	btcInputs, err := parseBtcInputs(c.PostForm("inputs"))
+	if err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	req := &upload_service.BatchUploadRequest{
+		MetaId:        c.PostForm("metaId"),
+		Address:       c.PostForm("address"),
+		Path:          path,
+		Operation:     operation,
+		ChangeAddress: c.PostForm("changeAddress"),
+		FeeRate:       feeRate,
+		PayerAddress:  c.PostForm("payerAddress"),
+		Chain:         c.PostForm("chain"),
+		BtcInputs:     btcInputs,
+	}
+	for _, header := range form.File["files"] {
+		file, err := header.Open()
+		if err != nil {
+			respond.ServerError(c, "failed to read file")
+			return
+		}
+		content, err := ioutil.ReadAll(file)
+		file.Close()
+		if err != nil {
+			respond.ServerError(c, "failed to read file")
+			return
+		}
+		req.Files = append(req.Files, &upload_service.BatchUploadFile{
+			FileName:    header.Filename,
+			Content:     content,
+			ContentType: header.Header.Get("Content-Type"),
+		})
+	}
+
+	resp, err := h.uploadService.BatchPreUpload(req)
+	if err != nil {
+		if errors.Is(err, upload_service.ErrInvalidBatch) || errors.Is(err, upload_service.ErrInvalidPayer) ||
+			errors.Is(err, upload_service.ErrInsufficientFunds) || errors.Is(err, upload_service.ErrUnsupportedChain) ||
+			errors.Is(err, upload_service.ErrBtcDisabled) || errors.Is(err, upload_service.ErrInvalidBtcUpload) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
+// BatchCommitUploadRequest batch commit upload request
+type BatchCommitUploadRequest struct {
+	Files        []*upload_service.BatchCommitItem `json:"files" binding:"required" description:"Signed transaction of every file (fileId, signedRawTx)"`
+	SignedCommit string                            `json:"signedCommit" description:"BTC batch: signed commit PSBT, used for every file without signedRawTx"`
+}
+
+// BatchCommitUpload commit the signed transactions of a batch
+// @Summary      Batch commit upload
+// @Description  Submit the signed transactions of a batch pre-upload. Each is checked and broadcast like commit-upload, in request order. For a BTC batch pass the signed commit PSBT once as signedCommit and leave signedRawTx empty, the commit is broadcast with the first file. A file that fails does not stop the others: its entry in files has status failed and the error in message. pinIds maps the fileId of every committed file to its pinId.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        request  body      BatchCommitUploadRequest  true  "Batch commit upload request"
+// @Success      200      {object}  respond.Response{data=upload_service.BatchCommitResponse}  "Batch committed, return the result of every file"
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /files/batch-commit-upload [post]
+func (h *UploadHandler) BatchCommitUpload(c *gin.Context) {
+	var req BatchCommitUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	resp, err := h.uploadService.BatchCommitUpload(req.Files, req.SignedCommit)
+	if err != nil {
+		if errors.Is(err, upload_service.ErrInvalidBatch) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, resp)
+}
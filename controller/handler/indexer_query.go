@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 
+	"meta-media-service/archive"
 	"meta-media-service/controller/respond"
+	"meta-media-service/preview"
 	"meta-media-service/service/indexer_service"
 
 	"github.com/gin-gonic/gin"
@@ -13,25 +18,36 @@ import (
 type IndexerQueryHandler struct {
 	indexerFileService *indexer_service.IndexerFileService
 	syncStatusService  *indexer_service.SyncStatusService
+	thumbnailService   *indexer_service.ThumbnailService
+	avatarImageService *indexer_service.AvatarImageService
+	safeContentService *indexer_service.SafeContentService
+	archiveService     *indexer_service.ArchiveService
+	previewService     *indexer_service.PreviewService
 }
 
 // NewIndexerQueryHandler create indexer query handler instance
-func NewIndexerQueryHandler(indexerFileService *indexer_service.IndexerFileService, syncStatusService *indexer_service.SyncStatusService) *IndexerQueryHandler {
+func NewIndexerQueryHandler(indexerFileService *indexer_service.IndexerFileService, syncStatusService *indexer_service.SyncStatusService, thumbnailService *indexer_service.ThumbnailService, avatarImageService *indexer_service.AvatarImageService, safeContentService *indexer_service.SafeContentService, archiveService *indexer_service.ArchiveService, previewService *indexer_service.PreviewService) *IndexerQueryHandler {
 	return &IndexerQueryHandler{
 		indexerFileService: indexerFileService,
 		syncStatusService:  syncStatusService,
+		thumbnailService:   thumbnailService,
+		avatarImageService: avatarImageService,
+		safeContentService: safeContentService,
+		archiveService:     archiveService,
+		previewService:     previewService,
 	}
 }
 
 // GetByPinID get file information by PIN ID
 // @Summary      Get file by PIN ID
-// @Description  Query file details by PIN ID
+// @Description  Query file details by PIN ID, including media metadata (dimensions, duration, codecs, EXIF) for image/video/audio files
 // @Tags         Indexer File Query
 // @Accept       json
 // @Produce      json
 // @Param        pinId  path      string  true  "PIN ID"
 // @Success      200    {object}  respond.Response{data=respond.IndexerFileResponse}
 // @Failure      404    {object}  respond.Response
+// @Failure      451    {object}  respond.Response
 // @Router       /files/{pinId} [get]
 func (h *IndexerQueryHandler) GetByPinID(c *gin.Context) {
 	pinID := c.Param("pinId")
@@ -42,11 +58,17 @@ func (h *IndexerQueryHandler) GetByPinID(c *gin.Context) {
 
 	file, err := h.indexerFileService.GetFileByPinID(pinID)
 	if err != nil {
-		respond.NotFound(c, err.Error())
+		respondNotFound(c, err)
 		return
 	}
 
-	respond.Success(c, respond.ToIndexerFileResponse(file))
+	resp := respond.ToIndexerFileResponse(file)
+	// Media metadata is optional, the file is returned without it on lookup errors
+	if metadata, err := h.indexerFileService.GetMediaMetadataByPinID(pinID); err == nil {
+		resp.Media = respond.ToIndexerMediaMetadataResponse(metadata)
+	}
+
+	respond.Success(c, resp)
 }
 
 // GetByCreatorAddress get file list by creator address
@@ -123,12 +145,13 @@ func (h *IndexerQueryHandler) GetByCreatorMetaID(c *gin.Context) {
 
 // ListFiles get file list with cursor pagination
 // @Summary      Query file list
-// @Description  Query file list with cursor pagination
+// @Description  Query file list with cursor pagination. With mismatch=true only files whose detected content contradicts the declared content type are returned.
 // @Tags         Indexer File Query
 // @Accept       json
 // @Produce      json
-// @Param        cursor  query  int  false  "Cursor (last file ID)" default(0)
-// @Param        size    query  int  false  "Page size"             default(20)
+// @Param        cursor    query  int   false  "Cursor (last file ID)"                    default(0)
+// @Param        size      query  int   false  "Page size"                                default(20)
+// @Param        mismatch  query  bool  false  "Only files with a content type mismatch"  default(false)
 // @Success      200     {object}  respond.Response{data=respond.IndexerFileListResponse}
 // @Failure      500     {object}  respond.Response
 // @Router       /files [get]
@@ -140,8 +163,14 @@ func (h *IndexerQueryHandler) ListFiles(c *gin.Context) {
 	cursor, _ := strconv.ParseInt(cursorStr, 10, 64)
 	size, _ := strconv.Atoi(sizeStr)
 
+	mismatch, _ := strconv.ParseBool(c.DefaultQuery("mismatch", "false"))
+
 	// Query file list
-	files, nextCursor, hasMore, err := h.indexerFileService.ListFiles(cursor, size)
+	listFiles := h.indexerFileService.ListFiles
+	if mismatch {
+		listFiles = h.indexerFileService.ListContentTypeMismatchFiles
+	}
+	files, nextCursor, hasMore, err := listFiles(cursor, size)
 	if err != nil {
 		respond.ServerError(c, err.Error())
 		return
@@ -152,13 +181,14 @@ func (h *IndexerQueryHandler) ListFiles(c *gin.Context) {
 
 // GetFileContent get file content by PIN ID
 // @Summary      Get file content
-// @Description  Get file content by PIN ID
+// @Description  Get file content by PIN ID. HTML, XML and script content is served as an attachment, SVG is served as a sanitized copy.
 // @Tags         Indexer File Query
 // @Accept       json
 // @Produce      octet-stream
 // @Param        pinId  path      string  true  "PIN ID"
 // @Success      200    {file}    binary
 // @Failure      404    {object}  respond.Response
+// @Failure      451    {object}  respond.Response
 // @Router       /files/content/{pinId} [get]
 func (h *IndexerQueryHandler) GetFileContent(c *gin.Context) {
 	pinID := c.Param("pinId")
@@ -167,18 +197,241 @@ func (h *IndexerQueryHandler) GetFileContent(c *gin.Context) {
 		return
 	}
 
-	content, contentType, fileName, err := h.indexerFileService.GetFileContent(pinID)
+	served, err := h.safeContentService.GetFileContent(pinID)
+	if err != nil {
+		respondNotFound(c, err)
+		return
+	}
+
+	writeServedContent(c, served)
+}
+
+// GetFilePreview get rendered preview of a text file by PIN ID
+// @Summary      Get text preview
+// @Description  Render a text PIN as an embeddable, sanitized HTML fragment: Markdown is converted, JSON is pretty-printed and source code is syntax-highlighted (span classes tok-keyword, tok-string, tok-comment, tok-number, tok-literal, tok-tag, tok-attr). The charset is detected and transcoded to UTF-8. Long files are truncated with a "view full" link.
+// @Tags         Indexer File Query
+// @Accept       json
+// @Produce      json
+// @Param        pinId  path      string  true  "PIN ID"
+// @Success      200    {object}  respond.Response{data=respond.IndexerFilePreviewResponse}
+// @Failure      400    {object}  respond.Response
+// @Failure      404    {object}  respond.Response
+// @Failure      451    {object}  respond.Response
+// @Router       /files/{pinId}/preview [get]
+func (h *IndexerQueryHandler) GetFilePreview(c *gin.Context) {
+	pinID := c.Param("pinId")
+	if pinID == "" {
+		respond.InvalidParam(c, "pinId is required")
+		return
+	}
+
+	rendered, err := h.previewService.GetPreview(pinID)
 	if err != nil {
-		respond.NotFound(c, err.Error())
+		if errors.Is(err, preview.ErrUnsupported) || errors.Is(err, preview.ErrBinary) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respondNotFound(c, err)
+		return
+	}
+
+	respond.Success(c, respond.ToIndexerFilePreviewResponse(pinID, indexer_service.ContentURL(pinID), rendered))
+}
+
+// GetFileThumbnail get resized image by PIN ID
+// @Summary      Get image thumbnail
+// @Description  Resize a JPEG/PNG/GIF (first frame) image file. Derivatives are cached, so repeated requests are cheap.
+// @Tags         Indexer File Query
+// @Accept       json
+// @Produce      image/jpeg
+// @Produce      image/png
+// @Param        pinId   path      string  true   "PIN ID"
+// @Param        w       query     int     false  "Width (0 = derived from height)"
+// @Param        h       query     int     false  "Height (0 = derived from width)"
+// @Param        fit     query     string  false  "Fit mode: contain/cover/fill" default(contain)
+// @Param        format  query     string  false  "Output format: jpeg/png (default: png for png/gif sources, jpeg otherwise)"
+// @Success      200     {file}    binary
+// @Failure      400     {object}  respond.Response
+// @Failure      404     {object}  respond.Response
+// @Failure      451     {object}  respond.Response
+// @Router       /files/thumbnail/{pinId} [get]
+func (h *IndexerQueryHandler) GetFileThumbnail(c *gin.Context) {
+	pinID := c.Param("pinId")
+	if pinID == "" {
+		respond.InvalidParam(c, "pinId is required")
 		return
 	}
 
-	// Set response headers
-	c.Header("Content-Type", contentType)
-	c.Header("Content-Disposition", "inline; filename=\""+fileName+"\"")
+	width, errW := strconv.Atoi(c.DefaultQuery("w", "0"))
+	height, errH := strconv.Atoi(c.DefaultQuery("h", "0"))
+	if errW != nil || errH != nil {
+		respond.InvalidParam(c, "w and h must be integers")
+		return
+	}
+
+	opts := indexer_service.ThumbnailOptions{
+		Width:  width,
+		Height: height,
+		Fit:    c.Query("fit"),
+		Format: c.Query("format"),
+	}
+
+	content, contentType, err := h.thumbnailService.GetThumbnail(pinID, opts)
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrInvalidThumbnailOptions) || errors.Is(err, indexer_service.ErrNotImage) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respondNotFound(c, err)
+		return
+	}
+
+	// Derivatives of on-chain content never change
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
 	c.Data(200, contentType, content)
 }
 
+// GetSimilarFiles get near-duplicate images by PIN ID
+// @Summary      Get similar images
+// @Description  Find images that look like the given image PIN (re-encoded, resized or slightly edited copies) by perceptual hash Hamming distance. Results are ordered by distance, then by timestamp (earliest first). truncated is set when too many images share a hash band with the query image and only part of them were checked.
+// @Tags         Indexer File Query
+// @Accept       json
+// @Produce      json
+// @Param        pinId         path   string  true   "Image PIN ID"
+// @Param        max_distance  query  int     false  "Maximum Hamming distance (0-11)" default(8)
+// @Param        size          query  int     false  "Maximum number of results"       default(20)
+// @Success      200  {object}  respond.Response{data=respond.IndexerSimilarFileListResponse}
+// @Failure      400  {object}  respond.Response
+// @Failure      404  {object}  respond.Response
+// @Failure      451  {object}  respond.Response
+// @Router       /files/{pinId}/similar [get]
+func (h *IndexerQueryHandler) GetSimilarFiles(c *gin.Context) {
+	pinID := c.Param("pinId")
+	if pinID == "" {
+		respond.InvalidParam(c, "pinId is required")
+		return
+	}
+
+	maxDistance, err := strconv.Atoi(c.DefaultQuery("max_distance", strconv.Itoa(indexer_service.DefaultSimilarDistance)))
+	if err != nil {
+		respond.InvalidParam(c, "max_distance must be an integer")
+		return
+	}
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	similar, truncated, err := h.indexerFileService.GetSimilarFiles(pinID, maxDistance, size)
+	if err != nil {
+		switch {
+		case errors.Is(err, indexer_service.ErrInvalidSimilarDistance):
+			respond.InvalidParam(c, err.Error())
+		case errors.Is(err, indexer_service.ErrImageHashNotFound):
+			respond.NotFound(c, err.Error())
+		case errors.Is(err, indexer_service.ErrContentBlocked):
+			respond.UnavailableForLegalReasons(c, err.Error())
+		default:
+			respond.ServerError(c, err.Error())
+		}
+		return
+	}
+
+	resp := respond.IndexerSimilarFileListResponse{
+		PinID:       pinID,
+		MaxDistance: maxDistance,
+		Truncated:   truncated,
+		Files:       make([]respond.IndexerSimilarFileResponse, 0, len(similar)),
+	}
+	for _, item := range similar {
+		resp.Files = append(resp.Files, respond.ToIndexerSimilarFileResponse(item.File, item.Distance))
+	}
+
+	respond.Success(c, resp)
+}
+
+// GetArchiveEntries list entries of an archive by PIN ID
+// @Summary      List archive entries
+// @Description  List the entries of a ZIP, TAR or tar.gz archive PIN with sizes and types. Archives exceeding the entry count, expanded size or compression ratio limits are rejected. The index is cached after the first listing.
+// @Tags         Indexer File Query
+// @Accept       json
+// @Produce      json
+// @Param        pinId  path      string  true  "Archive PIN ID"
+// @Success      200    {object}  respond.Response{data=respond.IndexerArchiveEntryListResponse}
+// @Failure      400    {object}  respond.Response
+// @Failure      404    {object}  respond.Response
+// @Failure      451    {object}  respond.Response
+// @Router       /files/{pinId}/entries [get]
+func (h *IndexerQueryHandler) GetArchiveEntries(c *gin.Context) {
+	pinID := c.Param("pinId")
+	if pinID == "" {
+		respond.InvalidParam(c, "pinId is required")
+		return
+	}
+
+	index, err := h.archiveService.GetEntries(pinID)
+	if err != nil {
+		respondArchiveError(c, err)
+		return
+	}
+
+	respond.Success(c, respond.ToIndexerArchiveEntryListResponse(pinID, index))
+}
+
+// GetArchiveEntryContent stream a single archive entry by PIN ID and entry path
+// @Summary      Get archive entry content
+// @Description  Stream one regular file out of a ZIP, TAR or tar.gz archive PIN. Active content (HTML, XML, SVG, script) is served as an attachment.
+// @Tags         Indexer File Query
+// @Accept       json
+// @Produce      octet-stream
+// @Param        pinId  path      string  true  "Archive PIN ID"
+// @Param        path   path      string  true  "Entry path inside the archive, e.g. docs/readme.txt"
+// @Success      200    {file}    binary
+// @Failure      400    {object}  respond.Response
+// @Failure      404    {object}  respond.Response
+// @Failure      451    {object}  respond.Response
+// @Router       /files/{pinId}/entries/{path} [get]
+func (h *IndexerQueryHandler) GetArchiveEntryContent(c *gin.Context) {
+	pinID := c.Param("pinId")
+	entryPath := strings.TrimPrefix(c.Param("path"), "/")
+	if pinID == "" || entryPath == "" {
+		respond.InvalidParam(c, "pinId and path are required")
+		return
+	}
+
+	entry, err := h.archiveService.OpenEntry(pinID, entryPath)
+	if err != nil {
+		respondArchiveError(c, err)
+		return
+	}
+	defer entry.Reader.Close()
+
+	// Entries of on-chain content never change
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", indexer_service.ContentSecurityPolicy(entry.ContentType))
+	c.Header("Content-Disposition", indexer_service.ContentDisposition(entry.Attachment, entry.FileName))
+	c.DataFromReader(200, entry.Size, entry.ContentType, entry.Reader, nil)
+}
+
+// respondNotFound map lookup errors to responses, blocked content gets 451
+func respondNotFound(c *gin.Context, err error) {
+	if errors.Is(err, indexer_service.ErrContentBlocked) {
+		respond.UnavailableForLegalReasons(c, err.Error())
+		return
+	}
+	respond.NotFound(c, err.Error())
+}
+
+// respondArchiveError map archive errors to responses
+func respondArchiveError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, indexer_service.ErrNotArchive), errors.Is(err, archive.ErrUnsupportedFormat),
+		errors.Is(err, archive.ErrTooManyEntries), errors.Is(err, archive.ErrTooLarge),
+		errors.Is(err, archive.ErrCompressionRatio), errors.Is(err, archive.ErrNotRegularFile):
+		respond.InvalidParam(c, err.Error())
+	default:
+		respondNotFound(c, err)
+	}
+}
+
 // GetSyncStatus get indexer sync status
 // @Summary      Get sync status
 // @Description  Get indexer synchronization status (includes latest block height from node)
@@ -207,7 +460,7 @@ func (h *IndexerQueryHandler) GetSyncStatus(c *gin.Context) {
 
 // GetStats get indexer statistics
 // @Summary      Get statistics
-// @Description  Get indexer statistics (total files count, etc.)
+// @Description  Get indexer statistics (total files count, storage cache hit/miss stats, etc.)
 // @Tags         Indexer Status
 // @Accept       json
 // @Produce      json
@@ -222,7 +475,7 @@ func (h *IndexerQueryHandler) GetStats(c *gin.Context) {
 		return
 	}
 
-	respond.Success(c, respond.ToIndexerStatsResponse(filesCount))
+	respond.Success(c, respond.ToIndexerStatsResponse(filesCount, h.indexerFileService.GetStorageCacheStats()))
 }
 
 // ListAvatars get avatar list with cursor pagination
@@ -263,6 +516,7 @@ func (h *IndexerQueryHandler) ListAvatars(c *gin.Context) {
 // @Param        metaId  path  string  true  "MetaID"
 // @Success      200     {object}  respond.Response{data=respond.IndexerAvatarResponse}
 // @Failure      404     {object}  respond.Response
+// @Failure      451     {object}  respond.Response
 // @Router       /avatars/metaid/{metaId} [get]
 func (h *IndexerQueryHandler) GetLatestAvatarByMetaID(c *gin.Context) {
 	metaID := c.Param("metaId")
@@ -273,7 +527,7 @@ func (h *IndexerQueryHandler) GetLatestAvatarByMetaID(c *gin.Context) {
 
 	avatar, err := h.indexerFileService.GetLatestAvatarByMetaID(metaID)
 	if err != nil {
-		respond.NotFound(c, err.Error())
+		respondNotFound(c, err)
 		return
 	}
 
@@ -289,6 +543,7 @@ func (h *IndexerQueryHandler) GetLatestAvatarByMetaID(c *gin.Context) {
 // @Param        address  path  string  true  "Address"
 // @Success      200      {object}  respond.Response{data=respond.IndexerAvatarResponse}
 // @Failure      404      {object}  respond.Response
+// @Failure      451      {object}  respond.Response
 // @Router       /avatars/address/{address} [get]
 func (h *IndexerQueryHandler) GetLatestAvatarByAddress(c *gin.Context) {
 	address := c.Param("address")
@@ -299,7 +554,7 @@ func (h *IndexerQueryHandler) GetLatestAvatarByAddress(c *gin.Context) {
 
 	avatar, err := h.indexerFileService.GetLatestAvatarByAddress(address)
 	if err != nil {
-		respond.NotFound(c, err.Error())
+		respondNotFound(c, err)
 		return
 	}
 
@@ -308,13 +563,14 @@ func (h *IndexerQueryHandler) GetLatestAvatarByAddress(c *gin.Context) {
 
 // GetAvatarContent get avatar content by PIN ID
 // @Summary      Get avatar content
-// @Description  Get avatar content by PIN ID
+// @Description  Get avatar content by PIN ID. SVG avatars are served as a sanitized copy.
 // @Tags         Indexer Avatar Query
 // @Accept       json
 // @Produce      octet-stream
 // @Param        pinId  path      string  true  "PIN ID"
 // @Success      200    {file}    binary
 // @Failure      404    {object}  respond.Response
+// @Failure      451    {object}  respond.Response
 // @Router       /avatars/content/{pinId} [get]
 func (h *IndexerQueryHandler) GetAvatarContent(c *gin.Context) {
 	pinID := c.Param("pinId")
@@ -323,14 +579,76 @@ func (h *IndexerQueryHandler) GetAvatarContent(c *gin.Context) {
 		return
 	}
 
-	content, contentType, fileName, err := h.indexerFileService.GetAvatarContent(pinID)
+	served, err := h.safeContentService.GetAvatarContent(pinID)
 	if err != nil {
-		respond.NotFound(c, err.Error())
+		respondNotFound(c, err)
 		return
 	}
 
-	// Set response headers
-	c.Header("Content-Type", contentType)
-	c.Header("Content-Disposition", "inline; filename=\""+fileName+"\"")
-	c.Data(200, contentType, content)
+	writeServedContent(c, served)
+}
+
+// writeServedContent write raw content with hardened headers
+// nosniff stops browsers from upgrading content to an active type, the CSP sandbox
+// keeps any document that is rendered anyway from running script on our origin.
+func writeServedContent(c *gin.Context, served *indexer_service.ServedContent) {
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", indexer_service.ContentSecurityPolicy(served.ContentType))
+	c.Header("Content-Disposition", indexer_service.ContentDisposition(served.Attachment, served.FileName))
+	c.Data(200, served.ContentType, served.Content)
+}
+
+// GetAvatarImageByMetaID get standard-size square avatar image by MetaID
+// @Summary      Get avatar image by MetaID
+// @Description  Get the latest avatar as a square, center-cropped image. Sizes are rounded up to 32/48/64/96/128/256/512. Users without a decodable avatar get a deterministic identicon.
+// @Tags         Indexer Avatar Query
+// @Accept       json
+// @Produce      image/jpeg
+// @Produce      image/png
+// @Param        metaId  path      string  true   "MetaID"
+// @Param        size    query     int     false  "Size in pixels (32-512)" default(128)
+// @Success      200     {file}    binary
+// @Failure      400     {object}  respond.Response
+// @Failure      500     {object}  respond.Response
+// @Failure      451     {object}  respond.Response
+// @Router       /avatars/metaid/{metaId}/image [get]
+func (h *IndexerQueryHandler) GetAvatarImageByMetaID(c *gin.Context) {
+	metaID := c.Param("metaId")
+	if metaID == "" {
+		respond.InvalidParam(c, "metaId is required")
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "0"))
+	if err != nil {
+		respond.InvalidParam(c, "size must be an integer")
+		return
+	}
+
+	avatarImage, err := h.avatarImageService.GetAvatarImage(metaID, size)
+	if err != nil {
+		switch {
+		case errors.Is(err, indexer_service.ErrInvalidAvatarSize):
+			respond.InvalidParam(c, err.Error())
+		case errors.Is(err, indexer_service.ErrContentBlocked):
+			respond.UnavailableForLegalReasons(c, err.Error())
+		default:
+			respond.ServerError(c, err.Error())
+		}
+		return
+	}
+
+	// The latest avatar of a MetaID can change, so allow short caching and revalidation by avatar PIN
+	etag := fmt.Sprintf("\"%s-%d\"", avatarImage.PinID, avatarImage.Size)
+	if avatarImage.PinID == "" {
+		etag = fmt.Sprintf("\"identicon-%s-%d\"", metaID, avatarImage.Size)
+	}
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(304)
+		return
+	}
+
+	c.Data(200, avatarImage.ContentType, avatarImage.Content)
 }
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"meta-media-service/controller/respond"
+	"meta-media-service/service/upload_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SponsorHandler fee sponsorship admin handler
+type SponsorHandler struct {
+	sponsorService *upload_service.SponsorService
+}
+
+// NewSponsorHandler create sponsor handler instance
+func NewSponsorHandler(sponsorService *upload_service.SponsorService) *SponsorHandler {
+	return &SponsorHandler{
+		sponsorService: sponsorService,
+	}
+}
+
+// SponsoredUpload upload a file paid by the sponsor wallet
+// @Summary      Sponsored upload
+// @Description  Upload a file in one request with chain fees paid by the sponsor wallet. The user signs the wallet message "meta-media sponsored upload\nmetaId: {sha256(address)}\noperation: {operation}\npath: {path}\ncontentType: {contentType}\nsha256: {file sha256}\ntimestamp: {timestamp}" and receives an owner output, which makes the PINs theirs. Eligibility (allowlist, content types) and the daily byte and fee budgets per MetaID are set in the sponsor config.
+// @Tags         Sponsorship
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file         formData  file    false  "File to upload (required unless uploadId is given)"
+// @Param        uploadId     formData  string  false  "ID of a completed resumable upload, used instead of file"
+// @Param        path         formData  string  true   "File path"
+// @Param        address      formData  string  true   "User address, owner of the PINs"
+// @Param        operation    formData  string  false  "Operation type"  default(create)
+// @Param        contentType  formData  string  false  "Content type (defaults to the file type, then application/octet-stream)"
+// @Param        timestamp    formData  int     true   "Unix seconds of the signature"
+// @Param        signature    formData  string  true   "Base64 wallet message signature"
+// @Success      200  {object}  respond.Response{data=upload_service.SponsoredUploadResponse}  "Upload successful, return transaction ID, Pin ID and sponsored cost"
+// @Failure      400  {object}  respond.Response  "Parameter error, not eligible or daily budget exceeded"
+// @Failure      401  {object}  respond.Response  "Invalid signature or sponsorship disabled"
+// @Failure      500  {object}  respond.Response  "Server error or broadcast failed"
+// @Router       /files/sponsored-upload [post]
+func (h *UploadHandler) SponsoredUpload(c *gin.Context) {
+	content, fileName, fileContentType, ok := h.readUploadContent(c)
+	if !ok {
+		return
+	}
+	path := c.PostForm("path")
+	if path == "" {
+		respond.InvalidParam(c, "path is required")
+		return
+	}
+	address := c.PostForm("address")
+	if address == "" {
+		respond.InvalidParam(c, "address is required")
+		return
+	}
+	timestamp, err := strconv.ParseInt(c.PostForm("timestamp"), 10, 64)
+	if err != nil {
+		respond.InvalidParam(c, "timestamp must be unix seconds")
+		return
+	}
+	signature := c.PostForm("signature")
+	if signature == "" {
+		respond.InvalidParam(c, "signature is required")
+		return
+	}
+	contentType := c.PostForm("contentType")
+	if contentType == "" {
+		contentType = fileContentType
+	}
+
+	resp, err := h.sponsorService.Upload(&upload_service.SponsoredUploadRequest{
+		Upload: &upload_service.UploadRequest{
+			Address:     address,
+			FileName:    fileName,
+			Content:     content,
+			Path:        path,
+			Operation:   c.PostForm("operation"),
+			ContentType: contentType,
+		},
+		Timestamp: timestamp,
+		Signature: signature,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, upload_service.ErrSponsorDisabled), errors.Is(err, upload_service.ErrSponsorUnauthorized):
+			respond.Unauthorized(c, err.Error())
+		case errors.Is(err, upload_service.ErrSponsorNotEligible),
+			errors.Is(err, upload_service.ErrSponsorBudgetExceeded),
+			errors.Is(err, upload_service.ErrInsufficientFunds):
+			respond.InvalidParam(c, err.Error())
+		default:
+			respond.ServerError(c, err.Error())
+		}
+		return
+	}
+	respond.Success(c, resp)
+}
+
+// GetSponsorReport get sponsorship costs with cursor pagination
+// @Summary      Sponsorship report
+// @Description  Totals (uploads, bytes of successful uploads, transactions, satoshis) of sponsored uploads matching the filters, and a page of records, newest first
+// @Tags         Uploader Admin
+// @Produce      json
+// @Security     AdminToken
+// @Param        metaId  query  string  false  "Only uploads of this MetaID"
+// @Param        from    query  string  false  "First day (UTC, YYYY-MM-DD)"
+// @Param        to      query  string  false  "Last day (UTC, YYYY-MM-DD)"
+// @Param        cursor  query  int     false  "Cursor (last record ID)" default(0)
+// @Param        size    query  int     false  "Page size"               default(20)
+// @Success      200     {object}  respond.Response{data=upload_service.SponsorReportResponse}
+// @Failure      400     {object}  respond.Response
+// @Failure      401     {object}  respond.Response
+// @Router       /admin/sponsorships [get]
+func (h *SponsorHandler) GetSponsorReport(c *gin.Context) {
+	cursor, _ := strconv.ParseInt(c.DefaultQuery("cursor", "0"), 10, 64)
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	report, err := h.sponsorService.Report(c.Query("metaId"), c.Query("from"), c.Query("to"), cursor, size)
+	if err != nil {
+		if errors.Is(err, upload_service.ErrInvalidReportRange) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, report)
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"meta-media-service/conf"
+	"meta-media-service/model"
+	"meta-media-service/service/upload_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tusContentType content type of PATCH request bodies
+const tusContentType = "application/offset+octet-stream"
+
+// TusHandler resumable upload (tus protocol) handler
+// Responses follow the tus protocol (status codes and headers) instead of the JSON envelope.
+type TusHandler struct {
+	tusService *upload_service.TusService
+}
+
+// NewTusHandler create resumable upload handler instance
+func NewTusHandler(tusService *upload_service.TusService) *TusHandler {
+	return &TusHandler{
+		tusService: tusService,
+	}
+}
+
+// TusResumableMiddleware set Tus-Resumable on every response and reject unsupported protocol versions
+func TusResumableMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Tus-Resumable", upload_service.TusVersion)
+		if c.Request.Method != http.MethodOptions && c.GetHeader("Tus-Resumable") != upload_service.TusVersion {
+			c.Header("Tus-Version", upload_service.TusVersion)
+			c.String(http.StatusPreconditionFailed, "unsupported tus version")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Options report tus server capabilities
+// @Summary      Resumable upload capabilities
+// @Description  tus protocol discovery: supported version, extensions and max upload size
+// @Tags         File Upload
+// @Success      204
+// @Header       204  {string}  Tus-Version    "Supported tus versions"
+// @Header       204  {string}  Tus-Extension  "Supported extensions"
+// @Header       204  {int}     Tus-Max-Size   "Max upload size (bytes)"
+// @Router       /files/uploads [options]
+func (h *TusHandler) Options(c *gin.Context) {
+	c.Header("Tus-Version", upload_service.TusVersion)
+	c.Header("Tus-Extension", upload_service.TusExtensions)
+	c.Header("Tus-Max-Size", strconv.FormatInt(conf.Cfg.Uploader.MaxFileSize, 10))
+	c.Status(http.StatusNoContent)
+}
+
+// Create create a resumable upload
+// @Summary      Create resumable upload
+// @Description  tus creation: reserve an upload of Upload-Length bytes. The Location header is the upload URL. Send the file name and type in Upload-Metadata as "filename <base64>,filetype <base64>".
+// @Tags         File Upload
+// @Param        Tus-Resumable    header  string  true   "tus version"  default(1.0.0)
+// @Param        Upload-Length    header  int     true   "Total size (bytes)"
+// @Param        Upload-Metadata  header  string  false  "Comma separated key/base64 value pairs (filename, filetype)"
+// @Success      201
+// @Header       201  {string}  Location        "Upload URL"
+// @Header       201  {string}  Upload-Expires  "Expiry of the upload"
+// @Failure      400  {string}  string  "Invalid Upload-Length or Upload-Metadata"
+// @Failure      412  {string}  string  "Unsupported tus version"
+// @Failure      413  {string}  string  "Upload exceeds max file size"
+// @Router       /files/uploads [post]
+func (h *TusHandler) Create(c *gin.Context) {
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		c.String(http.StatusBadRequest, "Upload-Length must be a non-negative integer")
+		return
+	}
+
+	upload, err := h.tusService.Create(length, c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+upload.UploadId)
+	c.Header("Upload-Offset", "0")
+	setUploadExpires(c, upload)
+	c.Status(http.StatusCreated)
+}
+
+// Head get the offset of a resumable upload
+// @Summary      Get resumable upload offset
+// @Description  tus core: get how many bytes of the upload have been received
+// @Tags         File Upload
+// @Param        Tus-Resumable  header  string  true  "tus version"  default(1.0.0)
+// @Param        uploadId       path    string  true  "Upload ID"
+// @Success      200
+// @Header       200  {int}     Upload-Offset   "Bytes received"
+// @Header       200  {int}     Upload-Length   "Total size (bytes)"
+// @Header       200  {string}  Upload-Expires  "Expiry of the upload"
+// @Failure      404  {string}  string  "Upload not found"
+// @Failure      410  {string}  string  "Upload expired"
+// @Router       /files/uploads/{uploadId} [head]
+func (h *TusHandler) Head(c *gin.Context) {
+	upload, err := h.tusService.Get(c.Param("uploadId"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.UploadLength, 10))
+	if upload.Metadata != "" {
+		c.Header("Upload-Metadata", upload.Metadata)
+	}
+	setUploadExpires(c, upload)
+	c.Status(http.StatusOK)
+}
+
+// Patch append bytes to a resumable upload
+// @Summary      Append to resumable upload
+// @Description  tus core: append the request body at Upload-Offset. After an interruption, get the offset with HEAD and continue from there.
+// @Tags         File Upload
+// @Accept       application/offset+octet-stream
+// @Param        Tus-Resumable  header  string  true  "tus version"  default(1.0.0)
+// @Param        Upload-Offset  header  int     true  "Offset the body starts at"
+// @Param        uploadId       path    string  true  "Upload ID"
+// @Success      204
+// @Header       204  {int}     Upload-Offset   "Bytes received"
+// @Header       204  {string}  Upload-Expires  "Expiry of the upload"
+// @Failure      400  {string}  string  "Invalid Upload-Offset"
+// @Failure      404  {string}  string  "Upload not found"
+// @Failure      409  {string}  string  "Upload-Offset does not match the received bytes"
+// @Failure      410  {string}  string  "Upload expired"
+// @Failure      413  {string}  string  "Body exceeds Upload-Length"
+// @Failure      415  {string}  string  "Content-Type is not application/offset+octet-stream"
+// @Router       /files/uploads/{uploadId} [patch]
+func (h *TusHandler) Patch(c *gin.Context) {
+	if c.ContentType() != tusContentType {
+		c.String(http.StatusUnsupportedMediaType, "Content-Type must be "+tusContentType)
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.String(http.StatusBadRequest, "Upload-Offset must be a non-negative integer")
+		return
+	}
+
+	uploadId := c.Param("uploadId")
+	upload, err := h.tusService.Get(uploadId)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	if c.Request.ContentLength > upload.UploadLength-offset {
+		c.String(http.StatusRequestEntityTooLarge, "body exceeds Upload-Length")
+		return
+	}
+
+	upload, err = h.tusService.Write(uploadId, offset, c.Request.Body)
+	if err != nil {
+		if upload == nil || errors.Is(err, upload_service.ErrOffsetMismatch) {
+			h.respondError(c, err)
+			return
+		}
+		// Bytes staged before the failure are kept, the client resumes from the reported offset
+		log.Printf("Resumable upload write failed: uploadId=%s, offset=%d, err=%v", uploadId, upload.UploadOffset, err)
+		c.Header("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+	setUploadExpires(c, upload)
+	c.Status(http.StatusNoContent)
+}
+
+// Delete terminate a resumable upload
+// @Summary      Delete resumable upload
+// @Description  tus termination: delete the upload and its staged bytes
+// @Tags         File Upload
+// @Param        Tus-Resumable  header  string  true  "tus version"  default(1.0.0)
+// @Param        uploadId       path    string  true  "Upload ID"
+// @Success      204
+// @Failure      404  {string}  string  "Upload not found"
+// @Failure      410  {string}  string  "Upload expired"
+// @Router       /files/uploads/{uploadId} [delete]
+func (h *TusHandler) Delete(c *gin.Context) {
+	if err := h.tusService.Terminate(c.Param("uploadId")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondError map resumable upload errors to tus status codes
+func (h *TusHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, upload_service.ErrUploadNotFound):
+		c.String(http.StatusNotFound, err.Error())
+	case errors.Is(err, upload_service.ErrUploadExpired):
+		c.String(http.StatusGone, err.Error())
+	case errors.Is(err, upload_service.ErrOffsetMismatch), errors.Is(err, upload_service.ErrUploadLocked):
+		c.String(http.StatusConflict, err.Error())
+	case errors.Is(err, upload_service.ErrUploadTooLarge):
+		c.String(http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, upload_service.ErrInvalidMetadata):
+		c.String(http.StatusBadRequest, err.Error())
+	default:
+		c.String(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// setUploadExpires set the Upload-Expires header of an upload
+func setUploadExpires(c *gin.Context, upload *model.TusUpload) {
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
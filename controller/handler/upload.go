@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"strconv"
 
 	"meta-media-service/common"
 	"meta-media-service/conf"
 	"meta-media-service/controller/respond"
+	"meta-media-service/model"
 	"meta-media-service/service/upload_service"
 
 	"github.com/gin-gonic/gin"
@@ -15,13 +19,19 @@ import (
 
 // UploadHandler upload handler
 type UploadHandler struct {
-	uploadService *upload_service.UploadService
+	uploadService    *upload_service.UploadService
+	tusService       *upload_service.TusService
+	assistantService *upload_service.AssistantService
+	sponsorService   *upload_service.SponsorService
 }
 
 // NewUploadHandler create upload handler instance
-func NewUploadHandler(uploadService *upload_service.UploadService) *UploadHandler {
+func NewUploadHandler(uploadService *upload_service.UploadService, tusService *upload_service.TusService, assistantService *upload_service.AssistantService, sponsorService *upload_service.SponsorService) *UploadHandler {
 	return &UploadHandler{
-		uploadService: uploadService,
+		uploadService:    uploadService,
+		tusService:       tusService,
+		assistantService: assistantService,
+		sponsorService:   sponsorService,
 	}
 }
 
@@ -47,6 +57,14 @@ type TxInputUtxoRequest struct {
 	SignMode string `json:"signMode"`
 }
 
+// BtcInputRequest BTC payer UTXO of a commit transaction
+type BtcInputRequest struct {
+	TxId         string `json:"txId"`
+	Vout         uint32 `json:"vout"`
+	Amount       int64  `json:"amount"`
+	ScriptPubKey string `json:"scriptPubKey"`
+}
+
 // TxOutputRequest transaction output request
 type TxOutputRequest struct {
 	Address string `json:"address" binding:"required"`
@@ -61,44 +79,50 @@ type PreUploadResponseData struct {
 	TxId      string `json:"txId" example:"abc123..." description:"Transaction ID"`
 	PinId     string `json:"pinId" example:"abc123...i0" description:"Pin ID"`
 	PreTxRaw  string `json:"preTxRaw" example:"0100000..." description:"Pre-transaction raw data (hex)"`
-	Status    string `json:"status" example:"pending" description:"Status: pending, success, failed"`
+	Status    string `json:"status" example:"pending" description:"Status: pending, success (file transaction confirmed), failed"`
+	TxState   string `json:"txState" example:"built" description:"State of the file transaction: built, broadcast, in_mempool, confirmed, failed, replaced. A file whose transaction was broadcast is not committed again"`
 	Message   string `json:"message" example:"success" description:"Message"`
 	CalTxFee  int64  `json:"calTxFee" example:"1000" description:"Calculated transaction fee (satoshis)"`
 	CalTxSize int64  `json:"calTxSize" example:"500" description:"Calculated transaction size (bytes)"`
+	ChunkType string `json:"chunkType" example:"single" description:"Chunk type: single, multi (file above chunk threshold)"`
+
+	Chunks []upload_service.PreUploadChunk `json:"chunks" description:"Chunk transactions to sign and commit one by one (multi only)"`
+	Inputs []upload_service.FundedInput    `json:"inputs" description:"Payer inputs of preTxRaw with their sighash preimages (only with payerAddress)"`
+
+	Chain       string `json:"chain" example:"btc" description:"Chain: mvc, btc"`
+	CommitPsbt  string `json:"commitPsbt" example:"cHNidP8B..." description:"BTC commit PSBT to sign (base64, btc only)"`
+	CommitTxId  string `json:"commitTxId" example:"def456..." description:"BTC commit transaction ID (btc only)"`
+	RevealTxRaw string `json:"revealTxRaw" example:"0200000..." description:"BTC signed reveal transaction, broadcast after the commit (btc only)"`
 }
 
 // PreUpload pre-upload file
 // @Summary      Pre-upload file
-// @Description  Upload file and generate unsigned transaction, return transaction for client signing
+// @Description  Upload file and generate unsigned transaction, return transaction for client signing. Files above the chunk threshold are split into chunk PINs: sign and commit each entry of chunks with /files/commit-chunk, then sign indexPreTxRaw from /files/{fileId}/progress and commit it with /files/commit-upload. With payerAddress the uploader selects unreserved UTXOs of the payer at the fee rate and returns fully specified transactions: sign every entry of inputs (sigHash, SIGHASH_ALL|FORKID) and put the unlocking scripts in the transaction. With chain btc the file is inscribed with a taproot commit transaction funded from inputs (P2WPKH or P2TR) and a reveal transaction that sends the PIN to address: sign commitPsbt and submit it with /files/commit-upload, which broadcasts the commit and then the reveal. txId and pinId are those of the reveal.
 // @Tags         File Upload
 // @Accept       multipart/form-data
 // @Produce      json
-// @Param        file           formData  file    true   "File to upload"
+// @Param        file           formData  file    false  "File to upload (required unless uploadId is given)"
+// @Param        uploadId       formData  string  false  "ID of a completed resumable upload, used instead of file"
 // @Param        path           formData  string  true   "File path"
 // @Param        operation      formData  string  false  "Operation type"        default(create)
 // @Param        contentType    formData  string  false  "Content type"
 // @Param        changeAddress  formData  string  false  "Change address"
 // @Param        metaId         formData  string  false  "MetaID"
 // @Param        address        formData  string  false  "Address"
-// @Param        feeRate        formData  int     false  "Fee rate"           default(1)
+// @Param        feeRate        formData  int     false  "Fee rate (satoshis per byte, defaults to config, MVC rates below the configured one are raised to it)"
 // @Param        outputs        formData  string  false  "Output list json"
 // @Param        otherOutputs   formData  string  false  "Other output list json"
+// @Param        payerAddress   formData  string  false  "Payer address, its UTXOs are selected as inputs (change goes to changeAddress, defaults to payerAddress)"
+// @Param        chain          formData  string  false  "Chain to inscribe on: mvc, btc"  default(mvc)
+// @Param        inputs         formData  string  false  "BTC commit input list json: [{txId, vout, amount, scriptPubKey}] (btc only)"
 // @Success      200  {object}  respond.Response{data=PreUploadResponseData}  "Pre-upload successful, return transaction and file info"
 // @Failure      400  {object}  respond.Response  "Parameter error"
 // @Failure      500  {object}  respond.Response  "Server error"
 // @Router       /files/pre-upload [post]
 func (h *UploadHandler) PreUpload(c *gin.Context) {
 	// Read file content
-	file, header, err := c.Request.FormFile("file")
-	if err != nil {
-		respond.InvalidParam(c, "file is required")
-		return
-	}
-	defer file.Close()
-
-	content, err := ioutil.ReadAll(file)
-	if err != nil {
-		respond.ServerError(c, "failed to read file")
+	content, fileName, fileContentType, ok := h.readUploadContent(c)
+	if !ok {
 		return
 	}
 
@@ -116,7 +140,7 @@ func (h *UploadHandler) PreUpload(c *gin.Context) {
 
 	contentType := c.PostForm("contentType")
 	if contentType == "" {
-		contentType = header.Header.Get("Content-Type")
+		contentType = fileContentType
 	}
 
 	changeAddress := c.PostForm("changeAddress")
@@ -126,7 +150,7 @@ func (h *UploadHandler) PreUpload(c *gin.Context) {
 	// }
 
 	feeRateStr := c.PostForm("feeRate")
-	feeRate := int64(1)
+	feeRate := int64(0) // Defaults to config
 	if feeRateStr != "" {
 		if rate, err := strconv.ParseInt(feeRateStr, 10, 64); err == nil {
 			feeRate = rate
@@ -136,42 +160,22 @@ func (h *UploadHandler) PreUpload(c *gin.Context) {
 	// Get additional form parameters
 	metaId := c.PostForm("metaId")
 	address := c.PostForm("address")
+	payerAddress := c.PostForm("payerAddress")
 
 	// Parse outputs and otherOutputs
-	var outputs []*common.TxOutput
-	var otherOutputs []*common.TxOutput
-
-	outputsStr := c.PostForm("outputs")
-	if outputsStr != "" && outputsStr != "[]" {
-		var outputsReq []*TxOutputRequest
-		if err := json.Unmarshal([]byte(outputsStr), &outputsReq); err == nil {
-			for _, out := range outputsReq {
-				outputs = append(outputs, &common.TxOutput{
-					Address: out.Address,
-					Amount:  out.Amount,
-				})
-			}
-		}
-	}
-
-	otherOutputsStr := c.PostForm("otherOutputs")
-	if otherOutputsStr != "" && otherOutputsStr != "[]" {
-		var otherOutputsReq []*TxOutputRequest
-		if err := json.Unmarshal([]byte(otherOutputsStr), &otherOutputsReq); err == nil {
-			for _, out := range otherOutputsReq {
-				otherOutputs = append(otherOutputs, &common.TxOutput{
-					Address: out.Address,
-					Amount:  out.Amount,
-				})
-			}
-		}
+	outputs := parseTxOutputs(c.PostForm("outputs"))
+	otherOutputs := parseTxOutputs(c.PostForm("otherOutputs"))
+	btcInputs, err := parseBtcInputs(c.PostForm("inputs"))
+	if err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
 	}
 
 	// Build upload request
 	req := &upload_service.UploadRequest{
 		MetaId:        metaId,
 		Address:       address,
-		FileName:      header.Filename,
+		FileName:      fileName,
 		Content:       content,
 		Path:          path,
 		Operation:     operation,
@@ -180,11 +184,20 @@ func (h *UploadHandler) PreUpload(c *gin.Context) {
 		Outputs:       outputs,
 		OtherOutputs:  otherOutputs,
 		FeeRate:       feeRate,
+		PayerAddress:  payerAddress,
+		Chain:         c.PostForm("chain"),
+		BtcInputs:     btcInputs,
 	}
 
 	// Upload file
 	resp, err := h.uploadService.PreUpload(req)
 	if err != nil {
+		if errors.Is(err, upload_service.ErrInvalidPayer) || errors.Is(err, upload_service.ErrInsufficientFunds) ||
+			errors.Is(err, upload_service.ErrUnsupportedChain) || errors.Is(err, upload_service.ErrBtcDisabled) ||
+			errors.Is(err, upload_service.ErrInvalidBtcUpload) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
 		respond.ServerError(c, err.Error())
 		return
 	}
@@ -194,11 +207,12 @@ func (h *UploadHandler) PreUpload(c *gin.Context) {
 
 // DirectUpload direct upload file with existing PreTxHex (one-step upload)
 // @Summary      Direct upload file (one-step)
-// @Description  Upload file and add MetaID OP_RETURN output to existing PreTxHex, then broadcast immediately. This is a one-step upload process that combines building and broadcasting. Supports UTXO merge transaction for SIGHASH_SINGLE compatibility.
+// @Description  Upload file and add MetaID OP_RETURN output to existing PreTxHex, then broadcast immediately. This is a one-step upload process that combines building and broadcasting. Supports UTXO merge transaction for SIGHASH_SINGLE compatibility. Files above the chunk threshold are rejected, upload them with pre-upload. The final transaction and the merge transaction are checked like commit-upload before broadcast. MVC only, BTC uploads go through pre-upload.
 // @Tags         File Upload
 // @Accept       multipart/form-data
 // @Produce      json
-// @Param        file             formData  file    true   "File to upload"
+// @Param        file             formData  file    false  "File to upload (required unless uploadId is given)"
+// @Param        uploadId         formData  string  false  "ID of a completed resumable upload, used instead of file"
 // @Param        path             formData  string  true   "File path"
 // @Param        preTxHex         formData  string  true   "Pre-transaction hex (signed, with inputs and outputs)"
 // @Param        mergeTxHex       formData  string  false  "Merge transaction hex (optional, broadcasted before main transaction)"
@@ -207,24 +221,17 @@ func (h *UploadHandler) PreUpload(c *gin.Context) {
 // @Param        metaId           formData  string  false  "MetaID"
 // @Param        address          formData  string  false  "Address (also used as change address if changeAddress is not provided)"
 // @Param        changeAddress    formData  string  false  "Change address (optional, defaults to address)"
-// @Param        feeRate          formData  int     false  "Fee rate (satoshis per byte, optional)"
+// @Param        feeRate          formData  int     false  "Fee rate (satoshis per byte, optional, rates below the configured one are raised to it)"
 // @Param        totalInputAmount formData  int     false  "Total input amount in satoshis (optional, for automatic change calculation)"
+// @Param        chain            formData  string  false  "Chain, only mvc is supported"  default(mvc)
 // @Success      200  {object}  respond.Response{data=CommitUploadResponseData}  "Upload successful, return transaction ID and Pin ID"
-// @Failure      400  {object}  respond.Response  "Parameter error"
+// @Failure      400  {object}  respond.Response  "Parameter error or transaction rejected"
 // @Failure      500  {object}  respond.Response  "Server error"
 // @Router       /files/direct-upload [post]
 func (h *UploadHandler) DirectUpload(c *gin.Context) {
 	// Read file content
-	file, header, err := c.Request.FormFile("file")
-	if err != nil {
-		respond.InvalidParam(c, "file is required")
-		return
-	}
-	defer file.Close()
-
-	content, err := ioutil.ReadAll(file)
-	if err != nil {
-		respond.ServerError(c, "failed to read file")
+	content, fileName, fileContentType, ok := h.readUploadContent(c)
+	if !ok {
 		return
 	}
 
@@ -240,6 +247,10 @@ func (h *UploadHandler) DirectUpload(c *gin.Context) {
 		respond.InvalidParam(c, "preTxHex is required")
 		return
 	}
+	if chain := c.PostForm("chain"); chain != "" && chain != model.ChainMvc {
+		respond.InvalidParam(c, "direct upload supports mvc only, use pre-upload for "+chain)
+		return
+	}
 
 	// Get optional parameters
 	operation := c.PostForm("operation")
@@ -249,7 +260,7 @@ func (h *UploadHandler) DirectUpload(c *gin.Context) {
 
 	contentType := c.PostForm("contentType")
 	if contentType == "" {
-		contentType = header.Header.Get("Content-Type")
+		contentType = fileContentType
 	}
 
 	metaId := c.PostForm("metaId")
@@ -278,7 +289,7 @@ func (h *UploadHandler) DirectUpload(c *gin.Context) {
 	req := &upload_service.DirectUploadRequest{
 		MetaId:           metaId,
 		Address:          address,
-		FileName:         header.Filename,
+		FileName:         fileName,
 		Content:          content,
 		Path:             path,
 		Operation:        operation,
@@ -293,6 +304,10 @@ func (h *UploadHandler) DirectUpload(c *gin.Context) {
 	// Upload file (one-step: build + broadcast)
 	resp, err := h.uploadService.DirectUpload(req)
 	if err != nil {
+		if errors.Is(err, upload_service.ErrInvalidSignedTx) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
 		respond.ServerError(c, err.Error())
 		return
 	}
@@ -300,16 +315,94 @@ func (h *UploadHandler) DirectUpload(c *gin.Context) {
 	respond.Success(c, resp)
 }
 
+// parseTxOutputs parse an output list json form field, invalid json is ignored
+func parseTxOutputs(outputsStr string) []*common.TxOutput {
+	var outputs []*common.TxOutput
+	if outputsStr == "" || outputsStr == "[]" {
+		return outputs
+	}
+	var outputsReq []*TxOutputRequest
+	if err := json.Unmarshal([]byte(outputsStr), &outputsReq); err == nil {
+		for _, out := range outputsReq {
+			outputs = append(outputs, &common.TxOutput{
+				Address: out.Address,
+				Amount:  out.Amount,
+			})
+		}
+	}
+	return outputs
+}
+
+// parseBtcInputs parse a BTC commit input list json form field
+func parseBtcInputs(inputsStr string) ([]*common.BtcUtxo, error) {
+	var inputs []*common.BtcUtxo
+	if inputsStr == "" || inputsStr == "[]" {
+		return inputs, nil
+	}
+	var inputsReq []*BtcInputRequest
+	if err := json.Unmarshal([]byte(inputsStr), &inputsReq); err != nil {
+		return nil, errors.New("inputs must be a json list of {txId, vout, amount, scriptPubKey}")
+	}
+	for _, in := range inputsReq {
+		pkScript, err := hex.DecodeString(in.ScriptPubKey)
+		if err != nil || in.TxId == "" || in.Amount <= 0 {
+			return nil, fmt.Errorf("invalid input %s:%d", in.TxId, in.Vout)
+		}
+		inputs = append(inputs, &common.BtcUtxo{
+			TxId:     in.TxId,
+			Vout:     in.Vout,
+			Amount:   in.Amount,
+			PkScript: pkScript,
+		})
+	}
+	return inputs, nil
+}
+
+// readUploadContent read the uploaded file from the multipart form, or from a completed resumable upload
+// when uploadId is given. Responds with an error and returns false on failure.
+func (h *UploadHandler) readUploadContent(c *gin.Context) (content []byte, fileName, contentType string, ok bool) {
+	if uploadId := c.PostForm("uploadId"); uploadId != "" {
+		content, upload, err := h.tusService.Content(uploadId)
+		if err != nil {
+			switch {
+			case errors.Is(err, upload_service.ErrUploadNotFound), errors.Is(err, upload_service.ErrUploadExpired):
+				respond.NotFound(c, err.Error())
+			case errors.Is(err, upload_service.ErrUploadIncomplete):
+				respond.InvalidParam(c, err.Error())
+			default:
+				respond.ServerError(c, err.Error())
+			}
+			return nil, "", "", false
+		}
+		return content, upload.FileName, upload.ContentType, true
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		respond.InvalidParam(c, "file or uploadId is required")
+		return nil, "", "", false
+	}
+	defer file.Close()
+
+	content, err = ioutil.ReadAll(file)
+	if err != nil {
+		respond.ServerError(c, "failed to read file")
+		return nil, "", "", false
+	}
+	return content, header.Filename, header.Header.Get("Content-Type"), true
+}
+
 // CommitUploadRequest commit upload request
 type CommitUploadRequest struct {
 	FileId      string `json:"fileId" binding:"required" example:"metaid_abc123" description:"File ID (from pre-upload response)"`
-	SignedRawTx string `json:"signedRawTx" binding:"required" example:"0100000..." description:"Signed raw transaction data (hex)"`
+	SignedRawTx string `json:"signedRawTx" binding:"required" example:"0100000..." description:"Signed raw transaction data (hex), for BTC files the signed commit PSBT (base64 or hex) or commit transaction (hex)"`
 }
 
 // CommitUploadResponseData commit upload response data
 type CommitUploadResponseData struct {
 	FileId  string `json:"fileId" example:"metaid_abc123" description:"File ID"`
-	Status  string `json:"status" example:"success" description:"Status: success, failed"`
+	Status  string `json:"status" example:"pending" description:"Status: pending until the file transaction is confirmed, then success"`
+	TxState string `json:"txState" example:"broadcast" description:"State of the file transaction: broadcast, in_mempool, confirmed"`
 	TxId    string `json:"txId" example:"abc123..." description:"Transaction ID"`
 	PinId   string `json:"pinId" example:"abc123...i0" description:"Pin ID"`
 	Message string `json:"message" example:"success" description:"Message"`
@@ -317,13 +410,13 @@ type CommitUploadResponseData struct {
 
 // CommitUpload commit upload: broadcast signed transaction
 // @Summary      Commit upload
-// @Description  Submit signed transaction for broadcast
+// @Description  Submit signed transaction for broadcast. The transaction must carry the MetaID output of the file (path, content type, content sha256), its inputs must pass script verification and its fee must meet the configured fee rate. pinId is derived from the owner output as the indexer does. For BTC files submit the signed commit PSBT: the commit transaction is checked against the pre-upload and broadcast, then the reveal transaction.
 // @Tags         File Upload
 // @Accept       json
 // @Produce      json
 // @Param        request  body      CommitUploadRequest  true  "Commit upload request"
 // @Success      200      {object}  respond.Response{data=CommitUploadResponseData}  "Upload successful, return transaction ID and Pin ID"
-// @Failure      400      {object}  respond.Response  "Parameter error or file not found"
+// @Failure      400      {object}  respond.Response  "Parameter error or transaction rejected"
 // @Failure      500      {object}  respond.Response  "Server error or broadcast failed"
 // @Router       /files/commit-upload [post]
 func (h *UploadHandler) CommitUpload(c *gin.Context) {
@@ -336,6 +429,185 @@ func (h *UploadHandler) CommitUpload(c *gin.Context) {
 	// Commit upload
 	resp, err := h.uploadService.CommitUpload(req.FileId, req.SignedRawTx)
 	if err != nil {
+		if errors.Is(err, upload_service.ErrInvalidBtcUpload) || errors.Is(err, upload_service.ErrInvalidSignedTx) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
+// CommitChunkRequest commit chunk request
+type CommitChunkRequest struct {
+	FileId      string `json:"fileId" binding:"required" example:"metaid_abc123" description:"File ID (from pre-upload response)"`
+	ChunkIndex  int64  `json:"chunkIndex" binding:"min=0" example:"0" description:"Chunk index (from pre-upload response)"`
+	SignedRawTx string `json:"signedRawTx" binding:"required" example:"0100000..." description:"Signed raw chunk transaction data (hex)"`
+}
+
+// CommitChunk commit one chunk of a chunked upload
+// @Summary      Commit chunk
+// @Description  Submit the signed transaction of one chunk for broadcast, checked like commit-upload. Returns the upload progress, which contains indexPreTxRaw once all chunks are broadcast.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CommitChunkRequest  true  "Commit chunk request"
+// @Success      200      {object}  respond.Response{data=upload_service.UploadProgressResponse}  "Chunk broadcast, return upload progress"
+// @Failure      400      {object}  respond.Response  "Parameter error or transaction rejected"
+// @Failure      404      {object}  respond.Response  "File or chunk not found"
+// @Failure      500      {object}  respond.Response  "Server error or broadcast failed"
+// @Router       /files/commit-chunk [post]
+func (h *UploadHandler) CommitChunk(c *gin.Context) {
+	var req CommitChunkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	resp, err := h.uploadService.CommitChunk(req.FileId, req.ChunkIndex, req.SignedRawTx)
+	if err != nil {
+		if errors.Is(err, upload_service.ErrFileNotFound) || errors.Is(err, upload_service.ErrChunkNotFound) {
+			respond.NotFound(c, err.Error())
+			return
+		}
+		if errors.Is(err, upload_service.ErrInvalidSignedTx) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
+// GetUploadProgress get upload broadcast progress
+// @Summary      Get upload progress
+// @Description  Get how many transactions of an upload are broadcast. For chunked uploads, indexPreTxRaw is returned once all chunks are broadcast; sign it and submit it with /files/commit-upload.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        fileId  path      string  true  "File ID"
+// @Success      200     {object}  respond.Response{data=upload_service.UploadProgressResponse}
+// @Failure      404     {object}  respond.Response  "File not found"
+// @Failure      500     {object}  respond.Response  "Server error"
+// @Router       /files/{fileId}/progress [get]
+func (h *UploadHandler) GetUploadProgress(c *gin.Context) {
+	resp, err := h.uploadService.GetUploadProgress(c.Param("fileId"))
+	if err != nil {
+		if errors.Is(err, upload_service.ErrFileNotFound) {
+			respond.NotFound(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
+// GetTxHistory get the broadcast history of an upload
+// @Summary      Get transaction history
+// @Description  Get the transactions of an upload and every state they went through: built, signed, broadcast, in_mempool, confirmed (with block height), failed or replaced. Broadcast transactions are followed by a background tracker, which rebroadcasts those the node drops.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        fileId  path      string  true  "File ID"
+// @Success      200     {object}  respond.Response{data=upload_service.TxHistoryResponse}
+// @Failure      404     {object}  respond.Response  "File not found"
+// @Failure      500     {object}  respond.Response  "Server error"
+// @Router       /files/{fileId}/history [get]
+func (h *UploadHandler) GetTxHistory(c *gin.Context) {
+	resp, err := h.uploadService.GetTxHistory(c.Param("fileId"))
+	if err != nil {
+		if errors.Is(err, upload_service.ErrFileNotFound) {
+			respond.NotFound(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
+// GetFile get the upload record of a file
+// @Summary      Get file
+// @Description  Get the upload record of a file by file ID: status, transaction and Pin ID once committed, broadcast state and block height.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        fileId  path      string  true  "File ID"
+// @Success      200     {object}  respond.Response{data=upload_service.FileInfo}
+// @Failure      404     {object}  respond.Response  "File not found"
+// @Failure      500     {object}  respond.Response  "Server error"
+// @Router       /files/{fileId} [get]
+func (h *UploadHandler) GetFile(c *gin.Context) {
+	resp, err := h.uploadService.GetFile(c.Param("fileId"))
+	if err != nil {
+		if errors.Is(err, upload_service.ErrFileNotFound) {
+			respond.NotFound(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
+// GetFileByTxID get the upload record of a file by transaction ID
+// @Summary      Get file by transaction
+// @Description  Get the upload record of the file a transaction belongs to. Besides the file (or index, reveal) transaction, chunk, merge and BTC commit transactions are found.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        txId  path      string  true  "Transaction ID"
+// @Success      200   {object}  respond.Response{data=upload_service.FileInfo}
+// @Failure      404   {object}  respond.Response  "File not found"
+// @Failure      500   {object}  respond.Response  "Server error"
+// @Router       /files/tx/{txId} [get]
+func (h *UploadHandler) GetFileByTxID(c *gin.Context) {
+	resp, err := h.uploadService.GetFileByTxID(c.Param("txId"))
+	if err != nil {
+		if errors.Is(err, upload_service.ErrFileNotFound) {
+			respond.NotFound(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
+// ListFiles list upload records with cursor pagination
+// @Summary      List files
+// @Description  List upload records, newest first, optionally filtered by owner address, MetaID and status. Pass nextCursor of a page as cursor to get the next one.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        address  query     string  false  "Owner address"
+// @Param        metaId   query     string  false  "MetaID"
+// @Param        status   query     string  false  "Status (pending/success/failed)"
+// @Param        cursor   query     int     false  "Cursor (nextCursor of the previous page)"  default(0)
+// @Param        size     query     int     false  "Page size (max 100)"                       default(20)
+// @Success      200      {object}  respond.Response{data=upload_service.FileListResponse}
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /files [get]
+func (h *UploadHandler) ListFiles(c *gin.Context) {
+	cursor, _ := strconv.ParseInt(c.DefaultQuery("cursor", "0"), 10, 64)
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	resp, err := h.uploadService.ListFiles(c.Query("address"), c.Query("metaId"), c.Query("status"), cursor, size)
+	if err != nil {
+		if errors.Is(err, upload_service.ErrInvalidFileQuery) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
 		respond.ServerError(c, err.Error())
 		return
 	}
@@ -347,11 +619,14 @@ func (h *UploadHandler) CommitUpload(c *gin.Context) {
 type ConfigResponse struct {
 	MaxFileSize    int64  `json:"maxFileSize" example:"10485760" description:"Max file size (bytes)"`
 	SwaggerBaseUrl string `json:"swaggerBaseUrl" example:"localhost:7282" description:"Swagger API base URL"`
+	ChunkThreshold int64  `json:"chunkThreshold" example:"5242880" description:"Files larger than this (bytes) are uploaded in chunks"`
+	ChunkSize      int64  `json:"chunkSize" example:"1048576" description:"Chunk size (bytes)"`
+	MaxBatchFiles  int64  `json:"maxBatchFiles" example:"50" description:"Most files one batch pre-upload accepts"`
 }
 
 // GetConfig get configuration information
 // @Summary      Get configuration
-// @Description  Get upload service configuration information, including max file size and swagger base URL
+// @Description  Get upload service configuration information, including max file size, chunk threshold and swagger base URL
 // @Tags         Configuration
 // @Accept       json
 // @Produce      json
@@ -361,5 +636,8 @@ func (h *UploadHandler) GetConfig(c *gin.Context) {
 	respond.Success(c, ConfigResponse{
 		MaxFileSize:    conf.Cfg.Uploader.MaxFileSize,
 		SwaggerBaseUrl: conf.Cfg.Uploader.SwaggerBaseUrl,
+		ChunkThreshold: conf.Cfg.Uploader.ChunkThreshold,
+		ChunkSize:      conf.Cfg.Uploader.ChunkSize,
+		MaxBatchFiles:  conf.Cfg.Uploader.MaxBatchFiles,
 	})
 }
@@ -24,8 +24,16 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 	// Add timing middleware
 	r.Use(respond.TimingMiddleware())
 
+	// Serve raw content from a separate origin when configured
+	r.Use(respond.ContentOriginMiddleware(conf.Cfg.Indexer.ContentServing.ContentOrigin,
+		"/api/v1/files/content/", "/api/v1/avatars/content/", "/api/v1/files/:pinId/entries/"))
+
 	// Create indexer file service instance
 	indexerFileService := indexer_service.NewIndexerFileService(stor)
+	// Set scanner for fetching serve-from-chain content
+	if indexerService != nil {
+		indexerFileService.SetBlockScanner(indexerService.GetScanner())
+	}
 
 	// Create sync status service instance
 	syncStatusService := indexer_service.NewSyncStatusService()
@@ -34,8 +42,27 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 		syncStatusService.SetBlockScanner(indexerService.GetScanner())
 	}
 
+	// Create thumbnail service instance
+	thumbnailService := indexer_service.NewThumbnailService(stor, indexerFileService)
+
+	// Create avatar image service instance
+	avatarImageService := indexer_service.NewAvatarImageService(stor, indexerFileService)
+
+	// Create safe content service instance
+	safeContentService := indexer_service.NewSafeContentService(stor, indexerFileService)
+
+	// Create archive service instance
+	archiveService := indexer_service.NewArchiveService(stor, indexerFileService)
+
+	// Create preview service instance
+	previewService := indexer_service.NewPreviewService(indexerFileService)
+
+	// Create moderation service instance
+	moderationService := indexer_service.NewModerationService()
+
 	// Create handler
-	indexerQueryHandler := handler.NewIndexerQueryHandler(indexerFileService, syncStatusService)
+	indexerQueryHandler := handler.NewIndexerQueryHandler(indexerFileService, syncStatusService, thumbnailService, avatarImageService, safeContentService, archiveService, previewService)
+	adminHandler := handler.NewAdminHandler(moderationService)
 
 	// API v1 route group
 	v1 := r.Group("/api/v1")
@@ -49,9 +76,24 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 			// Get file by PIN ID
 			files.GET("/:pinId", indexerQueryHandler.GetByPinID)
 
+			// Get near-duplicate images by PIN ID
+			files.GET("/:pinId/similar", indexerQueryHandler.GetSimilarFiles)
+
+			// Get rendered preview of a text file by PIN ID
+			files.GET("/:pinId/preview", indexerQueryHandler.GetFilePreview)
+
+			// List entries of an archive by PIN ID
+			files.GET("/:pinId/entries", indexerQueryHandler.GetArchiveEntries)
+
+			// Stream a single archive entry by PIN ID and entry path
+			files.GET("/:pinId/entries/*path", indexerQueryHandler.GetArchiveEntryContent)
+
 			// Get file content by PIN ID
 			files.GET("/content/:pinId", indexerQueryHandler.GetFileContent)
 
+			// Get resized image by PIN ID
+			files.GET("/thumbnail/:pinId", indexerQueryHandler.GetFileThumbnail)
+
 			// Get files by creator address
 			files.GET("/creator/:address", indexerQueryHandler.GetByCreatorAddress)
 
@@ -71,6 +113,9 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 			// Get latest avatar by MetaID
 			avatars.GET("/metaid/:metaId", indexerQueryHandler.GetLatestAvatarByMetaID)
 
+			// Get standard-size square avatar image by MetaID
+			avatars.GET("/metaid/:metaId/image", indexerQueryHandler.GetAvatarImageByMetaID)
+
 			// Get latest avatar by address
 			avatars.GET("/address/:address", indexerQueryHandler.GetLatestAvatarByAddress)
 		}
@@ -80,6 +125,18 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 
 		// Statistics route
 		v1.GET("/stats", indexerQueryHandler.GetStats)
+
+		// Admin routes (bearer token required)
+		admin := v1.Group("/admin", respond.AdminAuthMiddleware(conf.Cfg.Indexer.Admin.Token))
+		{
+			// Moderation blocklist
+			admin.GET("/blocks", adminHandler.ListBlocks)
+			admin.POST("/blocks", adminHandler.CreateBlock)
+			admin.DELETE("/blocks/:id", adminHandler.DeleteBlock)
+
+			// Moderation audit log
+			admin.GET("/audit", adminHandler.ListAudits)
+		}
 	}
 
 	// Health check
@@ -3,23 +3,33 @@ package respond
 import (
 	"time"
 
+	"meta-media-service/archive"
 	"meta-media-service/model"
+	"meta-media-service/preview"
+	"meta-media-service/storage"
 )
 
 // IndexerFileResponse file information response structure
 type IndexerFileResponse struct {
 	// ID             int64     `json:"id" example:"1"`
-	PinID         string `json:"pin_id" example:"abc123def456i0"`
-	TxID          string `json:"tx_id" example:"abc123def456789"`
-	Path          string `json:"path" example:"/file/test.jpg"`
-	Operation     string `json:"operation" example:"create"`
-	ContentType   string `json:"content_type" example:"image/jpeg"`
-	FileType      string `json:"file_type" example:"image"`
-	FileExtension string `json:"file_extension" example:".jpg"`
-	FileName      string `json:"file_name" example:"test.jpg"`
-	FileSize      int64  `json:"file_size" example:"102400"`
-	FileMd5       string `json:"file_md5" example:"d41d8cd98f00b204e9800998ecf8427e"`
-	FileHash      string `json:"file_hash" example:"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"`
+	PinID       string `json:"pin_id" example:"abc123def456i0"`
+	TxID        string `json:"tx_id" example:"abc123def456789"`
+	Path        string `json:"path" example:"/file/test.jpg"`
+	Operation   string `json:"operation" example:"create"`
+	ContentType string `json:"content_type" example:"image/jpeg"`
+	// Declared (content_type) vs detected content type
+	DeclaredContentType string `json:"declared_content_type" example:"image/jpeg"`
+	DetectedContentType string `json:"detected_content_type" example:"image/jpeg"`
+	ContentTypeMismatch bool   `json:"content_type_mismatch" example:"false"`
+	FileType            string `json:"file_type" example:"image"`
+	FileExtension       string `json:"file_extension" example:".jpg"`
+	FileName            string `json:"file_name" example:"test.jpg"`
+	FileSize            int64  `json:"file_size" example:"102400"`
+	FileMd5             string `json:"file_md5" example:"d41d8cd98f00b204e9800998ecf8427e"`
+	FileHash            string `json:"file_hash" example:"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"`
+	// Image placeholder (images only)
+	BlurHash      string `json:"blur_hash,omitempty" example:"LEHV6nWB2yk8pyo0adR*.7kCMdnj"`
+	DominantColor string `json:"dominant_color,omitempty" example:"#8a6f4e"`
 	// StorageType    string    `json:"storage_type" example:"oss"`
 	StoragePath    string `json:"storage_path" example:"indexer/mvc/pinid123i0.jpg"`
 	ChainName      string `json:"chain_name" example:"mvc"`
@@ -29,11 +39,36 @@ type IndexerFileResponse struct {
 	CreatorAddress string `json:"creator_address" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"`
 	OwnerMetaId    string `json:"owner_meta_id" example:"abc123def456..."`
 	OwnerAddress   string `json:"owner_address" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"`
+	// Media metadata (image/video/audio only, returned by single file query)
+	Media *IndexerMediaMetadataResponse `json:"media,omitempty"`
 	// Status         string    `json:"status" example:"success"`
 	// CreatedAt      time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
 	// UpdatedAt      time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
 }
 
+// IndexerMediaMetadataResponse media metadata response structure
+type IndexerMediaMetadataResponse struct {
+	MediaType   string `json:"media_type" example:"image"`
+	Format      string `json:"format" example:"jpeg"`
+	Width       int    `json:"width,omitempty" example:"4032"`
+	Height      int    `json:"height,omitempty" example:"3024"`
+	Orientation int    `json:"orientation,omitempty" example:"6"`
+	FrameCount  int    `json:"frame_count,omitempty" example:"1"`
+	DurationMs  int64  `json:"duration_ms,omitempty" example:"0"`
+	Bitrate     int64  `json:"bitrate,omitempty" example:"0"`
+	VideoCodec  string `json:"video_codec,omitempty" example:""`
+	AudioCodec  string `json:"audio_codec,omitempty" example:""`
+	SampleRate  int    `json:"sample_rate,omitempty" example:"0"`
+	Channels    int    `json:"channels,omitempty" example:"0"`
+	CameraMake  string `json:"camera_make,omitempty" example:"Apple"`
+	CameraModel string `json:"camera_model,omitempty" example:"iPhone 13"`
+	CaptureTime string `json:"capture_time,omitempty" example:"2024:01:02 15:04:05"`
+	HasGPS      bool   `json:"has_gps" example:"true"`
+	Title       string `json:"title,omitempty" example:""`
+	Artist      string `json:"artist,omitempty" example:""`
+	Album       string `json:"album,omitempty" example:""`
+}
+
 // IndexerAvatarResponse avatar information response structure
 type IndexerAvatarResponse struct {
 	// ID            int64     `json:"id" example:"1"`
@@ -72,6 +107,52 @@ type IndexerFileListResponse struct {
 	HasMore    bool                  `json:"has_more" example:"true"`
 }
 
+// IndexerSimilarFileResponse near-duplicate image response structure
+type IndexerSimilarFileResponse struct {
+	IndexerFileResponse
+	Distance int `json:"distance" example:"3"` // Hamming distance of perceptual hashes (0 = visually identical)
+}
+
+// IndexerSimilarFileListResponse near-duplicate image list response structure
+type IndexerSimilarFileListResponse struct {
+	PinID       string                       `json:"pin_id" example:"abc123def456i0"`
+	MaxDistance int                          `json:"max_distance" example:"8"`
+	Truncated   bool                         `json:"truncated" example:"false"` // Only part of the candidate images were checked
+	Files       []IndexerSimilarFileResponse `json:"files"`
+}
+
+// IndexerArchiveEntryResponse archive entry response structure
+type IndexerArchiveEntryResponse struct {
+	Path           string `json:"path" example:"docs/readme.txt"`
+	Type           string `json:"type" example:"file"` // file/dir/link/other
+	Size           int64  `json:"size" example:"1024"`
+	CompressedSize int64  `json:"compressed_size" example:"512"`
+	ContentType    string `json:"content_type" example:"text/plain; charset=utf-8"` // Guessed from the extension (empty = unknown)
+	LinkTarget     string `json:"link_target,omitempty" example:""`
+	ModTime        int64  `json:"mod_time" example:"1699999999"` // Unix seconds (0 = unknown)
+}
+
+// IndexerArchiveEntryListResponse archive entry list response structure
+type IndexerArchiveEntryListResponse struct {
+	PinID      string                        `json:"pin_id" example:"abc123def456i0"`
+	Format     string                        `json:"format" example:"zip"` // zip/tar/tar.gz
+	EntryCount int                           `json:"entry_count" example:"12"`
+	TotalSize  int64                         `json:"total_size" example:"1048576"` // Total expanded size of all entries
+	Entries    []IndexerArchiveEntryResponse `json:"entries"`
+}
+
+// IndexerFilePreviewResponse rendered text preview response structure
+type IndexerFilePreviewResponse struct {
+	PinID      string `json:"pin_id" example:"abc123def456i0"`
+	Kind       string `json:"kind" example:"markdown"`   // markdown/json/code/text
+	Language   string `json:"language" example:""`       // Highlighted language of code and json
+	Charset    string `json:"charset" example:"utf-8"`   // Detected source charset, the HTML is always UTF-8
+	Size       int    `json:"size" example:"2048"`       // Size of the source file in bytes
+	Truncated  bool   `json:"truncated" example:"false"` // Only the head of the file is rendered
+	ContentURL string `json:"content_url" example:"/api/v1/files/content/abc123def456i0"`
+	HTML       string `json:"html" example:"<h1>Title</h1>"` // Sanitized HTML fragment
+}
+
 // IndexerAvatarListResponse avatar list response structure
 type IndexerAvatarListResponse struct {
 	Avatars    []IndexerAvatarResponse `json:"avatars"`
@@ -81,7 +162,19 @@ type IndexerAvatarListResponse struct {
 
 // IndexerStatsResponse statistics response structure
 type IndexerStatsResponse struct {
-	TotalFiles int64 `json:"total_files" example:"12345"`
+	TotalFiles   int64                             `json:"total_files" example:"12345"`
+	StorageCache *IndexerStorageCacheStatsResponse `json:"storage_cache,omitempty"`
+}
+
+// IndexerStorageCacheStatsResponse storage cache statistics response structure
+type IndexerStorageCacheStatsResponse struct {
+	Hits      int64   `json:"hits" example:"9000"`
+	Misses    int64   `json:"misses" example:"1000"`
+	HitRate   float64 `json:"hit_rate" example:"0.9"`
+	Evictions int64   `json:"evictions" example:"120"`
+	Entries   int64   `json:"entries" example:"3500"`
+	Bytes     int64   `json:"bytes" example:"524288000"`
+	MaxBytes  int64   `json:"max_bytes" example:"1073741824"`
 }
 
 // ToIndexerFileResponse convert model to response
@@ -91,17 +184,22 @@ func ToIndexerFileResponse(file *model.IndexerFile) IndexerFileResponse {
 	}
 	return IndexerFileResponse{
 		// ID:             file.ID,
-		PinID:         file.PinID,
-		TxID:          file.TxID,
-		Path:          file.Path,
-		Operation:     file.Operation,
-		ContentType:   file.ContentType,
-		FileType:      file.FileType,
-		FileExtension: file.FileExtension,
-		FileName:      file.FileName,
-		FileSize:      file.FileSize,
-		FileMd5:       file.FileMd5,
-		FileHash:      file.FileHash,
+		PinID:               file.PinID,
+		TxID:                file.TxID,
+		Path:                file.Path,
+		Operation:           file.Operation,
+		ContentType:         file.ContentType,
+		DeclaredContentType: file.ContentType,
+		DetectedContentType: file.DetectedContentType,
+		ContentTypeMismatch: file.ContentTypeMismatch,
+		FileType:            file.FileType,
+		FileExtension:       file.FileExtension,
+		FileName:            file.FileName,
+		FileSize:            file.FileSize,
+		FileMd5:             file.FileMd5,
+		FileHash:            file.FileHash,
+		BlurHash:            file.BlurHash,
+		DominantColor:       file.DominantColor,
 		// StorageType:    file.StorageType,
 		StoragePath:    file.StoragePath,
 		ChainName:      file.ChainName,
@@ -117,6 +215,34 @@ func ToIndexerFileResponse(file *model.IndexerFile) IndexerFileResponse {
 	}
 }
 
+// ToIndexerMediaMetadataResponse convert media metadata model to response
+func ToIndexerMediaMetadataResponse(metadata *model.IndexerMediaMetadata) *IndexerMediaMetadataResponse {
+	if metadata == nil {
+		return nil
+	}
+	return &IndexerMediaMetadataResponse{
+		MediaType:   metadata.MediaType,
+		Format:      metadata.Format,
+		Width:       metadata.Width,
+		Height:      metadata.Height,
+		Orientation: metadata.Orientation,
+		FrameCount:  metadata.FrameCount,
+		DurationMs:  metadata.DurationMs,
+		Bitrate:     metadata.Bitrate,
+		VideoCodec:  metadata.VideoCodec,
+		AudioCodec:  metadata.AudioCodec,
+		SampleRate:  metadata.SampleRate,
+		Channels:    metadata.Channels,
+		CameraMake:  metadata.CameraMake,
+		CameraModel: metadata.CameraModel,
+		CaptureTime: metadata.CaptureTime,
+		HasGPS:      metadata.HasGPS,
+		Title:       metadata.Title,
+		Artist:      metadata.Artist,
+		Album:       metadata.Album,
+	}
+}
+
 // ToIndexerFileListResponse convert file list to response
 func ToIndexerFileListResponse(files []*model.IndexerFile, nextCursor int64, hasMore bool) IndexerFileListResponse {
 	var fileResponses []IndexerFileResponse
@@ -130,6 +256,55 @@ func ToIndexerFileListResponse(files []*model.IndexerFile, nextCursor int64, has
 	}
 }
 
+// ToIndexerSimilarFileResponse convert model and distance to near-duplicate response
+func ToIndexerSimilarFileResponse(file *model.IndexerFile, distance int) IndexerSimilarFileResponse {
+	return IndexerSimilarFileResponse{
+		IndexerFileResponse: ToIndexerFileResponse(file),
+		Distance:            distance,
+	}
+}
+
+// ToIndexerArchiveEntryListResponse convert archive index to response
+func ToIndexerArchiveEntryListResponse(pinID string, index *archive.Index) IndexerArchiveEntryListResponse {
+	entries := make([]IndexerArchiveEntryResponse, 0, len(index.Entries))
+	for _, entry := range index.Entries {
+		var modTime int64
+		if !entry.ModTime.IsZero() {
+			modTime = entry.ModTime.Unix()
+		}
+		entries = append(entries, IndexerArchiveEntryResponse{
+			Path:           entry.Path,
+			Type:           entry.Type,
+			Size:           entry.Size,
+			CompressedSize: entry.CompressedSize,
+			ContentType:    entry.ContentType,
+			LinkTarget:     entry.LinkTarget,
+			ModTime:        modTime,
+		})
+	}
+	return IndexerArchiveEntryListResponse{
+		PinID:      pinID,
+		Format:     index.Format,
+		EntryCount: len(entries),
+		TotalSize:  index.TotalSize,
+		Entries:    entries,
+	}
+}
+
+// ToIndexerFilePreviewResponse convert rendered preview to response
+func ToIndexerFilePreviewResponse(pinID, contentURL string, p *preview.Preview) IndexerFilePreviewResponse {
+	return IndexerFilePreviewResponse{
+		PinID:      pinID,
+		Kind:       p.Kind,
+		Language:   p.Language,
+		Charset:    p.Charset,
+		Size:       p.Size,
+		Truncated:  p.Truncated,
+		ContentURL: contentURL,
+		HTML:       p.HTML,
+	}
+}
+
 // ToIndexerAvatarResponse convert model to response
 func ToIndexerAvatarResponse(avatar *model.IndexerUserAvatar) IndexerAvatarResponse {
 	if avatar == nil {
@@ -185,8 +360,109 @@ func ToIndexerSyncStatusResponse(status *model.IndexerSyncStatus, latestBlockHei
 }
 
 // ToIndexerStatsResponse convert stats to response
-func ToIndexerStatsResponse(totalFiles int64) IndexerStatsResponse {
-	return IndexerStatsResponse{
+func ToIndexerStatsResponse(totalFiles int64, cacheStats *storage.CacheStats) IndexerStatsResponse {
+	resp := IndexerStatsResponse{
 		TotalFiles: totalFiles,
 	}
+	if cacheStats != nil {
+		var hitRate float64
+		if total := cacheStats.Hits + cacheStats.Misses; total > 0 {
+			hitRate = float64(cacheStats.Hits) / float64(total)
+		}
+		resp.StorageCache = &IndexerStorageCacheStatsResponse{
+			Hits:      cacheStats.Hits,
+			Misses:    cacheStats.Misses,
+			HitRate:   hitRate,
+			Evictions: cacheStats.Evictions,
+			Entries:   cacheStats.Entries,
+			Bytes:     cacheStats.Bytes,
+			MaxBytes:  cacheStats.MaxBytes,
+		}
+	}
+	return resp
+}
+
+// IndexerBlockResponse moderation blocklist entry response structure
+type IndexerBlockResponse struct {
+	ID        int64     `json:"id" example:"1"`
+	BlockType string    `json:"block_type" example:"pin"` // pin/hash/address/metaid
+	Value     string    `json:"value" example:"abc123def456i0"`
+	Reason    string    `json:"reason" example:"DMCA notice #2024-017"`
+	Operator  string    `json:"operator" example:"legal@example.com"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// IndexerBlockListResponse moderation blocklist response structure
+type IndexerBlockListResponse struct {
+	Blocks     []IndexerBlockResponse `json:"blocks"`
+	NextCursor int64                  `json:"next_cursor" example:"100"`
+	HasMore    bool                   `json:"has_more" example:"true"`
+}
+
+// IndexerBlockAuditResponse moderation audit log entry response structure
+type IndexerBlockAuditResponse struct {
+	ID        int64     `json:"id" example:"1"`
+	BlockID   int64     `json:"block_id" example:"1"`
+	Action    string    `json:"action" example:"block"`   // block/unblock/match
+	BlockType string    `json:"block_type" example:"pin"` // pin/hash/address/metaid
+	Value     string    `json:"value" example:"abc123def456i0"`
+	PinID     string    `json:"pin_id,omitempty" example:""` // PIN a hash block was applied to (match only)
+	Reason    string    `json:"reason" example:"DMCA notice #2024-017"`
+	Operator  string    `json:"operator" example:"legal@example.com"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// IndexerBlockAuditListResponse moderation audit log response structure
+type IndexerBlockAuditListResponse struct {
+	Audits     []IndexerBlockAuditResponse `json:"audits"`
+	NextCursor int64                       `json:"next_cursor" example:"100"`
+	HasMore    bool                        `json:"has_more" example:"true"`
+}
+
+// ToIndexerBlockResponse convert model to response
+func ToIndexerBlockResponse(block *model.IndexerBlock) IndexerBlockResponse {
+	return IndexerBlockResponse{
+		ID:        block.ID,
+		BlockType: block.BlockType,
+		Value:     block.Value,
+		Reason:    block.Reason,
+		Operator:  block.Operator,
+		CreatedAt: block.CreatedAt,
+	}
+}
+
+// ToIndexerBlockListResponse convert blocklist to response
+func ToIndexerBlockListResponse(blocks []*model.IndexerBlock, nextCursor int64, hasMore bool) IndexerBlockListResponse {
+	blockResponses := make([]IndexerBlockResponse, 0, len(blocks))
+	for _, block := range blocks {
+		blockResponses = append(blockResponses, ToIndexerBlockResponse(block))
+	}
+	return IndexerBlockListResponse{
+		Blocks:     blockResponses,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}
+}
+
+// ToIndexerBlockAuditListResponse convert audit log to response
+func ToIndexerBlockAuditListResponse(audits []*model.IndexerBlockAudit, nextCursor int64, hasMore bool) IndexerBlockAuditListResponse {
+	auditResponses := make([]IndexerBlockAuditResponse, 0, len(audits))
+	for _, audit := range audits {
+		auditResponses = append(auditResponses, IndexerBlockAuditResponse{
+			ID:        audit.ID,
+			BlockID:   audit.BlockID,
+			Action:    audit.Action,
+			BlockType: audit.BlockType,
+			Value:     audit.Value,
+			PinID:     audit.PinID,
+			Reason:    audit.Reason,
+			Operator:  audit.Operator,
+			CreatedAt: audit.CreatedAt,
+		})
+	}
+	return IndexerBlockAuditListResponse{
+		Audits:     auditResponses,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}
 }
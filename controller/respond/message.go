@@ -1,6 +1,10 @@
 package respond
 
 import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -17,7 +21,7 @@ type Message struct {
 // Response response structure (for Swagger)
 // @Description Unified API response structure
 type Response struct {
-	Code           int         `json:"code" example:"0" description:"Response code: 0=success, 40000=param error, 40400=not found, 50000=server error"`
+	Code           int         `json:"code" example:"0" description:"Response code: 0=success, 40000=param error, 40100=unauthorized, 40400=not found, 45100=unavailable for legal reasons, 50000=server error"`
 	Message        string      `json:"message" example:"success" description:"Response message"`
 	ProcessingTime int64       `json:"processingTime" example:"123" description:"Request processing time (milliseconds)"`
 	Data           interface{} `json:"data" description:"Response data"`
@@ -27,7 +31,9 @@ type Response struct {
 const (
 	CodeSuccess      = 0     // Success
 	CodeInvalidParam = 40000 // Parameter error
+	CodeUnauthorized = 40100 // Missing or invalid admin token, assistant token or signature
 	CodeNotFound     = 40400 // Resource not found
+	CodeUnavailable  = 45100 // Content blocked for legal reasons
 	CodeServerError  = 50000 // Server error
 )
 
@@ -69,6 +75,16 @@ func ErrorWithData(c *gin.Context, code int, message string, data interface{}) {
 	})
 }
 
+// ErrorWithStatus return error response with an HTTP status other than 200
+func ErrorWithStatus(c *gin.Context, status, code int, message string) {
+	processingTime := getProcessingTime(c)
+	c.JSON(status, Message{
+		Code:           code,
+		Message:        message,
+		ProcessingTime: processingTime,
+	})
+}
+
 // InvalidParam return parameter error response
 func InvalidParam(c *gin.Context, message string) {
 	Error(c, CodeInvalidParam, message)
@@ -79,6 +95,16 @@ func NotFound(c *gin.Context, message string) {
 	Error(c, CodeNotFound, message)
 }
 
+// Unauthorized return 401 response for missing or invalid credentials
+func Unauthorized(c *gin.Context, message string) {
+	ErrorWithStatus(c, http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// UnavailableForLegalReasons return 451 response for blocked content
+func UnavailableForLegalReasons(c *gin.Context, message string) {
+	ErrorWithStatus(c, http.StatusUnavailableForLegalReasons, CodeUnavailable, message)
+}
+
 // ServerError return server error response
 func ServerError(c *gin.Context, message string) {
 	Error(c, CodeServerError, message)
@@ -101,3 +127,85 @@ func TimingMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminAuthMiddleware require "Authorization: Bearer <token>" on admin routes
+// All requests are rejected when token is empty, which keeps the admin API disabled by default.
+func AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			Unauthorized(c, "admin API is disabled")
+			c.Abort()
+			return
+		}
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			Unauthorized(c, "invalid admin token")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ContentOriginMiddleware isolate raw content on a separate origin
+// Content routes requested on another host are redirected to contentOrigin, and the
+// content origin serves nothing but content routes. Disabled when contentOrigin is empty.
+// contentPrefixes are path prefixes, a ":name" segment matches any single path segment.
+func ContentOriginMiddleware(contentOrigin string, contentPrefixes ...string) gin.HandlerFunc {
+	origin, err := url.Parse(strings.TrimRight(contentOrigin, "/"))
+	if contentOrigin == "" || err != nil || origin.Host == "" {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		isContentRoute := false
+		for _, prefix := range contentPrefixes {
+			if hasPathPrefix(c.Request.URL.Path, prefix) {
+				isContentRoute = true
+				break
+			}
+		}
+
+		onContentOrigin := strings.EqualFold(c.Request.Host, origin.Host)
+		switch {
+		case isContentRoute && !onContentOrigin:
+			c.Redirect(http.StatusFound, origin.Scheme+"://"+origin.Host+c.Request.URL.RequestURI())
+			c.Abort()
+		case !isContentRoute && onContentOrigin:
+			c.AbortWithStatus(http.StatusNotFound)
+		default:
+			c.Next()
+		}
+	}
+}
+
+// hasPathPrefix check if path starts with prefix, ":name" prefix segments match any segment
+func hasPathPrefix(path, prefix string) bool {
+	if !strings.Contains(prefix, ":") {
+		return strings.HasPrefix(path, prefix)
+	}
+
+	pathSegments := strings.Split(path, "/")
+	prefixSegments := strings.Split(prefix, "/")
+	if len(pathSegments) < len(prefixSegments) {
+		return false
+	}
+	last := len(prefixSegments) - 1
+	for i, segment := range prefixSegments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			if pathSegments[i] == "" {
+				return false
+			}
+		case i == last:
+			if !strings.HasPrefix(pathSegments[i], segment) {
+				return false
+			}
+		case pathSegments[i] != segment:
+			return false
+		}
+	}
+	return true
+}
@@ -14,7 +14,7 @@ import (
 )
 
 // SetupUploadRouter setup upload service router
-func SetupUploadRouter(stor storage.Storage) *gin.Engine {
+func SetupUploadRouter(stor storage.Storage, tusService *upload_service.TusService) *gin.Engine {
 	// Set Swagger host from config
 	uploaderDocs.SwaggerInfouploader.Host = conf.Cfg.Uploader.SwaggerBaseUrl
 
@@ -26,9 +26,14 @@ func SetupUploadRouter(stor storage.Storage) *gin.Engine {
 
 	// Create upload service instance
 	uploadService := upload_service.NewUploadService(stor)
+	assistantService := upload_service.NewAssistantService(uploadService)
+	sponsorService := upload_service.NewSponsorService(uploadService)
 
 	// Create handler instance
-	uploadHandler := handler.NewUploadHandler(uploadService)
+	uploadHandler := handler.NewUploadHandler(uploadService, tusService, assistantService, sponsorService)
+	tusHandler := handler.NewTusHandler(tusService)
+	assistantHandler := handler.NewAssistantHandler(assistantService)
+	sponsorHandler := handler.NewSponsorHandler(sponsorService)
 
 	// Static file service (upload page)
 	// Map web directory directly to root path for direct access to app.js
@@ -43,9 +48,42 @@ func SetupUploadRouter(stor storage.Storage) *gin.Engine {
 		v1.POST("/files/pre-upload", uploadHandler.PreUpload)
 		v1.POST("/files/commit-upload", uploadHandler.CommitUpload)
 		v1.POST("/files/direct-upload", uploadHandler.DirectUpload) // One-step upload (recommended)
+		v1.POST("/files/commit-chunk", uploadHandler.CommitChunk)   // Chunked upload: commit one chunk
+		v1.GET("/files", uploadHandler.ListFiles)                   // Upload records, cursor paginated
+		v1.GET("/files/:fileId", uploadHandler.GetFile)             // Upload record of a file
+		v1.GET("/files/tx/:txId", uploadHandler.GetFileByTxID)      // Upload record by transaction ID
+		v1.GET("/files/:fileId/progress", uploadHandler.GetUploadProgress)
+		v1.POST("/files/batch-pre-upload", uploadHandler.BatchPreUpload)
+		v1.POST("/files/batch-commit-upload", uploadHandler.BatchCommitUpload)
+		v1.GET("/files/:fileId/history", uploadHandler.GetTxHistory)      // Broadcast and confirmation states
+		v1.POST("/files/assistant-upload", uploadHandler.AssistantUpload) // Signed and paid by the assistant
+		v1.POST("/files/sponsored-upload", uploadHandler.SponsoredUpload) // Paid by the sponsor wallet
+
+		// Custodial assistants
+		v1.POST("/assistants", assistantHandler.CreateAssistant)
+		v1.GET("/assistants/:metaId", assistantHandler.GetAssistant)
+		v1.POST("/assistants/:metaId/rotate", assistantHandler.RotateAssistant)
+		v1.POST("/assistants/:metaId/limit", assistantHandler.SetAssistantLimit)
+		v1.POST("/assistants/:metaId/revoke", assistantHandler.RevokeAssistant)
+
+		// Resumable upload (tus protocol)
+		tus := v1.Group("/files/uploads", handler.TusResumableMiddleware())
+		{
+			tus.OPTIONS("", tusHandler.Options)
+			tus.POST("", tusHandler.Create)
+			tus.HEAD("/:uploadId", tusHandler.Head)
+			tus.PATCH("/:uploadId", tusHandler.Patch)
+			tus.DELETE("/:uploadId", tusHandler.Delete)
+		}
 
 		// Configuration
 		v1.GET("/config", uploadHandler.GetConfig)
+
+		// Admin routes (bearer token required)
+		admin := v1.Group("/admin", respond.AdminAuthMiddleware(conf.Cfg.Uploader.Admin.Token))
+		{
+			admin.GET("/sponsorships", sponsorHandler.GetSponsorReport)
+		}
 	}
 
 	// Health check
@@ -6,6 +6,9 @@ var (
 	// ErrNotFound record not found
 	ErrNotFound = errors.New("record not found")
 
+	// ErrDuplicate record with the same unique key exists
+	ErrDuplicate = errors.New("duplicate record")
+
 	// ErrUnsupportedDBType unsupported database type
 	ErrUnsupportedDBType = errors.New("unsupported database type")
 
@@ -13,7 +13,9 @@ type Database interface {
 	ListIndexerFilesWithCursor(cursor int64, size int) ([]*model.IndexerFile, error)
 	GetIndexerFilesByCreatorAddressWithCursor(address string, cursor int64, size int) ([]*model.IndexerFile, error)
 	GetIndexerFilesByCreatorMetaIDWithCursor(metaID string, cursor int64, size int) ([]*model.IndexerFile, error)
+	GetIndexerFilesWithContentTypeMismatchCursor(cursor int64, size int) ([]*model.IndexerFile, error)
 	GetIndexerFilesCount() (int64, error)
+	ForEachIndexerFile(fn func(file *model.IndexerFile) error) error
 
 	// IndexerUserAvatar operations
 	CreateIndexerUserAvatar(avatar *model.IndexerUserAvatar) error
@@ -22,6 +24,30 @@ type Database interface {
 	GetIndexerUserAvatarByAddress(address string) (*model.IndexerUserAvatar, error)
 	UpdateIndexerUserAvatar(avatar *model.IndexerUserAvatar) error
 	ListIndexerUserAvatarsWithCursor(cursor int64, size int) ([]*model.IndexerUserAvatar, error)
+	ForEachIndexerUserAvatar(fn func(avatar *model.IndexerUserAvatar) error) error
+
+	// IndexerBlob operations (content-addressed storage)
+	GetIndexerBlobByHash(fileHash string) (*model.IndexerBlob, error)
+	IncrIndexerBlobRef(blob *model.IndexerBlob) (int64, error)
+	DecrIndexerBlobRef(fileHash string) (int64, error)
+
+	// IndexerMediaMetadata operations
+	CreateOrUpdateIndexerMediaMetadata(metadata *model.IndexerMediaMetadata) error
+	GetIndexerMediaMetadataByPinID(pinID string) (*model.IndexerMediaMetadata, error)
+
+	// IndexerImageHash operations
+	CreateOrUpdateIndexerImageHash(hash *model.IndexerImageHash) error
+	GetIndexerImageHashByPinID(pinID string) (*model.IndexerImageHash, error)
+	ForEachIndexerImageHashByBands(bands [model.ImageHashBands][]int, fn func(hash *model.IndexerImageHash) error) error
+
+	// IndexerBlock operations (moderation blocklist)
+	CreateIndexerBlock(block *model.IndexerBlock) error
+	GetIndexerBlockByID(id int64) (*model.IndexerBlock, error)
+	FindIndexerBlocks(keys []model.BlockKey) ([]*model.IndexerBlock, error)
+	ListIndexerBlocksWithCursor(blockType string, cursor int64, size int) ([]*model.IndexerBlock, error)
+	DeleteIndexerBlock(id int64) error
+	CreateIndexerBlockAudit(audit *model.IndexerBlockAudit) error
+	ListIndexerBlockAuditsWithCursor(cursor int64, size int) ([]*model.IndexerBlockAudit, error)
 
 	// IndexerSyncStatus operations
 	CreateOrUpdateIndexerSyncStatus(status *model.IndexerSyncStatus) error
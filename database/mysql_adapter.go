@@ -3,12 +3,14 @@ package database
 import (
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"meta-media-service/model"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -86,6 +88,18 @@ func (m *MySQLDatabase) ListIndexerFilesWithCursor(cursor int64, size int) ([]*m
 	return files, err
 }
 
+func (m *MySQLDatabase) GetIndexerFilesWithContentTypeMismatchCursor(cursor int64, size int) ([]*model.IndexerFile, error) {
+	var files []*model.IndexerFile
+	query := m.db.Where("content_type_mismatch = ? AND status = ?", true, model.StatusSuccess)
+
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+
+	err := query.Order("id DESC").Limit(size).Find(&files).Error
+	return files, err
+}
+
 func (m *MySQLDatabase) GetIndexerFilesByCreatorAddressWithCursor(address string, cursor int64, size int) ([]*model.IndexerFile, error) {
 	var files []*model.IndexerFile
 	query := m.db.Where("creator_address = ? AND status = ?", address, model.StatusSuccess)
@@ -118,6 +132,18 @@ func (m *MySQLDatabase) GetIndexerFilesCount() (int64, error) {
 	return count, err
 }
 
+func (m *MySQLDatabase) ForEachIndexerFile(fn func(file *model.IndexerFile) error) error {
+	var files []*model.IndexerFile
+	return m.db.Order("id ASC").FindInBatches(&files, 500, func(tx *gorm.DB, batch int) error {
+		for _, file := range files {
+			if err := fn(file); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
 // IndexerUserAvatar operations
 
 func (m *MySQLDatabase) CreateIndexerUserAvatar(avatar *model.IndexerUserAvatar) error {
@@ -171,6 +197,223 @@ func (m *MySQLDatabase) ListIndexerUserAvatarsWithCursor(cursor int64, size int)
 	return avatars, err
 }
 
+func (m *MySQLDatabase) ForEachIndexerUserAvatar(fn func(avatar *model.IndexerUserAvatar) error) error {
+	var avatars []*model.IndexerUserAvatar
+	return m.db.Order("id ASC").FindInBatches(&avatars, 500, func(tx *gorm.DB, batch int) error {
+		for _, avatar := range avatars {
+			if err := fn(avatar); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// IndexerBlob operations
+
+func (m *MySQLDatabase) GetIndexerBlobByHash(fileHash string) (*model.IndexerBlob, error) {
+	var blob model.IndexerBlob
+	err := m.db.Where("file_hash = ?", fileHash).First(&blob).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	return &blob, err
+}
+
+func (m *MySQLDatabase) IncrIndexerBlobRef(blob *model.IndexerBlob) (int64, error) {
+	var refCount int64
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.IndexerBlob
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("file_hash = ?", blob.FileHash).First(&existing).Error
+		if err == gorm.ErrRecordNotFound {
+			blob.RefCount = 1
+			refCount = 1
+			return tx.Create(blob).Error
+		} else if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&existing).Update("ref_count", gorm.Expr("ref_count + 1")).Error; err != nil {
+			return err
+		}
+		refCount = existing.RefCount + 1
+		return nil
+	})
+	return refCount, err
+}
+
+func (m *MySQLDatabase) DecrIndexerBlobRef(fileHash string) (int64, error) {
+	var refCount int64
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.IndexerBlob
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("file_hash = ?", fileHash).First(&existing).Error
+		if err == gorm.ErrRecordNotFound {
+			return ErrNotFound
+		} else if err != nil {
+			return err
+		}
+
+		refCount = existing.RefCount - 1
+		if refCount <= 0 {
+			refCount = 0
+			return tx.Delete(&existing).Error
+		}
+		return tx.Model(&existing).Update("ref_count", refCount).Error
+	})
+	return refCount, err
+}
+
+// IndexerMediaMetadata operations
+
+func (m *MySQLDatabase) CreateOrUpdateIndexerMediaMetadata(metadata *model.IndexerMediaMetadata) error {
+	var existing model.IndexerMediaMetadata
+	err := m.db.Where("pin_id = ?", metadata.PinID).First(&existing).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return m.db.Create(metadata).Error
+	} else if err != nil {
+		return err
+	}
+
+	metadata.ID = existing.ID
+	metadata.CreatedAt = existing.CreatedAt
+	return m.db.Save(metadata).Error
+}
+
+func (m *MySQLDatabase) GetIndexerMediaMetadataByPinID(pinID string) (*model.IndexerMediaMetadata, error) {
+	var metadata model.IndexerMediaMetadata
+	err := m.db.Where("pin_id = ?", pinID).First(&metadata).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	return &metadata, err
+}
+
+// IndexerImageHash operations
+
+func (m *MySQLDatabase) CreateOrUpdateIndexerImageHash(hash *model.IndexerImageHash) error {
+	var existing model.IndexerImageHash
+	err := m.db.Where("pin_id = ?", hash.PinID).First(&existing).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return m.db.Create(hash).Error
+	} else if err != nil {
+		return err
+	}
+
+	hash.ID = existing.ID
+	hash.CreatedAt = existing.CreatedAt
+	return m.db.Save(hash).Error
+}
+
+func (m *MySQLDatabase) GetIndexerImageHashByPinID(pinID string) (*model.IndexerImageHash, error) {
+	var hash model.IndexerImageHash
+	err := m.db.Where("pin_id = ?", pinID).First(&hash).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	return &hash, err
+}
+
+func (m *MySQLDatabase) ForEachIndexerImageHashByBands(bands [model.ImageHashBands][]int, fn func(hash *model.IndexerImageHash) error) error {
+	var conditions []string
+	var args []interface{}
+	for i, values := range bands {
+		if len(values) == 0 {
+			continue
+		}
+		conditions = append(conditions, fmt.Sprintf("band%d IN ?", i))
+		args = append(args, values)
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	var hashes []*model.IndexerImageHash
+	return m.db.Where(strings.Join(conditions, " OR "), args...).Order("id ASC").
+		FindInBatches(&hashes, 500, func(tx *gorm.DB, batch int) error {
+			for _, hash := range hashes {
+				if err := fn(hash); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// IndexerBlock operations
+
+func (m *MySQLDatabase) CreateIndexerBlock(block *model.IndexerBlock) error {
+	var count int64
+	err := m.db.Model(&model.IndexerBlock{}).
+		Where("block_type = ? AND value = ?", block.BlockType, block.Value).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicate
+	}
+	return m.db.Create(block).Error
+}
+
+func (m *MySQLDatabase) GetIndexerBlockByID(id int64) (*model.IndexerBlock, error) {
+	var block model.IndexerBlock
+	err := m.db.Where("id = ?", id).First(&block).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	return &block, err
+}
+
+func (m *MySQLDatabase) FindIndexerBlocks(keys []model.BlockKey) ([]*model.IndexerBlock, error) {
+	pairs := make([][]interface{}, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, []interface{}{key.BlockType, key.Value})
+	}
+
+	var blocks []*model.IndexerBlock
+	err := m.db.Where("(block_type, value) IN ?", pairs).Find(&blocks).Error
+	return blocks, err
+}
+
+func (m *MySQLDatabase) ListIndexerBlocksWithCursor(blockType string, cursor int64, size int) ([]*model.IndexerBlock, error) {
+	var blocks []*model.IndexerBlock
+	query := m.db.Model(&model.IndexerBlock{})
+
+	if blockType != "" {
+		query = query.Where("block_type = ?", blockType)
+	}
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+
+	err := query.Order("id DESC").Limit(size).Find(&blocks).Error
+	return blocks, err
+}
+
+func (m *MySQLDatabase) DeleteIndexerBlock(id int64) error {
+	return m.db.Where("id = ?", id).Delete(&model.IndexerBlock{}).Error
+}
+
+func (m *MySQLDatabase) CreateIndexerBlockAudit(audit *model.IndexerBlockAudit) error {
+	return m.db.Create(audit).Error
+}
+
+func (m *MySQLDatabase) ListIndexerBlockAuditsWithCursor(cursor int64, size int) ([]*model.IndexerBlockAudit, error) {
+	var audits []*model.IndexerBlockAudit
+	query := m.db.Model(&model.IndexerBlockAudit{})
+
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+
+	err := query.Order("id DESC").Limit(size).Find(&audits).Error
+	return audits, err
+}
+
 // IndexerSyncStatus operations
 
 func (m *MySQLDatabase) CreateOrUpdateIndexerSyncStatus(status *model.IndexerSyncStatus) error {
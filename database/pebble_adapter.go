@@ -7,7 +7,10 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"meta-media-service/model"
 
@@ -18,9 +21,17 @@ import (
 type PebbleDatabase struct {
 	collections map[string]*pebble.DB // Map of collection name to PebbleDB instance
 
-	fileIDCounter   atomic.Int64
-	avatarIDCounter atomic.Int64
-	statusIDCounter atomic.Int64
+	fileIDCounter       atomic.Int64
+	avatarIDCounter     atomic.Int64
+	statusIDCounter     atomic.Int64
+	blobIDCounter       atomic.Int64
+	mediaIDCounter      atomic.Int64
+	imageHashIDCounter  atomic.Int64
+	blockIDCounter      atomic.Int64
+	blockAuditIDCounter atomic.Int64
+
+	blobMu  sync.Mutex // Serializes blob reference count updates
+	blockMu sync.Mutex // Serializes blocklist updates
 }
 
 // PebbleConfig PebbleDB configuration
@@ -31,10 +42,11 @@ type PebbleConfig struct {
 // Collection names and their key-value formats
 const (
 	// File collections
-	collectionFilePinID   = "file_pin"  // key: {pin_id}, value: JSON(IndexerFile) - PinID 到 ID 的映射
-	collectionFileAddress = "file_addr" // key: {address}:{pin_id}, value: JSON(IndexerFile) - 按地址索引
-	collectionFileMetaID  = "file_meta" // key: {meta_id}:{pin_id}, value: JSON(IndexerFile) - 按 MetaID 索引
-	collectionFileHash    = "file_hash" // key: {hash}:{pin_id}, value: JSON(IndexerFile) - 按 Hash 索引
+	collectionFilePinID    = "file_pin"      // key: {pin_id}, value: JSON(IndexerFile) - PinID 到 ID 的映射
+	collectionFileAddress  = "file_addr"     // key: {address}:{pin_id}, value: JSON(IndexerFile) - 按地址索引
+	collectionFileMetaID   = "file_meta"     // key: {meta_id}:{pin_id}, value: JSON(IndexerFile) - 按 MetaID 索引
+	collectionFileHash     = "file_hash"     // key: {hash}:{pin_id}, value: JSON(IndexerFile) - 按 Hash 索引
+	collectionFileMismatch = "file_mismatch" // key: {pin_id}, value: JSON(IndexerFile) - 内容类型与声明不符的文件
 
 	// Avatar collections
 	collectionAvatarPinID           = "avatar_pin"            // key: {pin_id}, value: JSON(IndexerUserAvatar) - PinID 到 ID 的映射
@@ -44,16 +56,36 @@ const (
 	collectionAvatarHash            = "avatar_hash"           // key: {hash}:{pin_id}, value: JSON(IndexerUserAvatar) - 按 Hash 索引
 	collectionLasestAvatarMetaID    = "avatar_lasest_meta_id" // key: {meta_id}, value: JSON(IndexerUserAvatar) - 按 MetaID 索引
 
+	// Blob collections
+	collectionBlobHash = "blob_hash" // key: {file_hash}, value: JSON(IndexerBlob) - 内容寻址存储引用计数
+
+	// Media metadata collections
+	collectionMediaPinID = "media_pin" // key: {pin_id}, value: JSON(IndexerMediaMetadata) - 媒体元数据
+
+	// Image hash collections
+	collectionImageHashPinID = "image_hash_pin"  // key: {pin_id}, value: JSON(IndexerImageHash) - 图片感知哈希
+	collectionImageHashBand  = "image_hash_band" // key: {band}:{value}:{pin_id}, value: empty - 按哈希分段索引（汉明距离查询）
+
+	// Moderation collections
+	collectionBlockKey   = "block_key"   // key: {block_type}:{value}, value: JSON(IndexerBlock) - 屏蔽名单
+	collectionBlockID    = "block_id"    // key: {id}, value: JSON(IndexerBlock) - 按 ID 索引（ID 补零至 20 位）
+	collectionBlockAudit = "block_audit" // key: {id}, value: JSON(IndexerBlockAudit) - 屏蔽审计日志（ID 补零至 20 位）
+
 	// System collections
 	collectionSyncStatus = "sync_status" // key: {chain_name}, value: JSON(IndexerSyncStatus) - 同步状态
-	collectionCounters   = "counters"    // key: file/avatar/status, value: {max_id} - ID 计数器
+	collectionCounters   = "counters"    // key: file/avatar/status/blob/media/image_hash/blocklist/blocklist_audit, value: {max_id} - ID 计数器
 )
 
 // Counter keys
 const (
-	keyFileCounter   = "file"
-	keyAvatarCounter = "avatar"
-	keyStatusCounter = "status"
+	keyFileCounter       = "file"
+	keyAvatarCounter     = "avatar"
+	keyStatusCounter     = "status"
+	keyBlobCounter       = "blob"
+	keyMediaCounter      = "media"
+	keyImageHashCounter  = "image_hash"
+	keyBlockCounter      = "blocklist"
+	keyBlockAuditCounter = "blocklist_audit"
 )
 
 // NewPebbleDatabase create PebbleDB database instance with multiple collections
@@ -76,12 +108,20 @@ func NewPebbleDatabase(config interface{}) (Database, error) {
 		collectionFileAddress,
 		collectionFileMetaID,
 		collectionFileHash,
+		collectionFileMismatch,
 		collectionAvatarPinID,
 		collectionAvatarMetaID,
 		collectionAvatarMetaIDTimestamp,
 		collectionAvatarAddr,
 		collectionAvatarHash,
 		collectionLasestAvatarMetaID,
+		collectionBlobHash,
+		collectionMediaPinID,
+		collectionImageHashPinID,
+		collectionImageHashBand,
+		collectionBlockKey,
+		collectionBlockID,
+		collectionBlockAudit,
 		collectionSyncStatus,
 		collectionCounters,
 	}
@@ -146,6 +186,41 @@ func (p *PebbleDatabase) loadCounters() error {
 		closer.Close()
 	}
 
+	// Load blob counter
+	if val, closer, err := counterDB.Get([]byte(keyBlobCounter)); err == nil {
+		count, _ := strconv.ParseInt(string(val), 10, 64)
+		p.blobIDCounter.Store(count)
+		closer.Close()
+	}
+
+	// Load media metadata counter
+	if val, closer, err := counterDB.Get([]byte(keyMediaCounter)); err == nil {
+		count, _ := strconv.ParseInt(string(val), 10, 64)
+		p.mediaIDCounter.Store(count)
+		closer.Close()
+	}
+
+	// Load image hash counter
+	if val, closer, err := counterDB.Get([]byte(keyImageHashCounter)); err == nil {
+		count, _ := strconv.ParseInt(string(val), 10, 64)
+		p.imageHashIDCounter.Store(count)
+		closer.Close()
+	}
+
+	// Load blocklist counter
+	if val, closer, err := counterDB.Get([]byte(keyBlockCounter)); err == nil {
+		count, _ := strconv.ParseInt(string(val), 10, 64)
+		p.blockIDCounter.Store(count)
+		closer.Close()
+	}
+
+	// Load blocklist audit counter
+	if val, closer, err := counterDB.Get([]byte(keyBlockAuditCounter)); err == nil {
+		count, _ := strconv.ParseInt(string(val), 10, 64)
+		p.blockAuditIDCounter.Store(count)
+		closer.Close()
+	}
+
 	return nil
 }
 
@@ -185,6 +260,16 @@ func (p *PebbleDatabase) CreateIndexerFile(file *model.IndexerFile) error {
 		return err
 	}
 
+	// Store in content type mismatch collection (removed when the flag is cleared)
+	// key: pin_id, value: JSON(IndexerFile)
+	if file.ContentTypeMismatch {
+		if err := p.collections[collectionFileMismatch].Set([]byte(file.PinID), data, pebble.Sync); err != nil {
+			return err
+		}
+	} else if err := p.collections[collectionFileMismatch].Delete([]byte(file.PinID), pebble.Sync); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -367,6 +452,30 @@ func (p *PebbleDatabase) GetIndexerFilesByCreatorMetaIDWithCursor(metaID string,
 	return files, nil
 }
 
+func (p *PebbleDatabase) GetIndexerFilesWithContentTypeMismatchCursor(cursor int64, size int) ([]*model.IndexerFile, error) {
+	var files []*model.IndexerFile
+
+	// key format: pin_id
+	iter, err := p.collections[collectionFileMismatch].NewIter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	// Cursor is not used for PinID-based iteration, iterate from the end like ListIndexerFilesWithCursor
+	for iter.Last(); iter.Valid() && len(files) < size; iter.Prev() {
+		var file model.IndexerFile
+		if err := json.Unmarshal(iter.Value(), &file); err != nil {
+			continue
+		}
+		if file.Status == model.StatusSuccess {
+			files = append(files, &file)
+		}
+	}
+
+	return files, nil
+}
+
 func (p *PebbleDatabase) GetIndexerFilesCount() (int64, error) {
 	var count int64
 
@@ -394,6 +503,26 @@ func (p *PebbleDatabase) GetIndexerFilesCount() (int64, error) {
 	return count, nil
 }
 
+func (p *PebbleDatabase) ForEachIndexerFile(fn func(file *model.IndexerFile) error) error {
+	iter, err := p.collections[collectionFilePinID].NewIter(nil)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		var file model.IndexerFile
+		if err := json.Unmarshal(iter.Value(), &file); err != nil {
+			continue
+		}
+		if err := fn(&file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // IndexerUserAvatar operations
 
 func (p *PebbleDatabase) CreateIndexerUserAvatar(avatar *model.IndexerUserAvatar) error {
@@ -568,7 +697,35 @@ func (p *PebbleDatabase) GetIndexerUserAvatarByAddress(address string) (*model.I
 }
 
 func (p *PebbleDatabase) UpdateIndexerUserAvatar(avatar *model.IndexerUserAvatar) error {
-	return p.CreateIndexerUserAvatar(avatar)
+	if err := p.CreateIndexerUserAvatar(avatar); err != nil {
+		return err
+	}
+
+	// Create only replaces the latest avatar with a later one. When the updated PIN is the latest
+	// avatar (e.g. its storage path changed during compaction), refresh the stored copy too.
+	latestAvatarDB := p.collections[collectionLasestAvatarMetaID]
+	existingData, closer, err := latestAvatarDB.Get([]byte(avatar.MetaId))
+	if err == pebble.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var existingAvatar model.IndexerUserAvatar
+	err = json.Unmarshal(existingData, &existingAvatar)
+	closer.Close()
+	if err != nil {
+		return err
+	}
+	if existingAvatar.PinID != avatar.PinID {
+		return nil
+	}
+
+	data, err := json.Marshal(avatar)
+	if err != nil {
+		return err
+	}
+	return latestAvatarDB.Set([]byte(avatar.MetaId), data, pebble.Sync)
 }
 
 func (p *PebbleDatabase) ListIndexerUserAvatarsWithCursor(cursor int64, size int) ([]*model.IndexerUserAvatar, error) {
@@ -615,6 +772,464 @@ func (p *PebbleDatabase) ListIndexerUserAvatarsWithCursor(cursor int64, size int
 	return avatars, nil
 }
 
+func (p *PebbleDatabase) ForEachIndexerUserAvatar(fn func(avatar *model.IndexerUserAvatar) error) error {
+	iter, err := p.collections[collectionAvatarPinID].NewIter(nil)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		var avatar model.IndexerUserAvatar
+		if err := json.Unmarshal(iter.Value(), &avatar); err != nil {
+			continue
+		}
+		if err := fn(&avatar); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IndexerBlob operations
+
+func (p *PebbleDatabase) GetIndexerBlobByHash(fileHash string) (*model.IndexerBlob, error) {
+	data, closer, err := p.collections[collectionBlobHash].Get([]byte(fileHash))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer closer.Close()
+
+	var blob model.IndexerBlob
+	if err := json.Unmarshal(data, &blob); err != nil {
+		return nil, err
+	}
+
+	return &blob, nil
+}
+
+func (p *PebbleDatabase) IncrIndexerBlobRef(blob *model.IndexerBlob) (int64, error) {
+	p.blobMu.Lock()
+	defer p.blobMu.Unlock()
+
+	existing, err := p.GetIndexerBlobByHash(blob.FileHash)
+	if err != nil && err != ErrNotFound {
+		return 0, err
+	}
+
+	if existing == nil {
+		// First reference, create blob record
+		existing = blob
+		existing.ID = p.blobIDCounter.Add(1)
+		existing.RefCount = 0
+		// Persist the counter first, so IDs are never reused after a restart
+		if err := p.collections[collectionCounters].Set(
+			[]byte(keyBlobCounter),
+			[]byte(strconv.FormatInt(existing.ID, 10)),
+			pebble.Sync,
+		); err != nil {
+			return 0, err
+		}
+	}
+	existing.RefCount++
+
+	data, err := json.Marshal(existing)
+	if err != nil {
+		return 0, err
+	}
+
+	// key: file_hash, value: JSON(IndexerBlob)
+	if err := p.collections[collectionBlobHash].Set([]byte(existing.FileHash), data, pebble.Sync); err != nil {
+		return 0, err
+	}
+
+	return existing.RefCount, nil
+}
+
+func (p *PebbleDatabase) DecrIndexerBlobRef(fileHash string) (int64, error) {
+	p.blobMu.Lock()
+	defer p.blobMu.Unlock()
+
+	existing, err := p.GetIndexerBlobByHash(fileHash)
+	if err != nil {
+		return 0, err
+	}
+
+	existing.RefCount--
+	if existing.RefCount <= 0 {
+		// Last reference released, remove blob record
+		if err := p.collections[collectionBlobHash].Delete([]byte(fileHash), pebble.Sync); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+
+	data, err := json.Marshal(existing)
+	if err != nil {
+		return 0, err
+	}
+	if err := p.collections[collectionBlobHash].Set([]byte(fileHash), data, pebble.Sync); err != nil {
+		return 0, err
+	}
+
+	return existing.RefCount, nil
+}
+
+// IndexerMediaMetadata operations
+
+func (p *PebbleDatabase) CreateOrUpdateIndexerMediaMetadata(metadata *model.IndexerMediaMetadata) error {
+	existing, err := p.GetIndexerMediaMetadataByPinID(metadata.PinID)
+	if err != nil && err != ErrNotFound {
+		return err
+	}
+
+	now := time.Now()
+	if existing != nil {
+		metadata.ID = existing.ID
+		metadata.CreatedAt = existing.CreatedAt
+	} else {
+		metadata.ID = p.mediaIDCounter.Add(1)
+		metadata.CreatedAt = now
+		if err := p.collections[collectionCounters].Set(
+			[]byte(keyMediaCounter),
+			[]byte(strconv.FormatInt(metadata.ID, 10)),
+			pebble.Sync,
+		); err != nil {
+			return err
+		}
+	}
+	metadata.UpdatedAt = now
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	// key: pin_id, value: JSON(IndexerMediaMetadata)
+	return p.collections[collectionMediaPinID].Set([]byte(metadata.PinID), data, pebble.Sync)
+}
+
+func (p *PebbleDatabase) GetIndexerMediaMetadataByPinID(pinID string) (*model.IndexerMediaMetadata, error) {
+	data, closer, err := p.collections[collectionMediaPinID].Get([]byte(pinID))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer closer.Close()
+
+	var metadata model.IndexerMediaMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// IndexerImageHash operations
+
+func (p *PebbleDatabase) CreateOrUpdateIndexerImageHash(hash *model.IndexerImageHash) error {
+	existing, err := p.GetIndexerImageHashByPinID(hash.PinID)
+	if err != nil && err != ErrNotFound {
+		return err
+	}
+
+	bandDB := p.collections[collectionImageHashBand]
+	now := time.Now()
+	if existing != nil {
+		hash.ID = existing.ID
+		hash.CreatedAt = existing.CreatedAt
+		// Drop band keys of the previous hash
+		for i, value := range existing.Bands() {
+			if err := bandDB.Delete(imageHashBandKey(i, value, existing.PinID), pebble.Sync); err != nil {
+				return err
+			}
+		}
+	} else {
+		hash.ID = p.imageHashIDCounter.Add(1)
+		hash.CreatedAt = now
+		if err := p.collections[collectionCounters].Set(
+			[]byte(keyImageHashCounter),
+			[]byte(strconv.FormatInt(hash.ID, 10)),
+			pebble.Sync,
+		); err != nil {
+			return err
+		}
+	}
+	hash.UpdatedAt = now
+
+	data, err := json.Marshal(hash)
+	if err != nil {
+		return err
+	}
+
+	// key: pin_id, value: JSON(IndexerImageHash)
+	if err := p.collections[collectionImageHashPinID].Set([]byte(hash.PinID), data, pebble.Sync); err != nil {
+		return err
+	}
+
+	// key: {band}:{value}:{pin_id}, value: empty
+	for i, value := range hash.Bands() {
+		if err := bandDB.Set(imageHashBandKey(i, value, hash.PinID), nil, pebble.Sync); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PebbleDatabase) GetIndexerImageHashByPinID(pinID string) (*model.IndexerImageHash, error) {
+	data, closer, err := p.collections[collectionImageHashPinID].Get([]byte(pinID))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer closer.Close()
+
+	var hash model.IndexerImageHash
+	if err := json.Unmarshal(data, &hash); err != nil {
+		return nil, err
+	}
+
+	return &hash, nil
+}
+
+func (p *PebbleDatabase) ForEachIndexerImageHashByBands(bands [model.ImageHashBands][]int, fn func(hash *model.IndexerImageHash) error) error {
+	bandDB := p.collections[collectionImageHashBand]
+
+	seen := make(map[string]bool)
+	for i, values := range bands {
+		for _, value := range values {
+			prefix := string(imageHashBandKey(i, value, ""))
+			iter, err := bandDB.NewIter(&pebble.IterOptions{
+				LowerBound: []byte(prefix),
+				UpperBound: []byte(prefix + "~"),
+			})
+			if err != nil {
+				return err
+			}
+
+			for iter.First(); iter.Valid(); iter.Next() {
+				pinID := strings.TrimPrefix(string(iter.Key()), prefix)
+				if seen[pinID] {
+					continue
+				}
+				seen[pinID] = true
+
+				hash, err := p.GetIndexerImageHashByPinID(pinID)
+				if err == nil {
+					err = fn(hash)
+				} else if err == ErrNotFound {
+					continue
+				}
+				if err != nil {
+					iter.Close()
+					return err
+				}
+			}
+			iter.Close()
+		}
+	}
+
+	return nil
+}
+
+// imageHashBandKey band index key, {band}:{value as 4 hex digits}:{pin_id}
+func imageHashBandKey(band, value int, pinID string) []byte {
+	return []byte(fmt.Sprintf("%d:%04x:%s", band, value, pinID))
+}
+
+// IndexerBlock operations
+
+func (p *PebbleDatabase) CreateIndexerBlock(block *model.IndexerBlock) error {
+	p.blockMu.Lock()
+	defer p.blockMu.Unlock()
+
+	keyDB := p.collections[collectionBlockKey]
+	key := blockKey(block.BlockType, block.Value)
+	if _, closer, err := keyDB.Get(key); err == nil {
+		closer.Close()
+		return ErrDuplicate
+	} else if err != pebble.ErrNotFound {
+		return err
+	}
+
+	now := time.Now()
+	block.ID = p.blockIDCounter.Add(1)
+	block.CreatedAt = now
+	block.UpdatedAt = now
+	if err := p.collections[collectionCounters].Set(
+		[]byte(keyBlockCounter),
+		[]byte(strconv.FormatInt(block.ID, 10)),
+		pebble.Sync,
+	); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+
+	// key: {block_type}:{value}, value: JSON(IndexerBlock)
+	if err := keyDB.Set(key, data, pebble.Sync); err != nil {
+		return err
+	}
+
+	// key: {id}, value: JSON(IndexerBlock)
+	return p.collections[collectionBlockID].Set(sequenceKey(block.ID), data, pebble.Sync)
+}
+
+func (p *PebbleDatabase) GetIndexerBlockByID(id int64) (*model.IndexerBlock, error) {
+	data, closer, err := p.collections[collectionBlockID].Get(sequenceKey(id))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer closer.Close()
+
+	var block model.IndexerBlock
+	if err := json.Unmarshal(data, &block); err != nil {
+		return nil, err
+	}
+
+	return &block, nil
+}
+
+func (p *PebbleDatabase) FindIndexerBlocks(keys []model.BlockKey) ([]*model.IndexerBlock, error) {
+	keyDB := p.collections[collectionBlockKey]
+
+	var blocks []*model.IndexerBlock
+	for _, key := range keys {
+		data, closer, err := keyDB.Get(blockKey(key.BlockType, key.Value))
+		if err != nil {
+			if err == pebble.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+
+		var block model.IndexerBlock
+		err = json.Unmarshal(data, &block)
+		closer.Close()
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, &block)
+	}
+
+	return blocks, nil
+}
+
+func (p *PebbleDatabase) ListIndexerBlocksWithCursor(blockType string, cursor int64, size int) ([]*model.IndexerBlock, error) {
+	var blocks []*model.IndexerBlock
+
+	// key format: {id}, newest first, IDs below the cursor only
+	opts := &pebble.IterOptions{}
+	if cursor > 0 {
+		opts.UpperBound = sequenceKey(cursor)
+	}
+	iter, err := p.collections[collectionBlockID].NewIter(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	for iter.Last(); iter.Valid() && len(blocks) < size; iter.Prev() {
+		var block model.IndexerBlock
+		if err := json.Unmarshal(iter.Value(), &block); err != nil {
+			continue
+		}
+		if blockType != "" && block.BlockType != blockType {
+			continue
+		}
+		blocks = append(blocks, &block)
+	}
+
+	return blocks, nil
+}
+
+func (p *PebbleDatabase) DeleteIndexerBlock(id int64) error {
+	p.blockMu.Lock()
+	defer p.blockMu.Unlock()
+
+	block, err := p.GetIndexerBlockByID(id)
+	if err != nil {
+		if err == ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if err := p.collections[collectionBlockKey].Delete(blockKey(block.BlockType, block.Value), pebble.Sync); err != nil {
+		return err
+	}
+	return p.collections[collectionBlockID].Delete(sequenceKey(id), pebble.Sync)
+}
+
+func (p *PebbleDatabase) CreateIndexerBlockAudit(audit *model.IndexerBlockAudit) error {
+	audit.ID = p.blockAuditIDCounter.Add(1)
+	audit.CreatedAt = time.Now()
+	if err := p.collections[collectionCounters].Set(
+		[]byte(keyBlockAuditCounter),
+		[]byte(strconv.FormatInt(audit.ID, 10)),
+		pebble.Sync,
+	); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(audit)
+	if err != nil {
+		return err
+	}
+
+	// key: {id}, value: JSON(IndexerBlockAudit)
+	return p.collections[collectionBlockAudit].Set(sequenceKey(audit.ID), data, pebble.Sync)
+}
+
+func (p *PebbleDatabase) ListIndexerBlockAuditsWithCursor(cursor int64, size int) ([]*model.IndexerBlockAudit, error) {
+	var audits []*model.IndexerBlockAudit
+
+	// key format: {id}, newest first, IDs below the cursor only
+	opts := &pebble.IterOptions{}
+	if cursor > 0 {
+		opts.UpperBound = sequenceKey(cursor)
+	}
+	iter, err := p.collections[collectionBlockAudit].NewIter(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	for iter.Last(); iter.Valid() && len(audits) < size; iter.Prev() {
+		var audit model.IndexerBlockAudit
+		if err := json.Unmarshal(iter.Value(), &audit); err != nil {
+			continue
+		}
+		audits = append(audits, &audit)
+	}
+
+	return audits, nil
+}
+
+// blockKey blocklist key, {block_type}:{value}
+func blockKey(blockType, value string) []byte {
+	return []byte(blockType + ":" + value)
+}
+
+// sequenceKey ID key zero-padded to 20 digits, so keys sort by ID
+func sequenceKey(id int64) []byte {
+	return []byte(fmt.Sprintf("%020d", id))
+}
+
 // IndexerSyncStatus operations
 
 func (p *PebbleDatabase) CreateOrUpdateIndexerSyncStatus(status *model.IndexerSyncStatus) error {
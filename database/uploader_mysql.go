@@ -55,6 +55,12 @@ func AutoMigrate() error {
 		&model.File{},
 		&model.FileChunk{},
 		&model.Assistant{},
+		&model.TusUpload{},
+		&model.UtxoReservation{},
+		&model.SponsoredUpload{},
+		&model.SponsorBudget{},
+		&model.FileTx{},
+		&model.FileTxEvent{},
 	)
 }
 
@@ -24,9 +24,9 @@ const docTemplateindexer = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/avatars": {
+        "/admin/audit": {
             "get": {
-                "description": "Query avatar list with cursor pagination",
+                "description": "Query block, unblock and hash match events with cursor pagination, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -34,14 +34,14 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer Avatar Query"
+                    "Indexer Admin"
                 ],
-                "summary": "Query avatar list",
+                "summary": "Query moderation audit log",
                 "parameters": [
                     {
                         "type": "integer",
                         "default": 0,
-                        "description": "Cursor (last avatar ID)",
+                        "description": "Cursor (last audit entry ID)",
                         "name": "cursor",
                         "in": "query"
                     },
@@ -65,25 +65,30 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerAvatarListResponse"
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerBlockAuditListResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
             }
         },
-        "/avatars/address/{address}": {
+        "/admin/blocks": {
             "get": {
-                "description": "Query the latest avatar information by address",
+                "description": "Query blocklist entries with cursor pagination, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -91,16 +96,29 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer Avatar Query"
+                    "Indexer Admin"
                 ],
-                "summary": "Get latest avatar by address",
+                "summary": "Query blocklist",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Address",
-                        "name": "address",
-                        "in": "path",
-                        "required": true
+                        "description": "Block type: pin/hash/address/metaid (empty for all)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor (last block ID)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -115,63 +133,34 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerAvatarResponse"
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerBlockListResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
-                    }
-                }
-            }
-        },
-        "/avatars/content/{pinId}": {
-            "get": {
-                "description": "Get avatar content by PIN ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/octet-stream"
-                ],
-                "tags": [
-                    "Indexer Avatar Query"
-                ],
-                "summary": "Get avatar content",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "PIN ID",
-                        "name": "pinId",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "file"
-                        }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
                     }
-                }
-            }
-        },
-        "/avatars/metaid/{metaId}": {
-            "get": {
-                "description": "Query the latest avatar information by MetaID",
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Block a PIN, a content hash (SHA256, also applied to PINs indexed later), a creator address or a creator MetaID. Blocked content returns 451 from content, thumbnail and preview endpoints and is hidden from lists.",
                 "consumes": [
                     "application/json"
                 ],
@@ -179,16 +168,18 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer Avatar Query"
+                    "Indexer Admin"
                 ],
-                "summary": "Get latest avatar by MetaID",
+                "summary": "Block content",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "MetaID",
-                        "name": "metaId",
-                        "in": "path",
-                        "required": true
+                        "description": "Block target",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.CreateBlockRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -203,25 +194,36 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerAvatarResponse"
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerBlockResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
             }
         },
-        "/files": {
-            "get": {
-                "description": "Query file list with cursor pagination",
+        "/admin/blocks/{id}": {
+            "delete": {
+                "description": "Remove a blocklist entry by ID. The entry stays in the audit log.",
                 "consumes": [
                     "application/json"
                 ],
@@ -229,23 +231,25 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
+                    "Indexer Admin"
                 ],
-                "summary": "Query file list",
+                "summary": "Unblock content",
                 "parameters": [
                     {
                         "type": "integer",
-                        "default": 0,
-                        "description": "Cursor (last file ID)",
-                        "name": "cursor",
-                        "in": "query"
+                        "description": "Block ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "size",
-                        "in": "query"
+                        "description": "Unblock reason and operator",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.DeleteBlockRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -260,49 +264,23 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerFileListResponse"
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerBlockResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
-                    }
-                }
-            }
-        },
-        "/files/content/{pinId}": {
-            "get": {
-                "description": "Get file content by PIN ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/octet-stream"
-                ],
-                "tags": [
-                    "Indexer File Query"
-                ],
-                "summary": "Get file content",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "PIN ID",
-                        "name": "pinId",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
                     },
                     "404": {
@@ -311,12 +289,17 @@ const docTemplateindexer = `{
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
             }
         },
-        "/files/creator/{address}": {
+        "/avatars": {
             "get": {
-                "description": "Query file list by creator address with cursor pagination",
+                "description": "Query avatar list with cursor pagination",
                 "consumes": [
                     "application/json"
                 ],
@@ -324,21 +307,14 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
+                    "Indexer Avatar Query"
                 ],
-                "summary": "Get files by creator address",
+                "summary": "Query avatar list",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Creator address",
-                        "name": "address",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "integer",
                         "default": 0,
-                        "description": "Cursor (last file ID)",
+                        "description": "Cursor (last avatar ID)",
                         "name": "cursor",
                         "in": "query"
                     },
@@ -362,7 +338,7 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerFileListResponse"
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerAvatarListResponse"
                                         }
                                     }
                                 }
@@ -378,9 +354,9 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/metaid/{metaId}": {
+        "/avatars/address/{address}": {
             "get": {
-                "description": "Query file list by creator MetaID with cursor pagination",
+                "description": "Query the latest avatar information by address",
                 "consumes": [
                     "application/json"
                 ],
@@ -388,30 +364,16 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
+                    "Indexer Avatar Query"
                 ],
-                "summary": "Get files by creator MetaID",
+                "summary": "Get latest avatar by address",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Creator MetaID",
-                        "name": "metaId",
+                        "description": "Address",
+                        "name": "address",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Cursor (last file ID)",
-                        "name": "cursor",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "size",
-                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -426,15 +388,21 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerFileListResponse"
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerAvatarResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
@@ -442,19 +410,19 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/{pinId}": {
+        "/avatars/content/{pinId}": {
             "get": {
-                "description": "Query file details by PIN ID",
+                "description": "Get avatar content by PIN ID. SVG avatars are served as a sanitized copy.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Indexer File Query"
+                    "Indexer Avatar Query"
                 ],
-                "summary": "Get file by PIN ID",
+                "summary": "Get avatar content",
                 "parameters": [
                     {
                         "type": "string",
@@ -468,19 +436,7 @@ const docTemplateindexer = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerFileResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
                         }
                     },
                     "404": {
@@ -488,13 +444,19 @@ const docTemplateindexer = `{
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
                     }
                 }
             }
         },
-        "/stats": {
+        "/avatars/metaid/{metaId}": {
             "get": {
-                "description": "Get indexer statistics (total files count, etc.)",
+                "description": "Query the latest avatar information by MetaID",
                 "consumes": [
                     "application/json"
                 ],
@@ -502,9 +464,18 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer Status"
+                    "Indexer Avatar Query"
+                ],
+                "summary": "Get latest avatar by MetaID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "MetaID",
+                        "name": "metaId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get statistics",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -517,72 +488,1112 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerStatsResponse"
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerAvatarResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/avatars/metaid/{metaId}/image": {
+            "get": {
+                "description": "Get the latest avatar as a square, center-cropped image. Sizes are rounded up to 32/48/64/96/128/256/512. Users without a decodable avatar get a deterministic identicon.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "Indexer Avatar Query"
+                ],
+                "summary": "Get avatar image by MetaID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "MetaID",
+                        "name": "metaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 128,
+                        "description": "Size in pixels (32-512)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files": {
+            "get": {
+                "description": "Query file list with cursor pagination. With mismatch=true only files whose detected content contradicts the declared content type are returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Query file list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor (last file ID)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Only files with a content type mismatch",
+                        "name": "mismatch",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerFileListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/content/{pinId}": {
+            "get": {
+                "description": "Get file content by PIN ID. HTML, XML and script content is served as an attachment, SVG is served as a sanitized copy.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get file content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/creator/{address}": {
+            "get": {
+                "description": "Query file list by creator address with cursor pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get files by creator address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Creator address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor (last file ID)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerFileListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/metaid/{metaId}": {
+            "get": {
+                "description": "Query file list by creator MetaID with cursor pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get files by creator MetaID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Creator MetaID",
+                        "name": "metaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor (last file ID)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerFileListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/thumbnail/{pinId}": {
+            "get": {
+                "description": "Resize a JPEG/PNG/GIF (first frame) image file. Derivatives are cached, so repeated requests are cheap.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get image thumbnail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width (0 = derived from height)",
+                        "name": "w",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Height (0 = derived from width)",
+                        "name": "h",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "contain",
+                        "description": "Fit mode: contain/cover/fill",
+                        "name": "fit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output format: jpeg/png (default: png for png/gif sources, jpeg otherwise)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{pinId}": {
+            "get": {
+                "description": "Query file details by PIN ID, including media metadata (dimensions, duration, codecs, EXIF) for image/video/audio files",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get file by PIN ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerFileResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{pinId}/entries": {
+            "get": {
+                "description": "List the entries of a ZIP, TAR or tar.gz archive PIN with sizes and types. Archives exceeding the entry count, expanded size or compression ratio limits are rejected. The index is cached after the first listing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "List archive entries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Archive PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerArchiveEntryListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{pinId}/entries/{path}": {
+            "get": {
+                "description": "Stream one regular file out of a ZIP, TAR or tar.gz archive PIN. Active content (HTML, XML, SVG, script) is served as an attachment.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get archive entry content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Archive PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entry path inside the archive, e.g. docs/readme.txt",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{pinId}/preview": {
+            "get": {
+                "description": "Render a text PIN as an embeddable, sanitized HTML fragment: Markdown is converted, JSON is pretty-printed and source code is syntax-highlighted (span classes tok-keyword, tok-string, tok-comment, tok-number, tok-literal, tok-tag, tok-attr). The charset is detected and transcoded to UTF-8. Long files are truncated with a \"view full\" link.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get text preview",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerFilePreviewResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{pinId}/similar": {
+            "get": {
+                "description": "Find images that look like the given image PIN (re-encoded, resized or slightly edited copies) by perceptual hash Hamming distance. Results are ordered by distance, then by timestamp (earliest first). truncated is set when too many images share a hash band with the query image and only part of them were checked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get similar images",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 8,
+                        "description": "Maximum Hamming distance (0-11)",
+                        "name": "max_distance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum number of results",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerSimilarFileListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "451": {
+                        "description": "Unavailable For Legal Reasons",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats": {
+            "get": {
+                "description": "Get indexer statistics (total files count, storage cache hit/miss stats, etc.)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer Status"
+                ],
+                "summary": "Get statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerStatsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "Get indexer synchronization status (includes latest block height from node)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer Status"
+                ],
+                "summary": "Get sync status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerSyncStatusResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
                     }
                 }
             }
+        }
+    },
+    "definitions": {
+        "controller_handler.CreateBlockRequest": {
+            "type": "object",
+            "required": [
+                "operator",
+                "reason",
+                "type",
+                "value"
+            ],
+            "properties": {
+                "operator": {
+                    "type": "string",
+                    "example": "legal@example.com"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "DMCA notice #2024-017"
+                },
+                "type": {
+                    "description": "pin/hash/address/metaid",
+                    "type": "string",
+                    "example": "pin"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "abc123def456i0"
+                }
+            }
+        },
+        "controller_handler.DeleteBlockRequest": {
+            "type": "object",
+            "required": [
+                "operator"
+            ],
+            "properties": {
+                "operator": {
+                    "type": "string",
+                    "example": "legal@example.com"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Counter-notice accepted"
+                }
+            }
+        },
+        "meta-media-service_controller_respond.IndexerArchiveEntryListResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_controller_respond.IndexerArchiveEntryResponse"
+                    }
+                },
+                "entry_count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "format": {
+                    "description": "zip/tar/tar.gz",
+                    "type": "string",
+                    "example": "zip"
+                },
+                "pin_id": {
+                    "type": "string",
+                    "example": "abc123def456i0"
+                },
+                "total_size": {
+                    "description": "Total expanded size of all entries",
+                    "type": "integer",
+                    "example": 1048576
+                }
+            }
+        },
+        "meta-media-service_controller_respond.IndexerArchiveEntryResponse": {
+            "type": "object",
+            "properties": {
+                "compressed_size": {
+                    "type": "integer",
+                    "example": 512
+                },
+                "content_type": {
+                    "description": "Guessed from the extension (empty = unknown)",
+                    "type": "string",
+                    "example": "text/plain; charset=utf-8"
+                },
+                "link_target": {
+                    "type": "string",
+                    "example": ""
+                },
+                "mod_time": {
+                    "description": "Unix seconds (0 = unknown)",
+                    "type": "integer",
+                    "example": 1699999999
+                },
+                "path": {
+                    "type": "string",
+                    "example": "docs/readme.txt"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 1024
+                },
+                "type": {
+                    "description": "file/dir/link/other",
+                    "type": "string",
+                    "example": "file"
+                }
+            }
+        },
+        "meta-media-service_controller_respond.IndexerAvatarListResponse": {
+            "type": "object",
+            "properties": {
+                "avatars": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_controller_respond.IndexerAvatarResponse"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "next_cursor": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "meta-media-service_controller_respond.IndexerAvatarResponse": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "avatar": {
+                    "type": "string",
+                    "example": "indexer/avatar/mvc/xyz789/xyz789i0.jpg"
+                },
+                "block_height": {
+                    "type": "integer",
+                    "example": 12345
+                },
+                "chain_name": {
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "file_extension": {
+                    "type": "string",
+                    "example": ".jpg"
+                },
+                "file_hash": {
+                    "type": "string",
+                    "example": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+                },
+                "file_md5": {
+                    "type": "string",
+                    "example": "d41d8cd98f00b204e9800998ecf8427e"
+                },
+                "file_size": {
+                    "type": "integer",
+                    "example": 102400
+                },
+                "file_type": {
+                    "type": "string",
+                    "example": "image"
+                },
+                "meta_id": {
+                    "type": "string",
+                    "example": "abc123def456..."
+                },
+                "pin_id": {
+                    "description": "ID            int64     ` + "`" + `json:\"id\" example:\"1\"` + "`" + `",
+                    "type": "string",
+                    "example": "xyz789i0"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1699999999
+                },
+                "tx_id": {
+                    "type": "string",
+                    "example": "xyz789"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "meta-media-service_controller_respond.IndexerBlockAuditListResponse": {
+            "type": "object",
+            "properties": {
+                "audits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_controller_respond.IndexerBlockAuditResponse"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "next_cursor": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "meta-media-service_controller_respond.IndexerBlockAuditResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "block/unblock/match",
+                    "type": "string",
+                    "example": "block"
+                },
+                "block_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "block_type": {
+                    "description": "pin/hash/address/metaid",
+                    "type": "string",
+                    "example": "pin"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "operator": {
+                    "type": "string",
+                    "example": "legal@example.com"
+                },
+                "pin_id": {
+                    "description": "PIN a hash block was applied to (match only)",
+                    "type": "string",
+                    "example": ""
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "DMCA notice #2024-017"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "abc123def456i0"
+                }
+            }
         },
-        "/status": {
-            "get": {
-                "description": "Get indexer synchronization status (includes latest block height from node)",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Indexer Status"
-                ],
-                "summary": "Get sync status",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerSyncStatusResponse"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
-                        }
+        "meta-media-service_controller_respond.IndexerBlockListResponse": {
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_controller_respond.IndexerBlockResponse"
                     }
+                },
+                "has_more": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "next_cursor": {
+                    "type": "integer",
+                    "example": 100
                 }
             }
-        }
-    },
-    "definitions": {
-        "meta-media-service_controller_respond.IndexerAvatarListResponse": {
+        },
+        "meta-media-service_controller_respond.IndexerBlockResponse": {
             "type": "object",
             "properties": {
-                "avatars": {
+                "block_type": {
+                    "description": "pin/hash/address/metaid",
+                    "type": "string",
+                    "example": "pin"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "operator": {
+                    "type": "string",
+                    "example": "legal@example.com"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "DMCA notice #2024-017"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "abc123def456i0"
+                }
+            }
+        },
+        "meta-media-service_controller_respond.IndexerFileListResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/meta-media-service_controller_respond.IndexerAvatarResponse"
+                        "$ref": "#/definitions/meta-media-service_controller_respond.IndexerFileResponse"
                     }
                 },
                 "has_more": {
@@ -595,21 +1606,61 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "meta-media-service_controller_respond.IndexerAvatarResponse": {
+        "meta-media-service_controller_respond.IndexerFilePreviewResponse": {
             "type": "object",
             "properties": {
-                "address": {
+                "charset": {
+                    "description": "Detected source charset, the HTML is always UTF-8",
                     "type": "string",
-                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                    "example": "utf-8"
                 },
-                "avatar": {
+                "content_url": {
                     "type": "string",
-                    "example": "indexer/avatar/mvc/xyz789/xyz789i0.jpg"
+                    "example": "/api/v1/files/content/abc123def456i0"
+                },
+                "html": {
+                    "description": "Sanitized HTML fragment",
+                    "type": "string",
+                    "example": "\u003ch1\u003eTitle\u003c/h1\u003e"
+                },
+                "kind": {
+                    "description": "markdown/json/code/text",
+                    "type": "string",
+                    "example": "markdown"
+                },
+                "language": {
+                    "description": "Highlighted language of code and json",
+                    "type": "string",
+                    "example": ""
+                },
+                "pin_id": {
+                    "type": "string",
+                    "example": "abc123def456i0"
+                },
+                "size": {
+                    "description": "Size of the source file in bytes",
+                    "type": "integer",
+                    "example": 2048
                 },
+                "truncated": {
+                    "description": "Only the head of the file is rendered",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "meta-media-service_controller_respond.IndexerFileResponse": {
+            "type": "object",
+            "properties": {
                 "block_height": {
                     "type": "integer",
                     "example": 12345
                 },
+                "blur_hash": {
+                    "description": "Image placeholder (images only)",
+                    "type": "string",
+                    "example": "LEHV6nWB2yk8pyo0adR*.7kCMdnj"
+                },
                 "chain_name": {
                     "type": "string",
                     "example": "mvc"
@@ -618,9 +1669,30 @@ const docTemplateindexer = `{
                     "type": "string",
                     "example": "image/jpeg"
                 },
-                "created_at": {
+                "content_type_mismatch": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "creator_address": {
                     "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "creator_meta_id": {
+                    "type": "string",
+                    "example": "abc123def456..."
+                },
+                "declared_content_type": {
+                    "description": "Declared (content_type) vs detected content type",
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "detected_content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "dominant_color": {
+                    "type": "string",
+                    "example": "#8a6f4e"
                 },
                 "file_extension": {
                     "type": "string",
@@ -634,6 +1706,10 @@ const docTemplateindexer = `{
                     "type": "string",
                     "example": "d41d8cd98f00b204e9800998ecf8427e"
                 },
+                "file_name": {
+                    "type": "string",
+                    "example": "test.jpg"
+                },
                 "file_size": {
                     "type": "integer",
                     "example": 102400
@@ -642,14 +1718,39 @@ const docTemplateindexer = `{
                     "type": "string",
                     "example": "image"
                 },
-                "meta_id": {
+                "media": {
+                    "description": "Media metadata (image/video/audio only, returned by single file query)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerMediaMetadataResponse"
+                        }
+                    ]
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "create"
+                },
+                "owner_address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "owner_meta_id": {
                     "type": "string",
                     "example": "abc123def456..."
                 },
+                "path": {
+                    "type": "string",
+                    "example": "/file/test.jpg"
+                },
                 "pin_id": {
-                    "description": "ID            int64     ` + "`" + `json:\"id\" example:\"1\"` + "`" + `",
+                    "description": "ID             int64     ` + "`" + `json:\"id\" example:\"1\"` + "`" + `",
                     "type": "string",
-                    "example": "xyz789i0"
+                    "example": "abc123def456i0"
+                },
+                "storage_path": {
+                    "description": "StorageType    string    ` + "`" + `json:\"storage_type\" example:\"oss\"` + "`" + `",
+                    "type": "string",
+                    "example": "indexer/mvc/pinid123i0.jpg"
                 },
                 "timestamp": {
                     "type": "integer",
@@ -657,40 +1758,127 @@ const docTemplateindexer = `{
                 },
                 "tx_id": {
                     "type": "string",
-                    "example": "xyz789"
+                    "example": "abc123def456789"
+                }
+            }
+        },
+        "meta-media-service_controller_respond.IndexerMediaMetadataResponse": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "type": "string",
+                    "example": ""
                 },
-                "updated_at": {
+                "artist": {
                     "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
+                    "example": ""
+                },
+                "audio_codec": {
+                    "type": "string",
+                    "example": ""
+                },
+                "bitrate": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "camera_make": {
+                    "type": "string",
+                    "example": "Apple"
+                },
+                "camera_model": {
+                    "type": "string",
+                    "example": "iPhone 13"
+                },
+                "capture_time": {
+                    "type": "string",
+                    "example": "2024:01:02 15:04:05"
+                },
+                "channels": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "format": {
+                    "type": "string",
+                    "example": "jpeg"
+                },
+                "frame_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "has_gps": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 3024
+                },
+                "media_type": {
+                    "type": "string",
+                    "example": "image"
+                },
+                "orientation": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "sample_rate": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "title": {
+                    "type": "string",
+                    "example": ""
+                },
+                "video_codec": {
+                    "type": "string",
+                    "example": ""
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 4032
                 }
             }
         },
-        "meta-media-service_controller_respond.IndexerFileListResponse": {
+        "meta-media-service_controller_respond.IndexerSimilarFileListResponse": {
             "type": "object",
             "properties": {
                 "files": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/meta-media-service_controller_respond.IndexerFileResponse"
+                        "$ref": "#/definitions/meta-media-service_controller_respond.IndexerSimilarFileResponse"
                     }
                 },
-                "has_more": {
-                    "type": "boolean",
-                    "example": true
-                },
-                "next_cursor": {
+                "max_distance": {
                     "type": "integer",
-                    "example": 100
+                    "example": 8
+                },
+                "pin_id": {
+                    "type": "string",
+                    "example": "abc123def456i0"
+                },
+                "truncated": {
+                    "description": "Only part of the candidate images were checked",
+                    "type": "boolean",
+                    "example": false
                 }
             }
         },
-        "meta-media-service_controller_respond.IndexerFileResponse": {
+        "meta-media-service_controller_respond.IndexerSimilarFileResponse": {
             "type": "object",
             "properties": {
                 "block_height": {
                     "type": "integer",
                     "example": 12345
                 },
+                "blur_hash": {
+                    "description": "Image placeholder (images only)",
+                    "type": "string",
+                    "example": "LEHV6nWB2yk8pyo0adR*.7kCMdnj"
+                },
                 "chain_name": {
                     "type": "string",
                     "example": "mvc"
@@ -699,6 +1887,10 @@ const docTemplateindexer = `{
                     "type": "string",
                     "example": "image/jpeg"
                 },
+                "content_type_mismatch": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "creator_address": {
                     "type": "string",
                     "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
@@ -707,6 +1899,24 @@ const docTemplateindexer = `{
                     "type": "string",
                     "example": "abc123def456..."
                 },
+                "declared_content_type": {
+                    "description": "Declared (content_type) vs detected content type",
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "detected_content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "distance": {
+                    "description": "Hamming distance of perceptual hashes (0 = visually identical)",
+                    "type": "integer",
+                    "example": 3
+                },
+                "dominant_color": {
+                    "type": "string",
+                    "example": "#8a6f4e"
+                },
                 "file_extension": {
                     "type": "string",
                     "example": ".jpg"
@@ -731,6 +1941,14 @@ const docTemplateindexer = `{
                     "type": "string",
                     "example": "image"
                 },
+                "media": {
+                    "description": "Media metadata (image/video/audio only, returned by single file query)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.IndexerMediaMetadataResponse"
+                        }
+                    ]
+                },
                 "operation": {
                     "type": "string",
                     "example": "create"
@@ -770,12 +1988,48 @@ const docTemplateindexer = `{
         "meta-media-service_controller_respond.IndexerStatsResponse": {
             "type": "object",
             "properties": {
+                "storage_cache": {
+                    "$ref": "#/definitions/meta-media-service_controller_respond.IndexerStorageCacheStatsResponse"
+                },
                 "total_files": {
                     "type": "integer",
                     "example": 12345
                 }
             }
         },
+        "meta-media-service_controller_respond.IndexerStorageCacheStatsResponse": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer",
+                    "example": 524288000
+                },
+                "entries": {
+                    "type": "integer",
+                    "example": 3500
+                },
+                "evictions": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "hit_rate": {
+                    "type": "number",
+                    "example": 0.9
+                },
+                "hits": {
+                    "type": "integer",
+                    "example": 9000
+                },
+                "max_bytes": {
+                    "type": "integer",
+                    "example": 1073741824
+                },
+                "misses": {
+                    "type": "integer",
+                    "example": 1000
+                }
+            }
+        },
         "meta-media-service_controller_respond.IndexerSyncStatusResponse": {
             "type": "object",
             "properties": {
@@ -821,6 +2075,14 @@ const docTemplateindexer = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Admin API token, sent as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -24,19 +24,50 @@ const docTemplateuploader = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/config": {
+        "/admin/sponsorships": {
             "get": {
-                "description": "Get upload service configuration information, including max file size and swagger base URL",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Totals (uploads, bytes of successful uploads, transactions, satoshis) of sponsored uploads matching the filters, and a page of records, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Configuration"
+                    "Uploader Admin"
+                ],
+                "summary": "Sponsorship report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only uploads of this MetaID",
+                        "name": "metaId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day (UTC, YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (UTC, YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor (last record ID)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get configuration",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -49,19 +80,36 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller_handler.ConfigResponse"
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.SponsorReportResponse"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
             }
         },
-        "/files/commit-upload": {
+        "/assistants": {
             "post": {
-                "description": "Submit signed transaction for broadcast",
+                "description": "Authorize a server-held assistant key to sign and pay for uploads of the owner, up to spendLimit satoshis. Fund assistantAddress, then upload with /files/assistant-upload and the returned accessToken. Creating again after a revoke issues a new key.",
                 "consumes": [
                     "application/json"
                 ],
@@ -69,23 +117,23 @@ const docTemplateuploader = `{
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Assistant"
                 ],
-                "summary": "Commit upload",
+                "summary": "Create assistant",
                 "parameters": [
                     {
-                        "description": "Commit upload request",
+                        "description": "Owner authorization (action: create)",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller_handler.CommitUploadRequest"
+                            "$ref": "#/definitions/controller_handler.AssistantAuthRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Upload successful, return transaction ID and Pin ID",
+                        "description": "accessToken is only returned here and on rotate",
                         "schema": {
                             "allOf": [
                                 {
@@ -95,7 +143,7 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller_handler.CommitUploadResponseData"
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.AssistantResponse"
                                         }
                                     }
                                 }
@@ -103,13 +151,13 @@ const docTemplateuploader = `{
                         }
                     },
                     "400": {
-                        "description": "Parameter error or file not found",
+                        "description": "Parameter error, invalid spend limit or assistant exists",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
                     },
-                    "500": {
-                        "description": "Server error or broadcast failed",
+                    "401": {
+                        "description": "Invalid signature or assistants disabled",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
@@ -117,94 +165,87 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/direct-upload": {
-            "post": {
-                "description": "Upload file and add MetaID OP_RETURN output to existing PreTxHex, then broadcast immediately. This is a one-step upload process that combines building and broadcasting. Supports UTXO merge transaction for SIGHASH_SINGLE compatibility.",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/assistants/{metaId}": {
+            "get": {
+                "description": "Get the assistant address, spend limit and spent amount of an owner MetaID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Assistant"
                 ],
-                "summary": "Direct upload file (one-step)",
+                "summary": "Get assistant",
                 "parameters": [
-                    {
-                        "type": "file",
-                        "description": "File to upload",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "File path",
-                        "name": "path",
-                        "in": "formData",
-                        "required": true
-                    },
                     {
                         "type": "string",
-                        "description": "Pre-transaction hex (signed, with inputs and outputs)",
-                        "name": "preTxHex",
-                        "in": "formData",
+                        "description": "Owner MetaID",
+                        "name": "metaId",
+                        "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.AssistantResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
+                    "404": {
+                        "description": "Assistant not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/assistants/{metaId}/limit": {
+            "post": {
+                "description": "Change how many satoshis the assistant may spend in total (fees and outputs). A limit below spentAmount stops further uploads.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Assistant"
+                ],
+                "summary": "Set assistant spend limit",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Merge transaction hex (optional, broadcasted before main transaction)",
-                        "name": "mergeTxHex",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "default": "create",
-                        "description": "Operation type",
-                        "name": "operation",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Content type",
-                        "name": "contentType",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "MetaID",
+                        "description": "Owner MetaID",
                         "name": "metaId",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Address (also used as change address if changeAddress is not provided)",
-                        "name": "address",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Change address (optional, defaults to address)",
-                        "name": "changeAddress",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Fee rate (satoshis per byte, optional)",
-                        "name": "feeRate",
-                        "in": "formData"
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Total input amount in satoshis (optional, for automatic change calculation)",
-                        "name": "totalInputAmount",
-                        "in": "formData"
+                        "description": "Owner authorization (action: limit)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.AssistantAuthRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Upload successful, return transaction ID and Pin ID",
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -214,7 +255,7 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller_handler.CommitUploadResponseData"
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.AssistantResponse"
                                         }
                                     }
                                 }
@@ -222,13 +263,19 @@ const docTemplateuploader = `{
                         }
                     },
                     "400": {
-                        "description": "Parameter error",
+                        "description": "Invalid spend limit",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
                     },
-                    "500": {
-                        "description": "Server error",
+                    "401": {
+                        "description": "Invalid signature",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Assistant not found",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
@@ -236,88 +283,40 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/pre-upload": {
+        "/assistants/{metaId}/revoke": {
             "post": {
-                "description": "Upload file and generate unsigned transaction, return transaction for client signing",
+                "description": "Stop the assistant from signing and invalidate its access token. The remaining balance is returned to the owner address (sweepTxId).",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Assistant"
                 ],
-                "summary": "Pre-upload file",
+                "summary": "Revoke assistant",
                 "parameters": [
-                    {
-                        "type": "file",
-                        "description": "File to upload",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "File path",
-                        "name": "path",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "default": "create",
-                        "description": "Operation type",
-                        "name": "operation",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Content type",
-                        "name": "contentType",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Change address",
-                        "name": "changeAddress",
-                        "in": "formData"
-                    },
                     {
                         "type": "string",
-                        "description": "MetaID",
+                        "description": "Owner MetaID",
                         "name": "metaId",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Address",
-                        "name": "address",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Fee rate",
-                        "name": "feeRate",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Output list json",
-                        "name": "outputs",
-                        "in": "formData"
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Other output list json",
-                        "name": "otherOutputs",
-                        "in": "formData"
+                        "description": "Owner authorization (action: revoke)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.AssistantAuthRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Pre-upload successful, return transaction and file info",
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -327,149 +326,2484 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller_handler.PreUploadResponseData"
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.AssistantResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Parameter error",
+                    "401": {
+                        "description": "Invalid signature",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
                     },
-                    "500": {
-                        "description": "Server error",
+                    "404": {
+                        "description": "Assistant not found",
                         "schema": {
                             "$ref": "#/definitions/meta-media-service_controller_respond.Response"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controller_handler.CommitUploadRequest": {
-            "type": "object",
-            "required": [
-                "fileId",
-                "signedRawTx"
-            ],
-            "properties": {
-                "fileId": {
-                    "type": "string",
-                    "example": "metaid_abc123"
-                },
-                "signedRawTx": {
-                    "type": "string",
-                    "example": "0100000..."
+        },
+        "/assistants/{metaId}/rotate": {
+            "post": {
+                "description": "Replace the assistant key and access token. The balance of the old key is moved to the new assistantAddress (sweepTxId).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Assistant"
+                ],
+                "summary": "Rotate assistant key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Owner MetaID",
+                        "name": "metaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Owner authorization (action: rotate)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.AssistantAuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.AssistantResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Assistant not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/config": {
+            "get": {
+                "description": "Get upload service configuration information, including max file size, chunk threshold and swagger base URL",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Configuration"
+                ],
+                "summary": "Get configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller_handler.ConfigResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/files": {
+            "get": {
+                "description": "List upload records, newest first, optionally filtered by owner address, MetaID and status. Pass nextCursor of a page as cursor to get the next one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "List files",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Owner address",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MetaID",
+                        "name": "metaId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status (pending/success/failed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor (nextCursor of the previous page)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.FileListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/assistant-upload": {
+            "post": {
+                "description": "Upload a file in one request: the assistant of metaId funds, signs and broadcasts all transactions (chunks and index for large files). Requires \"Authorization: Bearer \u003caccessToken\u003e\". The cost (fees and outputs) counts against the spend limit.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Assistant"
+                ],
+                "summary": "Assistant upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccessToken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Owner MetaID",
+                        "name": "metaId",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to upload (required unless uploadId is given)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of a completed resumable upload, used instead of file",
+                        "name": "uploadId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File path",
+                        "name": "path",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "create",
+                        "description": "Operation type",
+                        "name": "operation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content type",
+                        "name": "contentType",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Fee rate (satoshis per byte, defaults to config, MVC rates below the configured one are raised to it)",
+                        "name": "feeRate",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output list json, paid by the assistant",
+                        "name": "outputs",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload successful, return transaction ID and Pin ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller_handler.CommitUploadResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error, insufficient funds or spend limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid access token",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error or broadcast failed",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/batch-commit-upload": {
+            "post": {
+                "description": "Submit the signed transactions of a batch pre-upload. Each is checked and broadcast like commit-upload, in request order. For a BTC batch pass the signed commit PSBT once as signedCommit and leave signedRawTx empty, the commit is broadcast with the first file. A file that fails does not stop the others: its entry in files has status failed and the error in message. pinIds maps the fileId of every committed file to its pinId.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Batch commit upload",
+                "parameters": [
+                    {
+                        "description": "Batch commit upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.BatchCommitUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Batch committed, return the result of every file",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.BatchCommitResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/batch-pre-upload": {
+            "post": {
+                "description": "Pre-upload every files part like pre-upload with the shared parameters, in upload order. The MetaID indexer reads only the first MetaID output of an MVC transaction and numbers every PIN of a BTC reveal i0, so each file keeps its own PIN transaction. An MVC batch holds one file, since every MVC file would still need its own transaction and signature; several MVC files are rejected. On BTC one commit transaction (commitPsbt) funds a signed reveal per file; sign the commit once. Submit with /files/batch-commit-upload. Files above the chunk threshold and identical files are rejected. If one file fails, the records and UTXO reservations of the batch are rolled back.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Batch pre-upload files",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Files to upload (repeat the field for each file)",
+                        "name": "files",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File path, shared by all files",
+                        "name": "path",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "create",
+                        "description": "Operation type",
+                        "name": "operation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Change address",
+                        "name": "changeAddress",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MetaID",
+                        "name": "metaId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address",
+                        "name": "address",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Fee rate (satoshis per byte, defaults to config, MVC rates below the configured one are raised to it)",
+                        "name": "feeRate",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payer address, its UTXOs are selected as inputs (change goes to changeAddress, defaults to payerAddress)",
+                        "name": "payerAddress",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "mvc",
+                        "description": "Chain to inscribe on: mvc, btc",
+                        "name": "chain",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "BTC commit input list json: [{txId, vout, amount, scriptPubKey}] (btc only)",
+                        "name": "inputs",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pre-upload successful, return the transactions of every file",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.BatchPreUploadResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/commit-chunk": {
+            "post": {
+                "description": "Submit the signed transaction of one chunk for broadcast, checked like commit-upload. Returns the upload progress, which contains indexPreTxRaw once all chunks are broadcast.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Commit chunk",
+                "parameters": [
+                    {
+                        "description": "Commit chunk request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.CommitChunkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Chunk broadcast, return upload progress",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.UploadProgressResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error or transaction rejected",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "File or chunk not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error or broadcast failed",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/commit-upload": {
+            "post": {
+                "description": "Submit signed transaction for broadcast. The transaction must carry the MetaID output of the file (path, content type, content sha256), its inputs must pass script verification and its fee must meet the configured fee rate. pinId is derived from the owner output as the indexer does. For BTC files submit the signed commit PSBT: the commit transaction is checked against the pre-upload and broadcast, then the reveal transaction.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Commit upload",
+                "parameters": [
+                    {
+                        "description": "Commit upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.CommitUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload successful, return transaction ID and Pin ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller_handler.CommitUploadResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error or transaction rejected",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error or broadcast failed",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/direct-upload": {
+            "post": {
+                "description": "Upload file and add MetaID OP_RETURN output to existing PreTxHex, then broadcast immediately. This is a one-step upload process that combines building and broadcasting. Supports UTXO merge transaction for SIGHASH_SINGLE compatibility. Files above the chunk threshold are rejected, upload them with pre-upload. The final transaction and the merge transaction are checked like commit-upload before broadcast. MVC only, BTC uploads go through pre-upload.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Direct upload file (one-step)",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File to upload (required unless uploadId is given)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of a completed resumable upload, used instead of file",
+                        "name": "uploadId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File path",
+                        "name": "path",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pre-transaction hex (signed, with inputs and outputs)",
+                        "name": "preTxHex",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Merge transaction hex (optional, broadcasted before main transaction)",
+                        "name": "mergeTxHex",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "create",
+                        "description": "Operation type",
+                        "name": "operation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content type",
+                        "name": "contentType",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MetaID",
+                        "name": "metaId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address (also used as change address if changeAddress is not provided)",
+                        "name": "address",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Change address (optional, defaults to address)",
+                        "name": "changeAddress",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Fee rate (satoshis per byte, optional, rates below the configured one are raised to it)",
+                        "name": "feeRate",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Total input amount in satoshis (optional, for automatic change calculation)",
+                        "name": "totalInputAmount",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "mvc",
+                        "description": "Chain, only mvc is supported",
+                        "name": "chain",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload successful, return transaction ID and Pin ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller_handler.CommitUploadResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error or transaction rejected",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/pre-upload": {
+            "post": {
+                "description": "Upload file and generate unsigned transaction, return transaction for client signing. Files above the chunk threshold are split into chunk PINs: sign and commit each entry of chunks with /files/commit-chunk, then sign indexPreTxRaw from /files/{fileId}/progress and commit it with /files/commit-upload. With payerAddress the uploader selects unreserved UTXOs of the payer at the fee rate and returns fully specified transactions: sign every entry of inputs (sigHash, SIGHASH_ALL|FORKID) and put the unlocking scripts in the transaction. With chain btc the file is inscribed with a taproot commit transaction funded from inputs (P2WPKH or P2TR) and a reveal transaction that sends the PIN to address: sign commitPsbt and submit it with /files/commit-upload, which broadcasts the commit and then the reveal. txId and pinId are those of the reveal.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Pre-upload file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File to upload (required unless uploadId is given)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of a completed resumable upload, used instead of file",
+                        "name": "uploadId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File path",
+                        "name": "path",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "create",
+                        "description": "Operation type",
+                        "name": "operation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content type",
+                        "name": "contentType",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Change address",
+                        "name": "changeAddress",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MetaID",
+                        "name": "metaId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address",
+                        "name": "address",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Fee rate (satoshis per byte, defaults to config, MVC rates below the configured one are raised to it)",
+                        "name": "feeRate",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output list json",
+                        "name": "outputs",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Other output list json",
+                        "name": "otherOutputs",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payer address, its UTXOs are selected as inputs (change goes to changeAddress, defaults to payerAddress)",
+                        "name": "payerAddress",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "mvc",
+                        "description": "Chain to inscribe on: mvc, btc",
+                        "name": "chain",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "BTC commit input list json: [{txId, vout, amount, scriptPubKey}] (btc only)",
+                        "name": "inputs",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pre-upload successful, return transaction and file info",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller_handler.PreUploadResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/sponsored-upload": {
+            "post": {
+                "description": "Upload a file in one request with chain fees paid by the sponsor wallet. The user signs the wallet message \"meta-media sponsored upload\\nmetaId: {sha256(address)}\\noperation: {operation}\\npath: {path}\\ncontentType: {contentType}\\nsha256: {file sha256}\\ntimestamp: {timestamp}\" and receives an owner output, which makes the PINs theirs. Eligibility (allowlist, content types) and the daily byte and fee budgets per MetaID are set in the sponsor config.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sponsorship"
+                ],
+                "summary": "Sponsored upload",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File to upload (required unless uploadId is given)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of a completed resumable upload, used instead of file",
+                        "name": "uploadId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File path",
+                        "name": "path",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User address, owner of the PINs",
+                        "name": "address",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "create",
+                        "description": "Operation type",
+                        "name": "operation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content type (defaults to the file type, then application/octet-stream)",
+                        "name": "contentType",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Unix seconds of the signature",
+                        "name": "timestamp",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base64 wallet message signature",
+                        "name": "signature",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload successful, return transaction ID, Pin ID and sponsored cost",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.SponsoredUploadResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error, not eligible or daily budget exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature or sponsorship disabled",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error or broadcast failed",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/tx/{txId}": {
+            "get": {
+                "description": "Get the upload record of the file a transaction belongs to. Besides the file (or index, reveal) transaction, chunk, merge and BTC commit transactions are found.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Get file by transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "txId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.FileInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/uploads": {
+            "post": {
+                "description": "tus creation: reserve an upload of Upload-Length bytes. The Location header is the upload URL. Send the file name and type in Upload-Metadata as \"filename \u003cbase64\u003e,filetype \u003cbase64\u003e\".",
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Create resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "1.0.0",
+                        "description": "tus version",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Total size (bytes)",
+                        "name": "Upload-Length",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated key/base64 value pairs (filename, filetype)",
+                        "name": "Upload-Metadata",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Upload URL"
+                            },
+                            "Upload-Expires": {
+                                "type": "string",
+                                "description": "Expiry of the upload"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Upload-Length or Upload-Metadata",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "Unsupported tus version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Upload exceeds max file size",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "options": {
+                "description": "tus protocol discovery: supported version, extensions and max upload size",
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Resumable upload capabilities",
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "headers": {
+                            "Tus-Extension": {
+                                "type": "string",
+                                "description": "Supported extensions"
+                            },
+                            "Tus-Max-Size": {
+                                "type": "int",
+                                "description": "Max upload size (bytes)"
+                            },
+                            "Tus-Version": {
+                                "type": "string",
+                                "description": "Supported tus versions"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/files/uploads/{uploadId}": {
+            "delete": {
+                "description": "tus termination: delete the upload and its staged bytes",
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Delete resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "1.0.0",
+                        "description": "tus version",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Upload not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "Upload expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "description": "tus core: get how many bytes of the upload have been received",
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Get resumable upload offset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "1.0.0",
+                        "description": "tus version",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "headers": {
+                            "Upload-Expires": {
+                                "type": "string",
+                                "description": "Expiry of the upload"
+                            },
+                            "Upload-Length": {
+                                "type": "int",
+                                "description": "Total size (bytes)"
+                            },
+                            "Upload-Offset": {
+                                "type": "int",
+                                "description": "Bytes received"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "Upload expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "tus core: append the request body at Upload-Offset. After an interruption, get the offset with HEAD and continue from there.",
+                "consumes": [
+                    "application/offset+octet-stream"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Append to resumable upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "1.0.0",
+                        "description": "tus version",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset the body starts at",
+                        "name": "Upload-Offset",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "headers": {
+                            "Upload-Expires": {
+                                "type": "string",
+                                "description": "Expiry of the upload"
+                            },
+                            "Upload-Offset": {
+                                "type": "int",
+                                "description": "Bytes received"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Upload-Offset",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Upload-Offset does not match the received bytes",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "Upload expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Body exceeds Upload-Length",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type is not application/offset+octet-stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{fileId}": {
+            "get": {
+                "description": "Get the upload record of a file by file ID: status, transaction and Pin ID once committed, broadcast state and block height.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Get file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "fileId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.FileInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{fileId}/history": {
+            "get": {
+                "description": "Get the transactions of an upload and every state they went through: built, signed, broadcast, in_mempool, confirmed (with block height), failed or replaced. Broadcast transactions are followed by a background tracker, which rebroadcasts those the node drops.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Get transaction history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "fileId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.TxHistoryResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{fileId}/progress": {
+            "get": {
+                "description": "Get how many transactions of an upload are broadcast. For chunked uploads, indexPreTxRaw is returned once all chunks are broadcast; sign it and submit it with /files/commit-upload.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Get upload progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "fileId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-media-service_service_upload_service.UploadProgressResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-media-service_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller_handler.AssistantAuthRequest": {
+            "type": "object",
+            "required": [
+                "address",
+                "signature",
+                "timestamp"
+            ],
+            "properties": {
+                "address": {
+                    "description": "Owner address",
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "signature": {
+                    "description": "Base64 message signature",
+                    "type": "string",
+                    "example": "H6Xh2uM4eQ0K7Vtq1Yc3m1QmR8nOa9r0s5w9gRrJZ0="
+                },
+                "spendLimit": {
+                    "description": "Spend limit (satoshis), 0 for rotate and revoke",
+                    "type": "integer",
+                    "example": 1000000
+                },
+                "timestamp": {
+                    "description": "Unix seconds",
+                    "type": "integer",
+                    "example": 1735689600
+                }
+            }
+        },
+        "controller_handler.BatchCommitUploadRequest": {
+            "type": "object",
+            "required": [
+                "files"
+            ],
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.BatchCommitItem"
+                    }
+                },
+                "signedCommit": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller_handler.CommitChunkRequest": {
+            "type": "object",
+            "required": [
+                "fileId",
+                "signedRawTx"
+            ],
+            "properties": {
+                "chunkIndex": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "fileId": {
+                    "type": "string",
+                    "example": "metaid_abc123"
+                },
+                "signedRawTx": {
+                    "type": "string",
+                    "example": "0100000..."
+                }
+            }
+        },
+        "controller_handler.CommitUploadRequest": {
+            "type": "object",
+            "required": [
+                "fileId",
+                "signedRawTx"
+            ],
+            "properties": {
+                "fileId": {
+                    "type": "string",
+                    "example": "metaid_abc123"
+                },
+                "signedRawTx": {
+                    "type": "string",
+                    "example": "0100000..."
+                }
+            }
+        },
+        "controller_handler.CommitUploadResponseData": {
+            "type": "object",
+            "properties": {
+                "fileId": {
+                    "type": "string",
+                    "example": "metaid_abc123"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "pinId": {
+                    "type": "string",
+                    "example": "abc123...i0"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "txId": {
+                    "type": "string",
+                    "example": "abc123..."
+                },
+                "txState": {
+                    "type": "string",
+                    "example": "broadcast"
+                }
+            }
+        },
+        "controller_handler.ConfigResponse": {
+            "type": "object",
+            "properties": {
+                "chunkSize": {
+                    "type": "integer",
+                    "example": 1048576
+                },
+                "chunkThreshold": {
+                    "type": "integer",
+                    "example": 5242880
+                },
+                "maxBatchFiles": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "maxFileSize": {
+                    "type": "integer",
+                    "example": 10485760
+                },
+                "swaggerBaseUrl": {
+                    "type": "string",
+                    "example": "localhost:7282"
+                }
+            }
+        },
+        "controller_handler.PreUploadResponseData": {
+            "type": "object",
+            "properties": {
+                "calTxFee": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "calTxSize": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "chain": {
+                    "type": "string",
+                    "example": "btc"
+                },
+                "chunkType": {
+                    "type": "string",
+                    "example": "single"
+                },
+                "chunks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.PreUploadChunk"
+                    }
+                },
+                "commitPsbt": {
+                    "type": "string",
+                    "example": "cHNidP8B..."
+                },
+                "commitTxId": {
+                    "type": "string",
+                    "example": "def456..."
+                },
+                "fileId": {
+                    "type": "string",
+                    "example": "metaid_abc123"
+                },
+                "fileMd5": {
+                    "type": "string",
+                    "example": "5d41402abc4b2a76b9719d911017c592"
+                },
+                "filehash": {
+                    "type": "string",
+                    "example": "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+                },
+                "inputs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.FundedInput"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "pinId": {
+                    "type": "string",
+                    "example": "abc123...i0"
+                },
+                "preTxRaw": {
+                    "type": "string",
+                    "example": "0100000..."
+                },
+                "revealTxRaw": {
+                    "type": "string",
+                    "example": "0200000..."
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "txId": {
+                    "type": "string",
+                    "example": "abc123..."
+                },
+                "txState": {
+                    "type": "string",
+                    "example": "built"
+                }
+            }
+        },
+        "meta-media-service_controller_respond.Response": {
+            "description": "Unified API response structure",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "data": {},
+                "message": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "processingTime": {
+                    "type": "integer",
+                    "example": 123
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.AssistantResponse": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "description": "Bearer token of assistant uploads, only returned on create and rotate",
+                    "type": "string"
+                },
+                "address": {
+                    "description": "Owner address",
+                    "type": "string"
+                },
+                "assistantAddress": {
+                    "description": "Address to fund, uploads are paid from it",
+                    "type": "string"
+                },
+                "assistantMetaId": {
+                    "description": "MetaID of the assistant address",
+                    "type": "string"
+                },
+                "metaId": {
+                    "description": "Owner MetaID",
+                    "type": "string"
+                },
+                "spendLimit": {
+                    "description": "Satoshis the assistant may spend",
+                    "type": "integer"
+                },
+                "spentAmount": {
+                    "description": "Satoshis spent so far",
+                    "type": "integer"
+                },
+                "state": {
+                    "description": "active/revoked",
+                    "type": "string"
+                },
+                "sweepTxId": {
+                    "description": "Transaction moving the balance of the replaced key",
+                    "type": "string"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.BatchCommitItem": {
+            "type": "object",
+            "properties": {
+                "fileId": {
+                    "description": "File ID (from batch pre-upload response)",
+                    "type": "string"
+                },
+                "signedRawTx": {
+                    "description": "Signed raw transaction (hex), may be empty in a BTC batch with signedCommit",
+                    "type": "string"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.BatchCommitResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "Number of files that failed to commit",
+                    "type": "integer"
+                },
+                "files": {
+                    "description": "Commit result of every file, in request order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.UploadResponse"
+                    }
+                },
+                "pinIds": {
+                    "description": "FileId -\u003e PinId of the committed files",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "success": {
+                    "description": "Number of committed files",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.BatchPreUploadResponse": {
+            "type": "object",
+            "properties": {
+                "calTxFee": {
+                    "description": "Calculated fee of all transactions",
+                    "type": "integer"
+                },
+                "calTxSize": {
+                    "description": "Calculated size of all transactions",
+                    "type": "integer"
+                },
+                "chain": {
+                    "description": "mvc/btc",
+                    "type": "string"
+                },
+                "commitPsbt": {
+                    "description": "BTC commit PSBT of the whole batch for the payer to sign (base64)",
+                    "type": "string"
+                },
+                "commitTxId": {
+                    "description": "BTC commit transaction ID",
+                    "type": "string"
+                },
+                "files": {
+                    "description": "Pre-upload of every file, in request order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.PreUploadResponse"
+                    }
                 }
             }
         },
-        "controller_handler.CommitUploadResponseData": {
+        "meta-media-service_service_upload_service.ChunkProgress": {
+            "type": "object",
+            "properties": {
+                "chunkHash": {
+                    "description": "Chunk SHA256",
+                    "type": "string"
+                },
+                "chunkIndex": {
+                    "description": "Chunk index (0-based)",
+                    "type": "integer"
+                },
+                "chunkSize": {
+                    "description": "Chunk size (bytes)",
+                    "type": "integer"
+                },
+                "pinId": {
+                    "description": "Chunk Pin ID (once committed)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending/success",
+                    "type": "string"
+                },
+                "txId": {
+                    "description": "Chunk transaction ID (once committed)",
+                    "type": "string"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.FileInfo": {
             "type": "object",
             "properties": {
+                "address": {
+                    "description": "Owner address",
+                    "type": "string"
+                },
+                "blockHeight": {
+                    "description": "Block height, once confirmed",
+                    "type": "integer"
+                },
+                "chain": {
+                    "description": "mvc/btc",
+                    "type": "string"
+                },
+                "chunkType": {
+                    "description": "single/multi",
+                    "type": "string"
+                },
+                "commitTxId": {
+                    "description": "BTC commit transaction ID",
+                    "type": "string"
+                },
+                "contentType": {
+                    "description": "Content type",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "Creation time (Unix seconds)",
+                    "type": "integer"
+                },
+                "fileHash": {
+                    "description": "File SHA256",
+                    "type": "string"
+                },
                 "fileId": {
-                    "type": "string",
-                    "example": "metaid_abc123"
+                    "description": "File ID (metaid_filehash)",
+                    "type": "string"
                 },
-                "message": {
-                    "type": "string",
-                    "example": "success"
+                "fileMd5": {
+                    "description": "File MD5",
+                    "type": "string"
+                },
+                "fileName": {
+                    "description": "File name",
+                    "type": "string"
+                },
+                "fileSize": {
+                    "description": "File size (bytes)",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "Record ID, the cursor of file lists",
+                    "type": "integer"
+                },
+                "metaId": {
+                    "description": "MetaID",
+                    "type": "string"
+                },
+                "operation": {
+                    "description": "create/modify/revoke",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "MetaID path",
+                    "type": "string"
                 },
                 "pinId": {
-                    "type": "string",
-                    "example": "abc123...i0"
+                    "description": "Pin ID, once committed",
+                    "type": "string"
                 },
                 "status": {
-                    "type": "string",
-                    "example": "success"
+                    "description": "pending/success/failed",
+                    "type": "string"
                 },
                 "txId": {
-                    "type": "string",
-                    "example": "abc123..."
+                    "description": "File (or index, reveal) transaction ID, once committed",
+                    "type": "string"
+                },
+                "txState": {
+                    "description": "Broadcast state of the file transaction",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "Update time (Unix seconds)",
+                    "type": "integer"
                 }
             }
         },
-        "controller_handler.ConfigResponse": {
+        "meta-media-service_service_upload_service.FileListResponse": {
             "type": "object",
             "properties": {
-                "maxFileSize": {
-                    "type": "integer",
-                    "example": 10485760
+                "files": {
+                    "description": "Files, newest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.FileInfo"
+                    }
                 },
-                "swaggerBaseUrl": {
-                    "type": "string",
-                    "example": "localhost:7282"
+                "hasMore": {
+                    "description": "Whether more files may follow",
+                    "type": "boolean"
+                },
+                "nextCursor": {
+                    "description": "Cursor of the next page",
+                    "type": "integer"
                 }
             }
         },
-        "controller_handler.PreUploadResponseData": {
+        "meta-media-service_service_upload_service.FundedInput": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Payer address",
+                    "type": "string"
+                },
+                "amount": {
+                    "description": "Output amount (satoshis)",
+                    "type": "integer"
+                },
+                "index": {
+                    "description": "Input index in the transaction",
+                    "type": "integer"
+                },
+                "scriptPubKey": {
+                    "description": "Output locking script (hex)",
+                    "type": "string"
+                },
+                "sigHash": {
+                    "description": "Double SHA256 of the preimage, the digest to sign (hex)",
+                    "type": "string"
+                },
+                "sigHashPreimage": {
+                    "description": "Sighash preimage (hex)",
+                    "type": "string"
+                },
+                "sigHashType": {
+                    "description": "Sighash type to sign with (0x41, SIGHASH_ALL|FORKID)",
+                    "type": "integer"
+                },
+                "txId": {
+                    "description": "Transaction ID of the spent output",
+                    "type": "string"
+                },
+                "vout": {
+                    "description": "Output index of the spent output",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.PreUploadChunk": {
             "type": "object",
             "properties": {
                 "calTxFee": {
-                    "type": "integer",
-                    "example": 1000
+                    "description": "Calculated transaction fee",
+                    "type": "integer"
                 },
                 "calTxSize": {
-                    "type": "integer",
-                    "example": 500
+                    "description": "Calculated transaction size",
+                    "type": "integer"
+                },
+                "chunkHash": {
+                    "description": "Chunk SHA256",
+                    "type": "string"
+                },
+                "chunkIndex": {
+                    "description": "Chunk index (0-based)",
+                    "type": "integer"
+                },
+                "chunkSize": {
+                    "description": "Chunk size (bytes)",
+                    "type": "integer"
+                },
+                "inputs": {
+                    "description": "Payer inputs of PreTxRaw to sign (with payer address)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.FundedInput"
+                    }
+                },
+                "preTxRaw": {
+                    "description": "Pre-transaction raw data (empty once committed)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending/success",
+                    "type": "string"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.PreUploadResponse": {
+            "type": "object",
+            "properties": {
+                "calTxFee": {
+                    "description": "Calculated transaction fee (all transactions for chunked files)",
+                    "type": "integer"
+                },
+                "calTxSize": {
+                    "description": "Calculated transaction size (all transactions for chunked files)",
+                    "type": "integer"
+                },
+                "chain": {
+                    "description": "mvc/btc",
+                    "type": "string"
+                },
+                "chunkType": {
+                    "description": "single/multi",
+                    "type": "string"
+                },
+                "chunks": {
+                    "description": "Chunk transactions to sign and commit (multi only)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.PreUploadChunk"
+                    }
+                },
+                "commitPsbt": {
+                    "description": "BTC commit PSBT for the payer to sign (base64)",
+                    "type": "string"
+                },
+                "commitTxId": {
+                    "description": "BTC commit transaction ID",
+                    "type": "string"
+                },
+                "fileHash": {
+                    "description": "File hash",
+                    "type": "string"
                 },
                 "fileId": {
-                    "type": "string",
-                    "example": "metaid_abc123"
+                    "description": "File ID (unique identifier)",
+                    "type": "string"
                 },
                 "fileMd5": {
-                    "type": "string",
-                    "example": "5d41402abc4b2a76b9719d911017c592"
+                    "description": "File md5",
+                    "type": "string"
                 },
-                "filehash": {
-                    "type": "string",
-                    "example": "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+                "inputs": {
+                    "description": "Payer inputs of PreTxRaw to sign (with payer address)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.FundedInput"
+                    }
                 },
                 "message": {
-                    "type": "string",
-                    "example": "success"
+                    "description": "Message (e.g., exists, success, etc.)",
+                    "type": "string"
                 },
                 "pinId": {
-                    "type": "string",
-                    "example": "abc123...i0"
+                    "description": "Pin ID",
+                    "type": "string"
                 },
                 "preTxRaw": {
-                    "type": "string",
-                    "example": "0100000..."
+                    "description": "Pre-transaction raw data",
+                    "type": "string"
+                },
+                "revealTxRaw": {
+                    "description": "BTC signed reveal transaction, broadcast after the commit",
+                    "type": "string"
                 },
                 "status": {
-                    "type": "string",
-                    "example": "pending"
+                    "description": "pending/success (transaction confirmed)/failed",
+                    "type": "string"
                 },
                 "txId": {
-                    "type": "string",
-                    "example": "abc123..."
+                    "description": "Transaction ID",
+                    "type": "string"
+                },
+                "txState": {
+                    "description": "State of the file transaction, broadcast once the node accepted it",
+                    "type": "string"
                 }
             }
         },
-        "meta-media-service_controller_respond.Response": {
-            "description": "Unified API response structure",
+        "meta-media-service_service_upload_service.SponsorReportResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 0
+                "bytes": {
+                    "description": "File bytes of successful uploads",
+                    "type": "integer"
+                },
+                "fee": {
+                    "description": "Satoshis paid",
+                    "type": "integer"
+                },
+                "hasMore": {
+                    "description": "Whether there are more records",
+                    "type": "boolean"
+                },
+                "nextCursor": {
+                    "description": "Cursor of the next page",
+                    "type": "integer"
+                },
+                "records": {
+                    "description": "Page of records, newest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.SponsoredUploadRecord"
+                    }
+                },
+                "txCount": {
+                    "description": "Transactions paid",
+                    "type": "integer"
+                },
+                "uploads": {
+                    "description": "Sponsored upload requests matching the filters",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.SponsoredUploadRecord": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "User address, owner of the PINs",
+                    "type": "string"
+                },
+                "contentType": {
+                    "description": "Content type",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "Creation time (Unix seconds)",
+                    "type": "integer"
+                },
+                "day": {
+                    "description": "Budget day (UTC)",
+                    "type": "string"
+                },
+                "fee": {
+                    "description": "Satoshis paid",
+                    "type": "integer"
+                },
+                "fileId": {
+                    "description": "File ID",
+                    "type": "string"
+                },
+                "fileSize": {
+                    "description": "File size (bytes)",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "Record ID",
+                    "type": "integer"
                 },
-                "data": {},
                 "message": {
-                    "type": "string",
-                    "example": "success"
+                    "description": "Failure reason",
+                    "type": "string"
                 },
-                "processingTime": {
-                    "type": "integer",
-                    "example": 123
+                "metaId": {
+                    "description": "MetaID of the user",
+                    "type": "string"
+                },
+                "pinId": {
+                    "description": "File Pin ID",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending/success/failed",
+                    "type": "string"
+                },
+                "txCount": {
+                    "description": "Transactions paid",
+                    "type": "integer"
+                },
+                "txId": {
+                    "description": "File (or index) transaction ID",
+                    "type": "string"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.SponsoredUploadResponse": {
+            "type": "object",
+            "properties": {
+                "fee": {
+                    "description": "Satoshis paid by the sponsor in this request (fees and owner outputs)",
+                    "type": "integer"
+                },
+                "fileId": {
+                    "description": "File ID",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Message",
+                    "type": "string"
+                },
+                "pinId": {
+                    "description": "Pin ID",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status",
+                    "type": "string"
+                },
+                "txCount": {
+                    "description": "Transactions paid by the sponsor in this request",
+                    "type": "integer"
+                },
+                "txId": {
+                    "description": "Transaction ID",
+                    "type": "string"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.TxEvent": {
+            "type": "object",
+            "properties": {
+                "blockHeight": {
+                    "description": "Block height (confirmed)",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "Time of the change (Unix seconds)",
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Detail, e.g. the broadcast error",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "built/signed/broadcast/in_mempool/confirmed/failed/replaced",
+                    "type": "string"
+                },
+                "txId": {
+                    "description": "Transaction ID, empty while it is not signed",
+                    "type": "string"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.TxHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "blockHeight": {
+                    "description": "Block height of the file transaction, once confirmed",
+                    "type": "integer"
+                },
+                "events": {
+                    "description": "State changes, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.TxEvent"
+                    }
+                },
+                "fileId": {
+                    "description": "File ID",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending/success/failed",
+                    "type": "string"
+                },
+                "txState": {
+                    "description": "State of the file (or index, reveal) transaction",
+                    "type": "string"
+                },
+                "txs": {
+                    "description": "Transactions in broadcast order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.TxRecord"
+                    }
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.TxRecord": {
+            "type": "object",
+            "properties": {
+                "blockHeight": {
+                    "description": "Block height, once confirmed",
+                    "type": "integer"
+                },
+                "chain": {
+                    "description": "mvc/btc",
+                    "type": "string"
+                },
+                "chunkIndex": {
+                    "description": "Chunk index, -1 unless the role is chunk",
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Last broadcast error",
+                    "type": "string"
+                },
+                "rebroadcasts": {
+                    "description": "Times the tracker broadcast it again",
+                    "type": "integer"
+                },
+                "role": {
+                    "description": "file/chunk/merge/commit",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "broadcast/in_mempool/confirmed/failed/replaced",
+                    "type": "string"
+                },
+                "txId": {
+                    "description": "Transaction ID",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "Time of the last state change (Unix seconds)",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.UploadProgressResponse": {
+            "type": "object",
+            "properties": {
+                "blockHeight": {
+                    "description": "Block height of the file transaction, once confirmed",
+                    "type": "integer"
+                },
+                "broadcastTxs": {
+                    "description": "Transactions broadcast so far",
+                    "type": "integer"
+                },
+                "chunkNumber": {
+                    "description": "Number of chunks",
+                    "type": "integer"
+                },
+                "chunkType": {
+                    "description": "single/multi",
+                    "type": "string"
+                },
+                "chunks": {
+                    "description": "Chunk states ordered by index",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.ChunkProgress"
+                    }
+                },
+                "committedChunks": {
+                    "description": "Chunks broadcast so far",
+                    "type": "integer"
+                },
+                "fileId": {
+                    "description": "File ID",
+                    "type": "string"
+                },
+                "indexInputs": {
+                    "description": "Payer inputs of IndexPreTxRaw to sign (with payer address)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-media-service_service_upload_service.FundedInput"
+                    }
+                },
+                "indexPreTxRaw": {
+                    "description": "Index pre-transaction, set once all chunks are broadcast",
+                    "type": "string"
+                },
+                "pinId": {
+                    "description": "Index Pin ID (once committed)",
+                    "type": "string"
+                },
+                "progress": {
+                    "description": "Broadcast progress (0-100)",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "File status, success once the index transaction is broadcast",
+                    "type": "string"
+                },
+                "totalTxCount": {
+                    "description": "Transactions to broadcast (chunks + index)",
+                    "type": "integer"
+                },
+                "txId": {
+                    "description": "Index transaction ID (once committed)",
+                    "type": "string"
+                },
+                "txState": {
+                    "description": "Broadcast state of the file (or index) transaction",
+                    "type": "string"
+                }
+            }
+        },
+        "meta-media-service_service_upload_service.UploadResponse": {
+            "type": "object",
+            "properties": {
+                "fileId": {
+                    "description": "File ID",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Message",
+                    "type": "string"
+                },
+                "pinId": {
+                    "description": "Pin ID",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending until the transaction is confirmed, then success",
+                    "type": "string"
+                },
+                "txId": {
+                    "description": "Transaction ID",
+                    "type": "string"
+                },
+                "txState": {
+                    "description": "State of the file transaction",
+                    "type": "string"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Admin API token, sent as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
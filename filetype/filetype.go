@@ -0,0 +1,425 @@
+// Package filetype detects content types from file signatures and container structure.
+package filetype
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OctetStream generic binary type returned for unrecognized content
+const OctetStream = "application/octet-stream"
+
+// detector returns the content type of recognized content, "" otherwise
+type detector func(content []byte) string
+
+// detectors checked in order, specific container formats before generic magic numbers
+var detectors = []detector{
+	detectMagic,
+	detectISOBMFF,
+	detectRIFF,
+	detectEBML,
+	detectOgg,
+	detectZIP,
+	detectOLE,
+	detectExecutable,
+	detectFont,
+	detectAudioFrame,
+	detectMPEGTS,
+}
+
+// Detect detect content type from content
+// Containers are inspected: ZIP (OOXML/ODF/EPUB/JAR/APK), ISO BMFF brands (MP4/MOV/M4A/HEIC/AVIF/3GP),
+// RIFF (WAV/AVI/WebP), EBML (WebM/Matroska), Ogg (Vorbis/Opus/Theora/FLAC) and OLE2 (DOC/XLS/PPT).
+// Text content falls back to net/http sniffing. Returns OctetStream if the content is not recognized.
+func Detect(content []byte) string {
+	for _, detect := range detectors {
+		if contentType := detect(content); contentType != "" {
+			return contentType
+		}
+	}
+	return detectText(content)
+}
+
+// magic simple signature at a fixed offset
+type magic struct {
+	offset      int
+	signature   []byte
+	contentType string
+}
+
+var magics = []magic{
+	// Images
+	{0, []byte{0xFF, 0xD8, 0xFF}, "image/jpeg"},
+	{0, []byte("\x89PNG\r\n\x1a\n"), "image/png"},
+	{0, []byte("GIF87a"), "image/gif"},
+	{0, []byte("GIF89a"), "image/gif"},
+	{0, []byte("II*\x00"), "image/tiff"},
+	{0, []byte("MM\x00*"), "image/tiff"},
+	{0, []byte("8BPS"), "image/vnd.adobe.photoshop"},
+	{0, []byte{0xFF, 0x0A}, "image/jxl"},
+	{0, []byte("\x00\x00\x00\x0cJXL \r\n\x87\n"), "image/jxl"},
+	{0, []byte("\x00\x00\x00\x0cjP  \r\n\x87\n"), "image/jp2"},
+
+	// Documents
+	{0, []byte("%PDF-"), "application/pdf"},
+	{0, []byte("{\\rtf"), "application/rtf"},
+	{0, []byte("SQLite format 3\x00"), "application/vnd.sqlite3"},
+
+	// Archives
+	{0, []byte("Rar!\x1a\x07"), "application/vnd.rar"},
+	{0, []byte("7z\xbc\xaf\x27\x1c"), "application/x-7z-compressed"},
+	{0, []byte{0x1F, 0x8B}, "application/gzip"},
+	{0, []byte("BZh"), "application/x-bzip2"},
+	{0, []byte("\xfd7zXZ\x00"), "application/x-xz"},
+	{0, []byte{0x28, 0xB5, 0x2F, 0xFD}, "application/zstd"},
+	{257, []byte("ustar"), "application/x-tar"},
+
+	// Audio
+	{0, []byte("fLaC"), "audio/flac"},
+	{0, []byte("ID3"), "audio/mpeg"},
+	{0, []byte("MThd"), "audio/midi"},
+	{0, []byte("#!AMR"), "audio/amr"},
+
+	// Video
+	{0, []byte("FLV\x01"), "video/x-flv"},
+	{0, []byte{0x00, 0x00, 0x01, 0xBA}, "video/mpeg"},
+	{0, []byte{0x00, 0x00, 0x01, 0xB3}, "video/mpeg"},
+	{0, []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11}, "video/x-ms-asf"},
+}
+
+// detectMagic match fixed signatures, plus BMP, ICO and AIFF which need a header check
+func detectMagic(content []byte) string {
+	for _, m := range magics {
+		if len(content) >= m.offset+len(m.signature) && bytes.Equal(content[m.offset:m.offset+len(m.signature)], m.signature) {
+			return m.contentType
+		}
+	}
+
+	switch {
+	case isBMP(content):
+		return "image/bmp"
+	case isICO(content):
+		return "image/x-icon"
+	case len(content) >= 12 && bytes.HasPrefix(content, []byte("FORM")) &&
+		(bytes.Equal(content[8:12], []byte("AIFF")) || bytes.Equal(content[8:12], []byte("AIFC"))):
+		return "audio/aiff"
+	}
+	return ""
+}
+
+// isBMP check BMP file header and DIB header size
+func isBMP(content []byte) bool {
+	if len(content) < 18 || !bytes.HasPrefix(content, []byte("BM")) {
+		return false
+	}
+	switch binary.LittleEndian.Uint32(content[14:18]) {
+	case 12, 40, 52, 56, 64, 108, 124:
+		return true
+	}
+	return false
+}
+
+// isICO check icon directory header (reserved 0, type 1, at least one image)
+func isICO(content []byte) bool {
+	if len(content) < 22 || !bytes.HasPrefix(content, []byte{0x00, 0x00, 0x01, 0x00}) {
+		return false
+	}
+	count := binary.LittleEndian.Uint16(content[4:6])
+	// First entry: reserved byte must be 0, color planes 0 or 1
+	return count > 0 && content[9] == 0 && content[10] <= 1 && content[11] == 0
+}
+
+// detectISOBMFF detect ISO base media files by ftyp brands
+func detectISOBMFF(content []byte) string {
+	if len(content) < 16 || !bytes.Equal(content[4:8], []byte("ftyp")) {
+		return ""
+	}
+	size := int(binary.BigEndian.Uint32(content[0:4]))
+	if size < 16 || size > len(content) {
+		size = min(len(content), 256)
+	}
+
+	major := string(content[8:12])
+	brands := []string{major}
+	for offset := 16; offset+4 <= size; offset += 4 {
+		brands = append(brands, string(content[offset:offset+4]))
+	}
+	has := func(names ...string) bool {
+		for _, brand := range brands {
+			for _, name := range names {
+				if brand == name {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	switch {
+	case major == "avif" || major == "avis":
+		return "image/avif"
+	case major == "heic" || major == "heix" || major == "heim" || major == "heis" || major == "hevc" || major == "hevx":
+		return "image/heic"
+	case major == "mif1" || major == "msf1":
+		if has("avif", "avis") {
+			return "image/avif"
+		}
+		if has("heic", "heix") {
+			return "image/heic"
+		}
+		return "image/heif"
+	case major == "crx ":
+		return "image/x-canon-cr3"
+	case major == "qt  ":
+		return "video/quicktime"
+	case major == "M4A " || major == "M4B " || major == "F4A " || major == "F4B ":
+		return "audio/mp4"
+	case major == "M4V " || major == "M4VH" || major == "M4VP":
+		return "video/x-m4v"
+	case strings.HasPrefix(major, "3g2"):
+		return "video/3gpp2"
+	case strings.HasPrefix(major, "3gp"):
+		return "video/3gpp"
+	}
+	return "video/mp4"
+}
+
+// detectRIFF detect RIFF containers by form type
+func detectRIFF(content []byte) string {
+	if len(content) < 12 || !bytes.HasPrefix(content, []byte("RIFF")) {
+		return ""
+	}
+	switch string(content[8:12]) {
+	case "WEBP":
+		return "image/webp"
+	case "WAVE":
+		return "audio/wav"
+	case "AVI ":
+		return "video/x-msvideo"
+	}
+	return ""
+}
+
+// detectEBML detect WebM/Matroska by EBML DocType
+func detectEBML(content []byte) string {
+	if !bytes.HasPrefix(content, []byte{0x1A, 0x45, 0xDF, 0xA3}) {
+		return ""
+	}
+	head := content[:min(len(content), 64)]
+	if bytes.Contains(head, []byte("webm")) {
+		return "video/webm"
+	}
+	return "video/x-matroska"
+}
+
+// detectOgg detect Ogg streams by the codec of the first packet
+func detectOgg(content []byte) string {
+	if !bytes.HasPrefix(content, []byte("OggS")) || len(content) < 27 {
+		return ""
+	}
+	// First page: 27 byte header, segment table, then the first packet
+	packet := 27 + int(content[26])
+	if packet >= len(content) {
+		return "application/ogg"
+	}
+	data := content[packet:]
+	switch {
+	case bytes.HasPrefix(data, []byte("\x01vorbis")):
+		return "audio/ogg"
+	case bytes.HasPrefix(data, []byte("OpusHead")):
+		return "audio/opus"
+	case bytes.HasPrefix(data, []byte("\x7fFLAC")), bytes.HasPrefix(data, []byte("Speex   ")):
+		return "audio/ogg"
+	case bytes.HasPrefix(data, []byte("\x80theora")):
+		return "video/ogg"
+	}
+	return "application/ogg"
+}
+
+// detectZIP detect ZIP based formats by their entries
+func detectZIP(content []byte) string {
+	if !bytes.HasPrefix(content, []byte("PK\x03\x04")) && !bytes.HasPrefix(content, []byte("PK\x05\x06")) {
+		return ""
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		// Truncated or spanned archive, central directory not available
+		return "application/zip"
+	}
+
+	names := make(map[string]bool, len(reader.File))
+	prefixes := make(map[string]bool)
+	for _, file := range reader.File {
+		names[file.Name] = true
+		if dir, _, ok := strings.Cut(file.Name, "/"); ok {
+			prefixes[dir] = true
+		}
+	}
+
+	// ODF and EPUB declare their type in an uncompressed "mimetype" entry
+	if len(reader.File) > 0 && reader.File[0].Name == "mimetype" {
+		if declared := readZipEntry(reader.File[0], 128); declared != "" {
+			declared = strings.TrimSpace(declared)
+			if strings.HasPrefix(declared, "application/") {
+				return declared
+			}
+		}
+	}
+
+	switch {
+	case names["[Content_Types].xml"] && prefixes["word"]:
+		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	case names["[Content_Types].xml"] && prefixes["xl"]:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case names["[Content_Types].xml"] && prefixes["ppt"]:
+		return "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	case names["AndroidManifest.xml"] || names["classes.dex"]:
+		return "application/vnd.android.package-archive"
+	case names["META-INF/MANIFEST.MF"]:
+		return "application/java-archive"
+	}
+	return "application/zip"
+}
+
+// readZipEntry read at most limit bytes of a ZIP entry
+func readZipEntry(file *zip.File, limit int64) string {
+	rc, err := file.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, limit))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// detectOLE detect legacy Office documents in OLE2 compound files by stream names
+func detectOLE(content []byte) string {
+	if !bytes.HasPrefix(content, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}) {
+		return ""
+	}
+	switch {
+	case bytes.Contains(content, utf16LE("WordDocument")):
+		return "application/msword"
+	case bytes.Contains(content, utf16LE("Workbook")), bytes.Contains(content, utf16LE("Book")):
+		return "application/vnd.ms-excel"
+	case bytes.Contains(content, utf16LE("PowerPoint Document")):
+		return "application/vnd.ms-powerpoint"
+	}
+	return "application/x-ole-storage"
+}
+
+// utf16LE encode ASCII string as UTF-16LE (OLE2 directory entry names)
+func utf16LE(s string) []byte {
+	out := make([]byte, 0, len(s)*2)
+	for i := 0; i < len(s); i++ {
+		out = append(out, s[i], 0)
+	}
+	return out
+}
+
+// detectExecutable detect native executables, bytecode and scripts
+func detectExecutable(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, []byte("\x7fELF")):
+		return "application/x-elf"
+	case bytes.HasPrefix(content, []byte("MZ")):
+		return "application/vnd.microsoft.portable-executable"
+	case bytes.HasPrefix(content, []byte{0xFE, 0xED, 0xFA, 0xCE}), bytes.HasPrefix(content, []byte{0xFE, 0xED, 0xFA, 0xCF}),
+		bytes.HasPrefix(content, []byte{0xCE, 0xFA, 0xED, 0xFE}), bytes.HasPrefix(content, []byte{0xCF, 0xFA, 0xED, 0xFE}):
+		return "application/x-mach-binary"
+	case bytes.HasPrefix(content, []byte{0xCA, 0xFE, 0xBA, 0xBE}) && len(content) >= 8:
+		// Shared by Java class files (major version >= 45) and Mach-O universal binaries (architecture count)
+		if binary.BigEndian.Uint32(content[4:8]) < 45 {
+			return "application/x-mach-binary"
+		}
+		return "application/java-vm"
+	case bytes.HasPrefix(content, []byte("\x00asm")):
+		return "application/wasm"
+	case bytes.HasPrefix(content, []byte("dex\n")):
+		return "application/vnd.android.dex"
+	case bytes.HasPrefix(content, []byte("#!")):
+		return "text/x-shellscript"
+	}
+	return ""
+}
+
+// detectFont detect web and desktop fonts
+func detectFont(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, []byte("wOFF")):
+		return "font/woff"
+	case bytes.HasPrefix(content, []byte("wOF2")):
+		return "font/woff2"
+	case bytes.HasPrefix(content, []byte("OTTO")):
+		return "font/otf"
+	case bytes.HasPrefix(content, []byte("ttcf")):
+		return "font/collection"
+	case len(content) >= 12 && bytes.HasPrefix(content, []byte{0x00, 0x01, 0x00, 0x00}):
+		// TrueType: sfnt version 1.0 followed by a plausible table count
+		if tables := binary.BigEndian.Uint16(content[4:6]); tables > 0 && tables < 64 {
+			return "font/ttf"
+		}
+	}
+	return ""
+}
+
+// detectAudioFrame detect MPEG audio and ADTS AAC streams without a container by their frame header
+func detectAudioFrame(content []byte) string {
+	if len(content) < 4 || content[0] != 0xFF || content[1]&0xE0 != 0xE0 {
+		return ""
+	}
+	layer := content[1] >> 1 & 0x03
+	if layer == 0 {
+		// ADTS: layer bits are always 0, sampling frequency index 0-12
+		if content[1]&0xF6 == 0xF0 && content[2]>>2&0x0F <= 12 {
+			return "audio/aac"
+		}
+		return ""
+	}
+	// MPEG audio: valid version, bitrate and sample rate indexes
+	version := content[1] >> 3 & 0x03
+	bitrate := content[2] >> 4
+	sampleRate := content[2] >> 2 & 0x03
+	if version == 1 || bitrate == 0x0F || sampleRate == 0x03 {
+		return ""
+	}
+	return "audio/mpeg"
+}
+
+// detectMPEGTS detect MPEG transport streams by sync bytes of consecutive packets
+func detectMPEGTS(content []byte) string {
+	if len(content) < 188*3 {
+		return ""
+	}
+	for offset := 0; offset < 188*3; offset += 188 {
+		if content[offset] != 0x47 {
+			return ""
+		}
+	}
+	return "video/mp2t"
+}
+
+// detectText detect text formats (SVG, JSON, HTML, XML, plain text) with net/http sniffing as fallback
+func detectText(content []byte) string {
+	head := bytes.TrimPrefix(content[:min(len(content), 1024)], []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(head)
+
+	if bytes.HasPrefix(trimmed, []byte("<")) && bytes.Contains(bytes.ToLower(trimmed), []byte("<svg")) {
+		return "image/svg+xml"
+	}
+	if (bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("["))) && json.Valid(content) {
+		return "application/json"
+	}
+
+	return http.DetectContentType(content)
+}
@@ -0,0 +1,101 @@
+package filetype
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func zipArchive(t *testing.T, entries ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(entries); i += 2 {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: entries[i], Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(entries[i+1]))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	ftyp := func(major string, compatible ...string) []byte {
+		box := []byte("\x00\x00\x00\x00ftyp" + major + "\x00\x00\x00\x00")
+		for _, brand := range compatible {
+			box = append(box, brand...)
+		}
+		box[3] = byte(len(box))
+		return box
+	}
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{"jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0x10, 'J', 'F', 'I', 'F'}, "image/jpeg"},
+		{"webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "image/webp"},
+		{"heic", ftyp("heic", "mif1", "heic"), "image/heic"},
+		{"avif", ftyp("mif1", "avif", "miaf"), "image/avif"},
+		{"mp4", ftyp("isom", "isom", "iso2", "mp41"), "video/mp4"},
+		{"mov", ftyp("qt  ", "qt  "), "video/quicktime"},
+		{"m4a", ftyp("M4A ", "M4A ", "mp42"), "audio/mp4"},
+		{"webm", []byte("\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01\x42\x82\x84webm"), "video/webm"},
+		{"flac", []byte("fLaC\x00\x00\x00\x22"), "audio/flac"},
+		{"mp3 frame", []byte{0xFF, 0xFB, 0x90, 0x64}, "audio/mpeg"},
+		{"opus", append([]byte("OggS\x00\x02"+string(make([]byte, 20))+"\x01\x13"), "OpusHead"...), "audio/opus"},
+		{"7z", []byte("7z\xbc\xaf\x27\x1c\x00\x04"), "application/x-7z-compressed"},
+		{"docx", zipArchive(t, "[Content_Types].xml", "<Types/>", "word/document.xml", "<w/>"), "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"xlsx", zipArchive(t, "[Content_Types].xml", "<Types/>", "xl/workbook.xml", "<x/>"), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		{"odt", zipArchive(t, "mimetype", "application/vnd.oasis.opendocument.text", "content.xml", "<o/>"), "application/vnd.oasis.opendocument.text"},
+		{"epub", zipArchive(t, "mimetype", "application/epub+zip", "META-INF/container.xml", "<c/>"), "application/epub+zip"},
+		{"apk", zipArchive(t, "AndroidManifest.xml", "x", "classes.dex", "dex\n"), "application/vnd.android.package-archive"},
+		{"zip", zipArchive(t, "a.txt", "hello"), "application/zip"},
+		{"doc", append([]byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"), utf16LE("WordDocument")...), "application/msword"},
+		{"elf", []byte("\x7fELF\x02\x01\x01"), "application/x-elf"},
+		{"pe", []byte("MZ\x90\x00\x03"), "application/vnd.microsoft.portable-executable"},
+		{"svg", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"/>`), "image/svg+xml"},
+		{"json", []byte(`{"name": "pin"}`), "application/json"},
+		{"text", []byte("hello world"), "text/plain; charset=utf-8"},
+		{"unknown", []byte{0x00, 0x01, 0x02, 0x03, 0x04}, OctetStream},
+	}
+
+	for _, tt := range tests {
+		if got := Detect(tt.content); got != tt.want {
+			t.Errorf("%s: Detect() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMismatch(t *testing.T) {
+	tests := []struct {
+		declared, detected string
+		want               bool
+	}{
+		{"image/png", "application/vnd.microsoft.portable-executable", true},
+		{"image/png", "audio/mpeg", true},
+		{"image/jpg", "image/jpeg", false},
+		{"image/jpeg", "image/png", false},
+		{"application/json", "text/plain; charset=utf-8", false},
+		{"text/markdown;charset=utf-8", "text/plain; charset=utf-8", false},
+		{"image/png", "text/plain; charset=utf-8", true},
+		{"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/zip", false},
+		{"application/msword", "application/x-ole-storage", false},
+		{"application/x-msdownload", "application/vnd.microsoft.portable-executable", false},
+		{"text/plain", "text/x-shellscript", true},
+		{"", "application/x-elf", false},
+		{"application/octet-stream", "image/png", false},
+		{"image/png", OctetStream, false},
+	}
+
+	for _, tt := range tests {
+		if got := Mismatch(tt.declared, tt.detected); got != tt.want {
+			t.Errorf("Mismatch(%q, %q) = %v, want %v", tt.declared, tt.detected, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package filetype
+
+import (
+	"mime"
+	"strings"
+)
+
+// Content categories used to compare declared and detected types
+const (
+	CategoryImage      = "image"
+	CategoryVideo      = "video"
+	CategoryAudio      = "audio"
+	CategoryText       = "text"
+	CategoryDocument   = "document"
+	CategoryArchive    = "archive"
+	CategoryExecutable = "executable"
+	CategoryFont       = "font"
+	CategoryData       = "data"
+	CategoryOther      = "other"
+)
+
+// aliases non-standard or legacy names of the same type
+var aliases = map[string]string{
+	"image/jpg":                    "image/jpeg",
+	"image/pjpeg":                  "image/jpeg",
+	"image/ico":                    "image/x-icon",
+	"image/vnd.microsoft.icon":     "image/x-icon",
+	"image/x-ms-bmp":               "image/bmp",
+	"audio/mp3":                    "audio/mpeg",
+	"audio/mpeg3":                  "audio/mpeg",
+	"audio/x-mpeg":                 "audio/mpeg",
+	"audio/x-wav":                  "audio/wav",
+	"audio/wave":                   "audio/wav",
+	"audio/vnd.wave":               "audio/wav",
+	"audio/x-flac":                 "audio/flac",
+	"audio/x-m4a":                  "audio/mp4",
+	"audio/x-aiff":                 "audio/aiff",
+	"audio/mid":                    "audio/midi",
+	"video/avi":                    "video/x-msvideo",
+	"video/x-ms-wmv":               "video/x-ms-asf",
+	"application/x-rar-compressed": "application/vnd.rar",
+	"application/x-rar":            "application/vnd.rar",
+	"application/x-gzip":           "application/gzip",
+	"application/x-zip-compressed": "application/zip",
+	"application/x-zip":            "application/zip",
+	"application/x-javascript":     "text/javascript",
+	"application/javascript":       "text/javascript",
+	"application/x-sh":             "text/x-shellscript",
+	"application/x-msdownload":     "application/vnd.microsoft.portable-executable",
+	"application/x-msdos-program":  "application/vnd.microsoft.portable-executable",
+	"application/x-dosexec":        "application/vnd.microsoft.portable-executable",
+	"application/x-executable":     "application/x-elf",
+	"application/x-sharedlib":      "application/x-elf",
+	"application/font-woff":        "font/woff",
+	"application/x-font-ttf":       "font/ttf",
+	"application/x-font-otf":       "font/otf",
+	"application/x-sqlite3":        "application/vnd.sqlite3",
+}
+
+// executableTypes content a system or runtime may execute
+var executableTypes = map[string]bool{
+	"application/x-elf":                             true,
+	"application/vnd.microsoft.portable-executable": true,
+	"application/x-mach-binary":                     true,
+	"application/java-vm":                           true,
+	"application/java-archive":                      true,
+	"application/vnd.android.package-archive":       true,
+	"application/vnd.android.dex":                   true,
+	"application/wasm":                              true,
+	"text/x-shellscript":                            true,
+}
+
+// Normalize media type without parameters, lower case, with aliases resolved
+func Normalize(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	}
+	if alias, ok := aliases[mediaType]; ok {
+		return alias
+	}
+	return mediaType
+}
+
+// Category content category of a content type
+func Category(contentType string) string {
+	contentType = Normalize(contentType)
+	switch {
+	case executableTypes[contentType]:
+		return CategoryExecutable
+	case strings.HasPrefix(contentType, "image/"):
+		return CategoryImage
+	case strings.HasPrefix(contentType, "video/"):
+		return CategoryVideo
+	case strings.HasPrefix(contentType, "audio/"), contentType == "application/ogg":
+		return CategoryAudio
+	case strings.HasPrefix(contentType, "font/"):
+		return CategoryFont
+	case strings.HasPrefix(contentType, "text/"):
+		return CategoryText
+	case contentType == "application/pdf", contentType == "application/rtf", contentType == "application/msword",
+		contentType == "application/x-ole-storage", contentType == "application/epub+zip",
+		strings.HasPrefix(contentType, "application/vnd.ms-"),
+		strings.HasPrefix(contentType, "application/vnd.openxmlformats-officedocument."),
+		strings.HasPrefix(contentType, "application/vnd.oasis.opendocument."):
+		return CategoryDocument
+	case contentType == "application/zip", contentType == "application/vnd.rar", contentType == "application/x-tar",
+		contentType == "application/gzip", contentType == "application/x-bzip2", contentType == "application/x-xz",
+		contentType == "application/zstd", contentType == "application/x-7z-compressed":
+		return CategoryArchive
+	case contentType == "application/json", contentType == "application/xml", contentType == "application/vnd.sqlite3",
+		strings.HasSuffix(contentType, "+json"), strings.HasSuffix(contentType, "+xml"):
+		return CategoryData
+	}
+	return CategoryOther
+}
+
+// Mismatch check if detected content contradicts the declared content type
+// Unknown declarations or detections never mismatch. Executable content is flagged unless
+// declared as executable; otherwise the categories are compared, so image/png declared as
+// image/jpeg is tolerated but an image declared as audio is not. Generic detections (plain
+// text, ZIP, OLE2) also match the more specific formats built on them.
+func Mismatch(declared, detected string) bool {
+	declared, detected = Normalize(declared), Normalize(detected)
+	if declared == "" || declared == OctetStream || detected == "" || detected == OctetStream || declared == detected {
+		return false
+	}
+
+	declaredCategory, detectedCategory := Category(declared), Category(detected)
+	if detectedCategory == CategoryExecutable {
+		return declaredCategory != CategoryExecutable
+	}
+
+	switch detected {
+	case "text/plain", "text/xml", "application/xml", "application/json":
+		if isTextual(declared) {
+			return false
+		}
+	case "application/zip":
+		if isZipBased(declared) {
+			return false
+		}
+	case "application/x-ole-storage":
+		if declaredCategory == CategoryDocument {
+			return false
+		}
+	}
+
+	return declaredCategory != detectedCategory
+}
+
+// isTextual check if content type is a text format
+func isTextual(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/") || contentType == "application/json" ||
+		contentType == "application/xml" || contentType == "image/svg+xml" ||
+		strings.HasSuffix(contentType, "+json") || strings.HasSuffix(contentType, "+xml")
+}
+
+// isZipBased check if content type is a ZIP container format
+func isZipBased(contentType string) bool {
+	return strings.Contains(contentType, "zip") ||
+		strings.HasPrefix(contentType, "application/vnd.openxmlformats-officedocument.") ||
+		strings.HasPrefix(contentType, "application/vnd.oasis.opendocument.") ||
+		contentType == "application/java-archive" || contentType == "application/vnd.android.package-archive"
+}
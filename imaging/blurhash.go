@@ -0,0 +1,180 @@
+package imaging
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// placeholderSampleSize largest side of the downscaled image placeholders are computed from
+const placeholderSampleSize = 32
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+var ErrInvalidComponents = errors.New("blurhash components must be between 1 and 9")
+
+// BlurHash encode image as a BlurHash string (https://blurha.sh)
+// xComponents/yComponents: number of horizontal/vertical components (1-9), 4x3 is typical
+func BlurHash(img image.Image, xComponents, yComponents int) (string, error) {
+	if xComponents < 1 || xComponents > 9 || yComponents < 1 || yComponents > 9 {
+		return "", ErrInvalidComponents
+	}
+
+	pixels, width, height := linearPixels(img)
+	if width == 0 || height == 0 {
+		return "", fmt.Errorf("%w: empty image", ErrUnsupportedFormat)
+	}
+
+	// DCT factors, factors[0] is the average (DC) color
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1.0
+			}
+			var r, g, b float64
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := normalisation *
+						math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+					p := pixels[y*width+x]
+					r += basis * p[0]
+					g += basis * p[1]
+					b += basis * p[2]
+				}
+			}
+			scale := 1.0 / float64(width*height)
+			factors = append(factors, [3]float64{r * scale, g * scale, b * scale})
+		}
+	}
+
+	var hash strings.Builder
+	hash.WriteString(encodeBase83((xComponents-1)+(yComponents-1)*9, 1))
+
+	maximumValue := 1.0
+	if len(factors) > 1 {
+		actualMaximum := 0.0
+		for _, factor := range factors[1:] {
+			for _, v := range factor {
+				actualMaximum = math.Max(actualMaximum, math.Abs(v))
+			}
+		}
+		quantisedMaximum := int(math.Max(0, math.Min(82, math.Floor(actualMaximum*166-0.5))))
+		maximumValue = float64(quantisedMaximum+1) / 166
+		hash.WriteString(encodeBase83(quantisedMaximum, 1))
+	} else {
+		hash.WriteString(encodeBase83(0, 1))
+	}
+
+	dc := factors[0]
+	hash.WriteString(encodeBase83(linearToSRGB(dc[0])<<16+linearToSRGB(dc[1])<<8+linearToSRGB(dc[2]), 4))
+
+	for _, factor := range factors[1:] {
+		quant := func(v float64) int {
+			return int(math.Max(0, math.Min(18, math.Floor(signPow(v/maximumValue, 0.5)*9+9.5))))
+		}
+		hash.WriteString(encodeBase83(quant(factor[0])*19*19+quant(factor[1])*19+quant(factor[2]), 2))
+	}
+
+	return hash.String(), nil
+}
+
+// BlurHashComponents choose component counts matching the image aspect ratio
+func BlurHashComponents(img image.Image) (int, int) {
+	bounds := img.Bounds()
+	if bounds.Dy() > bounds.Dx() {
+		return 3, 4
+	}
+	return 4, 3
+}
+
+// DominantColor most common color of the image as "#rrggbb"
+// Pixels are grouped into 4096 buckets (4 bits per channel); the average of the largest bucket is returned.
+func DominantColor(img image.Image) string {
+	sample := flatten(Resize(img, placeholderSampleSize, placeholderSampleSize, FitContain))
+	bounds := sample.Bounds()
+
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	buckets := make(map[int]*bucket)
+	var best *bucket
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(sample.At(x, y)).(color.NRGBA)
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+			bk := buckets[key]
+			if bk == nil {
+				bk = &bucket{}
+				buckets[key] = bk
+			}
+			bk.count++
+			bk.r += int(c.R)
+			bk.g += int(c.G)
+			bk.b += int(c.B)
+			if best == nil || bk.count > best.count {
+				best = bk
+			}
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
+
+// linearPixels downscale image and convert it to linear RGB (alpha flattened onto white)
+func linearPixels(img image.Image) ([][3]float64, int, int) {
+	sample := flatten(Resize(img, placeholderSampleSize, placeholderSampleSize, FitContain))
+	bounds := sample.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	pixels := make([][3]float64, 0, width*height)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(sample.At(x, y)).(color.NRGBA)
+			pixels = append(pixels, [3]float64{sRGBToLinear(c.R), sRGBToLinear(c.G), sRGBToLinear(c.B)})
+		}
+	}
+	return pixels, width, height
+}
+
+// sRGBToLinear convert sRGB channel to linear light
+func sRGBToLinear(value uint8) float64 {
+	v := float64(value) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB convert linear light to sRGB channel (0-255)
+func linearToSRGB(value float64) int {
+	v := math.Max(0, math.Min(1, value))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+// signPow pow keeping the sign of value
+func signPow(value, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(value), exp), value)
+}
+
+// encodeBase83 encode value as a fixed-length base83 string
+func encodeBase83(value, length int) string {
+	result := make([]byte, length)
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		result[i-1] = base83Chars[digit]
+	}
+	return string(result)
+}
@@ -0,0 +1,40 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+)
+
+// DHash 64-bit difference hash of an image
+// The image is reduced to a 9x8 grayscale thumbnail and each bit records whether a pixel
+// is brighter than its right neighbour. The hash survives re-encoding, resizing and small
+// color changes, so near-duplicates have a small HammingDistance.
+func DHash(img image.Image) uint64 {
+	sample := flatten(Resize(img, 9, 8, FitFill))
+	bounds := sample.Bounds()
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			left := luminance(sample.At(bounds.Min.X+x, bounds.Min.Y+y))
+			right := luminance(sample.At(bounds.Min.X+x+1, bounds.Min.Y+y))
+			hash <<= 1
+			if left > right {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// HammingDistance number of differing bits between two hashes
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// luminance Rec. 601 luma of a color
+func luminance(c color.Color) uint32 {
+	g := color.GrayModel.Convert(c).(color.Gray)
+	return uint32(g.Y)
+}
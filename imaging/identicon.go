@@ -0,0 +1,50 @@
+package imaging
+
+import (
+	"crypto/sha256"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// identiconGrid number of cells per side, the left half is mirrored to the right
+const identiconGrid = 5
+
+// Identicon generate a deterministic symmetric identicon for seed
+// The same seed always produces the same size x size image.
+func Identicon(seed string, size int) image.Image {
+	hash := sha256.Sum256([]byte(seed))
+
+	// Foreground color from hash, kept mid-bright so it reads on the light background
+	fg := color.NRGBA{R: 64 + hash[0]%160, G: 64 + hash[1]%160, B: 64 + hash[2]%160, A: 255}
+	bg := color.NRGBA{R: 240, G: 240, B: 240, A: 255}
+
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	// Leave a margin of half a cell around the grid
+	cell := size / (identiconGrid + 1)
+	if cell < 1 {
+		return img
+	}
+	offset := (size - cell*identiconGrid) / 2
+
+	fill := image.NewUniform(fg)
+	half := (identiconGrid + 1) / 2
+	for row := 0; row < identiconGrid; row++ {
+		for col := 0; col < half; col++ {
+			// One bit per cell of the left half (including the middle column)
+			bit := row*half + col
+			if hash[3+bit/8]>>(bit%8)&1 == 0 {
+				continue
+			}
+			for _, c := range []int{col, identiconGrid - 1 - col} {
+				x0 := offset + c*cell
+				y0 := offset + row*cell
+				draw.Draw(img, image.Rect(x0, y0, x0+cell, y0+cell), fill, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	return img
+}
@@ -0,0 +1,175 @@
+// Package imaging provides pure Go image decoding, resizing and encoding helpers.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+// Fit modes
+const (
+	FitContain = "contain" // Scale to fit inside the box, keep aspect ratio
+	FitCover   = "cover"   // Scale to fill the box, keep aspect ratio, crop overflow (centered)
+	FitFill    = "fill"    // Stretch to the box, ignore aspect ratio
+)
+
+// Output formats
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+)
+
+// MaxPixels largest source image (width * height) that will be decoded
+const MaxPixels = 50 * 1000 * 1000
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrImageTooLarge     = errors.New("image too large")
+)
+
+// Decode decode JPEG, PNG or GIF (first frame) image
+// Returns the image and its source format (jpeg/png/gif).
+func Decode(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if int64(config.Width)*int64(config.Height) > MaxPixels {
+		return nil, "", fmt.Errorf("%w: %dx%d", ErrImageTooLarge, config.Width, config.Height)
+	}
+
+	var img image.Image
+	switch format {
+	case "jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	case "png":
+		img, err = png.Decode(bytes.NewReader(data))
+	case "gif":
+		// gif.Decode returns the first frame
+		img, err = gif.Decode(bytes.NewReader(data))
+	default:
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode %s image: %w", format, err)
+	}
+
+	return img, format, nil
+}
+
+// Resize resize image into a width x height box using the given fit mode
+// A zero width or height is derived from the other one, keeping the aspect ratio.
+// Images are never upscaled in contain mode.
+func Resize(src image.Image, width, height int, fit string) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW == 0 || srcH == 0 {
+		return src
+	}
+
+	if width <= 0 && height <= 0 {
+		return src
+	}
+	if width <= 0 {
+		width = max(1, srcW*height/srcH)
+		fit = FitFill
+	} else if height <= 0 {
+		height = max(1, srcH*width/srcW)
+		fit = FitFill
+	}
+
+	srcRect := bounds
+	dstW, dstH := width, height
+
+	switch fit {
+	case FitFill:
+	case FitCover:
+		// Crop the source to the target aspect ratio, centered
+		if srcW*height > srcH*width {
+			cropW := srcH * width / height
+			x0 := bounds.Min.X + (srcW-cropW)/2
+			srcRect = image.Rect(x0, bounds.Min.Y, x0+cropW, bounds.Max.Y)
+		} else {
+			cropH := srcW * height / width
+			y0 := bounds.Min.Y + (srcH-cropH)/2
+			srcRect = image.Rect(bounds.Min.X, y0, bounds.Max.X, y0+cropH)
+		}
+	default: // FitContain
+		if srcW <= width && srcH <= height {
+			return src
+		}
+		if srcW*height > srcH*width {
+			dstH = max(1, srcH*width/srcW)
+		} else {
+			dstW = max(1, srcW*height/srcH)
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, srcRect, draw.Src, nil)
+	return dst
+}
+
+// Encode encode image as JPEG or PNG
+// quality: JPEG quality (1-100), ignored for PNG
+func Encode(img image.Image, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch format {
+	case FormatJPEG:
+		if quality < 1 || quality > 100 {
+			quality = jpeg.DefaultQuality
+		}
+		// JPEG has no alpha channel, flatten onto white
+		if err := jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode jpeg: %w", err)
+		}
+	case FormatPNG:
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("failed to encode png: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ContentType get MIME type of output format
+func ContentType(format string) string {
+	if format == FormatPNG {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
+
+// DefaultFormat choose output format for a source format
+// Formats that may carry transparency are kept as PNG.
+func DefaultFormat(sourceFormat string) string {
+	if sourceFormat == "png" || sourceFormat == "gif" {
+		return FormatPNG
+	}
+	return FormatJPEG
+}
+
+// flatten draw image onto an opaque white background
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+
+	bounds := img.Bounds()
+	dst := image.NewRGBA(bounds)
+	draw.Draw(dst, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, bounds, img, bounds.Min, draw.Over)
+	return dst
+}
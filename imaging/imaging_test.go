@@ -0,0 +1,188 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func TestResize(t *testing.T) {
+	src := testImage(400, 200)
+
+	tests := []struct {
+		name          string
+		width, height int
+		fit           string
+		wantW, wantH  int
+	}{
+		{"contain", 100, 100, FitContain, 100, 50},
+		{"contain no upscale", 800, 800, FitContain, 400, 200},
+		{"cover", 100, 100, FitCover, 100, 100},
+		{"fill", 100, 100, FitFill, 100, 100},
+		{"width only", 100, 0, FitContain, 100, 50},
+		{"height only", 0, 100, FitContain, 200, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resize(src, tt.width, tt.height, tt.fit).Bounds()
+			if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+				t.Fatalf("size = %dx%d, want %dx%d", got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestDecodeEncodeRoundTrip(t *testing.T) {
+	var pngBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, testImage(64, 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	var gifBuf bytes.Buffer
+	if err := gif.Encode(&gifBuf, testImage(64, 32), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string][]byte{"png": pngBuf.Bytes(), "gif": gifBuf.Bytes()} {
+		img, format, err := Decode(data)
+		if err != nil {
+			t.Fatalf("%s: Decode: %v", name, err)
+		}
+		if format != name {
+			t.Fatalf("format = %s, want %s", format, name)
+		}
+
+		for _, outFormat := range []string{FormatJPEG, FormatPNG} {
+			out, err := Encode(Resize(img, 16, 16, FitCover), outFormat, 85)
+			if err != nil {
+				t.Fatalf("%s -> %s: Encode: %v", name, outFormat, err)
+			}
+			_, decodedFormat, err := image.DecodeConfig(bytes.NewReader(out))
+			if err != nil || decodedFormat != outFormat {
+				t.Fatalf("%s -> %s: decoded format %s, %v", name, outFormat, decodedFormat, err)
+			}
+		}
+	}
+
+	if _, _, err := Decode([]byte("not an image")); err == nil {
+		t.Fatal("Decode accepted invalid data")
+	}
+}
+
+func TestIdenticon(t *testing.T) {
+	a := Identicon("metaid-a", 64)
+	if a.Bounds().Dx() != 64 || a.Bounds().Dy() != 64 {
+		t.Fatalf("size = %v, want 64x64", a.Bounds())
+	}
+
+	encodedA, _ := Encode(a, FormatPNG, 0)
+	encodedAgain, _ := Encode(Identicon("metaid-a", 64), FormatPNG, 0)
+	encodedB, _ := Encode(Identicon("metaid-b", 64), FormatPNG, 0)
+	if !bytes.Equal(encodedA, encodedAgain) {
+		t.Fatal("identicon is not deterministic")
+	}
+	if bytes.Equal(encodedA, encodedB) {
+		t.Fatal("different seeds produced the same identicon")
+	}
+
+	// Left and right halves mirror each other
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 32; x++ {
+			if a.At(x, y) != a.At(63-x, y) {
+				t.Fatalf("pixel (%d,%d) is not mirrored", x, y)
+			}
+		}
+	}
+}
+
+func TestBlurHashAndDominantColor(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	// A small blue patch does not change the dominant color
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.NRGBA{B: 255, A: 255})
+		}
+	}
+
+	// Resampling may shift channels by one step
+	if got := DominantColor(img); got != "#ff0000" && got != "#fe0000" {
+		t.Fatalf("dominant color = %s, want #ff0000", got)
+	}
+
+	xc, yc := BlurHashComponents(img)
+	hash, err := BlurHash(img, xc, yc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// size flag + max AC + DC (4) + 2 per AC component
+	if want := 1 + 1 + 4 + 2*(xc*yc-1); len(hash) != want {
+		t.Fatalf("len(%q) = %d, want %d", hash, len(hash), want)
+	}
+	if hash[0] != base83Chars[(xc-1)+(yc-1)*9] {
+		t.Fatalf("size flag = %c", hash[0])
+	}
+
+	// Single component: the hash is just the average color
+	black := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for i := 3; i < len(black.Pix); i += 4 {
+		black.Pix[i] = 255
+	}
+	hash, err = BlurHash(black, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != "000000" {
+		t.Fatalf("hash = %q, want 000000", hash)
+	}
+
+	if _, err := BlurHash(img, 0, 3); err != ErrInvalidComponents {
+		t.Fatalf("err = %v, want ErrInvalidComponents", err)
+	}
+}
+
+func TestDHash(t *testing.T) {
+	img := testImage(200, 150)
+	hash := DHash(img)
+
+	// Resized and re-encoded copies stay close
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, Resize(img, 80, 0, FitContain)); err != nil {
+		t.Fatal(err)
+	}
+	small, _, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := HammingDistance(hash, DHash(small)); d > 4 {
+		t.Fatalf("distance to resized copy = %d, want <= 4", d)
+	}
+
+	// A different image is far away
+	other := image.NewNRGBA(image.Rect(0, 0, 200, 150))
+	for y := 0; y < 150; y++ {
+		for x := 0; x < 200; x++ {
+			other.Set(x, y, color.NRGBA{R: uint8(255 - x), G: uint8((x * y) % 256), B: uint8(y), A: 255})
+		}
+	}
+	if d := HammingDistance(hash, DHash(other)); d < 16 {
+		t.Fatalf("distance to different image = %d, want >= 16", d)
+	}
+}
@@ -54,6 +54,11 @@ func NewBlockScannerWithChain(rpcURL, rpcUser, rpcPassword string, startHeight i
 	}
 }
 
+// ChainType chain the scanner reads
+func (s *BlockScanner) ChainType() ChainType {
+	return s.chainType
+}
+
 // EnableZMQ enable ZMQ real-time transaction monitoring
 func (s *BlockScanner) EnableZMQ(zmqAddress string) {
 	s.zmqClient = NewZMQClient(zmqAddress, s.chainType)
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/bitcoinsv/bsvd/wire"
 	"github.com/btcsuite/btcd/chaincfg"
@@ -269,6 +270,64 @@ func (p *MetaIDParser) ParseAllPINs(tx interface{}, chainType ChainType) (*MetaI
 	}, nil
 }
 
+// ErrPINNotFound PIN not found in transaction
+var ErrPINNotFound = errors.New("pin not found in transaction")
+
+// FetchPIN fetch transaction from node and extract the PIN with the given ID
+// pinID format: "{txid}i{vout}"
+func (p *MetaIDParser) FetchPIN(pinID string, chainType ChainType) (*MetaIDData, error) {
+	if p.blockScanner == nil {
+		return nil, errors.New("blockScanner not set, cannot fetch transaction from node")
+	}
+
+	idx := strings.LastIndex(pinID, "i")
+	if idx <= 0 {
+		return nil, fmt.Errorf("invalid pin id: %s", pinID)
+	}
+	txID := pinID[:idx]
+
+	txHex, err := p.blockScanner.GetRawTransaction(txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction %s: %w", txID, err)
+	}
+
+	txBytes, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction hex: %w", err)
+	}
+
+	var tx interface{}
+	if chainType == ChainTypeBTC {
+		var btcTx btcwire.MsgTx
+		if err := btcTx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+			return nil, fmt.Errorf("failed to deserialize BTC transaction: %w", err)
+		}
+		tx = &btcTx
+	} else {
+		var mvcTx wire.MsgTx
+		if err := mvcTx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+			return nil, fmt.Errorf("failed to deserialize MVC transaction: %w", err)
+		}
+		tx = &mvcTx
+	}
+
+	metaDataTx, err := p.ParseAllPINs(tx, chainType)
+	if err != nil {
+		return nil, err
+	}
+	if metaDataTx == nil {
+		return nil, ErrPINNotFound
+	}
+
+	for _, metaData := range metaDataTx.MetaIDData {
+		if metaData.PinID == pinID {
+			return metaData, nil
+		}
+	}
+
+	return nil, ErrPINNotFound
+}
+
 // extractBTCAddress extract address from BTC transaction first input
 func extractBTCCreatorAddress(tx *btcwire.MsgTx) string {
 	// In Bitcoin, the address is typically extracted from the first input's previous output
@@ -0,0 +1,370 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+)
+
+// MPEG audio Layer III bitrates in kbps, indexed by bitrate index
+var (
+	mp3BitratesV1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+)
+
+// MPEG audio sample rates, indexed by version bits then sample rate index
+var mp3SampleRates = map[byte][3]int{
+	3: {44100, 48000, 32000}, // MPEG 1
+	2: {22050, 24000, 16000}, // MPEG 2
+	0: {11025, 12000, 8000},  // MPEG 2.5
+}
+
+// extractMP3 extract duration, bitrate and ID3 tags from MP3
+func extractMP3(content []byte) (*Info, error) {
+	info := &Info{MediaType: MediaTypeAudio, Format: "mp3", AudioCodec: "mp3"}
+
+	start := 0
+	if len(content) >= 10 && string(content[0:3]) == "ID3" {
+		size := int(content[6]&0x7F)<<21 | int(content[7]&0x7F)<<14 | int(content[8]&0x7F)<<7 | int(content[9]&0x7F)
+		end := 10 + size
+		if end > len(content) {
+			end = len(content)
+		}
+		parseID3v2(content[10:end], content[3], info)
+		start = end
+	}
+
+	audioEnd := len(content)
+	if len(content) >= 128 && string(content[len(content)-128:len(content)-125]) == "TAG" {
+		tag := content[len(content)-128:]
+		if info.Title == "" {
+			info.Title = cleanString(string(tag[3:33]))
+		}
+		if info.Artist == "" {
+			info.Artist = cleanString(string(tag[33:63]))
+		}
+		if info.Album == "" {
+			info.Album = cleanString(string(tag[63:93]))
+		}
+		audioEnd -= 128
+	}
+
+	// Find first frame header
+	for ; start+4 <= audioEnd; start++ {
+		if content[start] == 0xFF && content[start+1]&0xE0 == 0xE0 {
+			if parseMP3Frame(content[start:audioEnd], int64(audioEnd-start), info) {
+				return info, nil
+			}
+		}
+	}
+
+	if info.Title == "" && info.Artist == "" && info.Album == "" {
+		return nil, ErrUnsupported
+	}
+	return info, nil
+}
+
+// parseMP3Frame parse frame header and Xing/Info header, returns false if header is invalid
+func parseMP3Frame(frame []byte, audioSize int64, info *Info) bool {
+	version := (frame[1] >> 3) & 0x03
+	layer := (frame[1] >> 1) & 0x03
+	bitrateIndex := frame[2] >> 4
+	sampleRateIndex := (frame[2] >> 2) & 0x03
+	channelMode := frame[3] >> 6
+
+	// Only Layer III (layer bits 01) with valid version/rates
+	if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return false
+	}
+
+	info.SampleRate = mp3SampleRates[version][sampleRateIndex]
+	info.Channels = 2
+	if channelMode == 3 {
+		info.Channels = 1
+	}
+
+	kbps := mp3BitratesV2[bitrateIndex]
+	samplesPerFrame := int64(576)
+	if version == 3 {
+		kbps = mp3BitratesV1[bitrateIndex]
+		samplesPerFrame = 1152
+	}
+
+	// Xing/Info header follows side information
+	sideInfo := 17
+	if version == 3 && channelMode != 3 {
+		sideInfo = 32
+	} else if version != 3 && channelMode == 3 {
+		sideInfo = 9
+	}
+	xing := 4 + sideInfo
+	if len(frame) >= xing+12 {
+		tag := string(frame[xing : xing+4])
+		if (tag == "Xing" || tag == "Info") && binary.BigEndian.Uint32(frame[xing+4:])&0x01 != 0 {
+			frames := int64(binary.BigEndian.Uint32(frame[xing+8:]))
+			info.DurationMs = frames * samplesPerFrame * 1000 / int64(info.SampleRate)
+			info.Bitrate = bitrate(audioSize, info.DurationMs)
+			return true
+		}
+	}
+
+	// Constant bitrate
+	info.Bitrate = int64(kbps) * 1000
+	info.DurationMs = audioSize * 8 * 1000 / info.Bitrate
+	return true
+}
+
+// parseID3v2 read title, artist and album frames from ID3v2 tag
+func parseID3v2(data []byte, majorVersion byte, info *Info) {
+	idLen, headerLen := 4, 10
+	titleID, artistID, albumID := "TIT2", "TPE1", "TALB"
+	if majorVersion == 2 {
+		idLen, headerLen = 3, 6
+		titleID, artistID, albumID = "TT2", "TP1", "TAL"
+	}
+
+	pos := 0
+	for pos+headerLen <= len(data) {
+		id := string(data[pos : pos+idLen])
+		if data[pos] == 0 {
+			return // Padding
+		}
+
+		var size int
+		switch majorVersion {
+		case 2:
+			size = int(data[pos+3])<<16 | int(data[pos+4])<<8 | int(data[pos+5])
+		case 4:
+			size = int(data[pos+4]&0x7F)<<21 | int(data[pos+5]&0x7F)<<14 | int(data[pos+6]&0x7F)<<7 | int(data[pos+7]&0x7F)
+		default:
+			size = int(binary.BigEndian.Uint32(data[pos+4:]))
+		}
+		if size < 0 || pos+headerLen+size > len(data) {
+			return
+		}
+
+		frame := data[pos+headerLen : pos+headerLen+size]
+		switch id {
+		case titleID:
+			info.Title = decodeID3Text(frame)
+		case artistID:
+			info.Artist = decodeID3Text(frame)
+		case albumID:
+			info.Album = decodeID3Text(frame)
+		}
+		pos += headerLen + size
+	}
+}
+
+// decodeID3Text decode text frame with leading encoding byte
+func decodeID3Text(frame []byte) string {
+	if len(frame) < 2 {
+		return ""
+	}
+
+	text := frame[1:]
+	switch frame[0] {
+	case 0: // ISO-8859-1
+		runes := make([]rune, len(text))
+		for i, b := range text {
+			runes[i] = rune(b)
+		}
+		return cleanString(string(runes))
+	case 1: // UTF-16 with BOM
+		if len(text) >= 2 && text[0] == 0xFF && text[1] == 0xFE {
+			return decodeUTF16(text[2:], binary.LittleEndian)
+		}
+		if len(text) >= 2 && text[0] == 0xFE && text[1] == 0xFF {
+			text = text[2:]
+		}
+		return decodeUTF16(text, binary.BigEndian)
+	case 2: // UTF-16BE
+		return decodeUTF16(text, binary.BigEndian)
+	default: // UTF-8
+		return cleanString(string(text))
+	}
+}
+
+// decodeUTF16 decode UTF-16 bytes
+func decodeUTF16(data []byte, order binary.ByteOrder) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, order.Uint16(data[i:]))
+	}
+	return cleanString(string(utf16.Decode(units)))
+}
+
+// extractFLAC extract stream info and Vorbis comments from FLAC
+func extractFLAC(content []byte) (*Info, error) {
+	info := &Info{MediaType: MediaTypeAudio, Format: "flac", AudioCodec: "flac"}
+
+	found := false
+	pos := 4
+	for pos+4 <= len(content) {
+		header := content[pos]
+		blockType := header & 0x7F
+		length := int(content[pos+1])<<16 | int(content[pos+2])<<8 | int(content[pos+3])
+		if pos+4+length > len(content) {
+			break
+		}
+		block := content[pos+4 : pos+4+length]
+
+		switch blockType {
+		case 0: // STREAMINFO
+			// min/max block size(4) min/max frame size(6), then sample rate(20) channels(3) bps(5) total samples(36)
+			if len(block) >= 18 {
+				packed := binary.BigEndian.Uint64(block[10:])
+				info.SampleRate = int(packed >> 44)
+				info.Channels = int((packed>>41)&0x07) + 1
+				totalSamples := int64(packed & 0xFFFFFFFFF)
+				if info.SampleRate > 0 {
+					info.DurationMs = totalSamples * 1000 / int64(info.SampleRate)
+				}
+				found = true
+			}
+		case 4: // VORBIS_COMMENT
+			parseVorbisComment(block, info)
+		}
+
+		// Last metadata block flag
+		if header&0x80 != 0 {
+			break
+		}
+		pos += 4 + length
+	}
+
+	if !found {
+		return nil, ErrUnsupported
+	}
+	info.Bitrate = bitrate(int64(len(content)), info.DurationMs)
+	return info, nil
+}
+
+// parseVorbisComment read TITLE/ARTIST/ALBUM from Vorbis comment block
+func parseVorbisComment(block []byte, info *Info) {
+	if len(block) < 4 {
+		return
+	}
+	pos := 4 + int(binary.LittleEndian.Uint32(block))
+	if pos+4 > len(block) || pos < 0 {
+		return
+	}
+	count := int(binary.LittleEndian.Uint32(block[pos:]))
+	pos += 4
+
+	for i := 0; i < count && pos+4 <= len(block); i++ {
+		length := int(binary.LittleEndian.Uint32(block[pos:]))
+		pos += 4
+		if length < 0 || pos+length > len(block) {
+			return
+		}
+		key, value, ok := strings.Cut(string(block[pos:pos+length]), "=")
+		pos += length
+		if !ok {
+			continue
+		}
+
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			info.Title = cleanString(value)
+		case "ARTIST":
+			info.Artist = cleanString(value)
+		case "ALBUM":
+			info.Album = cleanString(value)
+		}
+	}
+}
+
+// extractWAV extract format, duration and INFO tags from WAV
+func extractWAV(content []byte) (*Info, error) {
+	info := &Info{MediaType: MediaTypeAudio, Format: "wav", AudioCodec: "pcm"}
+
+	var byteRate, dataSize int64
+	pos := 12
+	for pos+8 <= len(content) {
+		chunkID := string(content[pos : pos+4])
+		length := int64(binary.LittleEndian.Uint32(content[pos+4:]))
+		start := int64(pos + 8)
+		end := start + length
+		// Streamed WAV files may declare a data size larger than the file
+		if end > int64(len(content)) {
+			end = int64(len(content))
+		}
+		chunk := content[start:end]
+
+		switch chunkID {
+		case "fmt ":
+			if len(chunk) >= 16 {
+				if format := binary.LittleEndian.Uint16(chunk); format != 1 {
+					info.AudioCodec = wavCodec(format)
+				}
+				info.Channels = int(binary.LittleEndian.Uint16(chunk[2:]))
+				info.SampleRate = int(binary.LittleEndian.Uint32(chunk[4:]))
+				byteRate = int64(binary.LittleEndian.Uint32(chunk[8:]))
+			}
+		case "data":
+			dataSize = int64(len(chunk))
+		case "LIST":
+			if bytes.HasPrefix(chunk, []byte("INFO")) {
+				parseRIFFInfo(chunk[4:], info)
+			}
+		}
+
+		// Chunks are padded to even size
+		next := start + length + length%2
+		if next > int64(len(content)) {
+			break
+		}
+		pos = int(next)
+	}
+
+	if byteRate == 0 {
+		return nil, ErrUnsupported
+	}
+	info.DurationMs = dataSize * 1000 / byteRate
+	info.Bitrate = byteRate * 8
+
+	return info, nil
+}
+
+// parseRIFFInfo read INAM/IART/IPRD from LIST INFO sub-chunks
+func parseRIFFInfo(data []byte, info *Info) {
+	pos := 0
+	for pos+8 <= len(data) {
+		id := string(data[pos : pos+4])
+		length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		if length < 0 || pos+8+length > len(data) {
+			return
+		}
+		value := cleanString(string(data[pos+8 : pos+8+length]))
+
+		switch id {
+		case "INAM":
+			info.Title = value
+		case "IART":
+			info.Artist = value
+		case "IPRD":
+			info.Album = value
+		}
+		pos += 8 + length + length%2
+	}
+}
+
+// wavCodec name of WAVE format tag
+func wavCodec(format uint16) string {
+	switch format {
+	case 3:
+		return "pcm_float"
+	case 6:
+		return "alaw"
+	case 7:
+		return "mulaw"
+	case 0x55:
+		return "mp3"
+	case 0xFFFE:
+		return "pcm" // WAVE_FORMAT_EXTENSIBLE, usually PCM
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,125 @@
+package mediainfo
+
+import (
+	"encoding/binary"
+)
+
+// EXIF/TIFF tags
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+)
+
+// TIFF field types
+const (
+	tiffTypeASCII = 2
+	tiffTypeShort = 3
+	tiffTypeLong  = 4
+)
+
+// tiffEntry IFD entry
+type tiffEntry struct {
+	typ   uint16
+	count uint32
+	value []byte // Raw 4-byte value/offset field
+}
+
+// tiffReader TIFF structure reader
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// parseEXIF parse TIFF-structured EXIF data into info
+// Only camera, orientation and capture time are extracted. GPS is flagged, never read.
+func parseEXIF(data []byte, info *Info) {
+	if len(data) < 8 {
+		return
+	}
+
+	r := &tiffReader{data: data}
+	switch string(data[0:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return
+	}
+	if r.order.Uint16(data[2:]) != 42 {
+		return
+	}
+
+	ifd0 := r.readIFD(r.order.Uint32(data[4:]))
+	if ifd0 == nil {
+		return
+	}
+
+	info.CameraMake = r.ascii(ifd0[tagMake])
+	info.CameraModel = r.ascii(ifd0[tagModel])
+	if entry, ok := ifd0[tagOrientation]; ok && entry.typ == tiffTypeShort {
+		if orientation := int(r.order.Uint16(entry.value)); orientation >= 1 && orientation <= 8 {
+			info.Orientation = orientation
+		}
+	}
+	info.CaptureTime = r.ascii(ifd0[tagDateTime])
+
+	if entry, ok := ifd0[tagExifIFD]; ok && entry.typ == tiffTypeLong {
+		if exifIFD := r.readIFD(r.order.Uint32(entry.value)); exifIFD != nil {
+			if original := r.ascii(exifIFD[tagDateTimeOriginal]); original != "" {
+				info.CaptureTime = original
+			}
+		}
+	}
+
+	if entry, ok := ifd0[tagGPSIFD]; ok && entry.typ == tiffTypeLong {
+		if gpsIFD := r.readIFD(r.order.Uint32(entry.value)); len(gpsIFD) > 0 {
+			info.HasGPS = true
+		}
+	}
+}
+
+// readIFD read IFD entries at offset
+func (r *tiffReader) readIFD(offset uint32) map[uint16]tiffEntry {
+	if int64(offset)+2 > int64(len(r.data)) {
+		return nil
+	}
+	count := int(r.order.Uint16(r.data[offset:]))
+	start := int(offset) + 2
+	if start+count*12 > len(r.data) {
+		return nil
+	}
+
+	entries := make(map[uint16]tiffEntry, count)
+	for i := 0; i < count; i++ {
+		entry := r.data[start+i*12 : start+(i+1)*12]
+		entries[r.order.Uint16(entry)] = tiffEntry{
+			typ:   r.order.Uint16(entry[2:]),
+			count: r.order.Uint32(entry[4:]),
+			value: entry[8:12],
+		}
+	}
+	return entries
+}
+
+// ascii read ASCII entry value
+func (r *tiffReader) ascii(entry tiffEntry) string {
+	if entry.typ != tiffTypeASCII || entry.count == 0 {
+		return ""
+	}
+	if entry.count <= 4 {
+		return cleanString(string(entry.value[:entry.count]))
+	}
+
+	offset := int64(r.order.Uint32(entry.value))
+	end := offset + int64(entry.count)
+	if end > int64(len(r.data)) {
+		return ""
+	}
+	return cleanString(string(r.data[offset:end]))
+}
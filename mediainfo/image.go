@@ -0,0 +1,181 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+// extractImage extract dimensions, frame count and EXIF from JPEG/PNG/GIF/WebP
+func extractImage(content []byte) (*Info, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	info := &Info{
+		MediaType:  MediaTypeImage,
+		Format:     format,
+		Width:      config.Width,
+		Height:     config.Height,
+		FrameCount: 1,
+	}
+
+	switch format {
+	case "jpeg":
+		if exif := jpegEXIF(content); exif != nil {
+			parseEXIF(exif, info)
+		}
+	case "png":
+		parsePNGChunks(content, info)
+	case "gif":
+		if frames := gifFrameCount(content); frames > 0 {
+			info.FrameCount = frames
+		}
+	case "webp":
+		parseWebPChunks(content, info)
+	}
+
+	return info, nil
+}
+
+// jpegEXIF find EXIF TIFF data in JPEG APP1 segment
+func jpegEXIF(content []byte) []byte {
+	pos := 2
+	for pos+4 <= len(content) {
+		if content[pos] != 0xFF {
+			return nil
+		}
+		marker := content[pos+1]
+		// Start of scan / end of image: no more metadata segments
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+		// Fill bytes and markers without payload
+		if marker == 0xFF || (marker >= 0xD0 && marker <= 0xD7) || marker == 0x01 {
+			pos++
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(content[pos+2:]))
+		if length < 2 || pos+2+length > len(content) {
+			return nil
+		}
+		segment := content[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		pos += 2 + length
+	}
+	return nil
+}
+
+// parsePNGChunks read APNG frame count and eXIf chunk
+func parsePNGChunks(content []byte, info *Info) {
+	pos := 8
+	for pos+8 <= len(content) {
+		length := int(binary.BigEndian.Uint32(content[pos:]))
+		chunkType := string(content[pos+4 : pos+8])
+		if length < 0 || pos+12+length > len(content) {
+			return
+		}
+		data := content[pos+8 : pos+8+length]
+
+		switch chunkType {
+		case "acTL":
+			if len(data) >= 4 {
+				info.FrameCount = int(binary.BigEndian.Uint32(data))
+			}
+		case "eXIf":
+			parseEXIF(data, info)
+		case "IDAT", "IEND":
+			// Metadata chunks that matter precede image data
+			return
+		}
+		pos += 12 + length
+	}
+}
+
+// parseWebPChunks read animation frame count and EXIF chunk
+func parseWebPChunks(content []byte, info *Info) {
+	frames := 0
+	pos := 12
+	for pos+8 <= len(content) {
+		chunkType := string(content[pos : pos+4])
+		length := int(binary.LittleEndian.Uint32(content[pos+4:]))
+		if length < 0 || pos+8+length > len(content) {
+			break
+		}
+		data := content[pos+8 : pos+8+length]
+
+		switch chunkType {
+		case "ANMF":
+			frames++
+		case "EXIF":
+			parseEXIF(bytes.TrimPrefix(data, []byte("Exif\x00\x00")), info)
+		}
+		// Chunks are padded to even size
+		pos += 8 + length + length%2
+	}
+	if frames > 0 {
+		info.FrameCount = frames
+	}
+}
+
+// gifFrameCount count image descriptors in a GIF without decoding pixel data
+func gifFrameCount(content []byte) int {
+	if len(content) < 13 {
+		return 0
+	}
+
+	pos := 13
+	// Global color table
+	if flags := content[10]; flags&0x80 != 0 {
+		pos += 3 << (flags&0x07 + 1)
+	}
+
+	frames := 0
+	for pos < len(content) {
+		switch content[pos] {
+		case 0x2C: // Image descriptor
+			if pos+10 > len(content) {
+				return frames
+			}
+			frames++
+			flags := content[pos+9]
+			pos += 10
+			// Local color table
+			if flags&0x80 != 0 {
+				pos += 3 << (flags&0x07 + 1)
+			}
+			// LZW minimum code size, then data sub-blocks
+			pos++
+			pos = skipGIFSubBlocks(content, pos)
+		case 0x21: // Extension: label, then sub-blocks
+			pos = skipGIFSubBlocks(content, pos+2)
+		case 0x3B: // Trailer
+			return frames
+		default:
+			return frames
+		}
+	}
+	return frames
+}
+
+// skipGIFSubBlocks skip data sub-blocks until the block terminator
+func skipGIFSubBlocks(content []byte, pos int) int {
+	for pos < len(content) {
+		size := int(content[pos])
+		pos++
+		if size == 0 {
+			return pos
+		}
+		pos += size
+	}
+	return pos
+}
@@ -0,0 +1,164 @@
+package mediainfo
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// isoBox ISO base media file format box
+type isoBox struct {
+	typ  string
+	data []byte // Payload after the box header
+}
+
+// readISOBoxes split data into boxes
+func readISOBoxes(data []byte) []isoBox {
+	var boxes []isoBox
+	pos := 0
+	for pos+8 <= len(data) {
+		size := int64(binary.BigEndian.Uint32(data[pos:]))
+		typ := string(data[pos+4 : pos+8])
+		header := int64(8)
+
+		switch size {
+		case 0: // Box extends to end of data
+			size = int64(len(data) - pos)
+		case 1: // 64-bit large size
+			if pos+16 > len(data) {
+				return boxes
+			}
+			size = int64(binary.BigEndian.Uint64(data[pos+8:]))
+			header = 16
+		}
+		if size < header || int64(pos)+size > int64(len(data)) {
+			return boxes
+		}
+
+		boxes = append(boxes, isoBox{typ: typ, data: data[int64(pos)+header : int64(pos)+size]})
+		pos += int(size)
+	}
+	return boxes
+}
+
+// findISOBox find first child box of type
+func findISOBox(data []byte, typ string) []byte {
+	for _, box := range readISOBoxes(data) {
+		if box.typ == typ {
+			return box.data
+		}
+	}
+	return nil
+}
+
+// extractISOBMFF extract duration, codecs and resolution from MP4/MOV container boxes
+func extractISOBMFF(content []byte) (*Info, error) {
+	ftyp := findISOBox(content, "ftyp")
+	if len(ftyp) < 4 {
+		return nil, ErrUnsupported
+	}
+
+	info := &Info{MediaType: MediaTypeVideo, Format: "mp4"}
+	switch brand := string(ftyp[0:4]); brand {
+	case "qt  ":
+		info.Format = "mov"
+	case "heic", "heix", "mif1", "msf1", "avif":
+		// HEIF still images are not parsed
+		return nil, ErrUnsupported
+	case "M4A ", "M4B ":
+		info.MediaType = MediaTypeAudio
+		info.Format = "m4a"
+	}
+
+	moov := findISOBox(content, "moov")
+	if moov == nil {
+		return nil, ErrUnsupported
+	}
+
+	if mvhd := findISOBox(moov, "mvhd"); mvhd != nil {
+		info.DurationMs = parseMVHD(mvhd)
+	}
+
+	hasVideo := false
+	for _, box := range readISOBoxes(moov) {
+		if box.typ != "trak" {
+			continue
+		}
+		mdia := findISOBox(box.data, "mdia")
+		if mdia == nil {
+			continue
+		}
+
+		handler := ""
+		if hdlr := findISOBox(mdia, "hdlr"); len(hdlr) >= 12 {
+			handler = string(hdlr[8:12])
+		}
+		stsd := findISOBox(findISOBox(findISOBox(mdia, "minf"), "stbl"), "stsd")
+		// version/flags(4) entry_count(4) then first sample entry: size(4) format(4)
+		if len(stsd) < 16 {
+			continue
+		}
+		codec := strings.TrimSpace(string(stsd[12:16]))
+		entry := stsd[16:]
+
+		switch handler {
+		case "vide":
+			if info.VideoCodec != "" {
+				continue
+			}
+			hasVideo = true
+			info.VideoCodec = codec
+			// reserved(6) data_reference_index(2) pre_defined/reserved(16) width(2) height(2)
+			if len(entry) >= 28 {
+				info.Width = int(binary.BigEndian.Uint16(entry[24:]))
+				info.Height = int(binary.BigEndian.Uint16(entry[26:]))
+			}
+		case "soun":
+			if info.AudioCodec != "" {
+				continue
+			}
+			info.AudioCodec = codec
+			// reserved(6) data_reference_index(2) reserved(8) channelcount(2) samplesize(2) reserved(4) samplerate(16.16)
+			if len(entry) >= 28 {
+				info.Channels = int(binary.BigEndian.Uint16(entry[16:]))
+				info.SampleRate = int(binary.BigEndian.Uint32(entry[24:]) >> 16)
+			}
+		}
+	}
+
+	// MP4 files without a video track are audio
+	if !hasVideo && info.AudioCodec != "" {
+		info.MediaType = MediaTypeAudio
+	}
+	info.Bitrate = bitrate(int64(len(content)), info.DurationMs)
+
+	return info, nil
+}
+
+// parseMVHD get movie duration in milliseconds from mvhd box
+func parseMVHD(mvhd []byte) int64 {
+	if len(mvhd) < 4 {
+		return 0
+	}
+
+	var timescale, duration uint64
+	if mvhd[0] == 1 {
+		// version(1) flags(3) creation(8) modification(8) timescale(4) duration(8)
+		if len(mvhd) < 32 {
+			return 0
+		}
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[20:]))
+		duration = binary.BigEndian.Uint64(mvhd[24:])
+	} else {
+		// version(1) flags(3) creation(4) modification(4) timescale(4) duration(4)
+		if len(mvhd) < 20 {
+			return 0
+		}
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[12:]))
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:]))
+	}
+
+	if timescale == 0 || duration == ^uint64(0) || duration == 0xFFFFFFFF {
+		return 0
+	}
+	return int64(duration * 1000 / timescale)
+}
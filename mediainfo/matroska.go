@@ -0,0 +1,229 @@
+package mediainfo
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+)
+
+// EBML element IDs
+const (
+	ebmlIDHeader      = 0x1A45DFA3
+	ebmlIDDocType     = 0x4282
+	ebmlIDSegment     = 0x18538067
+	ebmlIDInfo        = 0x1549A966
+	ebmlIDTimecode    = 0x2AD7B1
+	ebmlIDDuration    = 0x4489
+	ebmlIDTitle       = 0x7BA9
+	ebmlIDTracks      = 0x1654AE6B
+	ebmlIDTrackEntry  = 0xAE
+	ebmlIDTrackType   = 0x83
+	ebmlIDCodecID     = 0x86
+	ebmlIDVideo       = 0xE0
+	ebmlIDPixelWidth  = 0xB0
+	ebmlIDPixelHeight = 0xBA
+	ebmlIDAudio       = 0xE1
+	ebmlIDSampling    = 0xB5
+	ebmlIDChannels    = 0x9F
+	ebmlIDCluster     = 0x1F43B675
+)
+
+// ebmlElement EBML element
+type ebmlElement struct {
+	id   uint64
+	data []byte
+}
+
+// readEBMLVint read variable length integer, returns value, length and whether it is the reserved "unknown" value
+// keepMarker keeps the length marker bit (element IDs)
+func readEBMLVint(data []byte, keepMarker bool) (uint64, int, bool) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0, false
+	}
+
+	length := 1
+	for mask := byte(0x80); data[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 || length > len(data) {
+		return 0, 0, false
+	}
+
+	value := uint64(data[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	allOnes := value == uint64(0xFF>>length)
+	for i := 1; i < length; i++ {
+		value = value<<8 | uint64(data[i])
+		allOnes = allOnes && data[i] == 0xFF
+	}
+	return value, length, !keepMarker && allOnes
+}
+
+// readEBMLElements split data into child elements
+// Elements with unknown size extend to the end of data; clusters are skipped
+func readEBMLElements(data []byte) []ebmlElement {
+	var elements []ebmlElement
+	pos := 0
+	for pos < len(data) {
+		id, idLen, _ := readEBMLVint(data[pos:], true)
+		if idLen == 0 {
+			return elements
+		}
+		size, sizeLen, unknown := readEBMLVint(data[pos+idLen:], false)
+		if sizeLen == 0 {
+			return elements
+		}
+
+		start := pos + idLen + sizeLen
+		end := len(data)
+		if !unknown {
+			if size > uint64(len(data)-start) {
+				end = len(data)
+			} else {
+				end = start + int(size)
+			}
+		}
+
+		// Cluster payload holds media data only
+		if id == ebmlIDCluster && unknown {
+			return elements
+		}
+		elements = append(elements, ebmlElement{id: id, data: data[start:end]})
+		pos = end
+	}
+	return elements
+}
+
+// ebmlUint decode unsigned integer element
+func ebmlUint(data []byte) uint64 {
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+// ebmlFloat decode float element (4 or 8 bytes)
+func ebmlFloat(data []byte) float64 {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+	return 0
+}
+
+// extractMatroska extract duration, codecs and resolution from WebM/Matroska
+func extractMatroska(content []byte) (*Info, error) {
+	info := &Info{MediaType: MediaTypeVideo, Format: "matroska"}
+
+	var segment []byte
+	for _, element := range readEBMLElements(content) {
+		switch element.id {
+		case ebmlIDHeader:
+			for _, child := range readEBMLElements(element.data) {
+				if child.id == ebmlIDDocType && cleanString(string(child.data)) == "webm" {
+					info.Format = "webm"
+				}
+			}
+		case ebmlIDSegment:
+			segment = element.data
+		}
+	}
+	if segment == nil {
+		return nil, ErrUnsupported
+	}
+
+	timecodeScale := uint64(1000000)
+	var duration float64
+	hasVideo := false
+	for _, element := range readEBMLElements(segment) {
+		switch element.id {
+		case ebmlIDInfo:
+			for _, child := range readEBMLElements(element.data) {
+				switch child.id {
+				case ebmlIDTimecode:
+					if scale := ebmlUint(child.data); scale > 0 {
+						timecodeScale = scale
+					}
+				case ebmlIDDuration:
+					duration = ebmlFloat(child.data)
+				case ebmlIDTitle:
+					info.Title = cleanString(string(child.data))
+				}
+			}
+		case ebmlIDTracks:
+			for _, track := range readEBMLElements(element.data) {
+				if track.id != ebmlIDTrackEntry {
+					continue
+				}
+				if parseMatroskaTrack(track.data, info) {
+					hasVideo = true
+				}
+			}
+		}
+	}
+
+	if duration > 0 {
+		info.DurationMs = int64(duration * float64(timecodeScale) / 1e6)
+	}
+	if !hasVideo && info.AudioCodec != "" {
+		info.MediaType = MediaTypeAudio
+	}
+	info.Bitrate = bitrate(int64(len(content)), info.DurationMs)
+
+	return info, nil
+}
+
+// parseMatroskaTrack read first video and audio track entries, returns true for video tracks
+func parseMatroskaTrack(data []byte, info *Info) bool {
+	var trackType uint64
+	var codec string
+	var video, audio []byte
+	for _, child := range readEBMLElements(data) {
+		switch child.id {
+		case ebmlIDTrackType:
+			trackType = ebmlUint(child.data)
+		case ebmlIDCodecID:
+			codec = strings.TrimPrefix(strings.TrimPrefix(cleanString(string(child.data)), "V_"), "A_")
+		case ebmlIDVideo:
+			video = child.data
+		case ebmlIDAudio:
+			audio = child.data
+		}
+	}
+
+	switch trackType {
+	case 1: // Video
+		if info.VideoCodec != "" {
+			return true
+		}
+		info.VideoCodec = codec
+		for _, child := range readEBMLElements(video) {
+			switch child.id {
+			case ebmlIDPixelWidth:
+				info.Width = int(ebmlUint(child.data))
+			case ebmlIDPixelHeight:
+				info.Height = int(ebmlUint(child.data))
+			}
+		}
+		return true
+	case 2: // Audio
+		if info.AudioCodec != "" {
+			return false
+		}
+		info.AudioCodec = codec
+		for _, child := range readEBMLElements(audio) {
+			switch child.id {
+			case ebmlIDSampling:
+				info.SampleRate = int(ebmlFloat(child.data))
+			case ebmlIDChannels:
+				info.Channels = int(ebmlUint(child.data))
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// Package mediainfo extracts image, video and audio metadata from file content in pure Go.
+package mediainfo
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// Media types
+const (
+	MediaTypeImage = "image"
+	MediaTypeVideo = "video"
+	MediaTypeAudio = "audio"
+)
+
+// ErrUnsupported content is not a supported media container
+var ErrUnsupported = errors.New("unsupported media format")
+
+// Info media metadata
+type Info struct {
+	MediaType string // image/video/audio
+	Format    string // jpeg/png/gif/webp/mp4/mov/webm/matroska/mp3/flac/wav
+
+	// Visual
+	Width       int
+	Height      int
+	Orientation int // EXIF orientation 1-8 (0 = unknown)
+	FrameCount  int // Number of frames for images (>1 = animated)
+
+	// Timing
+	DurationMs int64
+	Bitrate    int64 // Bits per second
+
+	// Codecs
+	VideoCodec string
+	AudioCodec string
+	SampleRate int
+	Channels   int
+
+	// EXIF
+	CameraMake  string
+	CameraModel string
+	CaptureTime string // EXIF DateTimeOriginal, e.g. "2024:01:02 15:04:05"
+	HasGPS      bool   // GPS data present in source (coordinates are never extracted)
+
+	// Tags
+	Title  string
+	Artist string
+	Album  string
+}
+
+// Extract extract media metadata from content
+// contentType: detected content type, used to disambiguate formats without a magic number (MP3)
+func Extract(content []byte, contentType string) (*Info, error) {
+	switch {
+	case bytes.HasPrefix(content, []byte{0xFF, 0xD8, 0xFF}),
+		bytes.HasPrefix(content, []byte("\x89PNG\r\n\x1a\n")),
+		bytes.HasPrefix(content, []byte("GIF8")),
+		isRIFF(content, "WEBP"):
+		return extractImage(content)
+	case isRIFF(content, "WAVE"):
+		return extractWAV(content)
+	case bytes.HasPrefix(content, []byte("fLaC")):
+		return extractFLAC(content)
+	case bytes.HasPrefix(content, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return extractMatroska(content)
+	case len(content) >= 12 && string(content[4:8]) == "ftyp":
+		return extractISOBMFF(content)
+	case bytes.HasPrefix(content, []byte("ID3")),
+		isMP3ContentType(contentType) && len(content) > 1 && content[0] == 0xFF && content[1]&0xE0 == 0xE0:
+		return extractMP3(content)
+	}
+
+	return nil, ErrUnsupported
+}
+
+// isRIFF check RIFF container with form type
+func isRIFF(content []byte, formType string) bool {
+	return len(content) >= 12 && string(content[0:4]) == "RIFF" && string(content[8:12]) == formType
+}
+
+// isMP3ContentType check if content type is MPEG audio
+func isMP3ContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.Contains(contentType, "mpeg") || strings.Contains(contentType, "mp3")
+}
+
+// bitrate average bitrate in bits per second
+func bitrate(size int64, durationMs int64) int64 {
+	if durationMs <= 0 {
+		return 0
+	}
+	return size * 8 * 1000 / durationMs
+}
+
+// cleanString trim NUL padding and whitespace from tag values
+func cleanString(s string) string {
+	return strings.TrimSpace(strings.TrimRight(s, "\x00"))
+}
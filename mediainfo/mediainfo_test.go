@@ -0,0 +1,146 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+)
+
+// box build ISO BMFF box
+func box(typ string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	out := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint32(out, uint32(8+len(data)))
+	copy(out[4:], typ)
+	return append(out, data...)
+}
+
+// ifdEntry build big-endian IFD entry
+func ifdEntry(tag, typ uint16, count, value uint32) []byte {
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry, tag)
+	binary.BigEndian.PutUint16(entry[2:], typ)
+	binary.BigEndian.PutUint32(entry[4:], count)
+	binary.BigEndian.PutUint32(entry[8:], value)
+	return entry
+}
+
+func TestExtractJPEGWithEXIF(t *testing.T) {
+	var jpegBuf bytes.Buffer
+	if err := jpeg.Encode(&jpegBuf, image.NewGray(image.Rect(0, 0, 40, 30)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// TIFF header + IFD0 (Make, Orientation, GPS pointer) + GPS IFD with one entry
+	makeValue := []byte("Canon\x00")
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	ifd0Offset := 8
+	ifd0Size := 2 + 3*12 + 4
+	makeOffset := ifd0Offset + ifd0Size
+	gpsOffset := makeOffset + len(makeValue)
+
+	tiff = append(tiff, 0, 3)
+	tiff = append(tiff, ifdEntry(tagMake, tiffTypeASCII, uint32(len(makeValue)), uint32(makeOffset))...)
+	tiff = append(tiff, ifdEntry(tagOrientation, tiffTypeShort, 1, 6<<16)...)
+	tiff = append(tiff, ifdEntry(tagGPSIFD, tiffTypeLong, 1, uint32(gpsOffset))...)
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, makeValue...)
+	tiff = append(tiff, 0, 1)
+	tiff = append(tiff, ifdEntry(0x0002, 5, 3, 0)...)
+	tiff = append(tiff, 0, 0, 0, 0)
+
+	app1 := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(app1)+2))
+	content := append([]byte{0xFF, 0xD8}, append(append(segment, app1...), jpegBuf.Bytes()[2:]...)...)
+
+	info, err := Extract(content, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Width != 40 || info.Height != 30 {
+		t.Fatalf("size = %dx%d, want 40x30", info.Width, info.Height)
+	}
+	if info.CameraMake != "Canon" || info.Orientation != 6 || !info.HasGPS {
+		t.Fatalf("exif = make %q, orientation %d, gps %v", info.CameraMake, info.Orientation, info.HasGPS)
+	}
+}
+
+func TestExtractAnimatedGIF(t *testing.T) {
+	frame := image.NewPaletted(image.Rect(0, 0, 16, 8), []color.Color{color.Black, color.White})
+	anim := &gif.GIF{Image: []*image.Paletted{frame, frame, frame}, Delay: []int{10, 10, 10}}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := Extract(buf.Bytes(), "image/gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.FrameCount != 3 || info.Width != 16 || info.Height != 8 {
+		t.Fatalf("frames = %d, size = %dx%d", info.FrameCount, info.Width, info.Height)
+	}
+}
+
+func TestExtractMP4(t *testing.T) {
+	// mvhd v0: timescale 1000, duration 5000
+	mvhd := make([]byte, 20)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], 5000)
+
+	hdlr := append(make([]byte, 8), []byte("vide")...)
+	entry := make([]byte, 28)
+	binary.BigEndian.PutUint16(entry[24:], 1920)
+	binary.BigEndian.PutUint16(entry[26:], 1080)
+	stsd := append([]byte{0, 0, 0, 0, 0, 0, 0, 1}, box("avc1", entry)...)
+
+	trak := box("trak", box("mdia", box("hdlr", hdlr), box("minf", box("stbl", box("stsd", stsd)))))
+	content := append(box("ftyp", []byte("isom\x00\x00\x02\x00")), box("moov", box("mvhd", mvhd), trak)...)
+
+	info, err := Extract(content, "video/mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.MediaType != MediaTypeVideo || info.DurationMs != 5000 || info.VideoCodec != "avc1" || info.Width != 1920 || info.Height != 1080 {
+		t.Fatalf("info = %+v", info)
+	}
+}
+
+func TestExtractWAV(t *testing.T) {
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk, 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:], 2)
+	binary.LittleEndian.PutUint32(fmtChunk[4:], 8000)
+	binary.LittleEndian.PutUint32(fmtChunk[8:], 32000)
+
+	chunk := func(id string, data []byte) []byte {
+		out := make([]byte, 8)
+		copy(out, id)
+		binary.LittleEndian.PutUint32(out[4:], uint32(len(data)))
+		return append(out, data...)
+	}
+	body := append([]byte("WAVE"), chunk("fmt ", fmtChunk)...)
+	body = append(body, chunk("LIST", append([]byte("INFO"), chunk("INAM", []byte("Tone\x00\x00"))...))...)
+	body = append(body, chunk("data", make([]byte, 64000))...)
+	content := append([]byte("RIFF\x00\x00\x00\x00"), body...)
+
+	info, err := Extract(content, "audio/wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.DurationMs != 2000 || info.Channels != 2 || info.SampleRate != 8000 || info.Title != "Tone" {
+		t.Fatalf("info = %+v", info)
+	}
+}
+
+func TestExtractUnsupported(t *testing.T) {
+	if _, err := Extract([]byte("plain text"), "text/plain"); err != ErrUnsupported {
+		t.Fatalf("err = %v, want ErrUnsupported", err)
+	}
+}
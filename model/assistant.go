@@ -2,17 +2,31 @@ package model
 
 import "time"
 
+// Assistant states
+const (
+	AssistantStateExist   int64 = 0
+	AssistantStateDeleted int64 = 1
+)
+
 // Assistant assistant model
+// A custodial key a user authorized to sign and pay for their uploads. The private key is encrypted
+// with the configured master key (AES-256-GCM, base64).
 type Assistant struct {
 	ID                  int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	MetaId              string    `gorm:"uniqueIndex;type:varchar(64);not null" json:"meta_id"`          // MetaID
-	Address             string    `gorm:"index;type:varchar(255);not null" json:"address"`               // Mnemonic address
-	AssistantPrivateKey string    `gorm:"index;type:varchar(255);not null" json:"assistant_private_key"` // Mnemonic private key
-	AssistantAddress    string    `gorm:"index;type:varchar(255);not null" json:"assistant_address"`     // Mnemonic address
-	AssistantMetaId     string    `gorm:"index;type:varchar(255);not null" json:"assistant_meta_id"`     // Mnemonic MetaID
-	CreatedAt           time.Time `gorm:"autoCreateTime" json:"created_at"`                              // Creation time
-	UpdatedAt           time.Time `gorm:"autoUpdateTime" json:"updated_at"`                              // Update time
-	State               int64     `gorm:"type:int(11)" json:"state"`                                     // State 0:EXIST,1:DELETED
+	MetaId              string    `gorm:"uniqueIndex;type:varchar(64);not null" json:"meta_id"`      // MetaID
+	Address             string    `gorm:"index;type:varchar(255);not null" json:"address"`           // Mnemonic address
+	AssistantPrivateKey string    `gorm:"index;type:varchar(255);not null" json:"-"`                 // Encrypted assistant private key
+	AssistantAddress    string    `gorm:"index;type:varchar(255);not null" json:"assistant_address"` // Mnemonic address
+	AssistantMetaId     string    `gorm:"index;type:varchar(255);not null" json:"assistant_meta_id"` // Mnemonic MetaID
+	PrevPrivateKey      string    `gorm:"type:varchar(255)" json:"-"`                                // Encrypted key replaced by a rotation, kept until its balance is swept
+	PrevAddress         string    `gorm:"type:varchar(255)" json:"-"`                                // Address of the replaced key
+	SpendLimit          int64     `gorm:"default:0" json:"spend_limit"`                              // Satoshis the assistant may spend
+	SpentAmount         int64     `gorm:"default:0" json:"spent_amount"`                             // Satoshis spent so far (fees and outputs)
+	TokenHash           string    `gorm:"type:varchar(64)" json:"-"`                                 // SHA256 of the access token (hex)
+	AuthTimestamp       int64     `gorm:"default:0" json:"-"`                                        // Timestamp of the last accepted owner signature
+	CreatedAt           time.Time `gorm:"autoCreateTime" json:"created_at"`                          // Creation time
+	UpdatedAt           time.Time `gorm:"autoUpdateTime" json:"updated_at"`                          // Update time
+	State               int64     `gorm:"type:int(11)" json:"state"`                                 // State 0:EXIST,1:DELETED
 }
 
 // TableName specify table name
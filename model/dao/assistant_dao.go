@@ -0,0 +1,80 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+
+	"meta-media-service/database"
+	"meta-media-service/model"
+)
+
+// AssistantDAO assistant data access object (for Uploader service, always uses MySQL)
+type AssistantDAO struct{}
+
+// NewAssistantDAO create assistant DAO instance
+func NewAssistantDAO() *AssistantDAO {
+	return &AssistantDAO{}
+}
+
+// Create create assistant record
+func (dao *AssistantDAO) Create(assistant *model.Assistant) error {
+	return database.UploaderDB.Create(assistant).Error
+}
+
+// GetByMetaID get assistant by owner MetaID (any state)
+func (dao *AssistantDAO) GetByMetaID(metaId string) (*model.Assistant, error) {
+	var assistant model.Assistant
+	err := database.UploaderDB.Where("meta_id = ?", metaId).First(&assistant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &assistant, nil
+}
+
+// UpdateAuthorized save an assistant changed by an owner request if no other request changed it first
+// prevAuthTimestamp is the auth timestamp the assistant was read with. Returns false if it moved.
+// The spent amount is left to Charge, Refund and ResetSpent, so concurrent charges are never overwritten.
+func (dao *AssistantDAO) UpdateAuthorized(assistant *model.Assistant, prevAuthTimestamp int64) (bool, error) {
+	result := database.UploaderDB.Model(&model.Assistant{}).
+		Where("id = ? AND auth_timestamp = ?", assistant.ID, prevAuthTimestamp).
+		Select("address", "assistant_private_key", "assistant_address", "assistant_meta_id",
+			"prev_private_key", "prev_address", "spend_limit", "token_hash", "auth_timestamp", "state").
+		Updates(assistant)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ClearPrevKey forget the key replaced by a rotation once its balance is swept
+// prevAddress guards against clearing a key replaced by a later rotation.
+func (dao *AssistantDAO) ClearPrevKey(id int64, prevAddress string) error {
+	return database.UploaderDB.Model(&model.Assistant{}).
+		Where("id = ? AND prev_address = ?", id, prevAddress).
+		Updates(map[string]interface{}{"prev_private_key": "", "prev_address": ""}).Error
+}
+
+// Charge add amount to the spent amount of an active assistant if it stays within the spend limit
+// Returns false if the limit would be exceeded or the assistant is revoked.
+func (dao *AssistantDAO) Charge(id int64, amount int64) (bool, error) {
+	result := database.UploaderDB.Model(&model.Assistant{}).
+		Where("id = ? AND state = ? AND spent_amount + ? <= spend_limit", id, model.AssistantStateExist, amount).
+		Update("spent_amount", gorm.Expr("spent_amount + ?", amount))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ResetSpent start the spent amount of an assistant over, when a revoked assistant is created again
+func (dao *AssistantDAO) ResetSpent(id int64) error {
+	return database.UploaderDB.Model(&model.Assistant{}).
+		Where("id = ?", id).
+		Update("spent_amount", gorm.Expr("0")).Error
+}
+
+// Refund subtract a charge whose transaction was not broadcast
+func (dao *AssistantDAO) Refund(id int64, amount int64) error {
+	return database.UploaderDB.Model(&model.Assistant{}).
+		Where("id = ?", id).
+		Update("spent_amount", gorm.Expr("GREATEST(spent_amount - ?, 0)", amount)).Error
+}
@@ -0,0 +1,36 @@
+package dao
+
+import (
+	"meta-media-service/database"
+	"meta-media-service/model"
+)
+
+// FileChunkDAO file chunk data access object (for Uploader service, always uses MySQL)
+type FileChunkDAO struct{}
+
+// NewFileChunkDAO create file chunk DAO instance
+func NewFileChunkDAO() *FileChunkDAO {
+	return &FileChunkDAO{}
+}
+
+// CreateBatch create chunk records
+func (dao *FileChunkDAO) CreateBatch(chunks []*model.FileChunk) error {
+	return database.UploaderDB.Create(&chunks).Error
+}
+
+// ListByFileID get all chunks of a file ordered by chunk index
+func (dao *FileChunkDAO) ListByFileID(fileID string) ([]*model.FileChunk, error) {
+	var chunks []*model.FileChunk
+	err := database.UploaderDB.Where("file_id = ?", fileID).
+		Order("chunk_index ASC").
+		Find(&chunks).Error
+	if err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// DeleteByFileID delete all chunks of a file
+func (dao *FileChunkDAO) DeleteByFileID(fileID string) error {
+	return database.UploaderDB.Where("file_id = ?", fileID).Delete(&model.FileChunk{}).Error
+}
@@ -49,13 +49,25 @@ func (dao *FileDAO) GetByPath(path string) (*model.File, error) {
 	return &file, nil
 }
 
-// List query file list
-func (dao *FileDAO) List(offset, limit int) ([]*model.File, error) {
+// List query file list with cursor pagination, newest first
+// Empty address, metaId and status match all files. cursor is the ID of the last file of the previous page (0 for the first page).
+func (dao *FileDAO) List(address, metaId string, status model.Status, cursor int64, limit int) ([]*model.File, error) {
 	var files []*model.File
-	err := database.UploaderDB.Order("created_at DESC").
-		Offset(offset).
-		Limit(limit).
-		Find(&files).Error
+	query := database.UploaderDB.Model(&model.File{})
+	if address != "" {
+		query = query.Where("address = ?", address)
+	}
+	if metaId != "" {
+		query = query.Where("meta_id = ?", metaId)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+
+	err := query.Order("id DESC").Limit(limit).Find(&files).Error
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"meta-media-service/database"
+	"meta-media-service/model"
+)
+
+// FileTxDAO upload transaction data access object (for Uploader service, always uses MySQL)
+type FileTxDAO struct{}
+
+// NewFileTxDAO create upload transaction DAO instance
+func NewFileTxDAO() *FileTxDAO {
+	return &FileTxDAO{}
+}
+
+// ListByStates get transactions in one of the given states, ordered by ID after afterID
+// Transactions of an upload are recorded in broadcast order, so parents come before the transactions spending them.
+func (dao *FileTxDAO) ListByStates(states []model.TxState, afterID int64, limit int) ([]*model.FileTx, error) {
+	var txs []*model.FileTx
+	err := database.UploaderDB.Where("state IN ? AND id > ?", states, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&txs).Error
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// ListConfirmedSince get transactions of a chain confirmed at minHeight or above, ordered by ID after afterID
+func (dao *FileTxDAO) ListConfirmedSince(chain string, minHeight int64, afterID int64, limit int) ([]*model.FileTx, error) {
+	var txs []*model.FileTx
+	err := database.UploaderDB.Where("state = ? AND chain = ? AND block_height >= ? AND id > ?", model.TxStateConfirmed, chain, minHeight, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&txs).Error
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// GetByTxID get upload transaction by transaction ID
+func (dao *FileTxDAO) GetByTxID(txID string) (*model.FileTx, error) {
+	var ftx model.FileTx
+	err := database.UploaderDB.Where("tx_id = ?", txID).First(&ftx).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ftx, nil
+}
+
+// ListByFileID get transactions of a file in broadcast order
+func (dao *FileTxDAO) ListByFileID(fileID string) ([]*model.FileTx, error) {
+	var txs []*model.FileTx
+	err := database.UploaderDB.Where("file_id = ?", fileID).Order("id ASC").Find(&txs).Error
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// ListEventsByFileID get the state history of a file, oldest first
+func (dao *FileTxDAO) ListEventsByFileID(fileID string) ([]*model.FileTxEvent, error) {
+	var events []*model.FileTxEvent
+	err := database.UploaderDB.Where("file_id = ?", fileID).Order("id ASC").Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// CreateEvent create state history record
+func (dao *FileTxDAO) CreateEvent(event *model.FileTxEvent) error {
+	return database.UploaderDB.Create(event).Error
+}
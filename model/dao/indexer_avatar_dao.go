@@ -58,3 +58,8 @@ func (dao *IndexerUserAvatarDAO) Update(avatar *model.IndexerUserAvatar) error {
 func (dao *IndexerUserAvatarDAO) ListWithCursor(cursor int64, size int) ([]*model.IndexerUserAvatar, error) {
 	return dao.db.ListIndexerUserAvatarsWithCursor(cursor, size)
 }
+
+// ForEach iterate over all indexed avatars
+func (dao *IndexerUserAvatarDAO) ForEach(fn func(avatar *model.IndexerUserAvatar) error) error {
+	return dao.db.ForEachIndexerUserAvatar(fn)
+}
@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"meta-media-service/database"
+	"meta-media-service/model"
+)
+
+// IndexerBlobDAO indexer blob data access object
+type IndexerBlobDAO struct {
+	db database.Database
+}
+
+// NewIndexerBlobDAO create indexer blob DAO instance
+func NewIndexerBlobDAO() *IndexerBlobDAO {
+	return &IndexerBlobDAO{
+		db: database.DB,
+	}
+}
+
+// GetByHash get blob by file hash
+func (dao *IndexerBlobDAO) GetByHash(fileHash string) (*model.IndexerBlob, error) {
+	blob, err := dao.db.GetIndexerBlobByHash(fileHash)
+	if err == database.ErrNotFound {
+		return nil, nil
+	}
+	return blob, err
+}
+
+// IncrRef create blob record if not exists and increase its reference count
+// Returns the reference count after increment
+func (dao *IndexerBlobDAO) IncrRef(blob *model.IndexerBlob) (int64, error) {
+	return dao.db.IncrIndexerBlobRef(blob)
+}
+
+// DecrRef decrease blob reference count, the record is removed when count reaches 0
+// Returns the reference count after decrement
+func (dao *IndexerBlobDAO) DecrRef(fileHash string) (int64, error) {
+	return dao.db.DecrIndexerBlobRef(fileHash)
+}
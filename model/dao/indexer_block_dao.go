@@ -0,0 +1,61 @@
+package dao
+
+import (
+	"meta-media-service/database"
+	"meta-media-service/model"
+)
+
+// IndexerBlockDAO moderation blocklist data access object
+type IndexerBlockDAO struct {
+	db database.Database
+}
+
+// NewIndexerBlockDAO create moderation blocklist DAO instance
+func NewIndexerBlockDAO() *IndexerBlockDAO {
+	return &IndexerBlockDAO{
+		db: database.DB,
+	}
+}
+
+// Create create blocklist entry, returns database.ErrDuplicate if the target is already blocked
+func (dao *IndexerBlockDAO) Create(block *model.IndexerBlock) error {
+	return dao.db.CreateIndexerBlock(block)
+}
+
+// GetByID get blocklist entry by ID
+func (dao *IndexerBlockDAO) GetByID(id int64) (*model.IndexerBlock, error) {
+	block, err := dao.db.GetIndexerBlockByID(id)
+	if err == database.ErrNotFound {
+		return nil, nil
+	}
+	return block, err
+}
+
+// Find get blocklist entries matching any of the keys
+func (dao *IndexerBlockDAO) Find(keys []model.BlockKey) ([]*model.IndexerBlock, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return dao.db.FindIndexerBlocks(keys)
+}
+
+// ListWithCursor get blocklist entries with cursor pagination, newest first
+// blockType: only entries of this type (empty for all)
+func (dao *IndexerBlockDAO) ListWithCursor(blockType string, cursor int64, size int) ([]*model.IndexerBlock, error) {
+	return dao.db.ListIndexerBlocksWithCursor(blockType, cursor, size)
+}
+
+// Delete delete blocklist entry by ID
+func (dao *IndexerBlockDAO) Delete(id int64) error {
+	return dao.db.DeleteIndexerBlock(id)
+}
+
+// CreateAudit append moderation audit log entry
+func (dao *IndexerBlockDAO) CreateAudit(audit *model.IndexerBlockAudit) error {
+	return dao.db.CreateIndexerBlockAudit(audit)
+}
+
+// ListAuditsWithCursor get moderation audit log entries with cursor pagination, newest first
+func (dao *IndexerBlockDAO) ListAuditsWithCursor(cursor int64, size int) ([]*model.IndexerBlockAudit, error) {
+	return dao.db.ListIndexerBlockAuditsWithCursor(cursor, size)
+}
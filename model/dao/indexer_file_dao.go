@@ -57,7 +57,19 @@ func (dao *IndexerFileDAO) GetByCreatorMetaIDWithCursor(metaID string, cursor in
 	return dao.db.GetIndexerFilesByCreatorMetaIDWithCursor(metaID, cursor, size)
 }
 
+// GetWithContentTypeMismatchCursor get files whose detected content contradicts the declared type
+// cursor: last file ID (0 for first page)
+// size: page size
+func (dao *IndexerFileDAO) GetWithContentTypeMismatchCursor(cursor int64, size int) ([]*model.IndexerFile, error) {
+	return dao.db.GetIndexerFilesWithContentTypeMismatchCursor(cursor, size)
+}
+
 // GetFilesCount get total count of indexed files
 func (dao *IndexerFileDAO) GetFilesCount() (int64, error) {
 	return dao.db.GetIndexerFilesCount()
 }
+
+// ForEach iterate over all indexed files
+func (dao *IndexerFileDAO) ForEach(fn func(file *model.IndexerFile) error) error {
+	return dao.db.ForEachIndexerFile(fn)
+}
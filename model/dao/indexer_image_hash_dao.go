@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"meta-media-service/database"
+	"meta-media-service/model"
+)
+
+// IndexerImageHashDAO indexer image hash data access object
+type IndexerImageHashDAO struct {
+	db database.Database
+}
+
+// NewIndexerImageHashDAO create indexer image hash DAO instance
+func NewIndexerImageHashDAO() *IndexerImageHashDAO {
+	return &IndexerImageHashDAO{
+		db: database.DB,
+	}
+}
+
+// CreateOrUpdate create or update image hash by PIN ID
+func (dao *IndexerImageHashDAO) CreateOrUpdate(hash *model.IndexerImageHash) error {
+	return dao.db.CreateOrUpdateIndexerImageHash(hash)
+}
+
+// GetByPinID get image hash by PIN ID
+func (dao *IndexerImageHashDAO) GetByPinID(pinID string) (*model.IndexerImageHash, error) {
+	hash, err := dao.db.GetIndexerImageHashByPinID(pinID)
+	if err == database.ErrNotFound {
+		return nil, nil
+	}
+	return hash, err
+}
+
+// ForEachByBands iterate image hashes matching any candidate value of any band, each hash once
+// bands[i]: candidate values of band i
+// Iteration stops at the first error returned by fn, which is returned.
+func (dao *IndexerImageHashDAO) ForEachByBands(bands [model.ImageHashBands][]int, fn func(hash *model.IndexerImageHash) error) error {
+	return dao.db.ForEachIndexerImageHashByBands(bands, fn)
+}
@@ -0,0 +1,32 @@
+package dao
+
+import (
+	"meta-media-service/database"
+	"meta-media-service/model"
+)
+
+// IndexerMediaMetadataDAO indexer media metadata data access object
+type IndexerMediaMetadataDAO struct {
+	db database.Database
+}
+
+// NewIndexerMediaMetadataDAO create indexer media metadata DAO instance
+func NewIndexerMediaMetadataDAO() *IndexerMediaMetadataDAO {
+	return &IndexerMediaMetadataDAO{
+		db: database.DB,
+	}
+}
+
+// CreateOrUpdate create or update media metadata by PIN ID
+func (dao *IndexerMediaMetadataDAO) CreateOrUpdate(metadata *model.IndexerMediaMetadata) error {
+	return dao.db.CreateOrUpdateIndexerMediaMetadata(metadata)
+}
+
+// GetByPinID get media metadata by PIN ID
+func (dao *IndexerMediaMetadataDAO) GetByPinID(pinID string) (*model.IndexerMediaMetadata, error) {
+	metadata, err := dao.db.GetIndexerMediaMetadataByPinID(pinID)
+	if err == database.ErrNotFound {
+		return nil, nil
+	}
+	return metadata, err
+}
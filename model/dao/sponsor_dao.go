@@ -0,0 +1,115 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"meta-media-service/database"
+	"meta-media-service/model"
+)
+
+// SponsorDAO sponsored upload data access object (for Uploader service, always uses MySQL)
+type SponsorDAO struct{}
+
+// NewSponsorDAO create sponsor DAO instance
+func NewSponsorDAO() *SponsorDAO {
+	return &SponsorDAO{}
+}
+
+// SponsorTotals aggregated cost of sponsored uploads
+type SponsorTotals struct {
+	Uploads int64 // Sponsored upload requests
+	Bytes   int64 // File bytes of successful uploads
+	TxCount int64 // Transactions paid
+	Fee     int64 // Satoshis paid
+}
+
+// Charge add bytes and fee to the budget of a MetaID on a day if both stay within their limits
+// Returns false if a limit would be exceeded.
+func (dao *SponsorDAO) Charge(metaId, day string, bytes, fee, byteLimit, feeLimit int64) (bool, error) {
+	budget := &model.SponsorBudget{MetaId: metaId, Day: day}
+	if err := database.UploaderDB.Clauses(clause.OnConflict{DoNothing: true}).Create(budget).Error; err != nil {
+		return false, err
+	}
+	result := database.UploaderDB.Model(&model.SponsorBudget{}).
+		Where("meta_id = ? AND day = ? AND bytes + ? <= ? AND fee + ? <= ?", metaId, day, bytes, byteLimit, fee, feeLimit).
+		Updates(map[string]interface{}{
+			"bytes": gorm.Expr("bytes + ?", bytes),
+			"fee":   gorm.Expr("fee + ?", fee),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Refund subtract a charge that was not spent
+func (dao *SponsorDAO) Refund(metaId, day string, bytes, fee int64) error {
+	return database.UploaderDB.Model(&model.SponsorBudget{}).
+		Where("meta_id = ? AND day = ?", metaId, day).
+		Updates(map[string]interface{}{
+			"bytes": gorm.Expr("GREATEST(bytes - ?, 0)", bytes),
+			"fee":   gorm.Expr("GREATEST(fee - ?, 0)", fee),
+		}).Error
+}
+
+// GetBudget get the budget usage of a MetaID on a day
+func (dao *SponsorDAO) GetBudget(metaId, day string) (*model.SponsorBudget, error) {
+	var budget model.SponsorBudget
+	err := database.UploaderDB.Where("meta_id = ? AND day = ?", metaId, day).First(&budget).Error
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// CreateUpload create sponsored upload record
+func (dao *SponsorDAO) CreateUpload(upload *model.SponsoredUpload) error {
+	return database.UploaderDB.Create(upload).Error
+}
+
+// UpdateUpload save sponsored upload record
+func (dao *SponsorDAO) UpdateUpload(upload *model.SponsoredUpload) error {
+	return database.UploaderDB.Save(upload).Error
+}
+
+// ListUploadsWithCursor get sponsored uploads with cursor pagination, newest first
+// Empty filters match all records. from and to are inclusive days (2006-01-02).
+func (dao *SponsorDAO) ListUploadsWithCursor(metaId, from, to string, cursor int64, size int) ([]*model.SponsoredUpload, error) {
+	var uploads []*model.SponsoredUpload
+	query := dao.uploadQuery(metaId, from, to)
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+	err := query.Order("id DESC").Limit(size).Find(&uploads).Error
+	return uploads, err
+}
+
+// SumUploads aggregate the cost of sponsored uploads matching the filters
+// Bytes count successful uploads only, transactions and fees count everything that was broadcast.
+func (dao *SponsorDAO) SumUploads(metaId, from, to string) (*SponsorTotals, error) {
+	var totals SponsorTotals
+	err := dao.uploadQuery(metaId, from, to).
+		Select("COUNT(*) AS uploads, COALESCE(SUM(CASE WHEN status = ? THEN file_size ELSE 0 END), 0) AS bytes, "+
+			"COALESCE(SUM(tx_count), 0) AS tx_count, COALESCE(SUM(fee), 0) AS fee", model.StatusSuccess).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// uploadQuery sponsored upload query with report filters
+func (dao *SponsorDAO) uploadQuery(metaId, from, to string) *gorm.DB {
+	query := database.UploaderDB.Model(&model.SponsoredUpload{})
+	if metaId != "" {
+		query = query.Where("meta_id = ?", metaId)
+	}
+	if from != "" {
+		query = query.Where("day >= ?", from)
+	}
+	if to != "" {
+		query = query.Where("day <= ?", to)
+	}
+	return query
+}
@@ -0,0 +1,65 @@
+package dao
+
+import (
+	"time"
+
+	"meta-media-service/database"
+	"meta-media-service/model"
+)
+
+// TusUploadDAO resumable upload data access object (for Uploader service, always uses MySQL)
+type TusUploadDAO struct{}
+
+// NewTusUploadDAO create resumable upload DAO instance
+func NewTusUploadDAO() *TusUploadDAO {
+	return &TusUploadDAO{}
+}
+
+// Create create resumable upload record
+func (dao *TusUploadDAO) Create(upload *model.TusUpload) error {
+	return database.UploaderDB.Create(upload).Error
+}
+
+// GetByUploadID get resumable upload by upload ID
+func (dao *TusUploadDAO) GetByUploadID(uploadID string) (*model.TusUpload, error) {
+	var upload model.TusUpload
+	err := database.UploaderDB.Where("upload_id = ?", uploadID).First(&upload).Error
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// UpdateOffset advance the offset of an upload if it is still at expectedOffset
+// Returns false if another write moved the offset first.
+func (dao *TusUploadDAO) UpdateOffset(upload *model.TusUpload, expectedOffset int64) (bool, error) {
+	result := database.UploaderDB.Model(&model.TusUpload{}).
+		Where("upload_id = ? AND upload_offset = ?", upload.UploadId, expectedOffset).
+		Updates(map[string]interface{}{
+			"upload_offset": upload.UploadOffset,
+			"parts":         upload.Parts,
+			"expires_at":    upload.ExpiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ListExpired get uploads that expired before the given time
+func (dao *TusUploadDAO) ListExpired(before time.Time, limit int) ([]*model.TusUpload, error) {
+	var uploads []*model.TusUpload
+	err := database.UploaderDB.Where("expires_at < ?", before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&uploads).Error
+	if err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+// Delete delete resumable upload record
+func (dao *TusUploadDAO) Delete(id int64) error {
+	return database.UploaderDB.Delete(&model.TusUpload{}, id).Error
+}
@@ -0,0 +1,73 @@
+package dao
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"meta-media-service/database"
+	"meta-media-service/model"
+)
+
+// UtxoReservationDAO UTXO reservation data access object (for Uploader service, always uses MySQL)
+type UtxoReservationDAO struct{}
+
+// NewUtxoReservationDAO create UTXO reservation DAO instance
+func NewUtxoReservationDAO() *UtxoReservationDAO {
+	return &UtxoReservationDAO{}
+}
+
+// ListActiveByAddress get reservations of an address that have not expired
+func (dao *UtxoReservationDAO) ListActiveByAddress(address string, now time.Time) ([]*model.UtxoReservation, error) {
+	var reservations []*model.UtxoReservation
+	err := database.UploaderDB.Where("address = ? AND expires_at > ?", address, now).Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// Reserve create reservations, replacing expired reservations of the same outpoints
+// Fails with a duplicate key error if an outpoint is reserved by another upload meanwhile.
+func (dao *UtxoReservationDAO) Reserve(reservations []*model.UtxoReservation, now time.Time) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+	return database.UploaderDB.Transaction(func(tx *gorm.DB) error {
+		for _, r := range reservations {
+			err := tx.Where("tx_id = ? AND vout = ? AND expires_at <= ?", r.TxId, r.Vout, now).
+				Delete(&model.UtxoReservation{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Create(&reservations).Error
+	})
+}
+
+// ListByFileTx get reservations of one transaction of a file
+func (dao *UtxoReservationDAO) ListByFileTx(fileId string, txIndex int64) ([]*model.UtxoReservation, error) {
+	var reservations []*model.UtxoReservation
+	err := database.UploaderDB.Where("file_id = ? AND tx_index = ?", fileId, txIndex).Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// ReleaseByFileID delete all reservations of a file
+func (dao *UtxoReservationDAO) ReleaseByFileID(fileId string) error {
+	return database.UploaderDB.Where("file_id = ?", fileId).Delete(&model.UtxoReservation{}).Error
+}
+
+// ReleaseByFileTx delete reservations of one transaction of a file
+func (dao *UtxoReservationDAO) ReleaseByFileTx(fileId string, txIndex int64) error {
+	return database.UploaderDB.Where("file_id = ? AND tx_index = ?", fileId, txIndex).Delete(&model.UtxoReservation{}).Error
+}
+
+// ExtendByFileID move the expiry of all reservations of a file
+func (dao *UtxoReservationDAO) ExtendByFileID(fileId string, expiresAt time.Time) error {
+	return database.UploaderDB.Model(&model.UtxoReservation{}).
+		Where("file_id = ?", fileId).
+		Update("expires_at", expiresAt).Error
+}
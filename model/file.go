@@ -9,6 +9,12 @@ const (
 	ChunkTypeMulti  ChunkType = "multi"
 )
 
+// Chains uploads can be inscribed on
+const (
+	ChainMvc = "mvc"
+	ChainBtc = "btc"
+)
+
 type Status string
 
 const (
@@ -21,7 +27,7 @@ const (
 type File struct {
 	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
 
-	FileId string `gorm:"type:varchar(255)" json:"file_id"` // metaid_filehash
+	FileId string `gorm:"index;type:varchar(255)" json:"file_id"` // metaid_filehash
 
 	FileName        string    `gorm:"type:varchar(255)" json:"file_name"`         // File name
 	FileHash        string    `gorm:"type:varchar(255)" json:"file_hash"`         // File hash
@@ -33,20 +39,25 @@ type File struct {
 
 	ContentHex string `gorm:"type:text" json:"content_hex"` // Content hexadecimal
 
-	MetaId  string `gorm:"type:varchar(255)" json:"meta_id"` // MetaID
-	Address string `gorm:"type:varchar(255)" json:"address"` //
+	MetaId  string `gorm:"index;type:varchar(255)" json:"meta_id"` // MetaID
+	Address string `gorm:"index;type:varchar(255)" json:"address"` // Owner address
 
 	TxID        string `gorm:"uniqueIndex;type:varchar(64);not null" json:"tx_id"` // On-chain transaction ID
 	PinId       string `gorm:"index;type:varchar(255);not null" json:"pin_id"`     // Pin ID
 	Path        string `gorm:"index;type:varchar(255);not null" json:"path"`       // MetaID path
 	ContentType string `gorm:"type:varchar(100)" json:"content_type"`              // Content type
-	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`               // local/oss
+	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`               // local/oss/s3
 	StoragePath string `gorm:"type:varchar(500)" json:"storage_path"`              // Storage path
 	Operation   string `gorm:"type:varchar(20)" json:"operation"`                  // create/modify/revoke
+	Chain       string `gorm:"type:varchar(10);default:mvc" json:"chain"`          // mvc/btc
 
-	PreTxRaw string `gorm:"type:text" json:"pre_tx_raw"`    // Pre-transaction raw data
-	TxRaw    string `gorm:"type:text" json:"tx_raw"`        // Transaction raw data
-	Status   Status `gorm:"type:varchar(20)" json:"status"` // pending/success/failed
+	PreTxRaw    string  `gorm:"type:text" json:"pre_tx_raw"`            // Pre-transaction raw data (BTC: commit PSBT, base64)
+	TxRaw       string  `gorm:"type:text" json:"tx_raw"`                // Transaction raw data
+	CommitTxId  string  `gorm:"type:varchar(64)" json:"commit_tx_id"`   // BTC commit transaction ID
+	RevealTxRaw string  `gorm:"type:mediumtext" json:"reveal_tx_raw"`   // BTC signed reveal transaction (hex)
+	RevealKey   string  `gorm:"type:varchar(255)" json:"-"`             // BTC one-time reveal key (encrypted), kept to sign the reveal again
+	Status      Status  `gorm:"type:varchar(20)" json:"status"`         // pending/success (file transaction confirmed)/failed
+	TxState     TxState `gorm:"index;type:varchar(20)" json:"tx_state"` // Broadcast state of the file transaction (see FileTx)
 
 	BlockHeight int64     `gorm:"index" json:"block_height"`        // Block height
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"` // Creation time
@@ -58,3 +69,10 @@ type File struct {
 func (File) TableName() string {
 	return "tb_file"
 }
+
+// Committed whether the node accepted the file transaction
+// Status stays pending until the transaction is confirmed, a committed file is not built or committed again.
+// Records from before broadcast tracking have no TxState, they are committed once successful.
+func (f *File) Committed() bool {
+	return f.Status == StatusSuccess || f.TxState.Accepted()
+}
@@ -3,9 +3,13 @@ package model
 import "time"
 
 // FileChunk file chunk metadata model
+// Each chunk of a multi-chunk upload is inscribed as its own PIN. TxID holds a placeholder
+// until the chunk transaction is committed.
 type FileChunk struct {
 	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
 
+	FileId string `gorm:"index;type:varchar(255)" json:"file_id"` // Belonging file ID (metaid_filehash)
+
 	ChunkHash  string `gorm:"type:varchar(255)" json:"chunk_hash"` // Chunk hash
 	ChunkSize  int64  `json:"chunk_size"`                          // Chunk size
 	ChunkMd5   string `gorm:"type:varchar(255)" json:"chunk_md5"`  // Chunk MD5
@@ -19,7 +23,7 @@ type FileChunk struct {
 	Path        string `gorm:"index;type:varchar(255);not null" json:"path"`       // MetaID path
 	ContentType string `gorm:"type:varchar(100)" json:"content_type"`              // Content type
 	Size        int64  `json:"size"`                                               // File size
-	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`               // local/oss
+	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`               // local/oss/s3
 	StoragePath string `gorm:"type:varchar(500)" json:"storage_path"`              // Storage path
 	Operation   string `gorm:"type:varchar(20)" json:"operation"`                  // create/modify/revoke
 
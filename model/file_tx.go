@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+// TxState broadcast state of an upload transaction
+type TxState string
+
+const (
+	TxStateBuilt     TxState = "built"      // Built, waiting for the payer signature
+	TxStateSigned    TxState = "signed"     // Signed and verified, not accepted by the node yet
+	TxStateBroadcast TxState = "broadcast"  // Accepted by the node
+	TxStateMempool   TxState = "in_mempool" // Seen in the node mempool by the tracker
+	TxStateConfirmed TxState = "confirmed"  // Mined, BlockHeight is set (0 when mined before the searched blocks)
+	TxStateFailed    TxState = "failed"     // Rejected by the node, or dropped and not accepted again
+	TxStateReplaced  TxState = "replaced"   // Its inputs were spent by another transaction
+)
+
+// Accepted whether the node accepted a transaction in this state: broadcast, in the mempool or confirmed
+func (s TxState) Accepted() bool {
+	return s == TxStateBroadcast || s == TxStateMempool || s == TxStateConfirmed
+}
+
+// Roles of the transactions of an upload
+const (
+	TxRoleFile   = "file"   // PIN transaction of the file (index transaction for chunked files, reveal for BTC)
+	TxRoleChunk  = "chunk"  // PIN transaction of a chunk
+	TxRoleMerge  = "merge"  // UTXO merge transaction broadcast before the file transaction
+	TxRoleCommit = "commit" // BTC commit transaction
+)
+
+// FileTx transaction broadcast for an upload
+// Broadcast and in-mempool transactions are followed by the transaction tracker until they are mined,
+// or rebroadcast when the node drops them.
+type FileTx struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	FileId     string  `gorm:"index;type:varchar(255)" json:"file_id"`             // Belonging file ID (metaid_filehash)
+	TxId       string  `gorm:"uniqueIndex;type:varchar(64);not null" json:"tx_id"` // Transaction ID
+	Chain      string  `gorm:"type:varchar(10);default:mvc" json:"chain"`          // mvc/btc
+	Role       string  `gorm:"type:varchar(20)" json:"role"`                       // file/chunk/merge/commit
+	ChunkIndex int64   `json:"chunk_index"`                                        // Chunk index, -1 unless the role is chunk
+	TxRaw      string  `gorm:"type:mediumtext" json:"-"`                           // Signed transaction (hex), kept for rebroadcast
+	State      TxState `gorm:"index;type:varchar(20)" json:"state"`                // broadcast/in_mempool/confirmed/failed/replaced
+
+	BlockHeight  int64     `json:"block_height"`                     // Block height, once confirmed
+	Rebroadcasts int64     `json:"rebroadcasts"`                     // Times the tracker broadcast it again
+	Message      string    `gorm:"type:varchar(500)" json:"message"` // Last broadcast error
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"` // Creation time
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"` // Update time
+}
+
+// TableName specify table name
+func (FileTx) TableName() string {
+	return "tb_file_tx"
+}
+
+// FileTxEvent state change of an upload, the history of its transactions
+type FileTxEvent struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	FileId      string    `gorm:"index;type:varchar(255)" json:"file_id"` // Belonging file ID (metaid_filehash)
+	TxId        string    `gorm:"type:varchar(64)" json:"tx_id"`          // Transaction ID, empty while it is not signed
+	State       TxState   `gorm:"type:varchar(20)" json:"state"`          // State entered
+	BlockHeight int64     `json:"block_height"`                           // Block height (confirmed)
+	Message     string    `gorm:"type:varchar(500)" json:"message"`       // Detail, e.g. the broadcast error
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`       // Creation time
+}
+
+// TableName specify table name
+func (FileTxEvent) TableName() string {
+	return "tb_file_tx_event"
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// IndexerBlob content-addressed storage blob with reference counting
+// Used when storage layout is "cas": records sharing the same FileHash point at one blob.
+type IndexerBlob struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	FileHash    string `gorm:"uniqueIndex;type:varchar(64);not null" json:"file_hash"` // File Hash SHA256
+	StoragePath string `gorm:"type:varchar(500)" json:"storage_path"`                  // Storage path of the shared blob
+	FileSize    int64  `json:"file_size"`                                              // File size
+	RefCount    int64  `gorm:"type:bigint;not null;default:0" json:"ref_count"`        // Number of records referencing this blob
+
+	// Timestamps
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"` // Creation time
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"` // Update time
+}
+
+// TableName specify table name
+func (IndexerBlob) TableName() string {
+	return "tb_indexer_blob"
+}
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// Block types
+const (
+	BlockTypePin     = "pin"     // A single PIN
+	BlockTypeHash    = "hash"    // Content SHA256, also applied to PINs indexed later
+	BlockTypeAddress = "address" // Creator address
+	BlockTypeMetaID  = "metaid"  // Creator MetaID
+)
+
+// Block audit actions
+const (
+	BlockActionBlock   = "block"   // Entry added
+	BlockActionUnblock = "unblock" // Entry removed
+	BlockActionMatch   = "match"   // Hash block applied to a newly indexed PIN
+)
+
+// IndexerBlock moderation blocklist entry
+// Blocked content is not served and hidden from list results.
+type IndexerBlock struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	BlockType string `gorm:"uniqueIndex:uk_type_value;type:varchar(16);not null" json:"block_type"` // pin/hash/address/metaid
+	Value     string `gorm:"uniqueIndex:uk_type_value;type:varchar(255);not null" json:"value"`     // Blocked PIN ID, hash, address or MetaID
+	Reason    string `gorm:"type:varchar(1024)" json:"reason"`                                      // Reason, e.g. complaint reference
+	Operator  string `gorm:"type:varchar(255)" json:"operator"`                                     // Who added the entry
+
+	// Timestamps
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"` // Creation time
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"` // Update time
+}
+
+// TableName specify table name
+func (IndexerBlock) TableName() string {
+	return "tb_indexer_block"
+}
+
+// BlockKey blocklist lookup key
+type BlockKey struct {
+	BlockType string
+	Value     string
+}
+
+// IndexerBlockAudit moderation audit log entry
+// Entries are append-only, they outlive the blocks they refer to.
+type IndexerBlockAudit struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	BlockID   int64     `gorm:"index" json:"block_id"`                       // Blocklist entry ID
+	Action    string    `gorm:"type:varchar(16);not null" json:"action"`     // block/unblock/match
+	BlockType string    `gorm:"type:varchar(16);not null" json:"block_type"` // pin/hash/address/metaid
+	Value     string    `gorm:"type:varchar(255);not null" json:"value"`     // Blocked PIN ID, hash, address or MetaID
+	PinID     string    `gorm:"index;type:varchar(255)" json:"pin_id"`       // PIN a hash block was applied to (match only)
+	Reason    string    `gorm:"type:varchar(1024)" json:"reason"`            // Reason given for the action
+	Operator  string    `gorm:"type:varchar(255)" json:"operator"`           // Who performed the action (indexer for matches)
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`            // Action time
+}
+
+// TableName specify table name
+func (IndexerBlockAudit) TableName() string {
+	return "tb_indexer_block_audit"
+}
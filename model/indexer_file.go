@@ -2,6 +2,10 @@ package model
 
 import "time"
 
+// StorageTypeChain content is not mirrored, it is extracted from the transaction on read
+// StoragePath is still set and used as the cache location when caching on read is enabled.
+const StorageTypeChain = "chain"
+
 // IndexerFile indexer file metadata model (for indexer service)
 type IndexerFile struct {
 	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -15,7 +19,11 @@ type IndexerFile struct {
 	ParentPath  string `gorm:"type:varchar(500)" json:"parent_path"`                 // Parent path
 	Encryption  string `gorm:"type:varchar(50)" json:"encryption"`                   // Encryption method
 	Version     string `gorm:"type:varchar(50)" json:"version"`                      // Version
-	ContentType string `gorm:"type:varchar(100)" json:"content_type"`                // Content type
+	ContentType string `gorm:"type:varchar(100)" json:"content_type"`                // Content type (declared in PIN)
+
+	// Content type detection fields
+	DetectedContentType string `gorm:"type:varchar(100)" json:"detected_content_type"` // Content type detected from file signature
+	ContentTypeMismatch bool   `gorm:"index" json:"content_type_mismatch"`             // Detected content contradicts declared type
 
 	// File related fields
 	FileType      string `gorm:"type:varchar(20)" json:"file_type"`      // File type (image/video/audio/document/other)
@@ -25,8 +33,14 @@ type IndexerFile struct {
 	FileMd5       string `gorm:"type:varchar(64)" json:"file_md5"`       // File MD5
 	FileHash      string `gorm:"type:varchar(64)" json:"file_hash"`      // File Hash SHA256
 
+	// Image placeholder fields (images only)
+	BlurHash      string `gorm:"type:varchar(64)" json:"blur_hash"`     // BlurHash string
+	DominantColor string `gorm:"type:varchar(7)" json:"dominant_color"` // Dominant color (#rrggbb)
+	// Content of a decodable image type failed to decode, placeholder and perceptual hash are not computed
+	ImageDecodeFailed bool `gorm:"default:false" json:"image_decode_failed"`
+
 	// Storage related fields
-	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`  // local/oss
+	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`  // local/oss/s3/chain
 	StoragePath string `gorm:"type:varchar(500)" json:"storage_path"` // Storage path
 
 	// Blockchain related fields
@@ -21,7 +21,7 @@ type IndexerFileChunk struct {
 	ParentPinID string `gorm:"index;type:varchar(255)" json:"parent_pin_id"` // Parent file PIN ID
 
 	// Storage related fields
-	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`  // local/oss
+	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`  // local/oss/s3
 	StoragePath string `gorm:"type:varchar(500)" json:"storage_path"` // Storage path
 
 	// Blockchain related fields
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ImageHashBands number of 16-bit bands a perceptual hash is split into for lookup
+// Two hashes within Hamming distance d differ by at most d/ImageHashBands bits in some band.
+const ImageHashBands = 4
+
+// IndexerImageHash perceptual hash (dHash) of an image PIN
+// Band0-Band3 hold the hash split into 16-bit bands, indexed for Hamming distance lookup.
+type IndexerImageHash struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	PinID    string `gorm:"uniqueIndex;type:varchar(255);not null" json:"pin_id"` // PIN ID
+	FileHash string `gorm:"index;type:varchar(64)" json:"file_hash"`              // File Hash SHA256
+	Hash     string `gorm:"type:varchar(16);not null" json:"hash"`                // 64-bit dHash (hex)
+
+	// Lookup bands (bits 63-48, 47-32, 31-16, 15-0)
+	Band0 int `gorm:"index" json:"band0"`
+	Band1 int `gorm:"index" json:"band1"`
+	Band2 int `gorm:"index" json:"band2"`
+	Band3 int `gorm:"index" json:"band3"`
+
+	// Timestamps
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"` // Creation time
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"` // Update time
+}
+
+// TableName specify table name
+func (IndexerImageHash) TableName() string {
+	return "tb_indexer_image_hash"
+}
+
+// NewIndexerImageHash create image hash record with lookup bands filled
+func NewIndexerImageHash(pinID, fileHash string, hash uint64) *IndexerImageHash {
+	bands := SplitImageHash(hash)
+	return &IndexerImageHash{
+		PinID:    pinID,
+		FileHash: fileHash,
+		Hash:     fmt.Sprintf("%016x", hash),
+		Band0:    bands[0],
+		Band1:    bands[1],
+		Band2:    bands[2],
+		Band3:    bands[3],
+	}
+}
+
+// Value parse the hex hash
+func (h *IndexerImageHash) Value() (uint64, error) {
+	return strconv.ParseUint(h.Hash, 16, 64)
+}
+
+// Bands lookup band values
+func (h *IndexerImageHash) Bands() [ImageHashBands]int {
+	return [ImageHashBands]int{h.Band0, h.Band1, h.Band2, h.Band3}
+}
+
+// SplitImageHash split hash into 16-bit bands, most significant first
+func SplitImageHash(hash uint64) [ImageHashBands]int {
+	var bands [ImageHashBands]int
+	for i := range bands {
+		bands[i] = int(hash >> (48 - 16*i) & 0xffff)
+	}
+	return bands
+}
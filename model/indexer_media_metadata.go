@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// IndexerMediaMetadata image/video/audio metadata extracted at index time
+// GPS coordinates are never stored; HasGPS only flags that the source contains them.
+type IndexerMediaMetadata struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	PinID    string `gorm:"uniqueIndex;type:varchar(255);not null" json:"pin_id"` // PIN ID
+	FileHash string `gorm:"index;type:varchar(64)" json:"file_hash"`              // File Hash SHA256
+
+	MediaType string `gorm:"type:varchar(20)" json:"media_type"` // Media type: image/video/audio
+	Format    string `gorm:"type:varchar(20)" json:"format"`     // Container format: jpeg/png/gif/webp/mp4/mov/webm/mp3/flac/wav
+
+	// Visual
+	Width       int `json:"width"`       // Width in pixels
+	Height      int `json:"height"`      // Height in pixels
+	Orientation int `json:"orientation"` // EXIF orientation 1-8 (0 = unknown)
+	FrameCount  int `json:"frame_count"` // Number of frames (>1 = animated)
+
+	// Timing
+	DurationMs int64 `json:"duration_ms"` // Duration in milliseconds
+	Bitrate    int64 `json:"bitrate"`     // Average bitrate (bits per second)
+
+	// Codecs
+	VideoCodec string `gorm:"type:varchar(50)" json:"video_codec"` // Video codec: avc1/hvc1/VP9...
+	AudioCodec string `gorm:"type:varchar(50)" json:"audio_codec"` // Audio codec: mp4a/OPUS/mp3/flac/pcm...
+	SampleRate int    `json:"sample_rate"`                         // Audio sample rate (Hz)
+	Channels   int    `json:"channels"`                            // Audio channels
+
+	// EXIF
+	CameraMake  string `gorm:"type:varchar(100)" json:"camera_make"`  // Camera manufacturer
+	CameraModel string `gorm:"type:varchar(100)" json:"camera_model"` // Camera model
+	CaptureTime string `gorm:"type:varchar(32)" json:"capture_time"`  // Capture time (EXIF DateTimeOriginal)
+	HasGPS      bool   `json:"has_gps"`                               // GPS data present in source (stripped)
+
+	// Tags
+	Title  string `gorm:"type:varchar(255)" json:"title"`  // Title tag
+	Artist string `gorm:"type:varchar(255)" json:"artist"` // Artist tag
+	Album  string `gorm:"type:varchar(255)" json:"album"`  // Album tag
+
+	// Timestamps
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"` // Creation time
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"` // Update time
+}
+
+// TableName specify table name
+func (IndexerMediaMetadata) TableName() string {
+	return "tb_indexer_media_metadata"
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// SponsoredUpload upload funded and signed by the sponsor wallet
+type SponsoredUpload struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	FileId      string `gorm:"index;type:varchar(255)" json:"file_id"`               // File ID
+	MetaId      string `gorm:"index:idx_meta_day;type:varchar(64)" json:"meta_id"`   // MetaID of the user
+	Address     string `gorm:"type:varchar(100)" json:"address"`                     // User address, owner of the PINs
+	Day         string `gorm:"index:idx_meta_day;index;type:varchar(10)" json:"day"` // Budget day (UTC, 2006-01-02)
+	ContentType string `gorm:"type:varchar(100)" json:"content_type"`                // Content type
+	FileSize    int64  `json:"file_size"`                                            // File size charged to the byte budget
+	TxCount     int64  `json:"tx_count"`                                             // Transactions paid (chunks and index)
+	Fee         int64  `json:"fee"`                                                  // Satoshis paid: fees and owner outputs
+	TxId        string `gorm:"type:varchar(64)" json:"tx_id"`                        // File (or index) transaction ID
+	PinId       string `gorm:"type:varchar(100)" json:"pin_id"`                      // File Pin ID
+	Status      Status `gorm:"type:varchar(20)" json:"status"`                       // pending/success/failed
+	Message     string `gorm:"type:varchar(500)" json:"message"`                     // Failure reason
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"` // Creation time
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"` // Update time
+}
+
+// TableName specify table name
+func (SponsoredUpload) TableName() string {
+	return "tb_sponsored_upload"
+}
+
+// SponsorBudget sponsored usage of a MetaID on one day (UTC)
+type SponsorBudget struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	MetaId string `gorm:"uniqueIndex:idx_meta_day;type:varchar(64);not null" json:"meta_id"` // MetaID of the user
+	Day    string `gorm:"uniqueIndex:idx_meta_day;type:varchar(10);not null" json:"day"`     // Budget day (UTC, 2006-01-02)
+	Bytes  int64  `gorm:"default:0" json:"bytes"`                                            // File bytes sponsored
+	Fee    int64  `gorm:"default:0" json:"fee"`                                              // Satoshis spent
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"` // Creation time
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"` // Update time
+}
+
+// TableName specify table name
+func (SponsorBudget) TableName() string {
+	return "tb_sponsor_budget"
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// TusUpload resumable upload staged through the tus protocol
+// Received bytes are stored as parts under uploads/tus/{upload_id}/, Parts lists their offsets.
+type TusUpload struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	UploadId     string `gorm:"uniqueIndex;type:varchar(64);not null" json:"upload_id"` // Upload ID used in the upload URL
+	UploadLength int64  `json:"upload_length"`                                          // Total size (bytes)
+	UploadOffset int64  `json:"upload_offset"`                                          // Bytes received so far
+	Parts        string `gorm:"type:text" json:"parts"`                                 // JSON array of part start offsets
+	Metadata     string `gorm:"type:text" json:"metadata"`                              // Raw Upload-Metadata header
+	FileName     string `gorm:"type:varchar(255)" json:"file_name"`                     // File name from metadata
+	ContentType  string `gorm:"type:varchar(100)" json:"content_type"`                  // Content type from metadata
+
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`          // Staged bytes are deleted after this time
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"` // Creation time
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"` // Update time
+}
+
+// TableName specify table name
+func (TusUpload) TableName() string {
+	return "tb_tus_upload"
+}
+
+// Complete check if all bytes are received
+func (u *TusUpload) Complete() bool {
+	return u.UploadOffset == u.UploadLength
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// UtxoReservation payer UTXO selected as an input of a pending upload transaction
+// A reserved outpoint is not selected for other uploads until the reservation is released or expires.
+type UtxoReservation struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TxId         string `gorm:"uniqueIndex:idx_outpoint;type:varchar(64);not null" json:"tx_id"` // Transaction ID of the output
+	Vout         uint32 `gorm:"uniqueIndex:idx_outpoint;not null" json:"vout"`                   // Output index
+	Address      string `gorm:"type:varchar(100)" json:"address"`                                // Payer address
+	Amount       int64  `json:"amount"`                                                          // Output amount (satoshis)
+	ScriptPubKey string `gorm:"type:varchar(255)" json:"script_pub_key"`                         // Output locking script (hex)
+	FileId       string `gorm:"index;type:varchar(255)" json:"file_id"`                          // File the reserving transaction belongs to
+	TxIndex      int64  `json:"tx_index"`                                                        // Chunk index, -1 for the file (or index) transaction
+
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`          // Reservation is ignored after this time
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"` // Creation time
+}
+
+// TableName specify table name
+func (UtxoReservation) TableName() string {
+	return "tb_utxo_reservation"
+}
@@ -62,6 +62,20 @@ func (c *ClientController) BroadcastTx(net, txHexStr string) (string, error) {
 	return result.String(), nil
 }
 
+// BroadcastRawTx broadcast a transaction with only its hex parameter, for nodes whose second
+// sendrawtransaction parameter is not allowhighfees (BTC takes maxfeerate)
+func (c *ClientController) BroadcastRawTx(net, txHexStr string) (string, error) {
+	request := []interface{}{
+		txHexStr,
+	}
+
+	result, err := c.ClientMap[net].Call("sendrawtransaction", request)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
 // BroadcastTxBatch batch broadcast transactions
 // supports single transaction or transaction array
 func (c *ClientController) BroadcastTxBatch(net string, txHexStrs ...string) (*SendRawTransactionsResult, error) {
@@ -265,3 +279,29 @@ func (c *ClientController) GetMempool(net string) ([]string, error) {
 
 	return txIds, nil
 }
+
+// ListUnspent list spendable outputs of an address (listunspent, minconf 0)
+// The node wallet must watch the address (importaddress), it stands in for an address index.
+func (c *ClientController) ListUnspent(net string, address string) ([]*Unspent, error) {
+	request := []interface{}{
+		0,
+		9999999,
+		[]string{address},
+	}
+
+	result, err := c.ClientMap[net].Call("listunspent", request)
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.IsArray() {
+		return nil, errors.New("no query record")
+	}
+
+	unspents := make([]*Unspent, 0)
+	for _, item := range result.Array() {
+		unspents = append(unspents, NewUnspent(&item))
+	}
+
+	return unspents, nil
+}
@@ -1,6 +1,10 @@
 package node
 
 import (
+	"fmt"
+	"math"
+	"strconv"
+
 	"github.com/tidwall/gjson"
 )
 
@@ -471,3 +475,12 @@ func NewSendRawTransactionsResult(json *gjson.Result) *SendRawTransactionsResult
 
 	return result
 }
+
+// Satoshis amount of the output in satoshis
+func (u *Unspent) Satoshis() (int64, error) {
+	amount, err := strconv.ParseFloat(u.Amount, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid unspent amount %q: %w", u.Amount, err)
+	}
+	return int64(math.Round(amount * math.Pow10(int(Decimals)))), nil
+}
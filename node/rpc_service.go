@@ -21,6 +21,11 @@ func BroadcastTx(chain, txHex string) (string, error) {
 	return txId, err
 }
 
+func BroadcastRawTx(chain, txHex string) (string, error) {
+	client := NewClientController(chain)
+	return client.BroadcastRawTx(chain, txHex)
+}
+
 func BroadcastTxBatch(chain string, txHexStrs ...string) (*SendRawTransactionsResult, error) {
 	client := NewClientController(chain)
 	return client.BroadcastTxBatch(chain, txHexStrs...)
@@ -35,3 +40,22 @@ func GetMempool(chain string) ([]string, error) {
 	client := NewClientController(chain)
 	return client.GetMempool(chain)
 }
+
+func ListUnspent(chain, address string) ([]*Unspent, error) {
+	client := NewClientController(chain)
+	return client.ListUnspent(chain, address)
+}
+
+func GetBlockhash(chain string, height uint64) (string, error) {
+	client := NewClientController(chain)
+	return client.GetBlockhash(chain, height)
+}
+
+func GetBlockTxIds(chain, hash string) ([]string, error) {
+	client := NewClientController(chain)
+	block, err := client.GetBlock(chain, hash, 1)
+	if err != nil {
+		return nil, err
+	}
+	return block.Tx, nil
+}
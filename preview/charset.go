@@ -0,0 +1,64 @@
+package preview
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// DecodeText transcode text content to UTF-8
+// A byte order mark or a charset declared in the content type wins. Otherwise valid UTF-8 is kept,
+// an HTML/XML meta declaration is honored, GB18030 (a superset of GBK) is tried and windows-1252 is the fallback.
+// Returns the text without byte order mark and the name of the source charset.
+func DecodeText(content []byte, contentType string) (string, string) {
+	text, name := decodeText(content, contentType)
+	return strings.TrimPrefix(text, "\uFEFF"), name
+}
+
+// decodeText transcode text content to UTF-8, see DecodeText
+func decodeText(content []byte, contentType string) (string, string) {
+	encoding, name, certain := charset.DetermineEncoding(content, contentType)
+	if !certain {
+		switch {
+		case utf8.Valid(content):
+			return string(content), "utf-8"
+		case name == "windows-1252" && isGB18030(content):
+			return decodeWith(content, simplifiedchinese.GB18030.NewDecoder().Bytes), "gb18030"
+		}
+	}
+	if name == "utf-8" {
+		return string(content), name
+	}
+	return decodeWith(content, encoding.NewDecoder().Bytes), name
+}
+
+// decodeWith transcode content with a decoder, keeping the input on failure
+func decodeWith(content []byte, decode func([]byte) ([]byte, error)) string {
+	decoded, err := decode(content)
+	if err != nil {
+		return string(content)
+	}
+	return string(decoded)
+}
+
+// isGB18030 check if non-ASCII bytes form valid GBK double-byte sequences
+// Latin-1 text rarely passes: its accented letters are followed by ASCII, which is not a valid trail byte.
+func isGB18030(content []byte) bool {
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if c < 0x80 {
+			continue
+		}
+		if c == 0x80 || c == 0xFF || i+1 == len(content) {
+			return false
+		}
+		trail := content[i+1]
+		if trail < 0x40 || trail == 0x7F || trail == 0xFF {
+			return false
+		}
+		i++
+	}
+	return true
+}
@@ -0,0 +1,398 @@
+package preview
+
+import (
+	"html"
+	"strings"
+)
+
+// Token classes of highlighted code (span class="tok-...")
+const (
+	tokComment = "comment"
+	tokString  = "string"
+	tokNumber  = "number"
+	tokKeyword = "keyword"
+	tokLiteral = "literal"
+	tokTag     = "tag"
+	tokAttr    = "attr"
+)
+
+// language lexical rules of a highlighted language
+// A single-pass scanner is enough to tell comments, strings, numbers and keywords apart,
+// which is all a preview needs. Languages with markup syntax use highlightMarkup instead.
+type language struct {
+	lineComments    []string
+	blockComments   [][2]string
+	quotes          string // String delimiters
+	multilineQuotes string // Delimiters whose strings may span lines
+	tripleQuotes    bool   // Python-style """ and ''' strings
+	caseInsensitive bool   // Keywords match in any case (SQL)
+	markup          bool
+	keywords        map[string]bool
+	literals        map[string]bool
+}
+
+// words set of space-separated words
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(list) {
+		set[word] = true
+	}
+	return set
+}
+
+const (
+	jsKeywords = "break case catch class const continue debugger default delete do else export extends finally for function " +
+		"if import in instanceof let new return super switch this throw try typeof var void while with yield async await of static get set"
+	cKeywords = "auto break case char const continue default do double else enum extern float for goto if inline int long " +
+		"register restrict return short signed sizeof static struct switch typedef union unsigned void volatile while"
+)
+
+var (
+	cLineComments  = []string{"//"}
+	cBlockComments = [][2]string{{"/*", "*/"}}
+)
+
+// languages highlighted languages by name
+var languages = map[string]*language{
+	"go": {
+		lineComments: cLineComments, blockComments: cBlockComments, quotes: "\"'`", multilineQuotes: "`",
+		keywords: words("break case chan const continue default defer else fallthrough for func go goto if import interface " +
+			"map package range return select struct switch type var"),
+		literals: words("true false nil iota"),
+	},
+	"javascript": {
+		lineComments: cLineComments, blockComments: cBlockComments, quotes: "\"'`", multilineQuotes: "`",
+		keywords: words(jsKeywords),
+		literals: words("true false null undefined NaN Infinity"),
+	},
+	"typescript": {
+		lineComments: cLineComments, blockComments: cBlockComments, quotes: "\"'`", multilineQuotes: "`",
+		keywords: words(jsKeywords + " interface type enum implements private protected public readonly declare namespace " +
+			"abstract as keyof any number string boolean never unknown"),
+		literals: words("true false null undefined NaN Infinity"),
+	},
+	"python": {
+		lineComments: []string{"#"}, quotes: "\"'", tripleQuotes: true,
+		keywords: words("and as assert async await break class continue def del elif else except finally for from global if " +
+			"import in is lambda nonlocal not or pass raise return try while with yield match case"),
+		literals: words("True False None"),
+	},
+	"rust": {
+		lineComments: cLineComments, blockComments: cBlockComments, quotes: "\"",
+		keywords: words("as async await break const continue crate dyn else enum extern fn for if impl in let loop match mod " +
+			"move mut pub ref return self Self static struct super trait type unsafe use where while"),
+		literals: words("true false"),
+	},
+	"java": {
+		lineComments: cLineComments, blockComments: cBlockComments, quotes: "\"'",
+		keywords: words("abstract assert boolean break byte case catch char class const continue default do double else enum " +
+			"extends final finally float for goto if implements import instanceof int interface long native new package " +
+			"private protected public record return short static strictfp super switch synchronized this throw throws " +
+			"transient try var void volatile while"),
+		literals: words("true false null"),
+	},
+	"kotlin": {
+		lineComments: cLineComments, blockComments: cBlockComments, quotes: "\"'",
+		keywords: words("as break by class companion constructor continue data do else for fun if in interface internal is " +
+			"object open override package private public return sealed super suspend this throw try typealias val var when while"),
+		literals: words("true false null"),
+	},
+	"c": {
+		lineComments: cLineComments, blockComments: cBlockComments, quotes: "\"'",
+		keywords: words(cKeywords),
+		literals: words("NULL true false"),
+	},
+	"cpp": {
+		lineComments: cLineComments, blockComments: cBlockComments, quotes: "\"'",
+		keywords: words(cKeywords + " bool catch class const_cast constexpr delete dynamic_cast explicit friend mutable " +
+			"namespace new noexcept operator override private protected public reinterpret_cast static_cast template this " +
+			"throw try typename using virtual"),
+		literals: words("NULL nullptr true false"),
+	},
+	"csharp": {
+		lineComments: cLineComments, blockComments: cBlockComments, quotes: "\"'",
+		keywords: words("abstract as async await base bool break byte case catch char checked class const continue decimal " +
+			"default delegate do double else enum event explicit extern finally fixed float for foreach goto if implicit in " +
+			"int interface internal is lock long namespace new object operator out override params private protected public " +
+			"readonly ref return sbyte sealed short sizeof stackalloc static string struct switch this throw try typeof uint " +
+			"ulong unchecked unsafe ushort using var virtual void volatile while"),
+		literals: words("true false null"),
+	},
+	"php": {
+		lineComments: []string{"//", "#"}, blockComments: cBlockComments, quotes: "\"'",
+		keywords: words("abstract and array as break case catch class clone const continue declare default do echo else " +
+			"elseif empty extends final finally fn for foreach function global if implements include instanceof interface " +
+			"isset list namespace new or print private protected public require require_once return static switch throw " +
+			"trait try unset use var while yield"),
+		literals: words("true false null TRUE FALSE NULL"),
+	},
+	"ruby": {
+		lineComments: []string{"#"}, quotes: "\"'",
+		keywords: words("alias and begin break case class def do else elsif end ensure for if in module next not or redo " +
+			"require rescue retry return self super then undef unless until when while yield"),
+		literals: words("true false nil"),
+	},
+	"shell": {
+		lineComments: []string{"#"}, quotes: "\"'",
+		keywords: words("alias break case continue declare do done echo elif else esac exit export fi for function if in " +
+			"local readonly return set shift source then unset until while"),
+		literals: words("true false"),
+	},
+	"sql": {
+		lineComments: []string{"--"}, blockComments: cBlockComments, quotes: "'\"`", caseInsensitive: true,
+		keywords: words("select from where and or not insert into values update set delete create table drop alter index " +
+			"primary key foreign references join left right inner outer on group by order having limit offset as distinct " +
+			"union all case when then else end is like in exists between default unique view begin commit rollback"),
+		literals: words("null true false"),
+	},
+	"css": {
+		blockComments: cBlockComments, quotes: "\"'",
+	},
+	"yaml": {
+		lineComments: []string{"#"}, quotes: "\"'",
+		literals: words("true false null yes no on off"),
+	},
+	"toml": {
+		lineComments: []string{"#"}, quotes: "\"'",
+		literals: words("true false"),
+	},
+	"solidity": {
+		lineComments: cLineComments, blockComments: cBlockComments, quotes: "\"'",
+		keywords: words("address assert bool break bytes calldata constant continue contract delete do else emit enum event " +
+			"external for function if immutable import int interface internal is library mapping memory modifier new " +
+			"override payable pragma private public pure require return returns revert storage string struct uint using " +
+			"view virtual while"),
+		literals: words("true false"),
+	},
+	"swift": {
+		lineComments: cLineComments, blockComments: cBlockComments, quotes: "\"",
+		keywords: words("as break case catch class continue default deinit do else enum extension fileprivate for func guard " +
+			"if import in init internal is let mutating open override private protocol public repeat return self Self static " +
+			"struct super switch throw throws try var where while"),
+		literals: words("true false nil"),
+	},
+	"lua": {
+		// Block comments come first, "--[[" also starts with the line comment marker
+		lineComments: []string{"--"}, blockComments: [][2]string{{"--[[", "]]"}}, quotes: "\"'",
+		keywords: words("and break do else elseif end for function goto if in local not or repeat return then until while"),
+		literals: words("true false nil"),
+	},
+	"json": {
+		quotes:   "\"",
+		literals: words("true false null"),
+	},
+	"html": {markup: true},
+	"xml":  {markup: true},
+}
+
+// highlight render source code as a highlighted <pre> block
+func highlight(source, lang string) string {
+	var b strings.Builder
+	b.WriteString(`<pre class="preview-code language-` + lang + `"><code>`)
+	if l, ok := languages[lang]; ok && l.markup {
+		highlightMarkup(&b, source)
+	} else if ok {
+		highlightCode(&b, source, l)
+	} else {
+		b.WriteString(html.EscapeString(source))
+	}
+	b.WriteString("</code></pre>")
+	return b.String()
+}
+
+// span write a highlighted token
+func span(b *strings.Builder, class, text string) {
+	b.WriteString(`<span class="tok-` + class + `">` + html.EscapeString(text) + "</span>")
+}
+
+// highlightCode scan source with the lexical rules of a language
+func highlightCode(b *strings.Builder, source string, l *language) {
+	plainStart := 0
+	flush := func(end int) {
+		b.WriteString(html.EscapeString(source[plainStart:end]))
+	}
+
+	for i := 0; i < len(source); {
+		c := source[i]
+		start, end, class := i, 0, ""
+
+		switch {
+		case hasAnyPrefix(source[i:], l.blockComments) >= 0:
+			comment := l.blockComments[hasAnyPrefix(source[i:], l.blockComments)]
+			end = indexFrom(source, comment[1], i+len(comment[0])) + len(comment[1])
+			class = tokComment
+		case startsWithAny(source[i:], l.lineComments):
+			end = indexFrom(source, "\n", i)
+			class = tokComment
+		case l.tripleQuotes && (strings.HasPrefix(source[i:], `"""`) || strings.HasPrefix(source[i:], "'''")):
+			end = indexFrom(source, source[i:i+3], i+3) + 3
+			class = tokString
+		case strings.IndexByte(l.quotes, c) >= 0:
+			end = scanString(source, i, strings.IndexByte(l.multilineQuotes, c) >= 0)
+			class = tokString
+		case isDigit(c):
+			end = i + 1
+			for end < len(source) && (isIdentChar(source[end]) || source[end] == '.') {
+				end++
+			}
+			class = tokNumber
+		case isIdentStart(c):
+			end = i + 1
+			for end < len(source) && isIdentChar(source[end]) {
+				end++
+			}
+			word := source[i:end]
+			if l.caseInsensitive {
+				word = strings.ToLower(word)
+			}
+			switch {
+			case l.keywords[word]:
+				class = tokKeyword
+			case l.literals[word]:
+				class = tokLiteral
+			}
+		default:
+			i++
+			continue
+		}
+
+		if end > len(source) {
+			end = len(source)
+		}
+		if class != "" {
+			flush(start)
+			span(b, class, source[start:end])
+			plainStart = end
+		}
+		i = end
+	}
+	flush(len(source))
+}
+
+// highlightMarkup scan HTML/XML source for tags, attributes, strings and comments
+func highlightMarkup(b *strings.Builder, source string) {
+	plainStart := 0
+	flush := func(end int) {
+		b.WriteString(html.EscapeString(source[plainStart:end]))
+	}
+
+	for i := 0; i < len(source); {
+		// A < not followed by a name, "/", "!" or "?" is text
+		if source[i] != '<' || i+1 == len(source) || !(isIdentStart(source[i+1]) || strings.IndexByte("/!?", source[i+1]) >= 0) {
+			i++
+			continue
+		}
+		flush(i)
+
+		if strings.HasPrefix(source[i:], "<!--") {
+			end := indexFrom(source, "-->", i+4) + 3
+			if end > len(source) {
+				end = len(source)
+			}
+			span(b, tokComment, source[i:end])
+			i, plainStart = end, end
+			continue
+		}
+
+		// Tag name, including the leading < or </
+		end := i + 1
+		for end < len(source) && (isIdentChar(source[end]) || strings.IndexByte("/!?:-.", source[end]) >= 0) {
+			end++
+		}
+		span(b, tokTag, source[i:end])
+		i = end
+
+		// Attributes up to the closing >
+		for i < len(source) && source[i] != '>' {
+			switch c := source[i]; {
+			case c == '"' || c == '\'':
+				end := strings.IndexByte(source[i+1:], c)
+				if end < 0 {
+					end = len(source)
+				} else {
+					end += i + 2
+				}
+				span(b, tokString, source[i:end])
+				i = end
+			case isIdentStart(c):
+				end := i + 1
+				for end < len(source) && (isIdentChar(source[end]) || source[end] == '-' || source[end] == ':') {
+					end++
+				}
+				span(b, tokAttr, source[i:end])
+				i = end
+			default:
+				b.WriteString(html.EscapeString(source[i : i+1]))
+				i++
+			}
+		}
+		if i < len(source) {
+			span(b, tokTag, ">")
+			i++
+		}
+		plainStart = i
+	}
+	flush(len(source))
+}
+
+// scanString end of the string literal starting at start (after the closing quote)
+// Backslash escapes are honored; single-line strings end at the line end when unterminated.
+func scanString(source string, start int, multiline bool) int {
+	quote := source[start]
+	for i := start + 1; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			if !multiline {
+				return i
+			}
+		}
+	}
+	return len(source)
+}
+
+// indexFrom index of substr in s at or after from (len(s) if not found)
+func indexFrom(s, substr string, from int) int {
+	if from > len(s) {
+		return len(s)
+	}
+	if i := strings.Index(s[from:], substr); i >= 0 {
+		return from + i
+	}
+	return len(s)
+}
+
+// hasAnyPrefix index of the first delimiter pair whose opening delimiter starts s (-1 if none)
+func hasAnyPrefix(s string, pairs [][2]string) int {
+	for i, pair := range pairs {
+		if strings.HasPrefix(s, pair[0]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// startsWithAny check if s starts with any of the prefixes
+func startsWithAny(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
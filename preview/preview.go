@@ -0,0 +1,234 @@
+// Package preview renders text content (Markdown, JSON, source code, plain text) as embeddable HTML fragments.
+package preview
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"meta-media-service/filetype"
+	"meta-media-service/sanitize"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// Preview kinds
+const (
+	KindMarkdown = "markdown"
+	KindJSON     = "json"
+	KindCode     = "code"
+	KindText     = "text"
+)
+
+// binarySniffSize bytes inspected for binary content
+const binarySniffSize = 8192
+
+var (
+	ErrUnsupported = errors.New("content type has no preview")
+	ErrBinary      = errors.New("content is not text")
+)
+
+// languageExtensions highlighted language by file extension
+var languageExtensions = map[string]string{
+	".go": "go", ".js": "javascript", ".mjs": "javascript", ".cjs": "javascript", ".jsx": "javascript",
+	".ts": "typescript", ".tsx": "typescript", ".py": "python", ".rs": "rust", ".java": "java",
+	".kt": "kotlin", ".kts": "kotlin", ".c": "c", ".h": "c", ".cc": "cpp", ".cpp": "cpp", ".cxx": "cpp",
+	".hpp": "cpp", ".hh": "cpp", ".cs": "csharp", ".php": "php", ".rb": "ruby",
+	".sh": "shell", ".bash": "shell", ".zsh": "shell", ".sql": "sql",
+	".css": "css", ".scss": "css", ".less": "css", ".yaml": "yaml", ".yml": "yaml", ".toml": "toml",
+	".sol": "solidity", ".swift": "swift", ".lua": "lua",
+	".html": "html", ".htm": "html", ".xhtml": "html", ".xml": "xml", ".svg": "xml",
+}
+
+// languageContentTypes highlighted language by content type
+var languageContentTypes = map[string]string{
+	"text/javascript": "javascript", "application/typescript": "typescript", "text/typescript": "typescript",
+	"text/x-python": "python", "application/x-python": "python", "text/x-go": "go", "text/x-rust": "rust",
+	"text/x-java": "java", "text/x-java-source": "java", "text/x-kotlin": "kotlin",
+	"text/x-c": "c", "text/x-csrc": "c", "text/x-c++src": "cpp", "text/x-csharp": "csharp",
+	"application/x-httpd-php": "php", "text/x-php": "php", "text/x-ruby": "ruby",
+	"text/x-shellscript": "shell", "application/sql": "sql", "text/x-sql": "sql", "text/css": "css",
+	"application/yaml": "yaml", "application/x-yaml": "yaml", "text/yaml": "yaml", "text/x-yaml": "yaml",
+	"application/toml": "toml", "text/x-solidity": "solidity", "text/x-swift": "swift", "text/x-lua": "lua",
+	"text/html": "html", "application/xhtml+xml": "html", "text/xml": "xml", "application/xml": "xml",
+}
+
+// Options rendering options
+type Options struct {
+	MaxSize int    // Largest UTF-8 text rendered in bytes, longer text is truncated (0 = no limit)
+	FullURL string // Target of the "view full" link appended to truncated previews
+}
+
+// Preview rendered preview
+type Preview struct {
+	Kind      string // markdown/json/code/text
+	Language  string // Highlighted language of code (and json)
+	Charset   string // Detected source charset
+	HTML      string // Sanitized HTML fragment
+	Size      int    // Size of the source content in bytes
+	Truncated bool
+}
+
+// Classify preview kind and language of content by content type and file name
+// Returns ErrUnsupported for content types without a text preview.
+func Classify(contentType, fileName string) (string, string, error) {
+	contentType = filetype.Normalize(contentType)
+	ext := strings.ToLower(path.Ext(fileName))
+
+	switch {
+	case contentType == "text/markdown", contentType == "text/x-markdown", ext == ".md", ext == ".markdown":
+		return KindMarkdown, "", nil
+	case contentType == "application/json", strings.HasSuffix(contentType, "+json"), ext == ".json":
+		return KindJSON, "json", nil
+	}
+	if lang, ok := languageExtensions[ext]; ok {
+		return KindCode, lang, nil
+	}
+	if lang, ok := languageContentTypes[contentType]; ok {
+		return KindCode, lang, nil
+	}
+	if strings.HasSuffix(contentType, "+xml") {
+		return KindCode, "xml", nil
+	}
+	if strings.HasPrefix(contentType, "text/") || ext == ".txt" || ext == ".log" || ext == ".csv" {
+		return KindText, "", nil
+	}
+	return "", "", ErrUnsupported
+}
+
+// Render render text content as an HTML fragment
+// Markdown is converted and sanitized, JSON is pretty-printed, code is highlighted and
+// plain text is escaped. Returns ErrUnsupported or ErrBinary for content without a text preview.
+func Render(content []byte, contentType, fileName string, opts Options) (*Preview, error) {
+	kind, lang, err := Classify(contentType, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Preview{Kind: kind, Language: lang, Size: len(content)}
+
+	// Only the head of large files is decoded, a character takes at most 4 bytes in any supported charset
+	if opts.MaxSize > 0 && len(content) > 4*opts.MaxSize {
+		content = content[:4*opts.MaxSize]
+		p.Truncated = true
+	}
+
+	text, charsetName := DecodeText(content, contentType)
+	if isBinary(text) {
+		return nil, ErrBinary
+	}
+	p.Charset = charsetName
+
+	if opts.MaxSize > 0 && len(text) > opts.MaxSize {
+		text = truncate(text, opts.MaxSize)
+		p.Truncated = true
+	}
+
+	switch kind {
+	case KindMarkdown:
+		p.HTML = `<div class="preview-markdown">` + string(sanitize.HTML(renderMarkdown(text))) + `</div>`
+	case KindJSON:
+		// Truncated JSON cannot be parsed, it is highlighted as is
+		var indented bytes.Buffer
+		if !p.Truncated && json.Indent(&indented, []byte(text), "", "  ") == nil {
+			text = indented.String()
+		}
+		p.HTML = highlight(text, lang)
+	case KindCode:
+		p.HTML = highlight(text, lang)
+	default:
+		p.HTML = `<pre class="preview-text">` + html.EscapeString(text) + `</pre>`
+	}
+
+	if p.Truncated {
+		p.HTML += truncatedMarker(p.Size, opts.FullURL)
+	}
+	return p, nil
+}
+
+// isBinary check for NUL or many control characters at the start of text
+func isBinary(text string) bool {
+	if len(text) > binarySniffSize {
+		text = text[:binarySniffSize]
+	}
+	control := 0
+	for _, r := range text {
+		switch {
+		case r == 0:
+			return true
+		case r < 0x20 && r != '\n' && r != '\r' && r != '\t' && r != '\f' && r != '\v' && r != 0x1B:
+			control++
+		}
+	}
+	return control*10 > len(text)
+}
+
+// truncate cut text to at most maxSize bytes, preferably at a line end
+func truncate(text string, maxSize int) string {
+	cut := maxSize
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if line := strings.LastIndexByte(text[:cut], '\n'); line > cut/2 {
+		cut = line + 1
+	}
+	return text[:cut]
+}
+
+// truncatedMarker "view full" marker appended to truncated previews
+func truncatedMarker(size int, fullURL string) string {
+	marker := fmt.Sprintf(`<p class="preview-truncated">Preview truncated, the full file is %s.`, formatSize(size))
+	if fullURL != "" {
+		marker += ` <a href="` + html.EscapeString(fullURL) + `" rel="nofollow noopener noreferrer" target="_blank">View full file</a>`
+	}
+	return marker + "</p>"
+}
+
+// formatSize human-readable byte size
+func formatSize(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
+
+// renderMarkdown convert Markdown to (unsanitized) HTML, fenced code blocks are highlighted
+func renderMarkdown(text string) []byte {
+	renderer := &markdownRenderer{
+		HTMLRenderer: blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+			Flags: blackfriday.Smartypants | blackfriday.SmartypantsFractions | blackfriday.SmartypantsDashes,
+		}),
+	}
+	extensions := blackfriday.NoIntraEmphasis | blackfriday.Tables | blackfriday.FencedCode | blackfriday.Autolink |
+		blackfriday.Strikethrough | blackfriday.SpaceHeadings | blackfriday.BackslashLineBreak | blackfriday.DefinitionLists
+	return blackfriday.Run([]byte(text), blackfriday.WithRenderer(renderer), blackfriday.WithExtensions(extensions))
+}
+
+// markdownRenderer HTML renderer that highlights fenced code blocks of known languages
+type markdownRenderer struct {
+	*blackfriday.HTMLRenderer
+}
+
+func (r *markdownRenderer) RenderNode(w io.Writer, node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+	if node.Type == blackfriday.CodeBlock {
+		lang, _, _ := strings.Cut(strings.TrimSpace(string(node.Info)), " ")
+		lang = strings.ToLower(lang)
+		if alias, ok := languageExtensions["."+lang]; ok {
+			lang = alias
+		}
+		if _, ok := languages[lang]; ok {
+			io.WriteString(w, highlight(string(node.Literal), lang)+"\n")
+			return blackfriday.GoToNext
+		}
+	}
+	return r.HTMLRenderer.RenderNode(w, node, entering)
+}
@@ -0,0 +1,126 @@
+package preview
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		contentType string
+		fileName    string
+		kind        string
+		contains    []string
+		excludes    []string
+	}{
+		{
+			name:        "markdown",
+			content:     "# Title\n\nSome *text* <script>alert(1)</script> [link](javascript:alert(1))\n\n```go\nfunc main() {}\n```\n",
+			contentType: "text/markdown",
+			kind:        KindMarkdown,
+			contains:    []string{"<h1>Title</h1>", "<em>text</em>", `<pre class="preview-code language-go"><code><span class="tok-keyword">func</span> main`},
+			excludes:    []string{"<script", "alert", "javascript"},
+		},
+		{
+			name:        "json",
+			content:     `{"a":[1,true,null],"b":"<x>"}`,
+			contentType: "application/json",
+			kind:        KindJSON,
+			contains:    []string{`<span class="tok-string">&#34;a&#34;</span>: [`, `<span class="tok-number">1</span>`, `<span class="tok-literal">true</span>`, "&lt;x&gt;"},
+		},
+		{
+			name:        "code by extension",
+			content:     "# comment\ndef f(x):\n    return 'hi'\n",
+			contentType: "text/plain",
+			fileName:    "script.py",
+			kind:        KindCode,
+			contains:    []string{`<span class="tok-comment"># comment</span>`, `<span class="tok-keyword">def</span> f`, `<span class="tok-string">&#39;hi&#39;</span>`},
+		},
+		{
+			name:        "html source",
+			content:     `<a href="x">hi</a><!-- c -->`,
+			contentType: "text/html",
+			kind:        KindCode,
+			contains:    []string{`<span class="tok-tag">&lt;a</span> <span class="tok-attr">href</span>=<span class="tok-string">&#34;x&#34;</span>`, `<span class="tok-comment">&lt;!-- c --&gt;</span>`},
+			excludes:    []string{"<a href"},
+		},
+		{
+			name:        "plain text",
+			content:     "a < b & c",
+			contentType: "text/plain",
+			kind:        KindText,
+			contains:    []string{`<pre class="preview-text">a &lt; b &amp; c</pre>`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Render([]byte(tt.content), tt.contentType, tt.fileName, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Kind != tt.kind || p.Truncated {
+				t.Errorf("kind = %s, truncated = %v", p.Kind, p.Truncated)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(p.HTML, s) {
+					t.Errorf("html is missing %q:\n%s", s, p.HTML)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(p.HTML, s) {
+					t.Errorf("html contains %q:\n%s", s, p.HTML)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTruncatesAndRejects(t *testing.T) {
+	content := strings.Repeat("line of text\n", 100)
+	p, err := Render([]byte(content), "text/plain", "", Options{MaxSize: 100, FullURL: "/api/v1/files/content/x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Truncated || p.Size != len(content) || strings.Count(p.HTML, "line of text") != 7 {
+		t.Errorf("truncated = %v, size = %d, html = %s", p.Truncated, p.Size, p.HTML)
+	}
+	if !strings.Contains(p.HTML, `<a href="/api/v1/files/content/x"`) {
+		t.Errorf("missing view full link: %s", p.HTML)
+	}
+
+	if _, err := Render([]byte("\x89PNG\r\n\x1a\n\x00\x00"), "text/plain", "", Options{}); !errors.Is(err, ErrBinary) {
+		t.Errorf("binary error = %v, want ErrBinary", err)
+	}
+	if _, err := Render([]byte("x"), "image/png", "a.png", Options{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("image error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     []byte
+		contentType string
+		want        string
+		charset     string
+	}{
+		{"utf-8", []byte("héllo 世界"), "text/plain", "héllo 世界", "utf-8"},
+		{"declared", []byte{'c', 'a', 'f', 0xE9}, "text/plain; charset=iso-8859-1", "café", "windows-1252"},
+		{"utf-16 bom", []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, "text/plain", "hi", "utf-16le"},
+		{"gbk", []byte{0xC4, 0xE3, 0xBA, 0xC3}, "text/plain", "你好", "gb18030"},
+		{"latin-1", []byte{'c', 'a', 'f', 0xE9, ' ', '!'}, "text/plain", "café !", "windows-1252"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, charset := DecodeText(tt.content, tt.contentType)
+			if got != tt.want || charset != tt.charset {
+				t.Errorf("DecodeText = %q, %s, want %q, %s", got, charset, tt.want, tt.charset)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package sanitize
+
+import (
+	"bytes"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// maxHTMLDepth deepest element nesting kept, deeper elements are dropped (their text is kept)
+const maxHTMLDepth = 128
+
+// htmlElements allowed HTML elements and their allowed attributes
+// Unknown elements are dropped but their text is kept.
+var htmlElements = map[string]map[string]bool{
+	"p": {}, "br": {}, "hr": {}, "div": {},
+	"h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"blockquote": {}, "ul": {}, "ol": {"start": true}, "li": {},
+	"dl": {}, "dt": {}, "dd": {}, "details": {}, "summary": {},
+	"pre": {"class": true}, "code": {"class": true}, "span": {"class": true},
+	"em": {}, "strong": {}, "b": {}, "i": {}, "u": {}, "s": {}, "del": {}, "ins": {}, "mark": {},
+	"sup": {}, "sub": {}, "small": {}, "kbd": {}, "q": {}, "abbr": {"title": true},
+	"a":     {"href": true, "title": true},
+	"img":   {"src": true, "alt": true, "title": true, "width": true, "height": true},
+	"table": {}, "caption": {}, "thead": {}, "tbody": {}, "tfoot": {}, "tr": {},
+	"th": {"align": true, "colspan": true, "rowspan": true},
+	"td": {"align": true, "colspan": true, "rowspan": true},
+}
+
+// htmlDroppedElements elements dropped together with their content
+var htmlDroppedElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "frame": true, "frameset": true, "object": true,
+	"embed": true, "applet": true, "noscript": true, "noembed": true, "noframes": true, "template": true,
+	"textarea": true, "select": true, "title": true, "xmp": true, "plaintext": true, "svg": true, "math": true,
+	"head": true,
+}
+
+// htmlVoidElements allowed elements without end tag
+var htmlVoidElements = map[string]bool{"br": true, "hr": true, "img": true}
+
+var (
+	// codeClass language and highlight token classes of pre/code/span
+	codeClass = regexp.MustCompile(`^(language-[A-Za-z0-9_+#-]+|tok-[a-z]+|preview-[a-z]+)$`)
+	// integerValue numeric attribute values
+	integerValue = regexp.MustCompile(`^[0-9]{1,4}$`)
+)
+
+// HTML sanitize an HTML fragment with an element and attribute allowlist
+// Scripts, styles, frames, event handlers and unsafe URLs are removed, links get
+// rel="nofollow noopener noreferrer" and open in a new window. Unclosed elements are closed.
+func HTML(fragment []byte) []byte {
+	tokenizer := nethtml.NewTokenizer(bytes.NewReader(fragment))
+
+	var out bytes.Buffer
+	var open []string // Open allowed elements
+	skip := ""        // Dropped element whose content is being skipped
+	skipDepth := 0
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == nethtml.ErrorToken {
+			// io.EOF, or a read error of the in-memory reader which cannot happen
+			break
+		}
+		token := tokenizer.Token()
+
+		if skip != "" {
+			switch {
+			case tokenType == nethtml.StartTagToken && token.Data == skip:
+				skipDepth++
+			case tokenType == nethtml.EndTagToken && token.Data == skip:
+				skipDepth--
+				if skipDepth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		switch tokenType {
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			if htmlDroppedElements[token.Data] {
+				if tokenType == nethtml.StartTagToken {
+					skip, skipDepth = token.Data, 1
+				}
+				continue
+			}
+			attrs, ok := htmlElements[token.Data]
+			if !ok {
+				continue
+			}
+			if !htmlVoidElements[token.Data] {
+				if len(open) >= maxHTMLDepth {
+					continue
+				}
+				if tokenType == nethtml.SelfClosingTagToken {
+					// <p/> is not valid HTML, treat it as an empty element
+					writeHTMLStartTag(&out, token, attrs)
+					out.WriteString("</" + token.Data + ">")
+					continue
+				}
+				open = append(open, token.Data)
+			}
+			writeHTMLStartTag(&out, token, attrs)
+
+		case nethtml.EndTagToken:
+			// Close the innermost matching element and everything opened inside it
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != token.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					out.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+
+		case nethtml.TextToken:
+			out.WriteString(html.EscapeString(token.Data))
+
+		// Comments and doctypes are dropped
+		case nethtml.CommentToken, nethtml.DoctypeToken:
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		out.WriteString("</" + open[i] + ">")
+	}
+	return out.Bytes()
+}
+
+// writeHTMLStartTag write start tag with allowed attributes
+func writeHTMLStartTag(out *bytes.Buffer, token nethtml.Token, allowed map[string]bool) {
+	out.WriteString("<" + token.Data)
+	for _, attr := range token.Attr {
+		if attr.Namespace != "" || !allowed[attr.Key] || !allowedHTMLAttributeValue(attr.Key, attr.Val) {
+			continue
+		}
+		out.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	if token.Data == "a" {
+		out.WriteString(` rel="nofollow noopener noreferrer" target="_blank"`)
+	}
+	out.WriteString(">")
+}
+
+// allowedHTMLAttributeValue validate the value of an allowed attribute
+func allowedHTMLAttributeValue(key, value string) bool {
+	switch key {
+	case "href":
+		return safeURL(value, "http", "https", "mailto")
+	case "src":
+		return safeURL(value, "http", "https")
+	case "class":
+		for _, class := range strings.Fields(value) {
+			if !codeClass.MatchString(class) {
+				return false
+			}
+		}
+		return true
+	case "align":
+		return value == "left" || value == "right" || value == "center"
+	case "start", "width", "height", "colspan", "rowspan":
+		return integerValue.MatchString(value)
+	}
+	return true
+}
+
+// safeURL check URL is relative or uses one of the allowed schemes
+// Values with control characters or whitespace inside the scheme fail to parse and are rejected.
+func safeURL(value string, schemes ...string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" {
+		// Relative reference, "//host" is protocol-relative and still an http(s) URL
+		return true
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLRemovesActiveContent(t *testing.T) {
+	input := `<h1 onclick="x()">Title</h1>
+<script>alert(1)</script><style>body{display:none}</style>
+<p>a &lt; b <a href="javascript:alert(1)">bad</a> <a href="java&#x09;script:alert(1)">tab</a> <a href="https://example.com/x?a=1&amp;b=2" onmouseover="x()">ok</a></p>
+<img src="https://example.com/i.png" onerror="x()"><img src="data:text/html,<script>">
+<iframe src="https://evil"><p>inside</p></iframe>
+<pre class="preview-code language-go"><code><span class="tok-keyword">func</span></code></pre>
+<div class="evil" style="position:fixed">styled</div>
+<table><tr><td align="center" colspan="2">cell</td></tr>
+<ul><li>unclosed`
+
+	got := string(HTML([]byte(input)))
+
+	for _, banned := range []string{"script", "alert", "onclick", "onmouseover", "onerror", "iframe", "inside", "data:", "<style", "style=", "evil", "display"} {
+		if strings.Contains(got, banned) {
+			t.Errorf("output contains %q:\n%s", banned, got)
+		}
+	}
+	for _, kept := range []string{
+		`<h1>Title</h1>`, `a &lt; b`,
+		`<a href="https://example.com/x?a=1&amp;b=2" rel="nofollow noopener noreferrer" target="_blank">ok</a>`,
+		`<img src="https://example.com/i.png">`, `<pre class="preview-code language-go"><code><span class="tok-keyword">func</span></code></pre>`,
+		`<div>styled</div>`, `<td align="center" colspan="2">cell</td>`, `<li>unclosed</li></ul></table>`,
+	} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("output is missing %q:\n%s", kept, got)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Package sanitize removes active content from user-supplied documents before they are served inline.
+package sanitize
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	svgNamespace   = "http://www.w3.org/2000/svg"
+	xlinkNamespace = "http://www.w3.org/1999/xlink"
+
+	// MaxSVGSize largest SVG document that will be sanitized
+	MaxSVGSize = 10 * 1024 * 1024
+	// maxSVGDepth deepest element nesting accepted
+	maxSVGDepth = 256
+)
+
+var ErrInvalidSVG = errors.New("invalid svg")
+
+// svgElements allowed SVG elements (drawing, text, paint servers and filter primitives)
+// Anything else, including script, foreignObject, style, a and animation elements, is dropped with its subtree.
+var svgElements = map[string]bool{
+	"svg": true, "g": true, "defs": true, "symbol": true, "use": true, "title": true, "desc": true,
+	"path": true, "rect": true, "circle": true, "ellipse": true, "line": true, "polyline": true, "polygon": true,
+	"text": true, "tspan": true, "textPath": true, "image": true, "switch": true,
+	"linearGradient": true, "radialGradient": true, "stop": true, "pattern": true,
+	"clipPath": true, "mask": true, "marker": true,
+	"filter": true, "feBlend": true, "feColorMatrix": true, "feComponentTransfer": true, "feComposite": true,
+	"feConvolveMatrix": true, "feDiffuseLighting": true, "feDisplacementMap": true, "feDistantLight": true,
+	"feDropShadow": true, "feFlood": true, "feFuncA": true, "feFuncB": true, "feFuncG": true, "feFuncR": true,
+	"feGaussianBlur": true, "feMerge": true, "feMergeNode": true, "feMorphology": true, "feOffset": true,
+	"fePointLight": true, "feSpecularLighting": true, "feSpotLight": true, "feTile": true, "feTurbulence": true,
+}
+
+// svgAttributes allowed attributes (geometry, presentation and filter parameters)
+// Event handlers (on*) and other attributes are dropped.
+var svgAttributes = map[string]bool{
+	"id": true, "class": true, "style": true, "lang": true, "transform": true,
+	"version": true, "baseProfile": true, "viewBox": true, "preserveAspectRatio": true,
+	"x": true, "y": true, "x1": true, "y1": true, "x2": true, "y2": true, "cx": true, "cy": true,
+	"r": true, "rx": true, "ry": true, "fx": true, "fy": true, "fr": true,
+	"width": true, "height": true, "d": true, "points": true, "pathLength": true,
+	"fill": true, "fill-opacity": true, "fill-rule": true, "opacity": true,
+	"stroke": true, "stroke-width": true, "stroke-linecap": true, "stroke-linejoin": true,
+	"stroke-dasharray": true, "stroke-dashoffset": true, "stroke-opacity": true, "stroke-miterlimit": true,
+	"color": true, "display": true, "visibility": true, "overflow": true, "vector-effect": true,
+	"clip-path": true, "clip-rule": true, "clipPathUnits": true,
+	"mask": true, "maskUnits": true, "maskContentUnits": true,
+	"filter": true, "filterUnits": true, "primitiveUnits": true,
+	"marker-start": true, "marker-mid": true, "marker-end": true,
+	"markerWidth": true, "markerHeight": true, "markerUnits": true, "refX": true, "refY": true, "orient": true,
+	"offset": true, "stop-color": true, "stop-opacity": true,
+	"gradientUnits": true, "gradientTransform": true, "spreadMethod": true,
+	"patternUnits": true, "patternContentUnits": true, "patternTransform": true,
+	"font-family": true, "font-size": true, "font-weight": true, "font-style": true, "font-variant": true,
+	"text-anchor": true, "dominant-baseline": true, "alignment-baseline": true, "baseline-shift": true,
+	"letter-spacing": true, "word-spacing": true, "text-decoration": true, "writing-mode": true,
+	"textLength": true, "lengthAdjust": true, "startOffset": true, "method": true, "spacing": true,
+	"dx": true, "dy": true, "rotate": true,
+	"in": true, "in2": true, "result": true, "stdDeviation": true, "mode": true, "operator": true,
+	"k1": true, "k2": true, "k3": true, "k4": true, "values": true, "type": true, "tableValues": true,
+	"slope": true, "intercept": true, "amplitude": true, "exponent": true,
+	"flood-color": true, "flood-opacity": true, "lighting-color": true,
+	"baseFrequency": true, "numOctaves": true, "seed": true, "stitchTiles": true,
+	"scale": true, "xChannelSelector": true, "yChannelSelector": true, "radius": true,
+	"order": true, "kernelMatrix": true, "divisor": true, "bias": true, "targetX": true, "targetY": true,
+	"edgeMode": true, "preserveAlpha": true, "surfaceScale": true, "diffuseConstant": true,
+	"specularConstant": true, "specularExponent": true, "azimuth": true, "elevation": true,
+	"z": true, "pointsAtX": true, "pointsAtY": true, "pointsAtZ": true, "limitingConeAngle": true,
+	"color-interpolation-filters": true, "requiredFeatures": true, "systemLanguage": true,
+}
+
+var (
+	// urlReference url(...) references in attribute and style values
+	urlReference = regexp.MustCompile(`(?i)url\s*\(\s*['"]?\s*([^'")\s]*)`)
+	// dataImage embedded raster images allowed in <image href>
+	dataImage = regexp.MustCompile(`^data:image/(png|jpeg|gif|webp);base64,[A-Za-z0-9+/=\s]*$`)
+)
+
+// SVG sanitize an SVG document with an element and attribute allowlist
+// Scripts, event handlers, foreign content, external references and DTDs are removed.
+// Returns ErrInvalidSVG if the document is not well-formed SVG.
+func SVG(data []byte) ([]byte, error) {
+	if len(data) > MaxSVGSize {
+		return nil, fmt.Errorf("%w: document too large", ErrInvalidSVG)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var out bytes.Buffer
+	out.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+
+	depth := 0     // Current element depth
+	skipDepth := 0 // Depth of the dropped element whose subtree is being skipped (0 = not skipping)
+	rootSeen := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSVG, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth > maxSVGDepth {
+				return nil, fmt.Errorf("%w: nesting too deep", ErrInvalidSVG)
+			}
+			if depth == 1 {
+				if rootSeen || t.Name.Local != "svg" || (t.Name.Space != svgNamespace && t.Name.Space != "") {
+					return nil, fmt.Errorf("%w: root element is not svg", ErrInvalidSVG)
+				}
+				rootSeen = true
+			}
+			if skipDepth > 0 {
+				continue
+			}
+			if !allowedElement(t.Name) {
+				skipDepth = depth
+				continue
+			}
+			writeStartElement(&out, t, depth == 1)
+
+		case xml.EndElement:
+			if skipDepth == 0 {
+				fmt.Fprintf(&out, "</%s>", t.Name.Local)
+			} else if skipDepth == depth {
+				skipDepth = 0
+			}
+			depth--
+
+		case xml.CharData:
+			if skipDepth == 0 && depth > 0 {
+				xml.EscapeText(&out, t)
+			}
+
+		// Comments, processing instructions and directives (DOCTYPE/ENTITY) are dropped
+		case xml.Comment, xml.ProcInst, xml.Directive:
+		}
+	}
+
+	if !rootSeen {
+		return nil, fmt.Errorf("%w: no svg element", ErrInvalidSVG)
+	}
+	return out.Bytes(), nil
+}
+
+// allowedElement check element against the allowlist (SVG namespace only)
+func allowedElement(name xml.Name) bool {
+	if name.Space != svgNamespace && name.Space != "" {
+		return false
+	}
+	return svgElements[name.Local]
+}
+
+// writeStartElement write start tag with allowed attributes
+// The root element declares the SVG and XLink namespaces itself, source xmlns attributes are dropped.
+func writeStartElement(out *bytes.Buffer, element xml.StartElement, root bool) {
+	out.WriteString("<" + element.Name.Local)
+	if root {
+		out.WriteString(` xmlns="` + svgNamespace + `" xmlns:xlink="` + xlinkNamespace + `"`)
+	}
+
+	for _, attr := range element.Attr {
+		name, ok := allowedAttribute(element.Name.Local, attr)
+		if !ok {
+			continue
+		}
+		out.WriteString(" " + name + `="`)
+		xml.EscapeText(out, []byte(attr.Value))
+		out.WriteString(`"`)
+	}
+	out.WriteString(">")
+}
+
+// allowedAttribute check attribute against the allowlist and validate its value
+// Returns the attribute name to write
+func allowedAttribute(element string, attr xml.Attr) (string, bool) {
+	// href / xlink:href
+	if attr.Name.Local == "href" && (attr.Name.Space == "" || attr.Name.Space == xlinkNamespace) {
+		value := strings.TrimSpace(attr.Value)
+		if element == "image" {
+			if !dataImage.MatchString(value) {
+				return "", false
+			}
+		} else if !strings.HasPrefix(value, "#") {
+			// use/textPath/gradients may only reference fragments in the same document
+			return "", false
+		}
+		if attr.Name.Space == xlinkNamespace {
+			return "xlink:href", true
+		}
+		return "href", true
+	}
+
+	if attr.Name.Space != "" || !svgAttributes[attr.Name.Local] {
+		return "", false
+	}
+	if !safeAttributeValue(attr.Value) {
+		return "", false
+	}
+	return attr.Name.Local, true
+}
+
+// safeAttributeValue reject values that reference external resources or script
+// url(...) may only point at a fragment in the same document
+func safeAttributeValue(value string) bool {
+	lower := strings.ToLower(value)
+	if strings.Contains(lower, "javascript:") || strings.Contains(lower, "expression(") ||
+		strings.Contains(lower, "@import") || strings.Contains(lower, "\\") {
+		return false
+	}
+	for _, match := range urlReference.FindAllStringSubmatch(value, -1) {
+		if !strings.HasPrefix(match[1], "#") {
+			return false
+		}
+	}
+	return true
+}
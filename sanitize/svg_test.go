@@ -0,0 +1,54 @@
+package sanitize
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSVGRemovesActiveContent(t *testing.T) {
+	input := `<?xml version="1.0"?>
+<!DOCTYPE svg>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10" onload="alert(1)">
+  <script>alert(1)</script>
+  <foreignObject><div xmlns="http://www.w3.org/1999/xhtml"><iframe src="https://evil"/></div></foreignObject>
+  <a xlink:href="javascript:alert(1)"><rect width="5" height="5"/></a>
+  <style>@import url(https://evil/x.css);</style>
+  <circle cx="5" cy="5" r="4" fill="url(#g)" stroke="url(https://evil/track)" onclick="x()"/>
+  <use xlink:href="https://evil/sprite.svg#icon"/>
+  <use href="#shape"/>
+  <image href="data:image/png;base64,iVBORw0KGgo=" width="1" height="1"/>
+  <image href="https://evil/pixel.png"/>
+  <text x="1" y="9">a &lt; b</text>
+</svg>`
+
+	output, err := SVG([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(output)
+
+	for _, banned := range []string{"script", "alert", "foreignObject", "iframe", "onload", "onclick", "evil", "DOCTYPE", "<a", "<style", "@import"} {
+		if strings.Contains(got, banned) {
+			t.Errorf("output contains %q:\n%s", banned, got)
+		}
+	}
+	for _, kept := range []string{`viewBox="0 0 10 10"`, `fill="url(#g)"`, `<use href="#shape">`, `data:image/png;base64`, `a &lt; b`, `<circle`} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("output is missing %q:\n%s", kept, got)
+		}
+	}
+}
+
+func TestSVGRejectsInvalidDocuments(t *testing.T) {
+	for name, input := range map[string]string{
+		"not svg":        `<html><body>hi</body></html>`,
+		"malformed":      `<svg xmlns="http://www.w3.org/2000/svg"><g></svg>`,
+		"custom entity":  `<!DOCTYPE svg [<!ENTITY a "aaaa">]><svg xmlns="http://www.w3.org/2000/svg">&a;</svg>`,
+		"empty document": ``,
+	} {
+		if _, err := SVG([]byte(input)); !errors.Is(err, ErrInvalidSVG) {
+			t.Errorf("%s: err = %v, want ErrInvalidSVG", name, err)
+		}
+	}
+}
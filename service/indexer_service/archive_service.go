@@ -0,0 +1,155 @@
+package indexer_service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+
+	"meta-media-service/archive"
+	"meta-media-service/conf"
+	"meta-media-service/storage"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var ErrNotArchive = errors.New("file is not an archive")
+
+// ArchiveEntryContent archive entry prepared for streaming to browsers
+type ArchiveEntryContent struct {
+	Reader      io.ReadCloser
+	Size        int64
+	ContentType string
+	FileName    string
+	Attachment  bool // Force download instead of inline rendering
+}
+
+// ArchiveService archive inspection service
+// Entry indexes are cached in storage under a key derived from the source hash.
+type ArchiveService struct {
+	storage            storage.Storage
+	indexerFileService *IndexerFileService
+	group              singleflight.Group
+}
+
+// NewArchiveService create archive service instance
+func NewArchiveService(storage storage.Storage, indexerFileService *IndexerFileService) *ArchiveService {
+	return &ArchiveService{
+		storage:            storage,
+		indexerFileService: indexerFileService,
+	}
+}
+
+// GetEntries get entry index of an archive file by PIN ID
+func (s *ArchiveService) GetEntries(pinID string) (*archive.Index, error) {
+	file, err := s.indexerFileService.GetFileByPinID(pinID)
+	if err != nil {
+		return nil, err
+	}
+	if file.FileType != "archive" {
+		return nil, ErrNotArchive
+	}
+
+	sourceID := file.FileHash
+	if sourceID == "" {
+		sourceID = file.PinID
+	}
+	key := archiveIndexKey(sourceID)
+
+	// Serve cached index
+	if cached, err := s.storage.Get(key); err == nil {
+		var index archive.Index
+		if err := json.Unmarshal(cached, &index); err == nil {
+			return &index, nil
+		}
+		log.Printf("Invalid cached archive index %s, rebuilding", key)
+	}
+
+	v, err, _ := s.group.Do(key, func() (interface{}, error) {
+		content, _, _, err := s.indexerFileService.GetFileContent(pinID)
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := archive.List(content, archiveLimits())
+		if err != nil {
+			return nil, err
+		}
+
+		if data, err := json.Marshal(index); err == nil {
+			if err := s.storage.Save(key, data); err != nil {
+				log.Printf("Failed to cache archive index %s: %v", key, err)
+			}
+		}
+		return index, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*archive.Index), nil
+}
+
+// OpenEntry open a single entry of an archive file for streaming
+// Active content (HTML, XML, SVG, script) is forced to download, like raw file content.
+func (s *ArchiveService) OpenEntry(pinID, entryPath string) (*ArchiveEntryContent, error) {
+	file, err := s.indexerFileService.GetFileByPinID(pinID)
+	if err != nil {
+		return nil, err
+	}
+	if file.FileType != "archive" {
+		return nil, ErrNotArchive
+	}
+
+	content, _, _, err := s.indexerFileService.GetFileContent(pinID)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, entry, err := archive.Open(content, entryPath, archiveLimits())
+	if err != nil {
+		return nil, err
+	}
+
+	// Sniff the head of the entry, the extension alone may be misleading
+	reader := bufio.NewReader(rc)
+	head, _ := reader.Peek(512)
+	sniffed := http.DetectContentType(head)
+
+	served := &ArchiveEntryContent{
+		Reader: struct {
+			io.Reader
+			io.Closer
+		}{reader, rc},
+		Size:        entry.Size,
+		ContentType: entry.ContentType,
+		FileName:    path.Base(entry.Path),
+	}
+	if served.ContentType == "" {
+		served.ContentType = sniffed
+	}
+	if activeContentTypes[baseMediaType(served.ContentType)] || activeContentTypes[baseMediaType(sniffed)] || looksLikeSVG(head) {
+		served.Attachment = true
+	}
+
+	return served, nil
+}
+
+// archiveLimits decompression bomb limits from configuration
+func archiveLimits() archive.Limits {
+	return archive.Limits{
+		MaxEntries:   conf.Cfg.Indexer.Archive.MaxEntries,
+		MaxTotalSize: conf.Cfg.Indexer.Archive.MaxTotalSize,
+		MaxRatio:     conf.Cfg.Indexer.Archive.MaxRatio,
+	}
+}
+
+// archiveIndexKey deterministic storage key of a cached entry index
+// archives/{source hash}/entries.json
+func archiveIndexKey(sourceID string) string {
+	return fmt.Sprintf("archives/%s/entries.json", sourceID)
+}
@@ -0,0 +1,156 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"meta-media-service/conf"
+	"meta-media-service/imaging"
+	"meta-media-service/model"
+	"meta-media-service/storage"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Standard avatar sizes, requested sizes are rounded up to the next one
+var AvatarImageSizes = []int{32, 48, 64, 96, 128, 256, 512}
+
+var ErrInvalidAvatarSize = errors.New("invalid avatar size")
+
+// AvatarImage rendered avatar image
+type AvatarImage struct {
+	Content     []byte
+	ContentType string
+	PinID       string // Avatar PIN the image was rendered from (empty for identicons)
+	Size        int
+}
+
+// AvatarImageService square avatar rendering service
+// Rendered avatars are cached in storage per avatar PIN, so a newer avatar PIN is picked up immediately.
+type AvatarImageService struct {
+	storage            storage.Storage
+	indexerFileService *IndexerFileService
+	group              singleflight.Group
+}
+
+// NewAvatarImageService create avatar image service instance
+func NewAvatarImageService(storage storage.Storage, indexerFileService *IndexerFileService) *AvatarImageService {
+	return &AvatarImageService{
+		storage:            storage,
+		indexerFileService: indexerFileService,
+	}
+}
+
+// GetAvatarImage get square, center-cropped latest avatar of a MetaID
+// Users without a (decodable) avatar get a deterministic identicon
+func (s *AvatarImageService) GetAvatarImage(metaID string, size int) (*AvatarImage, error) {
+	size, err := NormalizeAvatarSize(size)
+	if err != nil {
+		return nil, err
+	}
+
+	avatar, err := s.indexerFileService.GetLatestAvatarByMetaID(metaID)
+	if err != nil {
+		return nil, err
+	}
+	if avatar == nil {
+		return s.identicon(metaID, size)
+	}
+
+	format := imaging.DefaultFormat(sourceImageFormat(avatar.ContentType))
+	key := avatarImageKey(avatar.PinID, size, format)
+
+	// Serve cached rendering
+	if content, err := s.storage.Get(key); err == nil {
+		return &AvatarImage{Content: content, ContentType: imaging.ContentType(format), PinID: avatar.PinID, Size: size}, nil
+	}
+
+	v, err, _ := s.group.Do(key, func() (interface{}, error) {
+		return s.render(avatar, size, format, key)
+	})
+	if err != nil {
+		// SVG and other avatars that cannot be decoded fall back to the identicon
+		if errors.Is(err, ErrNotImage) {
+			log.Printf("Avatar %s cannot be rendered, using identicon: %v", avatar.PinID, err)
+			return s.identicon(metaID, size)
+		}
+		return nil, err
+	}
+
+	return &AvatarImage{Content: v.([]byte), ContentType: imaging.ContentType(format), PinID: avatar.PinID, Size: size}, nil
+}
+
+// InvalidateAvatar remove cached renderings of an avatar PIN
+// Called when a newer avatar is indexed for the same MetaID
+func (s *AvatarImageService) InvalidateAvatar(pinID string) {
+	for _, size := range AvatarImageSizes {
+		for _, format := range []string{imaging.FormatJPEG, imaging.FormatPNG} {
+			key := avatarImageKey(pinID, size, format)
+			if !s.storage.Exists(key) {
+				continue
+			}
+			if err := s.storage.Delete(key); err != nil {
+				log.Printf("Failed to delete cached avatar image %s: %v", key, err)
+			}
+		}
+	}
+}
+
+// render decode, crop and resize avatar, save the rendering under key and return it
+func (s *AvatarImageService) render(avatar *model.IndexerUserAvatar, size int, format, key string) ([]byte, error) {
+	source, _, _, err := s.indexerFileService.GetAvatarContent(avatar.PinID)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := imaging.Decode(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+
+	content, err := imaging.Encode(imaging.Resize(img, size, size, imaging.FitCover), format, conf.Cfg.Indexer.Thumbnail.Quality)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Save(key, content); err != nil {
+		log.Printf("Failed to cache avatar image %s: %v", key, err)
+	}
+
+	return content, nil
+}
+
+// identicon render identicon of a MetaID (cheap to generate, not cached)
+func (s *AvatarImageService) identicon(metaID string, size int) (*AvatarImage, error) {
+	content, err := imaging.Encode(imaging.Identicon(metaID, size), imaging.FormatPNG, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &AvatarImage{Content: content, ContentType: imaging.ContentType(imaging.FormatPNG), Size: size}, nil
+}
+
+// NormalizeAvatarSize round size up to the next standard avatar size
+// 0 returns the default size (128)
+func NormalizeAvatarSize(size int) (int, error) {
+	if size == 0 {
+		return 128, nil
+	}
+
+	minSize, maxSize := AvatarImageSizes[0], AvatarImageSizes[len(AvatarImageSizes)-1]
+	if size < minSize || size > maxSize {
+		return 0, fmt.Errorf("%w: size must be between %d and %d", ErrInvalidAvatarSize, minSize, maxSize)
+	}
+	for _, standard := range AvatarImageSizes {
+		if size <= standard {
+			return standard, nil
+		}
+	}
+	return maxSize, nil
+}
+
+// avatarImageKey deterministic storage key of a rendered avatar
+// thumbnails/avatars/{pin_id}/{size}.{format}
+func avatarImageKey(pinID string, size int, format string) string {
+	return fmt.Sprintf("thumbnails/avatars/%s/%d.%s", pinID, size, format)
+}
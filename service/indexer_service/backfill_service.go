@@ -0,0 +1,180 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"meta-media-service/filetype"
+	"meta-media-service/imaging"
+	"meta-media-service/indexer"
+	"meta-media-service/model"
+	"meta-media-service/model/dao"
+	"meta-media-service/storage"
+)
+
+// BackfillService fill derived fields of records indexed before those fields existed
+// Currently: content type detection, image placeholders (BlurHash and dominant color) and perceptual hashes.
+type BackfillService struct {
+	storage        storage.Storage
+	chainFetcher   *ChainContentFetcher
+	indexerFileDAO *dao.IndexerFileDAO
+	imageHashDAO   *dao.IndexerImageHashDAO
+}
+
+// BackfillResult backfill result
+type BackfillResult struct {
+	Scanned     int64 // Records scanned
+	Pending     int64 // Records missing derived fields
+	Updated     int64
+	Undecodable int64 // Images marked as not decodable, they are not fetched again
+	Skipped     int64 // Content not available or nothing left to fill
+	Failed      int64
+}
+
+// NewBackfillService create backfill service instance
+// scanners: one per chain, used to fetch serve-from-chain content without a cached copy (none or nil to skip those)
+func NewBackfillService(stor storage.Storage, scanners ...*indexer.BlockScanner) *BackfillService {
+	return &BackfillService{
+		storage:        stor,
+		chainFetcher:   NewChainContentFetcher(scanners...),
+		indexerFileDAO: dao.NewIndexerFileDAO(),
+		imageHashDAO:   dao.NewIndexerImageHashDAO(),
+	}
+}
+
+// Backfill compute missing content type detection, image placeholders and perceptual hashes of existing file records
+// dryRun: only count records that need backfilling
+func (s *BackfillService) Backfill(dryRun bool) (*BackfillResult, error) {
+	result := &BackfillResult{}
+
+	err := s.indexerFileDAO.ForEach(func(file *model.IndexerFile) error {
+		result.Scanned++
+
+		pending, err := s.pending(file)
+		if err != nil {
+			log.Printf("Backfill: failed to check PIN %s: %v", file.PinID, err)
+			result.Failed++
+			return nil
+		}
+		if !pending {
+			return nil
+		}
+
+		result.Pending++
+		if dryRun {
+			return nil
+		}
+
+		content, err := s.content(file)
+		if err != nil {
+			log.Printf("Backfill: content of PIN %s not available: %v", file.PinID, err)
+			result.Skipped++
+			return nil
+		}
+
+		updated, err := s.backfillFile(file, content)
+		if err != nil {
+			log.Printf("Backfill: failed to update PIN %s: %v", file.PinID, err)
+			result.Failed++
+			return nil
+		}
+		if file.ImageDecodeFailed {
+			result.Undecodable++
+		} else if updated {
+			result.Updated++
+		} else {
+			result.Skipped++
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to backfill files: %w", err)
+	}
+
+	return result, nil
+}
+
+// pending check if a file record is missing derived fields
+func (s *BackfillService) pending(file *model.IndexerFile) (bool, error) {
+	if file.DetectedContentType == "" {
+		return true, nil
+	}
+	if !isDecodableImage(file) {
+		return false, nil
+	}
+	if needsImagePlaceholder(file) {
+		return true, nil
+	}
+
+	hash, err := s.imageHashDAO.GetByPinID(file.PinID)
+	if err != nil {
+		return false, err
+	}
+	return hash == nil, nil
+}
+
+// backfillFile fill missing derived fields of a file record from its content
+// Content type detection runs first, files it reclassifies as images get image fields in the same pass.
+// Returns whether anything was updated.
+func (s *BackfillService) backfillFile(file *model.IndexerFile, content []byte) (bool, error) {
+	dirty := false
+	if file.DetectedContentType == "" {
+		realContentType, detectedContentType := detectRealContentType(content, file.ContentType)
+		file.DetectedContentType = detectedContentType
+		file.ContentTypeMismatch = filetype.Mismatch(file.ContentType, detectedContentType)
+		file.FileType = detectFileType(realContentType)
+		dirty = true
+	}
+
+	hashSaved := false
+	if isDecodableImage(file) {
+		existingHash, err := s.imageHashDAO.GetByPinID(file.PinID)
+		if err != nil {
+			return false, err
+		}
+
+		if existingHash == nil || needsImagePlaceholder(file) {
+			img := decodeIndexedImage(file.PinID, content)
+			if img == nil {
+				// Remember the failure, the content would be fetched and decoded again on every run
+				file.ImageDecodeFailed = true
+				dirty = true
+			} else {
+				if existingHash == nil {
+					hash := model.NewIndexerImageHash(file.PinID, file.FileHash, imaging.DHash(img))
+					if err := s.imageHashDAO.CreateOrUpdate(hash); err != nil {
+						return false, err
+					}
+					hashSaved = true
+				}
+				if needsImagePlaceholder(file) {
+					file.BlurHash, file.DominantColor = computeImagePlaceholder(file.PinID, img)
+					dirty = true
+				}
+			}
+		}
+	}
+
+	if dirty {
+		if err := s.indexerFileDAO.Update(file); err != nil {
+			return false, err
+		}
+	}
+	return dirty || hashSaved, nil
+}
+
+// content read file content from storage, falling back to the chain for serve-from-chain files
+func (s *BackfillService) content(file *model.IndexerFile) ([]byte, error) {
+	content, err := s.storage.Get(file.StoragePath)
+	if err == nil {
+		return content, nil
+	}
+	if file.StorageType != model.StorageTypeChain || !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	if s.chainFetcher == nil {
+		return nil, errors.New("serve-from-chain content requires a node connection")
+	}
+	return s.chainFetcher.Fetch(file.PinID, file.ChainName, file.FileHash)
+}
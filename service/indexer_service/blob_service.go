@@ -0,0 +1,95 @@
+package indexer_service
+
+import (
+	"fmt"
+	"log"
+
+	"meta-media-service/conf"
+	"meta-media-service/model"
+	"meta-media-service/model/dao"
+	"meta-media-service/storage"
+)
+
+// BlobService content storage service
+// With layout "path" every record gets its own object; with layout "cas" records
+// with the same SHA256 share one blob, tracked by reference count.
+type BlobService struct {
+	storage        storage.Storage
+	indexerBlobDAO *dao.IndexerBlobDAO
+	layout         string
+}
+
+// NewBlobService create blob service instance using configured storage layout
+func NewBlobService(storage storage.Storage) *BlobService {
+	return &BlobService{
+		storage:        storage,
+		indexerBlobDAO: dao.NewIndexerBlobDAO(),
+		layout:         conf.Cfg.Storage.Layout,
+	}
+}
+
+// IsContentAddressed check if content-addressed layout is enabled
+func (s *BlobService) IsContentAddressed() bool {
+	return s.layout == storage.LayoutContentAddressed
+}
+
+// Store save content and return the storage path the record should point at
+// fileHash: SHA256 hex of content
+// legacyPath: per-record path used when content-addressed layout is disabled
+func (s *BlobService) Store(content []byte, fileHash, legacyPath string) (string, error) {
+	if !s.IsContentAddressed() {
+		if err := s.storage.Save(legacyPath, content); err != nil {
+			return "", err
+		}
+		return legacyPath, nil
+	}
+
+	return s.Acquire(content, fileHash)
+}
+
+// Acquire add a reference to the content-addressed blob for content, saving it if needed
+func (s *BlobService) Acquire(content []byte, fileHash string) (string, error) {
+	key := storage.ContentAddressedKey(fileHash)
+
+	refCount, err := s.indexerBlobDAO.IncrRef(&model.IndexerBlob{
+		FileHash:    fileHash,
+		StoragePath: key,
+		FileSize:    int64(len(content)),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to increase blob reference: %w", err)
+	}
+
+	// Save blob on first reference, or if the object went missing
+	if refCount == 1 || !s.storage.Exists(key) {
+		if err := s.storage.Save(key, content); err != nil {
+			if _, decrErr := s.indexerBlobDAO.DecrRef(fileHash); decrErr != nil {
+				log.Printf("Failed to roll back blob reference for %s: %v", fileHash, decrErr)
+			}
+			return "", err
+		}
+	} else {
+		log.Printf("Blob already stored, reusing: %s (refs: %d)", key, refCount)
+	}
+
+	return key, nil
+}
+
+// Release release the record's reference to its storage object
+// Content-addressed blobs are deleted only when the last reference is released.
+func (s *BlobService) Release(storagePath, fileHash string) error {
+	if !storage.IsContentAddressedKey(storagePath) {
+		return s.storage.Delete(storagePath)
+	}
+
+	refCount, err := s.indexerBlobDAO.DecrRef(fileHash)
+	if err != nil {
+		return fmt.Errorf("failed to decrease blob reference: %w", err)
+	}
+	if refCount > 0 {
+		log.Printf("Blob still referenced, keeping: %s (refs: %d)", storagePath, refCount)
+		return nil
+	}
+
+	return s.storage.Delete(storagePath)
+}
@@ -0,0 +1,97 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+
+	"meta-media-service/conf"
+	"meta-media-service/indexer"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ChainContentFetcher extract PIN content from its originating transaction on demand
+type ChainContentFetcher struct {
+	parsers map[indexer.ChainType]*indexer.MetaIDParser // Per chain, each reads from the node of its chain
+	group   singleflight.Group
+}
+
+// NewChainContentFetcher create chain content fetcher instance
+// Each scanner serves the records of its own chain, nil scanners are skipped. Returns nil without scanners.
+func NewChainContentFetcher(scanners ...*indexer.BlockScanner) *ChainContentFetcher {
+	parsers := make(map[indexer.ChainType]*indexer.MetaIDParser)
+	for _, scanner := range scanners {
+		if scanner == nil {
+			continue
+		}
+		parser := indexer.NewMetaIDParser("")
+		parser.SetBlockScanner(scanner)
+		parsers[scanner.ChainType()] = parser
+	}
+	if len(parsers) == 0 {
+		return nil
+	}
+
+	return &ChainContentFetcher{
+		parsers: parsers,
+	}
+}
+
+// Fetch fetch transaction from node and extract the PIN payload
+// Concurrent requests for the same PIN share one fetch. The payload is verified
+// against fileHash (SHA256 hex) when it is set.
+func (f *ChainContentFetcher) Fetch(pinID, chainName, fileHash string) ([]byte, error) {
+	v, err, _ := f.group.Do(pinID, func() (interface{}, error) {
+		chainType := indexer.ChainType(chainName)
+		if chainType != indexer.ChainTypeBTC {
+			chainType = indexer.ChainTypeMVC
+		}
+
+		parser, ok := f.parsers[chainType]
+		if !ok {
+			return nil, fmt.Errorf("no node connection for chain %s", chainType)
+		}
+		metaData, err := parser.FetchPIN(pinID, chainType)
+		if err != nil {
+			return nil, err
+		}
+
+		if fileHash != "" {
+			if actual := calculateSHA256(metaData.Content); actual != fileHash {
+				return nil, fmt.Errorf("on-chain content hash %s does not match recorded hash %s", actual, fileHash)
+			}
+		}
+
+		return metaData.Content, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]byte), nil
+}
+
+// shouldServeFromChain check if a file should be indexed as metadata only
+func shouldServeFromChain(fileType string, fileSize int64) bool {
+	lazyConf := conf.Cfg.Indexer.LazyContent
+	if !lazyConf.Enabled {
+		return false
+	}
+
+	if lazyConf.MinSize > 0 && fileSize < lazyConf.MinSize*1024 {
+		return false
+	}
+
+	if len(lazyConf.FileTypes) == 0 {
+		return true
+	}
+	for _, t := range lazyConf.FileTypes {
+		if t == fileType {
+			return true
+		}
+	}
+	return false
+}
+
+// errChainContentUnavailable node connection not configured for serve-from-chain files
+var errChainContentUnavailable = errors.New("content is served from chain but no node connection is available")
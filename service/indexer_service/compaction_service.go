@@ -0,0 +1,167 @@
+package indexer_service
+
+import (
+	"fmt"
+	"log"
+
+	"meta-media-service/model"
+	"meta-media-service/model/dao"
+	"meta-media-service/storage"
+)
+
+// CompactionService migrate per-PIN storage objects into the content-addressed layout
+type CompactionService struct {
+	storage              storage.Storage
+	blobService          *BlobService
+	indexerFileDAO       *dao.IndexerFileDAO
+	indexerUserAvatarDAO *dao.IndexerUserAvatarDAO
+	indexerBlobDAO       *dao.IndexerBlobDAO
+
+	seenHashes map[string]bool // Hashes migrated during the current run (for dry run accounting)
+}
+
+// CompactionResult compaction result
+type CompactionResult struct {
+	Scanned      int64 // Records scanned
+	Migrated     int64 // Records moved to a content-addressed blob
+	Deduplicated int64 // Migrated records whose blob already existed
+	Skipped      int64 // Records already content-addressed or served from chain
+	Failed       int64 // Records that could not be migrated
+	BytesFreed   int64 // Bytes no longer stored after deduplication
+}
+
+// NewCompactionService create compaction service instance
+func NewCompactionService(storage storage.Storage) *CompactionService {
+	return &CompactionService{
+		storage:              storage,
+		blobService:          NewBlobService(storage),
+		indexerFileDAO:       dao.NewIndexerFileDAO(),
+		indexerUserAvatarDAO: dao.NewIndexerUserAvatarDAO(),
+		indexerBlobDAO:       dao.NewIndexerBlobDAO(),
+	}
+}
+
+// Compact walk all file and avatar records and move their content to content-addressed blobs
+// dryRun: only report what would be migrated
+func (s *CompactionService) Compact(dryRun bool) (*CompactionResult, error) {
+	result := &CompactionResult{}
+	s.seenHashes = make(map[string]bool)
+
+	err := s.indexerFileDAO.ForEach(func(file *model.IndexerFile) error {
+		// Serve-from-chain files only have an object to migrate when a copy was cached on read
+		if file.StorageType == model.StorageTypeChain && (file.StoragePath == "" || !s.storage.Exists(file.StoragePath)) {
+			result.Scanned++
+			result.Skipped++
+			return nil
+		}
+
+		newPath, ok := s.compactRecord(result, file.PinID, file.StoragePath, file.FileHash, dryRun)
+		if !ok || dryRun {
+			return nil
+		}
+
+		oldPath := file.StoragePath
+		file.StoragePath = newPath
+		if err := s.indexerFileDAO.Update(file); err != nil {
+			s.rollback(result, file.PinID, newPath, file.FileHash, err)
+			return nil
+		}
+		s.removeLegacy(oldPath)
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to compact files: %w", err)
+	}
+
+	err = s.indexerUserAvatarDAO.ForEach(func(avatar *model.IndexerUserAvatar) error {
+		newPath, ok := s.compactRecord(result, avatar.PinID, avatar.Avatar, avatar.FileHash, dryRun)
+		if !ok || dryRun {
+			return nil
+		}
+
+		oldPath := avatar.Avatar
+		avatar.Avatar = newPath
+		if err := s.indexerUserAvatarDAO.Update(avatar); err != nil {
+			s.rollback(result, avatar.PinID, newPath, avatar.FileHash, err)
+			return nil
+		}
+		s.removeLegacy(oldPath)
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to compact avatars: %w", err)
+	}
+
+	return result, nil
+}
+
+// compactRecord move one record's content to its content-addressed blob
+// Returns the new storage path and whether the record should be updated
+func (s *CompactionService) compactRecord(result *CompactionResult, pinID, storagePath, fileHash string, dryRun bool) (string, bool) {
+	result.Scanned++
+
+	if storage.IsContentAddressedKey(storagePath) {
+		result.Skipped++
+		return "", false
+	}
+
+	content, err := s.storage.Get(storagePath)
+	if err != nil {
+		log.Printf("Compaction: failed to read %s (PIN %s): %v", storagePath, pinID, err)
+		result.Failed++
+		return "", false
+	}
+
+	// Never share a blob for content that no longer matches its recorded hash
+	if actualHash := calculateSHA256(content); fileHash == "" || actualHash != fileHash {
+		log.Printf("Compaction: hash mismatch for %s (PIN %s): recorded=%s actual=%s",
+			storagePath, pinID, fileHash, actualHash)
+		result.Failed++
+		return "", false
+	}
+
+	existingBlob, err := s.indexerBlobDAO.GetByHash(fileHash)
+	if err != nil {
+		log.Printf("Compaction: failed to get blob %s: %v", fileHash, err)
+		result.Failed++
+		return "", false
+	}
+	if existingBlob != nil || s.seenHashes[fileHash] {
+		result.Deduplicated++
+		result.BytesFreed += int64(len(content))
+	}
+	result.Migrated++
+	s.seenHashes[fileHash] = true
+
+	if dryRun {
+		log.Printf("Compaction (dry run): PIN %s %s -> %s", pinID, storagePath, storage.ContentAddressedKey(fileHash))
+		return "", false
+	}
+
+	newPath, err := s.blobService.Acquire(content, fileHash)
+	if err != nil {
+		log.Printf("Compaction: failed to store blob for PIN %s: %v", pinID, err)
+		result.Migrated--
+		result.Failed++
+		return "", false
+	}
+
+	return newPath, true
+}
+
+// rollback release the blob reference taken for a record whose update failed
+func (s *CompactionService) rollback(result *CompactionResult, pinID, newPath, fileHash string, cause error) {
+	log.Printf("Compaction: failed to update record for PIN %s: %v", pinID, cause)
+	if err := s.blobService.Release(newPath, fileHash); err != nil {
+		log.Printf("Compaction: failed to release blob %s: %v", newPath, err)
+	}
+	result.Migrated--
+	result.Failed++
+}
+
+// removeLegacy delete the per-PIN object after its record moved to a blob
+func (s *CompactionService) removeLegacy(oldPath string) {
+	if err := s.storage.Delete(oldPath); err != nil {
+		log.Printf("Compaction: failed to delete legacy object %s: %v", oldPath, err)
+	}
+}
@@ -0,0 +1,52 @@
+package indexer_service
+
+import (
+	"image"
+	"log"
+
+	"meta-media-service/filetype"
+	"meta-media-service/imaging"
+	"meta-media-service/model"
+)
+
+// decodeIndexedImage decode image content for index-time analysis
+// Returns nil if the content cannot be decoded (e.g. SVG)
+func decodeIndexedImage(pinID string, content []byte) image.Image {
+	img, _, err := imaging.Decode(content)
+	if err != nil {
+		log.Printf("Skipping image analysis for PIN %s: %v", pinID, err)
+		return nil
+	}
+	return img
+}
+
+// computeImagePlaceholder compute BlurHash and dominant color of an image
+func computeImagePlaceholder(pinID string, img image.Image) (string, string) {
+	xComponents, yComponents := imaging.BlurHashComponents(img)
+	blurHash, err := imaging.BlurHash(img, xComponents, yComponents)
+	if err != nil {
+		log.Printf("Failed to compute BlurHash for PIN %s: %v", pinID, err)
+		return "", ""
+	}
+
+	return blurHash, imaging.DominantColor(img)
+}
+
+// decodableImageTypes detected content types imaging.Decode supports
+var decodableImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// isDecodableImage check if an indexed file is an image that image fields can be computed for
+// Images whose content already failed to decode are excluded, so they are not fetched again.
+func isDecodableImage(file *model.IndexerFile) bool {
+	return file.FileType == "image" && decodableImageTypes[filetype.Normalize(file.DetectedContentType)] &&
+		!file.ImageDecodeFailed
+}
+
+// needsImagePlaceholder check if an indexed file is a decodable image without placeholder
+func needsImagePlaceholder(file *model.IndexerFile) bool {
+	return isDecodableImage(file) && file.BlurHash == ""
+}
@@ -0,0 +1,170 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"sort"
+
+	"meta-media-service/imaging"
+	"meta-media-service/model"
+)
+
+const (
+	// DefaultSimilarDistance default maximum Hamming distance of near-duplicates
+	DefaultSimilarDistance = 8
+	// MaxSimilarDistance largest supported distance (band values are probed within distance/4 = 2)
+	MaxSimilarDistance = 11
+	// maxSimilarCandidates upper bound of band matches checked per query, results are marked truncated beyond it
+	maxSimilarCandidates = 100000
+)
+
+var (
+	ErrInvalidSimilarDistance = errors.New("invalid similarity distance")
+	ErrImageHashNotFound      = errors.New("image hash not found")
+
+	// errSimilarCandidateLimit stops the candidate scan at maxSimilarCandidates
+	errSimilarCandidateLimit = errors.New("similar image candidate limit reached")
+)
+
+// SimilarFile near-duplicate image with its Hamming distance to the query image
+type SimilarFile struct {
+	File     *model.IndexerFile
+	Distance int
+}
+
+// GetSimilarFiles find images whose perceptual hash is within maxDistance of a PIN's
+// Results are ordered by distance, then by timestamp (earliest first, the likely original).
+// Blocked images are left out, a blocked query image returns ErrContentBlocked.
+// truncated: more than maxSimilarCandidates images share a band with the query image, only that many were checked.
+func (s *IndexerFileService) GetSimilarFiles(pinID string, maxDistance, limit int) (similar []*SimilarFile, truncated bool, err error) {
+	if maxDistance < 0 || maxDistance > MaxSimilarDistance {
+		return nil, false, fmt.Errorf("%w: distance must be between 0 and %d", ErrInvalidSimilarDistance, MaxSimilarDistance)
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	source, err := s.imageHashDAO.GetByPinID(pinID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get image hash: %w", err)
+	}
+	if source == nil {
+		return nil, false, ErrImageHashNotFound
+	}
+	if _, err := s.GetFileByPinID(pinID); errors.Is(err, ErrContentBlocked) {
+		return nil, false, err
+	}
+	sourceHash, err := source.Value()
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid image hash of PIN %s: %w", pinID, err)
+	}
+
+	// Pigeonhole: a match within maxDistance is within maxDistance/4 in at least one band
+	radius := maxDistance / model.ImageHashBands
+	var bands [model.ImageHashBands][]int
+	for i, value := range source.Bands() {
+		bands[i] = bandNeighbours(value, radius)
+	}
+
+	// Every band match is checked, only the ones within maxDistance are kept
+	distances := make(map[string]int)
+	var matches []string
+	scanned := 0
+	err = s.imageHashDAO.ForEachByBands(bands, func(candidate *model.IndexerImageHash) error {
+		if scanned >= maxSimilarCandidates {
+			return errSimilarCandidateLimit
+		}
+		scanned++
+		if candidate.PinID == pinID {
+			return nil
+		}
+		value, err := candidate.Value()
+		if err != nil {
+			return nil
+		}
+		if distance := imaging.HammingDistance(sourceHash, value); distance <= maxDistance {
+			distances[candidate.PinID] = distance
+			matches = append(matches, candidate.PinID)
+		}
+		return nil
+	})
+	if errors.Is(err, errSimilarCandidateLimit) {
+		log.Printf("Similar images of PIN %s: stopped after %d candidates", pinID, maxSimilarCandidates)
+		truncated = true
+	} else if err != nil {
+		return nil, false, fmt.Errorf("failed to find similar images: %w", err)
+	}
+
+	var files []*model.IndexerFile
+	for _, matchPinID := range matches {
+		file, err := s.indexerFileDAO.GetByPinID(matchPinID)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get file: %w", err)
+		}
+		if file == nil {
+			continue
+		}
+		similar = append(similar, &SimilarFile{File: file, Distance: distances[matchPinID]})
+		files = append(files, file)
+	}
+
+	// Drop blocked images
+	visible, err := s.moderationService.FilterFiles(files)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(visible) < len(files) {
+		kept := make(map[*model.IndexerFile]bool, len(visible))
+		for _, file := range visible {
+			kept[file] = true
+		}
+		unblocked := similar[:0]
+		for _, item := range similar {
+			if kept[item.File] {
+				unblocked = append(unblocked, item)
+			}
+		}
+		similar = unblocked
+	}
+
+	sort.Slice(similar, func(i, j int) bool {
+		if similar[i].Distance != similar[j].Distance {
+			return similar[i].Distance < similar[j].Distance
+		}
+		return similar[i].File.Timestamp < similar[j].File.Timestamp
+	})
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+
+	return similar, truncated, nil
+}
+
+// saveImageHash compute and save perceptual hash of an indexed image
+// Failures are logged only, they never fail indexing
+func (s *IndexerService) saveImageHash(pinID, fileHash string, img image.Image) {
+	hash := model.NewIndexerImageHash(pinID, fileHash, imaging.DHash(img))
+	if err := s.imageHashDAO.CreateOrUpdate(hash); err != nil {
+		log.Printf("Failed to save image hash for PIN %s: %v", pinID, err)
+	}
+}
+
+// bandNeighbours all 16-bit band values within Hamming distance radius of value
+func bandNeighbours(value, radius int) []int {
+	values := []int{value}
+	if radius >= 1 {
+		for i := 0; i < 16; i++ {
+			values = append(values, value^(1<<i))
+		}
+	}
+	if radius >= 2 {
+		for i := 0; i < 16; i++ {
+			for j := i + 1; j < 16; j++ {
+				values = append(values, value^(1<<i)^(1<<j))
+			}
+		}
+	}
+	return values
+}
@@ -3,19 +3,31 @@ package indexer_service
 import (
 	"errors"
 	"fmt"
+	"log"
 
+	"meta-media-service/conf"
+	"meta-media-service/indexer"
 	"meta-media-service/model"
 	"meta-media-service/model/dao"
 	"meta-media-service/storage"
 
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 )
 
+// chainCacheGroup collapses concurrent caches of the same serve-from-chain file, so a record takes one blob reference
+var chainCacheGroup singleflight.Group
+
 // IndexerFileService indexer file service
 type IndexerFileService struct {
 	indexerFileDAO       *dao.IndexerFileDAO
 	indexerUserAvatarDAO *dao.IndexerUserAvatarDAO
+	mediaMetadataDAO     *dao.IndexerMediaMetadataDAO
+	imageHashDAO         *dao.IndexerImageHashDAO
+	moderationService    *ModerationService // Blocked content is not served and hidden from lists
 	storage              storage.Storage
+	blobService          *BlobService         // Stores content read from chain with the configured layout
+	chainFetcher         *ChainContentFetcher // Extracts content of serve-from-chain files
 }
 
 // NewIndexerFileService create indexer file service instance
@@ -23,11 +35,21 @@ func NewIndexerFileService(storage storage.Storage) *IndexerFileService {
 	return &IndexerFileService{
 		indexerFileDAO:       dao.NewIndexerFileDAO(),
 		indexerUserAvatarDAO: dao.NewIndexerUserAvatarDAO(),
+		mediaMetadataDAO:     dao.NewIndexerMediaMetadataDAO(),
+		imageHashDAO:         dao.NewIndexerImageHashDAO(),
+		moderationService:    NewModerationService(),
 		storage:              storage,
+		blobService:          NewBlobService(storage),
 	}
 }
 
+// SetBlockScanner set block scanner used to fetch serve-from-chain content from node
+func (s *IndexerFileService) SetBlockScanner(scanner *indexer.BlockScanner) {
+	s.chainFetcher = NewChainContentFetcher(scanner)
+}
+
 // GetFileByPinID get file information by PIN ID
+// Returns ErrContentBlocked for blocked files.
 func (s *IndexerFileService) GetFileByPinID(pinID string) (*model.IndexerFile, error) {
 	file, err := s.indexerFileDAO.GetByPinID(pinID)
 	if err != nil {
@@ -36,9 +58,25 @@ func (s *IndexerFileService) GetFileByPinID(pinID string) (*model.IndexerFile, e
 		}
 		return nil, fmt.Errorf("failed to get file: %w", err)
 	}
+	if file == nil {
+		return nil, errors.New("file not found")
+	}
+	if err := s.moderationService.CheckFile(file); err != nil {
+		return nil, err
+	}
 	return file, nil
 }
 
+// GetMediaMetadataByPinID get media metadata of a file by PIN ID
+// Returns nil if the file has no media metadata
+func (s *IndexerFileService) GetMediaMetadataByPinID(pinID string) (*model.IndexerMediaMetadata, error) {
+	metadata, err := s.mediaMetadataDAO.GetByPinID(pinID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media metadata: %w", err)
+	}
+	return metadata, nil
+}
+
 // GetFilesByCreatorAddress get file list by creator address with cursor pagination
 // cursor: last file ID (0 for first page)
 // size: page size
@@ -65,6 +103,12 @@ func (s *IndexerFileService) GetFilesByCreatorAddress(address string, cursor int
 		hasMore = len(files) == size
 	}
 
+	// Blocked files are hidden, the cursor still advances past them
+	files, err = s.moderationService.FilterFiles(files)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
 	return files, nextCursor, hasMore, nil
 }
 
@@ -94,6 +138,12 @@ func (s *IndexerFileService) GetFilesByCreatorMetaID(metaID string, cursor int64
 		hasMore = len(files) == size
 	}
 
+	// Blocked files are hidden, the cursor still advances past them
+	files, err = s.moderationService.FilterFiles(files)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
 	return files, nextCursor, hasMore, nil
 }
 
@@ -123,6 +173,44 @@ func (s *IndexerFileService) ListFiles(cursor int64, size int) ([]*model.Indexer
 		hasMore = len(files) == size
 	}
 
+	// Blocked files are hidden, the cursor still advances past them
+	files, err = s.moderationService.FilterFiles(files)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	return files, nextCursor, hasMore, nil
+}
+
+// ListContentTypeMismatchFiles get files whose detected content contradicts the declared type with cursor pagination
+// cursor: last file ID (0 for first page)
+// size: page size
+// Returns: files, next_cursor, has_more, error
+func (s *IndexerFileService) ListContentTypeMismatchFiles(cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	files, err := s.indexerFileDAO.GetWithContentTypeMismatchCursor(cursor, size)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to list content type mismatch files: %w", err)
+	}
+
+	// Determine next cursor and has_more
+	var nextCursor int64
+	hasMore := false
+
+	if len(files) > 0 {
+		nextCursor = files[len(files)-1].ID
+		hasMore = len(files) == size
+	}
+
+	// Blocked files are hidden, the cursor still advances past them
+	files, err = s.moderationService.FilterFiles(files)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
 	return files, nextCursor, hasMore, nil
 }
 
@@ -134,8 +222,13 @@ func (s *IndexerFileService) GetFileContent(pinID string) ([]byte, string, strin
 		return nil, "", "", err
 	}
 
-	// Read file content from storage layer
-	content, err := s.storage.Get(file.StoragePath)
+	// Read file content from chain or storage layer
+	var content []byte
+	if file.StorageType == model.StorageTypeChain {
+		content, err = s.getChainContent(file)
+	} else {
+		content, err = s.storage.Get(file.StoragePath)
+	}
 	if err != nil {
 		return nil, "", "", fmt.Errorf("failed to get file content: %w", err)
 	}
@@ -143,11 +236,88 @@ func (s *IndexerFileService) GetFileContent(pinID string) ([]byte, string, strin
 	return content, file.ContentType, file.FileName, nil
 }
 
+// getChainContent get content of a serve-from-chain file
+// Served from storage when a cached copy exists, otherwise extracted from the transaction.
+func (s *IndexerFileService) getChainContent(file *model.IndexerFile) ([]byte, error) {
+	cacheOnRead := conf.Cfg.Indexer.LazyContent.CacheOnRead && file.StoragePath != ""
+
+	if cacheOnRead {
+		content, err := s.storage.Get(file.StoragePath)
+		if err == nil {
+			return content, nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Failed to read cached chain content %s: %v", file.StoragePath, err)
+		}
+	}
+
+	if s.chainFetcher == nil {
+		return nil, errChainContentUnavailable
+	}
+
+	content, err := s.chainFetcher.Fetch(file.PinID, file.ChainName, file.FileHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheOnRead {
+		if _, err, _ := chainCacheGroup.Do(file.PinID, func() (interface{}, error) {
+			return nil, s.cacheChainContent(file.PinID, content)
+		}); err != nil {
+			log.Printf("Failed to cache chain content of PIN %s: %v", file.PinID, err)
+		}
+	}
+
+	return content, nil
+}
+
+// cacheChainContent save content read from chain with the configured storage layout
+// With layout "cas" the record is pointed at the shared blob, taking one reference.
+func (s *IndexerFileService) cacheChainContent(pinID string, content []byte) error {
+	// Re-read the record, another read may have cached it meanwhile
+	file, err := s.indexerFileDAO.GetByPinID(pinID)
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return errors.New("file not found")
+	}
+
+	// The record already references its blob, or the layout is per-PIN: only the object is restored
+	if !s.blobService.IsContentAddressed() || storage.IsContentAddressedKey(file.StoragePath) {
+		return s.storage.Save(file.StoragePath, content)
+	}
+
+	blobPath, err := s.blobService.Acquire(content, file.FileHash)
+	if err != nil {
+		return err
+	}
+	file.StoragePath = blobPath
+	if err := s.indexerFileDAO.Update(file); err != nil {
+		if releaseErr := s.blobService.Release(blobPath, file.FileHash); releaseErr != nil {
+			log.Printf("Failed to release blob %s: %v", blobPath, releaseErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
 // GetFilesCount get total count of indexed files
 func (s *IndexerFileService) GetFilesCount() (int64, error) {
 	return s.indexerFileDAO.GetFilesCount()
 }
 
+// GetStorageCacheStats get storage cache statistics (nil if storage cache is disabled)
+func (s *IndexerFileService) GetStorageCacheStats() *storage.CacheStats {
+	cacheStorage, ok := s.storage.(*storage.CacheStorage)
+	if !ok {
+		return nil
+	}
+	stats := cacheStorage.Stats()
+	return &stats
+}
+
 // ListAvatars get avatar list with cursor pagination
 // cursor: last avatar ID (0 for first page)
 // size: page size
@@ -174,10 +344,17 @@ func (s *IndexerFileService) ListAvatars(cursor int64, size int) ([]*model.Index
 		hasMore = len(avatars) == size
 	}
 
+	// Blocked avatars are hidden, the cursor still advances past them
+	avatars, err = s.moderationService.FilterAvatars(avatars)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
 	return avatars, nextCursor, hasMore, nil
 }
 
 // GetLatestAvatarByMetaID get latest avatar information by MetaID
+// Returns ErrContentBlocked if the latest avatar is blocked.
 func (s *IndexerFileService) GetLatestAvatarByMetaID(metaID string) (*model.IndexerUserAvatar, error) {
 	avatar, err := s.indexerUserAvatarDAO.GetByMetaID(metaID)
 	if err != nil {
@@ -186,10 +363,16 @@ func (s *IndexerFileService) GetLatestAvatarByMetaID(metaID string) (*model.Inde
 		}
 		return nil, fmt.Errorf("failed to get avatar: %w", err)
 	}
+	if avatar != nil {
+		if err := s.moderationService.CheckAvatar(avatar); err != nil {
+			return nil, err
+		}
+	}
 	return avatar, nil
 }
 
 // GetLatestAvatarByAddress get latest avatar information by address
+// Returns ErrContentBlocked if the latest avatar is blocked.
 func (s *IndexerFileService) GetLatestAvatarByAddress(address string) (*model.IndexerUserAvatar, error) {
 	avatar, err := s.indexerUserAvatarDAO.GetByAddress(address)
 	if err != nil {
@@ -198,6 +381,11 @@ func (s *IndexerFileService) GetLatestAvatarByAddress(address string) (*model.In
 		}
 		return nil, fmt.Errorf("failed to get avatar: %w", err)
 	}
+	if avatar != nil {
+		if err := s.moderationService.CheckAvatar(avatar); err != nil {
+			return nil, err
+		}
+	}
 	return avatar, nil
 }
 
@@ -211,6 +399,12 @@ func (s *IndexerFileService) GetAvatarContent(pinID string) ([]byte, string, str
 		}
 		return nil, "", "", fmt.Errorf("failed to get avatar: %w", err)
 	}
+	if avatar == nil {
+		return nil, "", "", errors.New("avatar not found")
+	}
+	if err := s.moderationService.CheckAvatar(avatar); err != nil {
+		return nil, "", "", err
+	}
 
 	// Read avatar content from storage layer
 	content, err := s.storage.Get(avatar.Avatar)
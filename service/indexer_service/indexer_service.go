@@ -5,12 +5,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"image"
 	"log"
-	"net/http"
 	"path/filepath"
 	"strings"
 
 	"meta-media-service/conf"
+	"meta-media-service/filetype"
 	"meta-media-service/indexer"
 	"meta-media-service/model"
 	"meta-media-service/model/dao"
@@ -24,7 +25,13 @@ type IndexerService struct {
 	indexerFileDAO       *dao.IndexerFileDAO
 	indexerUserAvatarDAO *dao.IndexerUserAvatarDAO
 	syncStatusDAO        *dao.IndexerSyncStatusDAO
+	mediaMetadataDAO     *dao.IndexerMediaMetadataDAO
+	imageHashDAO         *dao.IndexerImageHashDAO
 	storage              storage.Storage
+	blobService          *BlobService
+	thumbnailService     *ThumbnailService
+	avatarImageService   *AvatarImageService
+	moderationService    *ModerationService
 	chainType            indexer.ChainType
 	parser               *indexer.MetaIDParser
 }
@@ -101,7 +108,13 @@ func NewIndexerServiceWithChain(storage storage.Storage, chainType indexer.Chain
 		indexerFileDAO:       dao.NewIndexerFileDAO(),
 		indexerUserAvatarDAO: dao.NewIndexerUserAvatarDAO(),
 		syncStatusDAO:        dao.NewIndexerSyncStatusDAO(),
+		mediaMetadataDAO:     dao.NewIndexerMediaMetadataDAO(),
+		imageHashDAO:         dao.NewIndexerImageHashDAO(),
 		storage:              storage,
+		blobService:          NewBlobService(storage),
+		thumbnailService:     NewThumbnailService(storage, NewIndexerFileService(storage)),
+		avatarImageService:   NewAvatarImageService(storage, NewIndexerFileService(storage)),
+		moderationService:    NewModerationService(),
 		chainType:            chainType,
 		parser:               parser,
 	}
@@ -157,6 +170,11 @@ func (s *IndexerService) GetScanner() *indexer.BlockScanner {
 	return s.scanner
 }
 
+// GetStorage get storage instance
+func (s *IndexerService) GetStorage() storage.Storage {
+	return s.storage
+}
+
 // onBlockComplete called after each block is successfully scanned
 func (s *IndexerService) onBlockComplete(height int64) error {
 	chainName := string(s.chainType)
@@ -278,7 +296,13 @@ func (s *IndexerService) processFileContent(metaData *indexer.MetaIDData, height
 	fileName := extractFileName(metaData.Path)
 
 	// Detect real content type from file content
-	realContentType := detectRealContentType(metaData.Content, metaData.ContentType)
+	realContentType, detectedContentType := detectRealContentType(metaData.Content, metaData.ContentType)
+
+	// Flag content that contradicts its declared type (e.g. an executable declared as an image)
+	contentTypeMismatch := filetype.Mismatch(metaData.ContentType, detectedContentType)
+	if contentTypeMismatch {
+		log.Printf("Content type mismatch: PIN=%s, Declared=%s, Detected=%s", metaData.PinID, metaData.ContentType, detectedContentType)
+	}
 
 	// Extract file extension (using real content type and path)
 	fileExtension := extractFileExtension(metaData.Path, realContentType, metaData.Content)
@@ -290,6 +314,10 @@ func (s *IndexerService) processFileContent(metaData *indexer.MetaIDData, height
 	// Detect file type from real content type
 	fileType := detectFileType(realContentType)
 
+	// Content matching a hash block is indexed as blocked: nothing is written to storage
+	// and no derivatives are generated
+	hashBlock := s.getHashBlock(metaData.PinID, fileHash)
+
 	// Determine storage path: indexer/{chain}/{txid}/{pinid}{extension}
 	// Use pinID as filename to ensure uniqueness, with file extension
 	storagePath := fmt.Sprintf("indexer/%s/%s%s",
@@ -299,57 +327,112 @@ func (s *IndexerService) processFileContent(metaData *indexer.MetaIDData, height
 
 	// Save file to storage
 	storageType := "local"
-	if conf.Cfg.Storage.Type == "oss" {
-		storageType = "oss"
-	}
+	if conf.Cfg.Storage.Type == "oss" || conf.Cfg.Storage.Type == "s3" {
+		storageType = conf.Cfg.Storage.Type
+	}
+
+	if hashBlock != nil {
+		// Metadata only, content is extracted from the transaction should the block be lifted
+		storageType = model.StorageTypeChain
+		log.Printf("File content is blocked, skipping storage: %s", metaData.PinID)
+	} else if shouldServeFromChain(fileType, int64(len(metaData.Content))) {
+		// Metadata only, content is extracted from the transaction on read
+		storageType = model.StorageTypeChain
+		log.Printf("File served from chain, skipping storage: %s (size: %d bytes)", metaData.PinID, len(metaData.Content))
+	} else {
+		var err error
+		storagePath, err = s.blobService.Store(metaData.Content, fileHash, storagePath)
+		if err != nil {
+			return fmt.Errorf("failed to save file to storage: %w", err)
+		}
 
-	if err := s.storage.Save(storagePath, metaData.Content); err != nil {
-		return fmt.Errorf("failed to save file to storage: %w", err)
+		log.Printf("File saved to storage: %s (size: %d bytes)", storagePath, len(metaData.Content))
 	}
 
-	log.Printf("File saved to storage: %s (size: %d bytes)", storagePath, len(metaData.Content))
-
 	// Calculate Creator MetaID (SHA256 of address)
 	creatorMetaID := calculateMetaID(creatorAddress)
 
+	// Compute progressive loading placeholder for images
+	var img image.Image
+	var blurHash, dominantColor string
+	imageDecodeFailed := false
+	if fileType == "image" && hashBlock == nil {
+		if img = decodeIndexedImage(metaData.PinID, metaData.Content); img != nil {
+			blurHash, dominantColor = computeImagePlaceholder(metaData.PinID, img)
+		} else {
+			imageDecodeFailed = decodableImageTypes[filetype.Normalize(detectedContentType)]
+		}
+	}
+
 	// Create database record
 	indexerFile := &model.IndexerFile{
-		PinID:          metaData.PinID,
-		TxID:           metaData.TxID,
-		Vout:           metaData.Vout,
-		Path:           metaData.Path,
-		Operation:      metaData.Operation,
-		ParentPath:     metaData.ParentPath,
-		Encryption:     metaData.Encryption,
-		Version:        metaData.Version,
-		ContentType:    metaData.ContentType,
-		FileType:       fileType,
-		FileExtension:  fileExtension,
-		FileName:       fileName,
-		FileSize:       int64(len(metaData.Content)),
-		FileMd5:        fileMd5,
-		FileHash:       fileHash,
-		StorageType:    storageType,
-		StoragePath:    storagePath,
-		ChainName:      metaData.ChainName,
-		BlockHeight:    height,
-		Timestamp:      timestamp,
-		CreatorMetaId:  creatorMetaID,
-		CreatorAddress: creatorAddress, // Use real creator address
-		OwnerAddress:   metaData.OwnerAddress,
-		OwnerMetaId:    calculateMetaID(metaData.OwnerAddress),
-		Status:         model.StatusSuccess,
-		State:          0,
+		PinID:               metaData.PinID,
+		TxID:                metaData.TxID,
+		Vout:                metaData.Vout,
+		Path:                metaData.Path,
+		Operation:           metaData.Operation,
+		ParentPath:          metaData.ParentPath,
+		Encryption:          metaData.Encryption,
+		Version:             metaData.Version,
+		ContentType:         metaData.ContentType,
+		DetectedContentType: detectedContentType,
+		ContentTypeMismatch: contentTypeMismatch,
+		FileType:            fileType,
+		FileExtension:       fileExtension,
+		FileName:            fileName,
+		FileSize:            int64(len(metaData.Content)),
+		FileMd5:             fileMd5,
+		FileHash:            fileHash,
+		BlurHash:            blurHash,
+		DominantColor:       dominantColor,
+		ImageDecodeFailed:   imageDecodeFailed,
+		StorageType:         storageType,
+		StoragePath:         storagePath,
+		ChainName:           metaData.ChainName,
+		BlockHeight:         height,
+		Timestamp:           timestamp,
+		CreatorMetaId:       creatorMetaID,
+		CreatorAddress:      creatorAddress, // Use real creator address
+		OwnerAddress:        metaData.OwnerAddress,
+		OwnerMetaId:         calculateMetaID(metaData.OwnerAddress),
+		Status:              model.StatusSuccess,
+		State:               0,
 	}
 
 	// Save to database
 	if err := s.indexerFileDAO.Create(indexerFile); err != nil {
+		// Drop the blob reference taken above so it does not leak
+		if s.blobService.IsContentAddressed() && storageType != model.StorageTypeChain {
+			if releaseErr := s.blobService.Release(storagePath, fileHash); releaseErr != nil {
+				log.Printf("Failed to release blob %s: %v", storagePath, releaseErr)
+			}
+		}
 		return fmt.Errorf("failed to save file to database: %w", err)
 	}
 
 	log.Printf("File indexed successfully: PIN=%s, Path=%s, Type=%s, Ext=%s, Size=%d",
 		metaData.PinID, metaData.Path, fileType, fileExtension, len(metaData.Content))
 
+	if hashBlock != nil {
+		s.moderationService.RecordHashMatch(hashBlock, metaData.PinID)
+		return nil
+	}
+
+	// Extract image/video/audio metadata
+	if isMediaFileType(fileType) {
+		s.saveMediaMetadata(metaData.PinID, fileHash, metaData.Content, realContentType)
+	}
+
+	// Save perceptual hash for near-duplicate lookup
+	if img != nil {
+		s.saveImageHash(metaData.PinID, fileHash, img)
+	}
+
+	// Pre-generate standard thumbnail sizes
+	if fileType == "image" && len(conf.Cfg.Indexer.Thumbnail.Pregenerate) > 0 {
+		s.thumbnailService.Pregenerate(metaData.Content, fileHash, metaData.ContentType)
+	}
+
 	return nil
 }
 
@@ -369,7 +452,7 @@ func (s *IndexerService) processAvatarContent(metaData *indexer.MetaIDData, heig
 	}
 
 	// Detect real content type from file content
-	realContentType := detectRealContentType(metaData.Content, metaData.ContentType)
+	realContentType, _ := detectRealContentType(metaData.Content, metaData.ContentType)
 
 	// Extract file extension from real content type
 	fileExtension := extractAvatarFileExtension(realContentType, metaData.Content)
@@ -390,7 +473,8 @@ func (s *IndexerService) processAvatarContent(metaData *indexer.MetaIDData, heig
 		fileExtension)
 
 	// Save file to storage
-	if err := s.storage.Save(storagePath, metaData.Content); err != nil {
+	storagePath, err := s.blobService.Store(metaData.Content, fileHash, storagePath)
+	if err != nil {
 		return fmt.Errorf("failed to save avatar to storage: %w", err)
 	}
 
@@ -399,6 +483,12 @@ func (s *IndexerService) processAvatarContent(metaData *indexer.MetaIDData, heig
 	// Calculate Creator MetaID (SHA256 of address)
 	creatorMetaID := calculateMetaID(creatorAddress)
 
+	// Previous latest avatar, its cached renderings are dropped if this one replaces it
+	previousAvatar, err := s.indexerUserAvatarDAO.GetByMetaID(creatorMetaID)
+	if err != nil {
+		log.Printf("Failed to get previous avatar for MetaID %s: %v", creatorMetaID, err)
+	}
+
 	// Create database record
 	indexerUserAvatar := &model.IndexerUserAvatar{
 		PinID:         metaData.PinID,
@@ -419,15 +509,44 @@ func (s *IndexerService) processAvatarContent(metaData *indexer.MetaIDData, heig
 
 	// Save to database
 	if err := s.indexerUserAvatarDAO.Create(indexerUserAvatar); err != nil {
+		// Drop the blob reference taken above so it does not leak
+		if s.blobService.IsContentAddressed() {
+			if releaseErr := s.blobService.Release(storagePath, fileHash); releaseErr != nil {
+				log.Printf("Failed to release blob %s: %v", storagePath, releaseErr)
+			}
+		}
 		return fmt.Errorf("failed to save avatar to database: %w", err)
 	}
 
 	log.Printf("Avatar indexed successfully: PIN=%s, Path=%s, Type=%s, Ext=%s, Size=%d, MetaID=%s, Address=%s",
 		metaData.PinID, metaData.Path, fileType, fileExtension, len(metaData.Content), creatorMetaID, creatorAddress)
 
+	// Avatars are always stored, a hash block keeps them from being served
+	if hashBlock := s.getHashBlock(metaData.PinID, fileHash); hashBlock != nil {
+		s.moderationService.RecordHashMatch(hashBlock, metaData.PinID)
+	}
+
+	// Drop cached renderings of the previous avatar once this one became the latest
+	if previousAvatar != nil && previousAvatar.PinID != metaData.PinID {
+		if latest, err := s.indexerUserAvatarDAO.GetByMetaID(creatorMetaID); err == nil && latest != nil && latest.PinID == metaData.PinID {
+			s.avatarImageService.InvalidateAvatar(previousAvatar.PinID)
+		}
+	}
+
 	return nil
 }
 
+// getHashBlock get the block of content being indexed, nil if its hash is not blocked
+// Lookup failures are logged only, blocked content is still refused when served.
+func (s *IndexerService) getHashBlock(pinID, fileHash string) *model.IndexerBlock {
+	block, err := s.moderationService.GetHashBlock(fileHash)
+	if err != nil {
+		log.Printf("Failed to check hash block for PIN %s: %v", pinID, err)
+		return nil
+	}
+	return block
+}
+
 // extractFileName extract file name from path (may return empty string)
 func extractFileName(path string) string {
 	// Remove host prefix if exists (e.g., "host:/file/test.jpg" -> "/file/test.jpg")
@@ -448,25 +567,18 @@ func extractFileName(path string) string {
 }
 
 // detectRealContentType detect real content type from file content
-func detectRealContentType(content []byte, declaredContentType string) string {
-	// Use http.DetectContentType to detect real content type from file content
-	// This function reads the first 512 bytes to determine the content type
-	detectedType := http.DetectContentType(content)
-
-	// Log if detected type differs from declared type
-	if detectedType != declaredContentType {
-		log.Printf("Content type mismatch - Declared: %s, Detected: %s", declaredContentType, detectedType)
-	}
+// Returns the content type used for classification (detected, or declared when the
+// content is not recognized) and the raw detected content type.
+func detectRealContentType(content []byte, declaredContentType string) (string, string) {
+	// Signatures and container structure (ZIP/ISO BMFF/RIFF/EBML/Ogg/OLE2), net/http sniffing for text
+	detectedType := filetype.Detect(content)
 
-	// Prefer detected type over declared type for better accuracy
-	// But for some specific types that http.DetectContentType can't detect well,
-	// we trust the declared type
-	if detectedType == "application/octet-stream" && declaredContentType != "" {
-		// If detection returns generic binary type but we have a declared type, use declared
-		return declaredContentType
+	// Unrecognized binary content: trust the declared type
+	if detectedType == filetype.OctetStream && declaredContentType != "" {
+		return declaredContentType, detectedType
 	}
 
-	return detectedType
+	return detectedType, detectedType
 }
 
 // extractFileExtension extract file extension from path, content type, or file content
@@ -511,14 +623,24 @@ func contentTypeToExtension(contentType string) string {
 		"image/bmp":     ".bmp",
 		"image/tiff":    ".tiff",
 		"image/ico":     ".ico",
+		"image/x-icon":  ".ico",
+		"image/heic":    ".heic",
+		"image/heif":    ".heif",
+		"image/avif":    ".avif",
+		"image/jxl":     ".jxl",
 
 		// Videos
-		"video/mp4":       ".mp4",
-		"video/mpeg":      ".mpeg",
-		"video/webm":      ".webm",
-		"video/ogg":       ".ogv",
-		"video/quicktime": ".mov",
-		"video/x-msvideo": ".avi",
+		"video/mp4":        ".mp4",
+		"video/mpeg":       ".mpeg",
+		"video/webm":       ".webm",
+		"video/ogg":        ".ogv",
+		"video/quicktime":  ".mov",
+		"video/x-msvideo":  ".avi",
+		"video/x-matroska": ".mkv",
+		"video/x-m4v":      ".m4v",
+		"video/3gpp":       ".3gp",
+		"video/x-flv":      ".flv",
+		"video/mp2t":       ".ts",
 
 		// Audio
 		"audio/mpeg": ".mp3",
@@ -528,6 +650,10 @@ func contentTypeToExtension(contentType string) string {
 		"audio/webm": ".weba",
 		"audio/aac":  ".aac",
 		"audio/flac": ".flac",
+		"audio/mp4":  ".m4a",
+		"audio/opus": ".opus",
+		"audio/aiff": ".aiff",
+		"audio/midi": ".mid",
 
 		// Documents
 		"application/pdf":    ".pdf",
@@ -537,6 +663,11 @@ func contentTypeToExtension(contentType string) string {
 		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         ".xlsx",
 		"application/vnd.ms-powerpoint":                                             ".ppt",
 		"application/vnd.openxmlformats-officedocument.presentationml.presentation": ".pptx",
+		"application/vnd.oasis.opendocument.text":                                   ".odt",
+		"application/vnd.oasis.opendocument.spreadsheet":                            ".ods",
+		"application/vnd.oasis.opendocument.presentation":                           ".odp",
+		"application/epub+zip":                                                      ".epub",
+		"application/rtf":                                                           ".rtf",
 
 		// Text
 		"text/plain":             ".txt",
@@ -556,6 +687,23 @@ func contentTypeToExtension(contentType string) string {
 		"application/x-7z-compressed":  ".7z",
 		"application/x-tar":            ".tar",
 		"application/gzip":             ".gz",
+		"application/vnd.rar":          ".rar",
+		"application/x-bzip2":          ".bz2",
+		"application/x-xz":             ".xz",
+		"application/zstd":             ".zst",
+		"application/java-archive":     ".jar",
+
+		// Executables
+		"application/vnd.microsoft.portable-executable": ".exe",
+		"application/vnd.android.package-archive":       ".apk",
+		"application/wasm":                              ".wasm",
+		"text/x-shellscript":                            ".sh",
+
+		// Fonts
+		"font/woff":  ".woff",
+		"font/woff2": ".woff2",
+		"font/ttf":   ".ttf",
+		"font/otf":   ".otf",
 	}
 
 	if ext, ok := extensionMap[contentType]; ok {
@@ -587,11 +735,13 @@ func detectFileType(contentType string) string {
 	case strings.Contains(contentType, "pdf"):
 		return "document"
 	case strings.Contains(contentType, "word") || strings.Contains(contentType, "excel") ||
-		strings.Contains(contentType, "powerpoint") || strings.Contains(contentType, "document"):
+		strings.Contains(contentType, "powerpoint") || strings.Contains(contentType, "document") ||
+		contentType == "application/epub+zip" || contentType == "application/rtf":
 		return "document"
 	case strings.Contains(contentType, "zip") || strings.Contains(contentType, "rar") ||
 		strings.Contains(contentType, "tar") || strings.Contains(contentType, "gzip") ||
-		strings.Contains(contentType, "compressed"):
+		strings.Contains(contentType, "compressed") || contentType == "application/x-xz" ||
+		contentType == "application/zstd":
 		return "archive"
 	case strings.Contains(contentType, "json") || strings.Contains(contentType, "xml"):
 		return "data"
@@ -0,0 +1,68 @@
+package indexer_service
+
+import (
+	"errors"
+	"log"
+
+	"meta-media-service/mediainfo"
+	"meta-media-service/model"
+)
+
+// isMediaFileType check if file type carries extractable media metadata
+func isMediaFileType(fileType string) bool {
+	return fileType == "image" || fileType == "video" || fileType == "audio"
+}
+
+// extractMediaMetadata extract media metadata from content
+// Returns nil if the container is not supported
+func extractMediaMetadata(pinID, fileHash string, content []byte, contentType string) *model.IndexerMediaMetadata {
+	info, err := mediainfo.Extract(content, contentType)
+	if err != nil {
+		if !errors.Is(err, mediainfo.ErrUnsupported) {
+			log.Printf("Failed to extract media metadata for PIN %s: %v", pinID, err)
+		}
+		return nil
+	}
+
+	return &model.IndexerMediaMetadata{
+		PinID:       pinID,
+		FileHash:    fileHash,
+		MediaType:   info.MediaType,
+		Format:      info.Format,
+		Width:       info.Width,
+		Height:      info.Height,
+		Orientation: info.Orientation,
+		FrameCount:  info.FrameCount,
+		DurationMs:  info.DurationMs,
+		Bitrate:     info.Bitrate,
+		VideoCodec:  info.VideoCodec,
+		AudioCodec:  info.AudioCodec,
+		SampleRate:  info.SampleRate,
+		Channels:    info.Channels,
+		CameraMake:  info.CameraMake,
+		CameraModel: info.CameraModel,
+		CaptureTime: info.CaptureTime,
+		HasGPS:      info.HasGPS,
+		Title:       info.Title,
+		Artist:      info.Artist,
+		Album:       info.Album,
+	}
+}
+
+// saveMediaMetadata extract and save media metadata of an indexed file
+// Failures are logged only, they never fail indexing
+func (s *IndexerService) saveMediaMetadata(pinID, fileHash string, content []byte, contentType string) {
+	metadata := extractMediaMetadata(pinID, fileHash, content, contentType)
+	if metadata == nil {
+		return
+	}
+
+	if err := s.mediaMetadataDAO.CreateOrUpdate(metadata); err != nil {
+		log.Printf("Failed to save media metadata for PIN %s: %v", pinID, err)
+		return
+	}
+
+	if metadata.HasGPS {
+		log.Printf("Media metadata saved: PIN=%s, Format=%s (source contains GPS data, not stored)", pinID, metadata.Format)
+	}
+}
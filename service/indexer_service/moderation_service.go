@@ -0,0 +1,336 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"meta-media-service/database"
+	"meta-media-service/model"
+	"meta-media-service/model/dao"
+)
+
+// moderationOperator operator recorded for blocks applied by the indexer
+const moderationOperator = "indexer"
+
+var (
+	ErrContentBlocked = errors.New("content is unavailable for legal reasons")
+	ErrInvalidBlock   = errors.New("invalid block")
+	ErrBlockExists    = errors.New("target is already blocked")
+	ErrBlockNotFound  = errors.New("block not found")
+)
+
+var (
+	pinIDPattern  = regexp.MustCompile(`^[0-9a-f]{64}i[0-9]+$`)
+	sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// ModerationService moderation blocklist
+// Targets are blocked by PIN ID, content hash, creator address or creator MetaID. Every change
+// is recorded in an append-only audit log.
+type ModerationService struct {
+	blockDAO *dao.IndexerBlockDAO
+}
+
+// NewModerationService create moderation service instance
+func NewModerationService() *ModerationService {
+	return &ModerationService{
+		blockDAO: dao.NewIndexerBlockDAO(),
+	}
+}
+
+// Block add a target to the blocklist
+func (s *ModerationService) Block(blockType, value, reason, operator string) (*model.IndexerBlock, error) {
+	value, err := normalizeBlockValue(blockType, value)
+	if err != nil {
+		return nil, err
+	}
+	reason = strings.TrimSpace(reason)
+	operator = strings.TrimSpace(operator)
+	if reason == "" || operator == "" {
+		return nil, fmt.Errorf("%w: reason and operator are required", ErrInvalidBlock)
+	}
+
+	block := &model.IndexerBlock{
+		BlockType: blockType,
+		Value:     value,
+		Reason:    reason,
+		Operator:  operator,
+	}
+	if err := s.blockDAO.Create(block); err != nil {
+		if errors.Is(err, database.ErrDuplicate) {
+			return nil, fmt.Errorf("%w: %s %s", ErrBlockExists, blockType, value)
+		}
+		return nil, fmt.Errorf("failed to create block: %w", err)
+	}
+
+	log.Printf("Blocked %s %s by %s: %s", blockType, value, operator, reason)
+	s.audit(block, model.BlockActionBlock, "", reason, operator)
+
+	return block, nil
+}
+
+// Unblock remove a blocklist entry by ID
+func (s *ModerationService) Unblock(id int64, reason, operator string) (*model.IndexerBlock, error) {
+	operator = strings.TrimSpace(operator)
+	if operator == "" {
+		return nil, fmt.Errorf("%w: operator is required", ErrInvalidBlock)
+	}
+
+	block, err := s.blockDAO.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block: %w", err)
+	}
+	if block == nil {
+		return nil, ErrBlockNotFound
+	}
+
+	if err := s.blockDAO.Delete(id); err != nil {
+		return nil, fmt.Errorf("failed to delete block: %w", err)
+	}
+
+	log.Printf("Unblocked %s %s by %s", block.BlockType, block.Value, operator)
+	s.audit(block, model.BlockActionUnblock, "", strings.TrimSpace(reason), operator)
+
+	return block, nil
+}
+
+// ListBlocks get blocklist entries with cursor pagination
+// blockType: only entries of this type (empty for all)
+// cursor: last block ID (0 for first page)
+// size: page size
+// Returns: blocks, next_cursor, has_more, error
+func (s *ModerationService) ListBlocks(blockType string, cursor int64, size int) ([]*model.IndexerBlock, int64, bool, error) {
+	if blockType != "" && !isBlockType(blockType) {
+		return nil, 0, false, fmt.Errorf("%w: unknown type %q", ErrInvalidBlock, blockType)
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	blocks, err := s.blockDAO.ListWithCursor(blockType, cursor, size)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to list blocks: %w", err)
+	}
+
+	var nextCursor int64
+	hasMore := false
+
+	if len(blocks) > 0 {
+		nextCursor = blocks[len(blocks)-1].ID
+		hasMore = len(blocks) == size
+	}
+
+	return blocks, nextCursor, hasMore, nil
+}
+
+// ListAudits get moderation audit log with cursor pagination
+// cursor: last audit entry ID (0 for first page)
+// size: page size
+// Returns: audits, next_cursor, has_more, error
+func (s *ModerationService) ListAudits(cursor int64, size int) ([]*model.IndexerBlockAudit, int64, bool, error) {
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	audits, err := s.blockDAO.ListAuditsWithCursor(cursor, size)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to list audit log: %w", err)
+	}
+
+	var nextCursor int64
+	hasMore := false
+
+	if len(audits) > 0 {
+		nextCursor = audits[len(audits)-1].ID
+		hasMore = len(audits) == size
+	}
+
+	return audits, nextCursor, hasMore, nil
+}
+
+// CheckFile return ErrContentBlocked if a file is blocked
+func (s *ModerationService) CheckFile(file *model.IndexerFile) error {
+	return s.check(fileBlockKeys(file))
+}
+
+// CheckAvatar return ErrContentBlocked if an avatar is blocked
+func (s *ModerationService) CheckAvatar(avatar *model.IndexerUserAvatar) error {
+	return s.check(avatarBlockKeys(avatar))
+}
+
+// FilterFiles drop blocked files from a list, keeping the order
+func (s *ModerationService) FilterFiles(files []*model.IndexerFile) ([]*model.IndexerFile, error) {
+	var keys []model.BlockKey
+	for _, file := range files {
+		keys = append(keys, fileBlockKeys(file)...)
+	}
+	blocked, err := s.find(keys)
+	if err != nil || len(blocked) == 0 {
+		return files, err
+	}
+
+	visible := make([]*model.IndexerFile, 0, len(files))
+	for _, file := range files {
+		if !blocked.matches(fileBlockKeys(file)) {
+			visible = append(visible, file)
+		}
+	}
+	return visible, nil
+}
+
+// FilterAvatars drop blocked avatars from a list, keeping the order
+func (s *ModerationService) FilterAvatars(avatars []*model.IndexerUserAvatar) ([]*model.IndexerUserAvatar, error) {
+	var keys []model.BlockKey
+	for _, avatar := range avatars {
+		keys = append(keys, avatarBlockKeys(avatar)...)
+	}
+	blocked, err := s.find(keys)
+	if err != nil || len(blocked) == 0 {
+		return avatars, err
+	}
+
+	visible := make([]*model.IndexerUserAvatar, 0, len(avatars))
+	for _, avatar := range avatars {
+		if !blocked.matches(avatarBlockKeys(avatar)) {
+			visible = append(visible, avatar)
+		}
+	}
+	return visible, nil
+}
+
+// GetHashBlock get the block of a content hash, nil if the hash is not blocked
+func (s *ModerationService) GetHashBlock(fileHash string) (*model.IndexerBlock, error) {
+	blocks, err := s.blockDAO.Find([]model.BlockKey{{BlockType: model.BlockTypeHash, Value: fileHash}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find hash block: %w", err)
+	}
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	return blocks[0], nil
+}
+
+// RecordHashMatch record in the audit log that a hash block was applied to a newly indexed PIN
+func (s *ModerationService) RecordHashMatch(block *model.IndexerBlock, pinID string) {
+	log.Printf("Content of PIN %s matches hash block %d: %s", pinID, block.ID, block.Reason)
+	s.audit(block, model.BlockActionMatch, pinID, block.Reason, moderationOperator)
+}
+
+// check return ErrContentBlocked if any key is blocked
+func (s *ModerationService) check(keys []model.BlockKey) error {
+	blocked, err := s.find(keys)
+	if err != nil {
+		return err
+	}
+	if len(blocked) > 0 {
+		return ErrContentBlocked
+	}
+	return nil
+}
+
+// find get blocked keys among keys
+func (s *ModerationService) find(keys []model.BlockKey) (blockedKeys, error) {
+	blocks, err := s.blockDAO.Find(keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check blocklist: %w", err)
+	}
+
+	blocked := make(blockedKeys, len(blocks))
+	for _, block := range blocks {
+		blocked[model.BlockKey{BlockType: block.BlockType, Value: block.Value}] = true
+	}
+	return blocked, nil
+}
+
+// audit append an audit log entry, failures are logged only
+func (s *ModerationService) audit(block *model.IndexerBlock, action, pinID, reason, operator string) {
+	audit := &model.IndexerBlockAudit{
+		BlockID:   block.ID,
+		Action:    action,
+		BlockType: block.BlockType,
+		Value:     block.Value,
+		PinID:     pinID,
+		Reason:    reason,
+		Operator:  operator,
+	}
+	if err := s.blockDAO.CreateAudit(audit); err != nil {
+		log.Printf("Failed to write moderation audit log (%s %s %s): %v", action, block.BlockType, block.Value, err)
+	}
+}
+
+// blockedKeys set of blocked keys
+type blockedKeys map[model.BlockKey]bool
+
+// matches check if any key is blocked
+func (b blockedKeys) matches(keys []model.BlockKey) bool {
+	for _, key := range keys {
+		if b[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// fileBlockKeys blocklist keys a file is blocked by
+func fileBlockKeys(file *model.IndexerFile) []model.BlockKey {
+	return blockKeys(file.PinID, file.FileHash, file.CreatorAddress, file.CreatorMetaId)
+}
+
+// avatarBlockKeys blocklist keys an avatar is blocked by
+func avatarBlockKeys(avatar *model.IndexerUserAvatar) []model.BlockKey {
+	return blockKeys(avatar.PinID, avatar.FileHash, avatar.Address, avatar.MetaId)
+}
+
+// blockKeys blocklist keys of a PIN, empty values are skipped
+func blockKeys(pinID, fileHash, address, metaID string) []model.BlockKey {
+	candidates := []model.BlockKey{
+		{BlockType: model.BlockTypePin, Value: pinID},
+		{BlockType: model.BlockTypeHash, Value: fileHash},
+		{BlockType: model.BlockTypeAddress, Value: address},
+		{BlockType: model.BlockTypeMetaID, Value: metaID},
+	}
+	keys := candidates[:0]
+	for _, key := range candidates {
+		if key.Value != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// isBlockType check if blockType is a known block type
+func isBlockType(blockType string) bool {
+	switch blockType {
+	case model.BlockTypePin, model.BlockTypeHash, model.BlockTypeAddress, model.BlockTypeMetaID:
+		return true
+	}
+	return false
+}
+
+// normalizeBlockValue validate a block target and bring it into the indexed form
+// PIN IDs, hashes and MetaIDs are lower-case hex, addresses are kept as is.
+func normalizeBlockValue(blockType, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch blockType {
+	case model.BlockTypePin:
+		value = strings.ToLower(value)
+		if !pinIDPattern.MatchString(value) {
+			return "", fmt.Errorf("%w: PIN ID must be {txid}i{vout}", ErrInvalidBlock)
+		}
+	case model.BlockTypeHash, model.BlockTypeMetaID:
+		value = strings.ToLower(value)
+		if !sha256Pattern.MatchString(value) {
+			return "", fmt.Errorf("%w: %s must be 64 hex characters", ErrInvalidBlock, blockType)
+		}
+	case model.BlockTypeAddress:
+		if value == "" || len(value) > 255 {
+			return "", fmt.Errorf("%w: address must be 1-255 characters", ErrInvalidBlock)
+		}
+	default:
+		return "", fmt.Errorf("%w: type must be pin, hash, address or metaid", ErrInvalidBlock)
+	}
+	return value, nil
+}
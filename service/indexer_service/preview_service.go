@@ -0,0 +1,55 @@
+package indexer_service
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"meta-media-service/conf"
+	"meta-media-service/preview"
+)
+
+// PreviewService rendered preview service for text, Markdown, JSON and code files
+type PreviewService struct {
+	indexerFileService *IndexerFileService
+}
+
+// NewPreviewService create preview service instance
+func NewPreviewService(indexerFileService *IndexerFileService) *PreviewService {
+	return &PreviewService{
+		indexerFileService: indexerFileService,
+	}
+}
+
+// GetPreview render preview of a file by PIN ID
+// The declared content type and file name choose the renderer, the detected type is used
+// when the declared one has no preview (e.g. JSON declared as application/octet-stream).
+func (s *PreviewService) GetPreview(pinID string) (*preview.Preview, error) {
+	file, err := s.indexerFileService.GetFileByPinID(pinID)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := file.ContentType
+	if _, _, err := preview.Classify(contentType, file.FileName); errors.Is(err, preview.ErrUnsupported) && file.DetectedContentType != "" {
+		contentType = file.DetectedContentType
+	}
+	if _, _, err := preview.Classify(contentType, file.FileName); err != nil {
+		return nil, err
+	}
+
+	content, _, _, err := s.indexerFileService.GetFileContent(pinID)
+	if err != nil {
+		return nil, err
+	}
+
+	return preview.Render(content, contentType, file.FileName, preview.Options{
+		MaxSize: conf.Cfg.Indexer.Preview.MaxSize,
+		FullURL: ContentURL(pinID),
+	})
+}
+
+// ContentURL URL of the raw content of a file, on the content origin when configured
+func ContentURL(pinID string) string {
+	return strings.TrimRight(conf.Cfg.Indexer.ContentServing.ContentOrigin, "/") + "/api/v1/files/content/" + url.PathEscape(pinID)
+}
@@ -0,0 +1,186 @@
+package indexer_service
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"meta-media-service/conf"
+	"meta-media-service/sanitize"
+	"meta-media-service/storage"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// SVG serving modes
+const (
+	SVGModeSanitize   = "sanitize"
+	SVGModeAttachment = "attachment"
+)
+
+// activeContentTypes content types a browser may execute script in when rendered inline
+var activeContentTypes = map[string]bool{
+	"text/html":                     true,
+	"application/xhtml+xml":         true,
+	"application/vnd.wap.xhtml+xml": true,
+	"text/xml":                      true,
+	"application/xml":               true,
+	"text/xsl":                      true,
+	"application/xslt+xml":          true,
+	"image/svg+xml":                 true,
+	"application/javascript":        true,
+	"application/x-javascript":      true,
+	"application/ecmascript":        true,
+	"text/javascript":               true,
+	"text/ecmascript":               true,
+	"application/x-shockwave-flash": true,
+	"multipart/x-mixed-replace":     true,
+}
+
+// ServedContent content prepared for serving to browsers
+type ServedContent struct {
+	Content     []byte
+	ContentType string
+	FileName    string
+	Attachment  bool // Force download instead of inline rendering
+	Sanitized   bool // Content was replaced by a sanitized copy
+}
+
+// SafeContentService prepares raw on-chain content for serving
+// Active content (HTML, XML, script) is forced to download; SVG is sanitized or forced to download.
+type SafeContentService struct {
+	storage            storage.Storage
+	indexerFileService *IndexerFileService
+	group              singleflight.Group
+}
+
+// NewSafeContentService create safe content service instance
+func NewSafeContentService(storage storage.Storage, indexerFileService *IndexerFileService) *SafeContentService {
+	return &SafeContentService{
+		storage:            storage,
+		indexerFileService: indexerFileService,
+	}
+}
+
+// GetFileContent get file content by PIN ID with the serving policy applied
+func (s *SafeContentService) GetFileContent(pinID string) (*ServedContent, error) {
+	content, contentType, fileName, err := s.indexerFileService.GetFileContent(pinID)
+	if err != nil {
+		return nil, err
+	}
+	return s.prepare(content, contentType, fileName), nil
+}
+
+// GetAvatarContent get avatar content by PIN ID with the serving policy applied
+func (s *SafeContentService) GetAvatarContent(pinID string) (*ServedContent, error) {
+	content, contentType, fileName, err := s.indexerFileService.GetAvatarContent(pinID)
+	if err != nil {
+		return nil, err
+	}
+	return s.prepare(content, contentType, fileName), nil
+}
+
+// prepare apply serving policy to content
+func (s *SafeContentService) prepare(content []byte, contentType, fileName string) *ServedContent {
+	served := &ServedContent{Content: content, ContentType: contentType, FileName: fileName}
+	if served.ContentType == "" {
+		served.ContentType = "application/octet-stream"
+	}
+
+	// Declared type and content are both checked, either may be misleading
+	mediaType := baseMediaType(contentType)
+	if mediaType == "image/svg+xml" || looksLikeSVG(content) {
+		if conf.Cfg.Indexer.ContentServing.SVGMode == SVGModeSanitize {
+			sanitized, err := s.sanitizedSVG(content)
+			if err == nil {
+				served.Content = sanitized
+				served.ContentType = "image/svg+xml"
+				served.Sanitized = true
+				return served
+			}
+			log.Printf("Failed to sanitize SVG %s, serving as attachment: %v", fileName, err)
+		}
+		served.Attachment = true
+		return served
+	}
+
+	if activeContentTypes[mediaType] || activeContentTypes[baseMediaType(http.DetectContentType(content))] {
+		served.Attachment = true
+	}
+	return served
+}
+
+// sanitizedSVG get sanitized copy of an SVG, cached in storage by content hash
+func (s *SafeContentService) sanitizedSVG(content []byte) ([]byte, error) {
+	key := fmt.Sprintf("sanitized/svg/%s.svg", calculateSHA256(content))
+
+	if sanitized, err := s.storage.Get(key); err == nil {
+		return sanitized, nil
+	}
+
+	v, err, _ := s.group.Do(key, func() (interface{}, error) {
+		sanitized, err := sanitize.SVG(content)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.storage.Save(key, sanitized); err != nil {
+			log.Printf("Failed to cache sanitized SVG %s: %v", key, err)
+		}
+		return sanitized, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// ContentSecurityPolicy CSP header for raw content of a content type
+// Documents are sandboxed (unique origin, no script). PDFs are not sandboxed because
+// browser PDF viewers refuse to run in sandboxed documents; their script runs in the viewer, not our origin.
+func ContentSecurityPolicy(contentType string) string {
+	policy := "default-src 'none'; img-src 'self' data:; media-src 'self'; style-src 'unsafe-inline'"
+	if baseMediaType(contentType) == "application/pdf" {
+		return policy
+	}
+	return policy + "; sandbox"
+}
+
+// ContentDisposition Content-Disposition header value with an encoded file name
+func ContentDisposition(attachment bool, fileName string) string {
+	disposition := "inline"
+	if attachment {
+		disposition = "attachment"
+	}
+	if fileName == "" {
+		return disposition
+	}
+	if value := mime.FormatMediaType(disposition, map[string]string{"filename": fileName}); value != "" {
+		return value
+	}
+	return disposition
+}
+
+// baseMediaType media type without parameters, lower case
+func baseMediaType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// looksLikeSVG check if content is an SVG document regardless of declared type
+func looksLikeSVG(content []byte) bool {
+	head := content
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	head = bytes.TrimSpace(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")))
+	if !bytes.HasPrefix(head, []byte("<")) {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(head), []byte("<svg"))
+}
@@ -0,0 +1,272 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"meta-media-service/indexer"
+	"meta-media-service/model"
+	"meta-media-service/model/dao"
+	"meta-media-service/storage"
+)
+
+// Scrub issue kinds
+const (
+	ScrubIssueMissing  = "missing"  // Record exists but object is missing
+	ScrubIssueCorrupt  = "corrupt"  // Object content does not match recorded hash
+	ScrubIssueOrphaned = "orphaned" // Object exists but no record references it
+)
+
+// Storage prefixes written by the indexer
+var scrubPrefixes = []string{"indexer/", "blobs/"}
+
+// maxScrubIssues issues kept in a scrub result, later ones are only logged and counted
+const maxScrubIssues = 1000
+
+// ScrubService storage integrity scrubber
+// Verifies that every indexed record has an object whose content matches the
+// recorded hashes, and that every stored object is referenced by a record.
+type ScrubService struct {
+	storage              storage.Storage // Used for repair writes
+	verifyStorage        storage.Storage // Used for verification reads (bypasses cache)
+	chainFetcher         *ChainContentFetcher
+	indexerFileDAO       *dao.IndexerFileDAO
+	indexerUserAvatarDAO *dao.IndexerUserAvatarDAO
+	running              atomic.Bool
+}
+
+// ScrubIssue scrub issue
+type ScrubIssue struct {
+	Kind        string // missing/corrupt/orphaned
+	Record      string // file/avatar (empty for orphaned objects)
+	PinID       string
+	StoragePath string
+	Detail      string
+	Repaired    bool
+}
+
+// ScrubResult scrub result
+type ScrubResult struct {
+	Scanned      int64 // Records scanned
+	OK           int64 // Records whose object is present and intact
+	Skipped      int64 // Serve-from-chain records without a cached copy
+	Missing      int64
+	Corrupt      int64
+	Orphaned     int64
+	Repaired     int64
+	RepairFailed int64
+	Issues       []ScrubIssue // First maxScrubIssues issues
+	Unlisted     int64        // Issues left out of Issues, see the log
+}
+
+// addIssue keep an issue, up to maxScrubIssues
+func (r *ScrubResult) addIssue(issue ScrubIssue) {
+	if len(r.Issues) >= maxScrubIssues {
+		r.Unlisted++
+		return
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// NewScrubService create scrub service instance
+// scanners: one per chain, used to re-fetch transactions of the records of that chain when repairing
+// (none or nil to disable repair)
+func NewScrubService(stor storage.Storage, scanners ...*indexer.BlockScanner) *ScrubService {
+	return &ScrubService{
+		storage:              stor,
+		verifyStorage:        storage.Unwrap(stor),
+		chainFetcher:         NewChainContentFetcher(scanners...),
+		indexerFileDAO:       dao.NewIndexerFileDAO(),
+		indexerUserAvatarDAO: dao.NewIndexerUserAvatarDAO(),
+	}
+}
+
+// Start run scrub periodically (blocking)
+func (s *ScrubService) Start(interval time.Duration, repair bool) {
+	log.Printf("Storage scrubber started (interval: %s, repair: %v)", interval, repair)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		result, err := s.Scrub(repair)
+		if err != nil {
+			log.Printf("Storage scrub failed: %v", err)
+			continue
+		}
+		log.Printf("Storage scrub finished: scanned=%d, ok=%d, skipped=%d, missing=%d, corrupt=%d, orphaned=%d, repaired=%d, repair_failed=%d",
+			result.Scanned, result.OK, result.Skipped, result.Missing, result.Corrupt, result.Orphaned, result.Repaired, result.RepairFailed)
+	}
+}
+
+// Scrub verify all file and avatar records and report missing, corrupt and orphaned objects
+// repair: re-fetch originating transactions from node to restore missing/corrupt objects
+func (s *ScrubService) Scrub(repair bool) (*ScrubResult, error) {
+	if !s.running.CompareAndSwap(false, true) {
+		return nil, errors.New("scrub already running")
+	}
+	defer s.running.Store(false)
+
+	if repair && s.chainFetcher == nil {
+		return nil, errors.New("repair requires a node connection")
+	}
+
+	result := &ScrubResult{}
+	// Storage path -> issue kind ("" when intact), shared blobs are verified once
+	checked := make(map[string]string)
+
+	err := s.indexerFileDAO.ForEach(func(file *model.IndexerFile) error {
+		// Serve-from-chain files are only checked when a cached copy exists
+		if file.StorageType == model.StorageTypeChain && !s.verifyStorage.Exists(file.StoragePath) {
+			result.Scanned++
+			result.Skipped++
+			checked[file.StoragePath] = ""
+			return nil
+		}
+		s.checkRecord(result, checked, "file", file.PinID, file.ChainName, file.StoragePath, file.FileMd5, file.FileHash, repair)
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to scrub files: %w", err)
+	}
+
+	err = s.indexerUserAvatarDAO.ForEach(func(avatar *model.IndexerUserAvatar) error {
+		s.checkRecord(result, checked, "avatar", avatar.PinID, avatar.ChainName, avatar.Avatar, avatar.FileMd5, avatar.FileHash, repair)
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to scrub avatars: %w", err)
+	}
+
+	if err := s.findOrphans(result, checked); err != nil {
+		return result, fmt.Errorf("failed to find orphaned objects: %w", err)
+	}
+
+	return result, nil
+}
+
+// checkRecord verify one record's object and repair it if requested
+func (s *ScrubService) checkRecord(result *ScrubResult, checked map[string]string, record, pinID, chainName, storagePath, fileMd5, fileHash string, repair bool) {
+	result.Scanned++
+
+	if kind, ok := checked[storagePath]; ok {
+		s.count(result, kind)
+		return
+	}
+
+	kind, detail := s.verify(storagePath, fileMd5, fileHash)
+	if kind == "" {
+		checked[storagePath] = ""
+		result.OK++
+		return
+	}
+
+	issue := ScrubIssue{
+		Kind:        kind,
+		Record:      record,
+		PinID:       pinID,
+		StoragePath: storagePath,
+		Detail:      detail,
+	}
+
+	if repair {
+		if err := s.repair(pinID, chainName, storagePath, fileHash); err != nil {
+			log.Printf("Scrub: failed to repair %s (PIN %s): %v", storagePath, pinID, err)
+			issue.Detail = fmt.Sprintf("%s; repair failed: %v", detail, err)
+			result.RepairFailed++
+		} else {
+			issue.Repaired = true
+			result.Repaired++
+		}
+	}
+
+	log.Printf("Scrub: %s object %s (PIN %s): %s", kind, storagePath, pinID, issue.Detail)
+	result.addIssue(issue)
+	s.count(result, kind)
+
+	if issue.Repaired {
+		checked[storagePath] = ""
+	} else {
+		checked[storagePath] = kind
+	}
+}
+
+// verify read object and compare against recorded hashes
+// Returns issue kind ("" when intact) and detail
+func (s *ScrubService) verify(storagePath, fileMd5, fileHash string) (string, string) {
+	content, err := s.verifyStorage.Get(storagePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ScrubIssueMissing, "object not found"
+		}
+		return ScrubIssueMissing, fmt.Sprintf("failed to read object: %v", err)
+	}
+
+	if fileHash != "" {
+		if actual := calculateSHA256(content); actual != fileHash {
+			return ScrubIssueCorrupt, fmt.Sprintf("sha256 mismatch: recorded=%s actual=%s", fileHash, actual)
+		}
+	}
+	if fileMd5 != "" {
+		if actual := calculateMD5(content); actual != fileMd5 {
+			return ScrubIssueCorrupt, fmt.Sprintf("md5 mismatch: recorded=%s actual=%s", fileMd5, actual)
+		}
+	}
+
+	return "", ""
+}
+
+// repair re-extract PIN content from its originating transaction and store it again
+func (s *ScrubService) repair(pinID, chainName, storagePath, fileHash string) error {
+	content, err := s.chainFetcher.Fetch(pinID, chainName, fileHash)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Save(storagePath, content)
+}
+
+// findOrphans list indexer objects and report those not referenced by any record
+func (s *ScrubService) findOrphans(result *ScrubResult, referenced map[string]string) error {
+	lister, ok := s.verifyStorage.(storage.Lister)
+	if !ok {
+		log.Printf("Scrub: storage does not support listing, skipping orphan detection")
+		return nil
+	}
+
+	for _, prefix := range scrubPrefixes {
+		err := lister.List(prefix, func(key string) error {
+			if _, ok := referenced[key]; ok {
+				return nil
+			}
+			result.Orphaned++
+			log.Printf("Scrub: orphaned object %s", key)
+			result.addIssue(ScrubIssue{
+				Kind:        ScrubIssueOrphaned,
+				StoragePath: key,
+				Detail:      "no record references this object",
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// count increase issue counter by kind
+func (s *ScrubService) count(result *ScrubResult, kind string) {
+	switch kind {
+	case "":
+		result.OK++
+	case ScrubIssueMissing:
+		result.Missing++
+	case ScrubIssueCorrupt:
+		result.Corrupt++
+	}
+}
@@ -0,0 +1,201 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"meta-media-service/conf"
+	"meta-media-service/imaging"
+	"meta-media-service/storage"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	ErrNotImage                = errors.New("file is not a supported image (jpeg/png/gif)")
+	ErrInvalidThumbnailOptions = errors.New("invalid thumbnail options")
+)
+
+// ThumbnailOptions thumbnail request options
+type ThumbnailOptions struct {
+	Width  int    // 0 = derived from height
+	Height int    // 0 = derived from width
+	Fit    string // contain/cover/fill (default contain)
+	Format string // jpeg/png (default: png for png/gif sources, jpeg otherwise)
+}
+
+// ThumbnailService image thumbnail service
+// Derivatives are cached in storage under a key derived from the source hash and options.
+type ThumbnailService struct {
+	storage            storage.Storage
+	indexerFileService *IndexerFileService
+	group              singleflight.Group
+}
+
+// NewThumbnailService create thumbnail service instance
+func NewThumbnailService(storage storage.Storage, indexerFileService *IndexerFileService) *ThumbnailService {
+	return &ThumbnailService{
+		storage:            storage,
+		indexerFileService: indexerFileService,
+	}
+}
+
+// GetThumbnail get thumbnail of an image file by PIN ID
+// Returns thumbnail content and content type
+func (s *ThumbnailService) GetThumbnail(pinID string, opts ThumbnailOptions) ([]byte, string, error) {
+	file, err := s.indexerFileService.GetFileByPinID(pinID)
+	if err != nil {
+		return nil, "", err
+	}
+	if file.FileType != "image" {
+		return nil, "", ErrNotImage
+	}
+
+	if err := normalizeThumbnailOptions(&opts, file.ContentType); err != nil {
+		return nil, "", err
+	}
+
+	sourceID := file.FileHash
+	if sourceID == "" {
+		sourceID = file.PinID
+	}
+	key := thumbnailKey(sourceID, opts)
+
+	// Serve cached derivative
+	if content, err := s.storage.Get(key); err == nil {
+		return content, imaging.ContentType(opts.Format), nil
+	}
+
+	v, err, _ := s.group.Do(key, func() (interface{}, error) {
+		source, _, _, err := s.indexerFileService.GetFileContent(pinID)
+		if err != nil {
+			return nil, err
+		}
+		return s.generate(source, key, opts)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return v.([]byte), imaging.ContentType(opts.Format), nil
+}
+
+// Pregenerate generate configured standard sizes for a newly indexed image
+func (s *ThumbnailService) Pregenerate(content []byte, fileHash, contentType string) {
+	for _, size := range conf.Cfg.Indexer.Thumbnail.Pregenerate {
+		width, height, err := ParseThumbnailSize(size)
+		if err != nil {
+			log.Printf("Invalid thumbnail pregenerate size %q: %v", size, err)
+			continue
+		}
+
+		opts := ThumbnailOptions{Width: width, Height: height, Fit: imaging.FitCover}
+		if err := normalizeThumbnailOptions(&opts, contentType); err != nil {
+			log.Printf("Invalid thumbnail pregenerate size %q: %v", size, err)
+			continue
+		}
+
+		key := thumbnailKey(fileHash, opts)
+		if s.storage.Exists(key) {
+			continue
+		}
+		if _, err := s.generate(content, key, opts); err != nil {
+			log.Printf("Failed to pregenerate thumbnail %s: %v", key, err)
+			// Decoding failures apply to every size
+			if errors.Is(err, ErrNotImage) {
+				return
+			}
+		}
+	}
+}
+
+// generate resize source image, save the derivative under key and return it
+func (s *ThumbnailService) generate(source []byte, key string, opts ThumbnailOptions) ([]byte, error) {
+	img, _, err := imaging.Decode(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+
+	thumbnail, err := imaging.Encode(imaging.Resize(img, opts.Width, opts.Height, opts.Fit), opts.Format, conf.Cfg.Indexer.Thumbnail.Quality)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Save(key, thumbnail); err != nil {
+		log.Printf("Failed to cache thumbnail %s: %v", key, err)
+	}
+
+	return thumbnail, nil
+}
+
+// ParseThumbnailSize parse size string "WxH"
+func ParseThumbnailSize(size string) (int, int, error) {
+	parts := strings.SplitN(strings.ToLower(strings.TrimSpace(size)), "x", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("%w: size must be WxH", ErrInvalidThumbnailOptions)
+	}
+	width, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: invalid width", ErrInvalidThumbnailOptions)
+	}
+	height, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: invalid height", ErrInvalidThumbnailOptions)
+	}
+	return width, height, nil
+}
+
+// normalizeThumbnailOptions validate options and fill in defaults
+// contentType: declared content type of the source, used to choose the default format
+func normalizeThumbnailOptions(opts *ThumbnailOptions, contentType string) error {
+	maxDimension := conf.Cfg.Indexer.Thumbnail.MaxDimension
+
+	if opts.Width < 0 || opts.Height < 0 || (opts.Width == 0 && opts.Height == 0) {
+		return fmt.Errorf("%w: w or h is required", ErrInvalidThumbnailOptions)
+	}
+	if opts.Width > maxDimension || opts.Height > maxDimension {
+		return fmt.Errorf("%w: w and h must not exceed %d", ErrInvalidThumbnailOptions, maxDimension)
+	}
+
+	switch opts.Fit {
+	case "":
+		opts.Fit = imaging.FitContain
+	case imaging.FitContain, imaging.FitCover, imaging.FitFill:
+	default:
+		return fmt.Errorf("%w: fit must be contain, cover or fill", ErrInvalidThumbnailOptions)
+	}
+
+	switch opts.Format {
+	case "":
+		opts.Format = imaging.DefaultFormat(sourceImageFormat(contentType))
+	case "jpg", imaging.FormatJPEG:
+		opts.Format = imaging.FormatJPEG
+	case imaging.FormatPNG:
+	default:
+		return fmt.Errorf("%w: format must be jpeg or png", ErrInvalidThumbnailOptions)
+	}
+
+	return nil
+}
+
+// sourceImageFormat get image format from content type (jpeg/png/gif)
+func sourceImageFormat(contentType string) string {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "png"):
+		return "png"
+	case strings.Contains(contentType, "gif"):
+		return "gif"
+	default:
+		return "jpeg"
+	}
+}
+
+// thumbnailKey deterministic storage key of a derivative
+// thumbnails/{source hash}/{w}x{h}_{fit}.{format}
+func thumbnailKey(sourceID string, opts ThumbnailOptions) string {
+	return fmt.Sprintf("thumbnails/%s/%dx%d_%s.%s", sourceID, opts.Width, opts.Height, opts.Fit, opts.Format)
+}
@@ -0,0 +1,623 @@
+package upload_service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	"github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+	"gorm.io/gorm"
+
+	"meta-media-service/common"
+	"meta-media-service/conf"
+	"meta-media-service/indexer"
+	"meta-media-service/model"
+	"meta-media-service/model/dao"
+	"meta-media-service/node"
+	"meta-media-service/tool"
+)
+
+// Assistant actions authorized by an owner signature
+const (
+	AssistantActionCreate = "create"
+	AssistantActionRotate = "rotate"
+	AssistantActionLimit  = "limit"
+	AssistantActionRevoke = "revoke"
+)
+
+// assistantAuthWindow max clock difference of an owner signature timestamp
+const assistantAuthWindow = 5 * time.Minute
+
+var (
+	ErrAssistantDisabled     = errors.New("assistants are disabled")
+	ErrAssistantNotFound     = errors.New("assistant not found")
+	ErrAssistantExists       = errors.New("assistant already exists")
+	ErrAssistantUnauthorized = errors.New("assistant authorization failed")
+	ErrInvalidSpendLimit     = errors.New("invalid spend limit")
+	ErrSpendLimitExceeded    = errors.New("assistant spend limit exceeded")
+)
+
+// AssistantAuth owner authorization of an assistant action
+// Signature is the wallet message signature (base64) of AssistantAuthMessage by Address.
+type AssistantAuth struct {
+	Address    string // Owner address, the MetaID is SHA256 of it
+	SpendLimit int64  // Spend limit (satoshis), signed for every action
+	Timestamp  int64  // Unix seconds, must be within 5 minutes and newer than the last accepted one
+	Signature  string // Base64 compact signature
+}
+
+// AssistantResponse assistant info
+type AssistantResponse struct {
+	MetaId           string `json:"metaId"`                // Owner MetaID
+	Address          string `json:"address"`               // Owner address
+	AssistantAddress string `json:"assistantAddress"`      // Address to fund, uploads are paid from it
+	AssistantMetaId  string `json:"assistantMetaId"`       // MetaID of the assistant address
+	SpendLimit       int64  `json:"spendLimit"`            // Satoshis the assistant may spend
+	SpentAmount      int64  `json:"spentAmount"`           // Satoshis spent so far
+	State            string `json:"state"`                 // active/revoked
+	AccessToken      string `json:"accessToken,omitempty"` // Bearer token of assistant uploads, only returned on create and rotate
+	SweepTxId        string `json:"sweepTxId,omitempty"`   // Transaction moving the balance of the replaced key
+}
+
+// AssistantService custodial assistant keys
+// An owner authorizes an assistant with a wallet signature. The server keeps its private key
+// encrypted with the master key and uses it to sign and pay for uploads within the spend limit.
+type AssistantService struct {
+	assistantDAO   assistantStore
+	reservationDAO reservationLister
+	uploadService  *UploadService
+
+	// sweep move all unreserved UTXOs of an address to another address, returns the transaction ID
+	sweep func(priHex, fromAddress, toAddress string) (string, error)
+}
+
+// assistantStore assistant persistence, implemented by dao.AssistantDAO
+type assistantStore interface {
+	Create(assistant *model.Assistant) error
+	GetByMetaID(metaId string) (*model.Assistant, error)
+	UpdateAuthorized(assistant *model.Assistant, prevAuthTimestamp int64) (bool, error)
+	ClearPrevKey(id int64, prevAddress string) error
+	Charge(id int64, amount int64) (bool, error)
+	ResetSpent(id int64) error
+	Refund(id int64, amount int64) error
+}
+
+// reservationLister pending reservations of payer UTXOs, implemented by dao.UtxoReservationDAO
+type reservationLister interface {
+	ListActiveByAddress(address string, now time.Time) ([]*model.UtxoReservation, error)
+}
+
+// NewAssistantService create assistant service instance
+func NewAssistantService(uploadService *UploadService) *AssistantService {
+	s := &AssistantService{
+		assistantDAO:   dao.NewAssistantDAO(),
+		reservationDAO: uploadService.utxoReservationDAO,
+		uploadService:  uploadService,
+	}
+	s.sweep = s.sweepUtxos
+	return s
+}
+
+// AssistantAuthMessage message the owner signs to authorize an action
+func AssistantAuthMessage(action string, auth *AssistantAuth) string {
+	return fmt.Sprintf("meta-media assistant\naction: %s\nmetaId: %s\nspendLimit: %d\ntimestamp: %d",
+		action, CalculateMetaID(auth.Address), auth.SpendLimit, auth.Timestamp)
+}
+
+// CalculateMetaID calculate MetaID from address (SHA256 hash)
+func CalculateMetaID(address string) string {
+	hash := sha256.Sum256([]byte(address))
+	return hex.EncodeToString(hash[:])
+}
+
+// Create create an assistant for the owner, or a new one if the previous one was revoked
+func (s *AssistantService) Create(auth *AssistantAuth) (*AssistantResponse, error) {
+	if err := s.checkEnabled(); err != nil {
+		return nil, err
+	}
+	if err := checkSpendLimit(auth.SpendLimit); err != nil {
+		return nil, err
+	}
+	metaId := CalculateMetaID(auth.Address)
+	existing, err := s.assistantDAO.GetByMetaID(metaId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get assistant: %w", err)
+	}
+	if existing != nil && existing.State == model.AssistantStateExist {
+		return nil, ErrAssistantExists
+	}
+	if err := verifyAssistantAuth(AssistantActionCreate, auth, existing); err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		assistant := &model.Assistant{
+			MetaId:        metaId,
+			Address:       auth.Address,
+			SpendLimit:    auth.SpendLimit,
+			AuthTimestamp: auth.Timestamp,
+			State:         model.AssistantStateExist,
+		}
+		token, err := s.replaceKey(assistant)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.assistantDAO.Create(assistant); err != nil {
+			return nil, fmt.Errorf("failed to save assistant: %w", err)
+		}
+		log.Printf("Assistant created: metaId=%s, assistantAddress=%s, spendLimit=%d", metaId, assistant.AssistantAddress, assistant.SpendLimit)
+		resp := toAssistantResponse(assistant)
+		resp.AccessToken = token
+		return resp, nil
+	}
+
+	// A revoked assistant gets a new key, the old balance (if any is left) moves to it.
+	// Its access token is only returned now, so nothing is charged before the spent amount is reset.
+	prevAuth := existing.AuthTimestamp
+	existing.Address = auth.Address
+	existing.SpendLimit = auth.SpendLimit
+	existing.AuthTimestamp = auth.Timestamp
+	existing.State = model.AssistantStateExist
+	resp, err := s.rotate(existing, prevAuth)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.assistantDAO.ResetSpent(existing.ID); err != nil {
+		return nil, fmt.Errorf("failed to reset spent amount: %w", err)
+	}
+	existing.SpentAmount = 0
+	resp.SpentAmount = 0
+	return resp, nil
+}
+
+// Get get assistant info by owner MetaID
+func (s *AssistantService) Get(metaId string) (*AssistantResponse, error) {
+	assistant, err := s.getAssistant(metaId)
+	if err != nil {
+		return nil, err
+	}
+	return toAssistantResponse(assistant), nil
+}
+
+// Rotate replace the key and access token of an assistant, its balance moves to the new key
+func (s *AssistantService) Rotate(metaId string, auth *AssistantAuth) (*AssistantResponse, error) {
+	assistant, prevAuth, err := s.authorize(AssistantActionRotate, metaId, auth)
+	if err != nil {
+		return nil, err
+	}
+	return s.rotate(assistant, prevAuth)
+}
+
+// SetLimit change the spend limit of an assistant
+// A limit below the spent amount stops further uploads.
+func (s *AssistantService) SetLimit(metaId string, auth *AssistantAuth) (*AssistantResponse, error) {
+	if err := checkSpendLimit(auth.SpendLimit); err != nil {
+		return nil, err
+	}
+	assistant, prevAuth, err := s.authorize(AssistantActionLimit, metaId, auth)
+	if err != nil {
+		return nil, err
+	}
+	assistant.SpendLimit = auth.SpendLimit
+	if err := s.update(assistant, prevAuth); err != nil {
+		return nil, err
+	}
+	log.Printf("Assistant spend limit changed: metaId=%s, spendLimit=%d", metaId, assistant.SpendLimit)
+	return toAssistantResponse(assistant), nil
+}
+
+// Revoke stop an assistant from signing and return its balance to the owner address
+// The assistant is saved as revoked before anything is swept. The encrypted key is kept, so a
+// balance that could not be swept moves on the next create.
+func (s *AssistantService) Revoke(metaId string, auth *AssistantAuth) (*AssistantResponse, error) {
+	assistant, prevAuth, err := s.authorize(AssistantActionRevoke, metaId, auth)
+	if err != nil {
+		return nil, err
+	}
+	assistant.State = model.AssistantStateDeleted
+	assistant.TokenHash = ""
+	if err := s.update(assistant, prevAuth); err != nil {
+		return nil, err
+	}
+	log.Printf("Assistant revoked: metaId=%s", metaId)
+
+	resp := toAssistantResponse(assistant)
+	if _, err := s.sweepPrevKey(assistant, assistant.Address); err != nil {
+		log.Printf("Failed to sweep replaced key of revoked assistant: metaId=%s, err=%v", metaId, err)
+	}
+	priHex, err := s.decryptKey(assistant.AssistantPrivateKey, assistant.AssistantAddress)
+	if err != nil {
+		log.Printf("Failed to sweep revoked assistant: metaId=%s, err=%v", metaId, err)
+		return resp, nil
+	}
+	if resp.SweepTxId, err = s.sweep(priHex, assistant.AssistantAddress, assistant.Address); err != nil {
+		log.Printf("Failed to sweep revoked assistant: metaId=%s, err=%v", metaId, err)
+	}
+	return resp, nil
+}
+
+// Upload upload a file signed and paid by the assistant of metaId
+// The file is recorded under the owner MetaID, its PINs are created by the assistant address.
+func (s *AssistantService) Upload(metaId, token string, req *UploadRequest) (*UploadResponse, error) {
+	if err := s.checkEnabled(); err != nil {
+		return nil, err
+	}
+	assistant, err := s.getAssistant(metaId)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte(token))
+	if assistant.State != model.AssistantStateExist || assistant.TokenHash == "" ||
+		subtle.ConstantTimeCompare([]byte(hex.EncodeToString(hash[:])), []byte(assistant.TokenHash)) != 1 {
+		return nil, ErrAssistantUnauthorized
+	}
+	priHex, err := s.decryptKey(assistant.AssistantPrivateKey, assistant.AssistantAddress)
+	if err != nil {
+		return nil, err
+	}
+	changeScript, err := addressScript(netParams(), assistant.AssistantAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	req.MetaId = assistant.MetaId
+	req.Address = assistant.Address
+	req.PayerAddress = assistant.AssistantAddress
+	req.ChangeAddress = assistant.AssistantAddress
+	pre, err := s.uploadService.PreUpload(req)
+	if err != nil {
+		return nil, err
+	}
+	if pre.committed() {
+		return &UploadResponse{FileId: pre.FileId, Status: pre.Status, TxState: pre.TxState, TxId: pre.TxId, PinId: pre.PinId, Message: pre.Message}, nil
+	}
+
+	resp, err := s.uploadService.commitFunded(pre, s.signer(assistant, priHex, changeScript).commit)
+	if err != nil {
+		if errors.Is(err, ErrSpendLimitExceeded) {
+			s.uploadService.releaseReservations(pre.FileId)
+		}
+		return nil, err
+	}
+	log.Printf("Assistant upload committed: metaId=%s, fileId=%s, pinId=%s", metaId, resp.FileId, resp.PinId)
+	return resp, nil
+}
+
+// signer signs the transactions of an assistant upload and charges them to its spend limit
+func (s *AssistantService) signer(assistant *model.Assistant, priHex string, changeScript []byte) *chargedSigner {
+	return &chargedSigner{
+		priHex:       priHex,
+		changeScript: changeScript,
+		charge: func(cost int64) error {
+			charged, err := s.assistantDAO.Charge(assistant.ID, cost)
+			if err != nil {
+				return fmt.Errorf("failed to charge assistant: %w", err)
+			}
+			if !charged {
+				return fmt.Errorf("%w: spent %d of %d, transaction costs %d", ErrSpendLimitExceeded, assistant.SpentAmount, assistant.SpendLimit, cost)
+			}
+			assistant.SpentAmount += cost
+			return nil
+		},
+		refund: func(cost int64) {
+			if err := s.assistantDAO.Refund(assistant.ID, cost); err != nil {
+				log.Printf("Failed to refund assistant charge: metaId=%s, amount=%d, err=%v", assistant.MetaId, cost, err)
+			}
+			assistant.SpentAmount -= cost
+		},
+	}
+}
+
+// authorize get the active assistant of metaId and verify an owner signature for action
+// Returns the assistant and the auth timestamp to update it with.
+func (s *AssistantService) authorize(action, metaId string, auth *AssistantAuth) (*model.Assistant, int64, error) {
+	if err := s.checkEnabled(); err != nil {
+		return nil, 0, err
+	}
+	if CalculateMetaID(auth.Address) != metaId {
+		return nil, 0, fmt.Errorf("%w: address does not belong to MetaID", ErrAssistantUnauthorized)
+	}
+	assistant, err := s.getAssistant(metaId)
+	if err != nil {
+		return nil, 0, err
+	}
+	if assistant.State != model.AssistantStateExist {
+		return nil, 0, ErrAssistantNotFound
+	}
+	if err := verifyAssistantAuth(action, auth, assistant); err != nil {
+		return nil, 0, err
+	}
+	prevAuth := assistant.AuthTimestamp
+	assistant.AuthTimestamp = auth.Timestamp
+	return assistant, prevAuth, nil
+}
+
+// rotate give an assistant a new key and access token and sweep the old key into it
+// The new key is saved first with the old key kept beside it, so a sweep that fails or is
+// interrupted leaves both keys recoverable. The old key is forgotten once its sweep is broadcast,
+// a failed sweep is retried by the next rotate or revoke.
+func (s *AssistantService) rotate(assistant *model.Assistant, prevAuth int64) (*AssistantResponse, error) {
+	// Only one replaced key is kept, a key left by an earlier rotation moves first
+	if _, err := s.sweepPrevKey(assistant, assistant.AssistantAddress); err != nil {
+		return nil, fmt.Errorf("failed to move balance of the key replaced earlier: %w", err)
+	}
+	if assistant.PrevAddress != "" {
+		return nil, fmt.Errorf("key replaced earlier still has pending uploads, rotate again later")
+	}
+	if _, err := s.decryptKey(assistant.AssistantPrivateKey, assistant.AssistantAddress); err != nil {
+		return nil, err
+	}
+	assistant.PrevPrivateKey = assistant.AssistantPrivateKey
+	assistant.PrevAddress = assistant.AssistantAddress
+
+	token, err := s.replaceKey(assistant)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.update(assistant, prevAuth); err != nil {
+		return nil, err
+	}
+	log.Printf("Assistant key replaced: metaId=%s, assistantAddress=%s", assistant.MetaId, assistant.AssistantAddress)
+
+	resp := toAssistantResponse(assistant)
+	resp.AccessToken = token
+	if resp.SweepTxId, err = s.sweepPrevKey(assistant, assistant.AssistantAddress); err != nil {
+		log.Printf("Failed to sweep replaced assistant key, kept for the next rotate or revoke: metaId=%s, err=%v", assistant.MetaId, err)
+	}
+	return resp, nil
+}
+
+// sweepPrevKey move the balance of the key replaced by a rotation to toAddress and forget the key
+// The key is kept while pending uploads still hold reservations of its UTXOs.
+func (s *AssistantService) sweepPrevKey(assistant *model.Assistant, toAddress string) (string, error) {
+	if assistant.PrevAddress == "" {
+		return "", nil
+	}
+	priHex, err := s.decryptKey(assistant.PrevPrivateKey, assistant.PrevAddress)
+	if err != nil {
+		return "", err
+	}
+	txId, err := s.sweep(priHex, assistant.PrevAddress, toAddress)
+	if err != nil {
+		return "", err
+	}
+	reserved, err := s.reservationDAO.ListActiveByAddress(assistant.PrevAddress, time.Now())
+	if err != nil {
+		return txId, fmt.Errorf("failed to get reservations of the replaced key: %w", err)
+	}
+	if len(reserved) > 0 {
+		return txId, nil
+	}
+	if err := s.assistantDAO.ClearPrevKey(assistant.ID, assistant.PrevAddress); err != nil {
+		return txId, fmt.Errorf("failed to clear the replaced key: %w", err)
+	}
+	assistant.PrevPrivateKey = ""
+	assistant.PrevAddress = ""
+	return txId, nil
+}
+
+// replaceKey generate a key and access token for an assistant (not saved)
+// Returns the access token, only its hash is stored.
+func (s *AssistantService) replaceKey(assistant *model.Assistant) (string, error) {
+	priHex, address, err := common.GenerateMvcKey(netParams())
+	if err != nil {
+		return "", fmt.Errorf("failed to generate assistant key: %w", err)
+	}
+	encrypted, err := EncryptPrivateKey(priHex)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt assistant key: %w", err)
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+	tokenHash := sha256.Sum256([]byte(token))
+
+	assistant.AssistantPrivateKey = encrypted
+	assistant.AssistantAddress = address
+	assistant.AssistantMetaId = CalculateMetaID(address)
+	assistant.TokenHash = hex.EncodeToString(tokenHash[:])
+	return token, nil
+}
+
+// decryptKey decrypt an encrypted assistant private key and check it belongs to address
+func (s *AssistantService) decryptKey(encryptedKey, address string) (string, error) {
+	priHex, err := decryptPrivateKey(encryptedKey)
+	if err != nil {
+		return "", err
+	}
+	keyAddress, err := common.MvcAddressFromPriHex(netParams(), priHex)
+	if err != nil {
+		return "", err
+	}
+	if keyAddress != address {
+		return "", fmt.Errorf("assistant key does not match address %s", address)
+	}
+	return priHex, nil
+}
+
+// decryptPrivateKey decrypt a server-held private key encrypted with the master key (base64)
+func decryptPrivateKey(encryptedKey string) (string, error) {
+	return decryptKeyWithSecret(conf.Cfg.Uploader.Assistant.MasterKey, encryptedKey)
+}
+
+// EncryptPrivateKey encrypt a private key (hex) with the master key, for keys kept in config
+func EncryptPrivateKey(priHex string) (string, error) {
+	return encryptKeyWithSecret(conf.Cfg.Uploader.Assistant.MasterKey, priHex)
+}
+
+// decryptKeyWithSecret decrypt a private key encrypted with secret (base64), returning it as hex
+func decryptKeyWithSecret(secret, encryptedKey string) (string, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(encryptedKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted key: %w", err)
+	}
+	priBytes, err := tool.AESGCMDecrypt(secret, encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt key (secret changed?): %w", err)
+	}
+	return hex.EncodeToString(priBytes), nil
+}
+
+// encryptKeyWithSecret encrypt a private key (hex) with secret, returning it as base64
+func encryptKeyWithSecret(secret, priHex string) (string, error) {
+	priBytes, err := hex.DecodeString(priHex)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key hex: %w", err)
+	}
+	encrypted, err := tool.AESGCMEncrypt(secret, priBytes)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// sweepUtxos move all unreserved UTXOs of an address to another address
+// Returns the transaction ID, empty if there was nothing above dust to move.
+func (s *AssistantService) sweepUtxos(priHex, fromAddress, toAddress string) (string, error) {
+	if fromAddress == "" || fromAddress == toAddress {
+		return "", nil
+	}
+	utxos, err := s.uploadService.payerUtxos(fromAddress)
+	if err != nil {
+		return "", err
+	}
+	if len(utxos) == 0 {
+		return "", nil
+	}
+	toScript, err := addressScript(netParams(), toAddress)
+	if err != nil {
+		return "", err
+	}
+
+	tx := wire2.NewMsgTx(10)
+	ins := make([]*common.TxInputUtxo, 0, len(utxos))
+	total := int64(0)
+	for _, utxo := range utxos {
+		hash, err := chainhash.NewHashFromStr(utxo.txId)
+		if err != nil {
+			return "", err
+		}
+		tx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(hash, utxo.vout), nil))
+		ins = append(ins, &common.TxInputUtxo{
+			TxId:     utxo.txId,
+			TxIndex:  int64(utxo.vout),
+			PkScript: hex.EncodeToString(utxo.scriptPubKey),
+			Amount:   uint64(utxo.amount),
+			PriHex:   priHex,
+			SignMode: common.SignModeLegacy,
+		})
+		total += utxo.amount
+	}
+	tx.AddTxOut(wire2.NewTxOut(0, toScript))
+	value := total - int64(signedTxSize(tx))*conf.Cfg.Uploader.FeeRate
+	if value < changeDustLimit {
+		return "", nil
+	}
+	tx.TxOut[0].Value = value
+
+	if err := common.SignMvcP2PKHInputs(tx, ins); err != nil {
+		return "", fmt.Errorf("failed to sign sweep transaction: %w", err)
+	}
+	rawTx, err := indexer.TxToHex(tx)
+	if err != nil {
+		return "", err
+	}
+	txId, err := node.BroadcastTx(conf.Cfg.Net, rawTx)
+	if err != nil {
+		return "", fmt.Errorf("failed to broadcast sweep transaction: %w", err)
+	}
+	log.Printf("Assistant balance swept: from=%s, to=%s, amount=%d, txId=%s", fromAddress, toAddress, value, txId)
+	return txId, nil
+}
+
+// getAssistant get assistant by owner MetaID
+func (s *AssistantService) getAssistant(metaId string) (*model.Assistant, error) {
+	assistant, err := s.assistantDAO.GetByMetaID(metaId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssistantNotFound
+		}
+		return nil, fmt.Errorf("failed to get assistant: %w", err)
+	}
+	return assistant, nil
+}
+
+// update save an assistant changed by an owner request
+func (s *AssistantService) update(assistant *model.Assistant, prevAuth int64) error {
+	updated, err := s.assistantDAO.UpdateAuthorized(assistant, prevAuth)
+	if err != nil {
+		return fmt.Errorf("failed to update assistant: %w", err)
+	}
+	if !updated {
+		return fmt.Errorf("%w: assistant changed by another request", ErrAssistantUnauthorized)
+	}
+	return nil
+}
+
+// checkEnabled assistants need a master key
+func (s *AssistantService) checkEnabled() error {
+	if conf.Cfg.Uploader.Assistant.MasterKey == "" {
+		return ErrAssistantDisabled
+	}
+	return nil
+}
+
+// verifyAssistantAuth verify the owner signature of an action
+// assistant is the current record (nil if there is none), its owner and last auth timestamp are checked.
+func verifyAssistantAuth(action string, auth *AssistantAuth, assistant *model.Assistant) error {
+	now := time.Now().Unix()
+	if auth.Timestamp < now-int64(assistantAuthWindow.Seconds()) || auth.Timestamp > now+int64(assistantAuthWindow.Seconds()) {
+		return fmt.Errorf("%w: timestamp is not within 5 minutes", ErrAssistantUnauthorized)
+	}
+	if assistant != nil && auth.Timestamp <= assistant.AuthTimestamp {
+		return fmt.Errorf("%w: signature already used", ErrAssistantUnauthorized)
+	}
+	if err := common.VerifyMessageSignature(netParams(), auth.Address, AssistantAuthMessage(action, auth), auth.Signature); err != nil {
+		return fmt.Errorf("%w: %v", ErrAssistantUnauthorized, err)
+	}
+	return nil
+}
+
+// checkSpendLimit check a requested spend limit against the configured cap
+func checkSpendLimit(limit int64) error {
+	if limit <= 0 || limit > conf.Cfg.Uploader.Assistant.MaxSpendLimit {
+		return fmt.Errorf("%w: must be between 1 and %d satoshis", ErrInvalidSpendLimit, conf.Cfg.Uploader.Assistant.MaxSpendLimit)
+	}
+	return nil
+}
+
+// toAssistantResponse convert an assistant to its response
+func toAssistantResponse(assistant *model.Assistant) *AssistantResponse {
+	state := "active"
+	if assistant.State != model.AssistantStateExist {
+		state = "revoked"
+	}
+	return &AssistantResponse{
+		MetaId:           assistant.MetaId,
+		Address:          assistant.Address,
+		AssistantAddress: assistant.AssistantAddress,
+		AssistantMetaId:  assistant.AssistantMetaId,
+		SpendLimit:       assistant.SpendLimit,
+		SpentAmount:      assistant.SpentAmount,
+		State:            state,
+	}
+}
+
+// netParams network parameters of the configured chain
+func netParams() *chaincfg2.Params {
+	if conf.Cfg.Net == "mainnet" {
+		return &chaincfg2.MainNetParams
+	}
+	return &chaincfg2.TestNet3Params
+}
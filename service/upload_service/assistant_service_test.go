@@ -0,0 +1,439 @@
+package upload_service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	bsvec2 "github.com/bitcoinsv/bsvd/bsvec"
+	"github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	txscript2 "github.com/bitcoinsv/bsvd/txscript"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+	"gorm.io/gorm"
+
+	"meta-media-service/common"
+	"meta-media-service/conf"
+	"meta-media-service/indexer"
+	"meta-media-service/model"
+)
+
+const (
+	testMasterKey = "test-master-key"
+	// testMessageMagic prefix of wallet signed messages
+	testMessageMagic = "Bitcoin Signed Message:\n"
+)
+
+// setTestConfig install a minimal testnet configuration for the duration of a test
+func setTestConfig(t *testing.T) {
+	t.Helper()
+	prev := conf.Cfg
+	conf.Cfg = &conf.Config{
+		Net: "testnet",
+		Uploader: conf.UploaderConfig{
+			FeeRate:        1,
+			ChunkThreshold: 5 * 1024 * 1024,
+			ChunkSize:      1024 * 1024,
+			MaxBatchFiles:  50,
+			Assistant: conf.AssistantConfig{
+				MasterKey:     testMasterKey,
+				MaxSpendLimit: 100000000,
+			},
+		},
+	}
+	t.Cleanup(func() { conf.Cfg = prev })
+}
+
+// newTestKey generate a testnet key, its address and P2PKH script
+func newTestKey(t *testing.T) (priHex, address string, script []byte) {
+	t.Helper()
+	priHex, address, err := common.GenerateMvcKey(netParams())
+	if err != nil {
+		t.Fatalf("GenerateMvcKey() failed, err: %v", err)
+	}
+	script, err = addressScript(netParams(), address)
+	if err != nil {
+		t.Fatalf("addressScript() failed, err: %v", err)
+	}
+	return priHex, address, script
+}
+
+// signTestMessage wallet message signature (base64 compact) of message by priHex
+func signTestMessage(t *testing.T, priHex, message string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	_ = wire2.WriteVarString(&buf, 0, testMessageMagic)
+	_ = wire2.WriteVarString(&buf, 0, message)
+	priBytes, _ := hex.DecodeString(priHex)
+	privateKey, _ := bsvec2.PrivKeyFromBytes(bsvec2.S256(), priBytes)
+	sig, err := bsvec2.SignCompact(bsvec2.S256(), privateKey, chainhash.DoubleHashB(buf.Bytes()), true)
+	if err != nil {
+		t.Fatalf("SignCompact() failed, err: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+// fundedTestTx pre-transaction spending one payer output of amount, with an OP_RETURN and change
+func fundedTestTx(t *testing.T, payerScript []byte, amount, change int64) (string, []*FundedInput) {
+	t.Helper()
+	prevHash := chainhash.DoubleHashH([]byte(fmt.Sprintf("prev-%d-%d", amount, change)))
+	tx := wire2.NewMsgTx(10)
+	tx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(&prevHash, 1), nil))
+	opReturn, _ := txscript2.NullDataScript([]byte("metaid"))
+	tx.AddTxOut(wire2.NewTxOut(0, opReturn))
+	tx.AddTxOut(wire2.NewTxOut(change, payerScript))
+	preTxRaw, err := indexer.TxToHex(tx)
+	if err != nil {
+		t.Fatalf("TxToHex() failed, err: %v", err)
+	}
+	return preTxRaw, []*FundedInput{{
+		Index:        0,
+		TxId:         prevHash.String(),
+		Vout:         1,
+		Amount:       amount,
+		ScriptPubKey: hex.EncodeToString(payerScript),
+	}}
+}
+
+// fakeAssistantStore in-memory assistant store that logs every write
+type fakeAssistantStore struct {
+	assistant *model.Assistant
+	calls     *[]string
+	chargeErr error
+}
+
+func (f *fakeAssistantStore) Create(assistant *model.Assistant) error {
+	*f.calls = append(*f.calls, "create")
+	copied := *assistant
+	f.assistant = &copied
+	return nil
+}
+
+func (f *fakeAssistantStore) GetByMetaID(metaId string) (*model.Assistant, error) {
+	if f.assistant == nil || f.assistant.MetaId != metaId {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *f.assistant
+	return &copied, nil
+}
+
+func (f *fakeAssistantStore) UpdateAuthorized(assistant *model.Assistant, prevAuthTimestamp int64) (bool, error) {
+	*f.calls = append(*f.calls, "update")
+	if f.assistant.AuthTimestamp != prevAuthTimestamp {
+		return false, nil
+	}
+	spent := f.assistant.SpentAmount
+	copied := *assistant
+	copied.SpentAmount = spent // Never written by authorized updates
+	f.assistant = &copied
+	return true, nil
+}
+
+func (f *fakeAssistantStore) ClearPrevKey(id int64, prevAddress string) error {
+	*f.calls = append(*f.calls, "clear "+prevAddress)
+	if f.assistant.PrevAddress == prevAddress {
+		f.assistant.PrevPrivateKey = ""
+		f.assistant.PrevAddress = ""
+	}
+	return nil
+}
+
+func (f *fakeAssistantStore) Charge(id int64, amount int64) (bool, error) {
+	if f.chargeErr != nil {
+		return false, f.chargeErr
+	}
+	if f.assistant.State != model.AssistantStateExist || f.assistant.SpentAmount+amount > f.assistant.SpendLimit {
+		return false, nil
+	}
+	*f.calls = append(*f.calls, fmt.Sprintf("charge %d", amount))
+	f.assistant.SpentAmount += amount
+	return true, nil
+}
+
+func (f *fakeAssistantStore) ResetSpent(id int64) error {
+	*f.calls = append(*f.calls, "reset")
+	f.assistant.SpentAmount = 0
+	return nil
+}
+
+func (f *fakeAssistantStore) Refund(id int64, amount int64) error {
+	*f.calls = append(*f.calls, fmt.Sprintf("refund %d", amount))
+	f.assistant.SpentAmount -= amount
+	if f.assistant.SpentAmount < 0 {
+		f.assistant.SpentAmount = 0
+	}
+	return nil
+}
+
+// fakeReservations reservation lister returning a fixed count for every address
+type fakeReservations struct {
+	active int
+}
+
+func (f *fakeReservations) ListActiveByAddress(address string, now time.Time) ([]*model.UtxoReservation, error) {
+	return make([]*model.UtxoReservation, f.active), nil
+}
+
+// newTestAssistantService assistant service with an active assistant of a fresh owner key
+// The sweep is faked: it logs "sweep <from> <to>" and fails while sweepErr is set.
+func newTestAssistantService(t *testing.T) (*AssistantService, *fakeAssistantStore, string, *error) {
+	t.Helper()
+	ownerPriHex, ownerAddress, _ := newTestKey(t)
+	calls := []string{}
+	store := &fakeAssistantStore{calls: &calls}
+	var sweepErr error
+	s := &AssistantService{
+		assistantDAO:   store,
+		reservationDAO: &fakeReservations{},
+	}
+	s.sweep = func(priHex, fromAddress, toAddress string) (string, error) {
+		address, err := common.MvcAddressFromPriHex(netParams(), priHex)
+		if err != nil || address != fromAddress {
+			t.Fatalf("sweep of %s signed with the key of %s", fromAddress, address)
+		}
+		calls = append(calls, "sweep "+fromAddress+" "+toAddress)
+		if sweepErr != nil {
+			return "", sweepErr
+		}
+		return "sweep-" + fromAddress, nil
+	}
+
+	assistant := &model.Assistant{
+		ID:            1,
+		MetaId:        CalculateMetaID(ownerAddress),
+		Address:       ownerAddress,
+		SpendLimit:    10000,
+		AuthTimestamp: time.Now().Unix() - 60,
+		State:         model.AssistantStateExist,
+	}
+	if _, err := s.replaceKey(assistant); err != nil {
+		t.Fatalf("replaceKey() failed, err: %v", err)
+	}
+	store.assistant = assistant
+	return s, store, ownerPriHex, &sweepErr
+}
+
+// testAuth owner authorization of action signed by ownerPriHex
+func testAuth(t *testing.T, action, ownerPriHex, ownerAddress string, spendLimit, timestamp int64) *AssistantAuth {
+	auth := &AssistantAuth{Address: ownerAddress, SpendLimit: spendLimit, Timestamp: timestamp}
+	auth.Signature = signTestMessage(t, ownerPriHex, AssistantAuthMessage(action, auth))
+	return auth
+}
+
+func TestAssistantSignerCharge(t *testing.T) {
+	setTestConfig(t)
+	commitErr := errors.New("broadcast failed")
+	tests := []struct {
+		name       string
+		spendLimit int64
+		chargeErr  error
+		commitErr  error
+		wantErr    error
+		wantSpent  int64
+		wantCommit bool
+		wantCalls  []string
+	}{
+		{name: "within limit", spendLimit: 10000, wantSpent: 1000, wantCommit: true, wantCalls: []string{"charge 1000"}},
+		{name: "exact limit", spendLimit: 1000, wantSpent: 1000, wantCommit: true, wantCalls: []string{"charge 1000"}},
+		{name: "limit exceeded", spendLimit: 999, wantErr: ErrSpendLimitExceeded, wantCalls: []string{}},
+		{name: "charge fails", spendLimit: 10000, chargeErr: errors.New("db down"), wantCalls: []string{}},
+		{name: "commit fails", spendLimit: 10000, commitErr: commitErr, wantErr: commitErr, wantCommit: true, wantCalls: []string{"charge 1000", "refund 1000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, _, _ := newTestAssistantService(t)
+			store.assistant.SpendLimit = tt.spendLimit
+			store.chargeErr = tt.chargeErr
+			assistant := *store.assistant // The service works on its own copy, as read from the database
+			priHex, err := s.decryptKey(assistant.AssistantPrivateKey, assistant.AssistantAddress)
+			if err != nil {
+				t.Fatalf("decryptKey() failed, err: %v", err)
+			}
+			script, _ := addressScript(netParams(), assistant.AssistantAddress)
+			preTxRaw, inputs := fundedTestTx(t, script, 10000, 9000)
+
+			committed := false
+			err = s.signer(&assistant, priHex, script).commit(preTxRaw, inputs, func(signedRawTx string) error {
+				committed = true
+				tx, err := decodeTestTx(signedRawTx)
+				if err != nil || len(tx.TxIn[0].SignatureScript) == 0 {
+					t.Errorf("committed transaction is not signed, err: %v", err)
+				}
+				return tt.commitErr
+			})
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("commit() err = %v, want %v", err, tt.wantErr)
+			case tt.wantErr == nil && tt.chargeErr == nil && err != nil:
+				t.Errorf("commit() failed, err: %v", err)
+			case tt.chargeErr != nil && err == nil:
+				t.Error("commit() should fail when the charge fails")
+			}
+			if committed != tt.wantCommit {
+				t.Errorf("committed = %v, want %v", committed, tt.wantCommit)
+			}
+			if store.assistant.SpentAmount != tt.wantSpent {
+				t.Errorf("spent = %d, want %d", store.assistant.SpentAmount, tt.wantSpent)
+			}
+			if fmt.Sprint(*store.calls) != fmt.Sprint(tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", *store.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestAssistantRotateSavesKeyBeforeSweep(t *testing.T) {
+	setTestConfig(t)
+	s, store, ownerPriHex, _ := newTestAssistantService(t)
+	oldAddress := store.assistant.AssistantAddress
+
+	auth := testAuth(t, AssistantActionRotate, ownerPriHex, store.assistant.Address, 0, time.Now().Unix())
+	resp, err := s.Rotate(store.assistant.MetaId, auth)
+	if err != nil {
+		t.Fatalf("Rotate() failed, err: %v", err)
+	}
+	newAddress := store.assistant.AssistantAddress
+	if newAddress == oldAddress || resp.AssistantAddress != newAddress || resp.AccessToken == "" {
+		t.Fatalf("Rotate() did not save a new key: old=%s, new=%s, resp=%+v", oldAddress, newAddress, resp)
+	}
+	want := []string{"update", "sweep " + oldAddress + " " + newAddress, "clear " + oldAddress}
+	if fmt.Sprint(*store.calls) != fmt.Sprint(want) {
+		t.Errorf("calls = %v, want %v", *store.calls, want)
+	}
+	if resp.SweepTxId != "sweep-"+oldAddress {
+		t.Errorf("SweepTxId = %q, want the sweep of the old key", resp.SweepTxId)
+	}
+	if store.assistant.PrevAddress != "" || store.assistant.PrevPrivateKey != "" {
+		t.Errorf("replaced key kept after its sweep: %s", store.assistant.PrevAddress)
+	}
+}
+
+func TestAssistantRotateKeepsKeyWhenSweepFails(t *testing.T) {
+	setTestConfig(t)
+	s, store, ownerPriHex, sweepErr := newTestAssistantService(t)
+	oldAddress := store.assistant.AssistantAddress
+
+	*sweepErr = errors.New("node unavailable")
+	now := time.Now().Unix()
+	auth := testAuth(t, AssistantActionRotate, ownerPriHex, store.assistant.Address, 0, now)
+	resp, err := s.Rotate(store.assistant.MetaId, auth)
+	if err != nil {
+		t.Fatalf("Rotate() failed, err: %v", err)
+	}
+	if resp.AccessToken == "" || resp.SweepTxId != "" {
+		t.Errorf("Rotate() = %+v, want a new token and no sweep", resp)
+	}
+	if store.assistant.PrevAddress != oldAddress {
+		t.Fatalf("PrevAddress = %q, want the unswept key %s", store.assistant.PrevAddress, oldAddress)
+	}
+	if _, err := s.decryptKey(store.assistant.PrevPrivateKey, oldAddress); err != nil {
+		t.Fatalf("unswept key is not recoverable, err: %v", err)
+	}
+
+	// The next rotation moves the unswept key first
+	*sweepErr = nil
+	*store.calls = nil
+	secondAddress := store.assistant.AssistantAddress
+	auth = testAuth(t, AssistantActionRotate, ownerPriHex, store.assistant.Address, 0, now+1)
+	if _, err := s.Rotate(store.assistant.MetaId, auth); err != nil {
+		t.Fatalf("Rotate() failed, err: %v", err)
+	}
+	thirdAddress := store.assistant.AssistantAddress
+	want := []string{
+		"sweep " + oldAddress + " " + secondAddress, "clear " + oldAddress,
+		"update", "sweep " + secondAddress + " " + thirdAddress, "clear " + secondAddress,
+	}
+	if fmt.Sprint(*store.calls) != fmt.Sprint(want) {
+		t.Errorf("calls = %v, want %v", *store.calls, want)
+	}
+}
+
+func TestAssistantRotateKeepsReservedKey(t *testing.T) {
+	setTestConfig(t)
+	s, store, ownerPriHex, _ := newTestAssistantService(t)
+	s.reservationDAO = &fakeReservations{active: 1}
+	oldAddress := store.assistant.AssistantAddress
+
+	now := time.Now().Unix()
+	auth := testAuth(t, AssistantActionRotate, ownerPriHex, store.assistant.Address, 0, now)
+	if _, err := s.Rotate(store.assistant.MetaId, auth); err != nil {
+		t.Fatalf("Rotate() failed, err: %v", err)
+	}
+	if store.assistant.PrevAddress != oldAddress {
+		t.Errorf("PrevAddress = %q, want %s kept while its UTXOs are reserved", store.assistant.PrevAddress, oldAddress)
+	}
+
+	// A second rotation would drop the reserved key, it is refused
+	auth = testAuth(t, AssistantActionRotate, ownerPriHex, store.assistant.Address, 0, now+1)
+	if _, err := s.Rotate(store.assistant.MetaId, auth); err == nil {
+		t.Error("Rotate() should fail while the replaced key has pending uploads")
+	}
+	if store.assistant.PrevAddress != oldAddress {
+		t.Errorf("PrevAddress = %q, want %s", store.assistant.PrevAddress, oldAddress)
+	}
+}
+
+func TestAssistantRevokeSavesBeforeSweep(t *testing.T) {
+	setTestConfig(t)
+	s, store, ownerPriHex, _ := newTestAssistantService(t)
+	assistantAddress := store.assistant.AssistantAddress
+	ownerAddress := store.assistant.Address
+
+	auth := testAuth(t, AssistantActionRevoke, ownerPriHex, ownerAddress, 0, time.Now().Unix())
+	resp, err := s.Revoke(store.assistant.MetaId, auth)
+	if err != nil {
+		t.Fatalf("Revoke() failed, err: %v", err)
+	}
+	want := []string{"update", "sweep " + assistantAddress + " " + ownerAddress}
+	if fmt.Sprint(*store.calls) != fmt.Sprint(want) {
+		t.Errorf("calls = %v, want %v", *store.calls, want)
+	}
+	if resp.State != "revoked" || store.assistant.State != model.AssistantStateDeleted || store.assistant.TokenHash != "" {
+		t.Errorf("assistant not revoked: %+v", store.assistant)
+	}
+	if store.assistant.AssistantPrivateKey == "" {
+		t.Error("revoked assistant key should be kept for a later sweep")
+	}
+
+	// A revoked assistant can no longer be charged
+	if charged, _ := store.Charge(store.assistant.ID, 1); charged {
+		t.Error("revoked assistant was charged")
+	}
+}
+
+func TestAssistantCreateAgainResetsSpent(t *testing.T) {
+	setTestConfig(t)
+	s, store, ownerPriHex, _ := newTestAssistantService(t)
+	store.assistant.SpentAmount = 700
+	store.assistant.State = model.AssistantStateDeleted
+	store.assistant.TokenHash = ""
+
+	auth := testAuth(t, AssistantActionCreate, ownerPriHex, store.assistant.Address, 5000, time.Now().Unix())
+	resp, err := s.Create(auth)
+	if err != nil {
+		t.Fatalf("Create() failed, err: %v", err)
+	}
+	if resp.SpentAmount != 0 || store.assistant.SpentAmount != 0 || resp.State != "active" || resp.SpendLimit != 5000 {
+		t.Errorf("Create() = %+v, spent %d, want an active assistant with nothing spent", resp, store.assistant.SpentAmount)
+	}
+	calls := *store.calls
+	if len(calls) == 0 || calls[len(calls)-1] != "reset" || calls[0] != "update" {
+		t.Errorf("calls = %v, want the key saved first and the spent amount reset last", calls)
+	}
+}
+
+// decodeTestTx deserialize a raw transaction (hex)
+func decodeTestTx(rawTx string) (*wire2.MsgTx, error) {
+	txBytes, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	tx := wire2.NewMsgTx(10)
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
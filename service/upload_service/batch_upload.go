@@ -0,0 +1,389 @@
+package upload_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+
+	"meta-media-service/common"
+	"meta-media-service/conf"
+	"meta-media-service/model"
+)
+
+var ErrInvalidBatch = errors.New("invalid batch upload")
+
+// BatchUploadFile one file of a batch upload
+type BatchUploadFile struct {
+	FileName    string // File name
+	Content     []byte // File content
+	ContentType string // Content type
+}
+
+// BatchUploadRequest batch upload request, every file is inscribed as one PIN
+// MetaID, path, payer, change address, fee rate and chain are shared by all files.
+type BatchUploadRequest struct {
+	MetaId        string             // MetaID
+	Address       string             // Address
+	Path          string             // MetaID path
+	Operation     string             // create/update
+	ChangeAddress string             // Change address
+	FeeRate       int64              // Fee rate
+	PayerAddress  string             // Payer address, if set the uploader selects its UTXOs as inputs (mvc)
+	Chain         string             // mvc (default)/btc
+	BtcInputs     []*common.BtcUtxo  // Payer UTXOs of the shared BTC commit transaction
+	Files         []*BatchUploadFile // Files in upload order
+}
+
+// BatchPreUploadResponse batch pre-upload response
+type BatchPreUploadResponse struct {
+	Files     []*PreUploadResponse `json:"files"`     // Pre-upload of every file, in request order
+	CalTxFee  int64                `json:"calTxFee"`  // Calculated fee of all transactions
+	CalTxSize int64                `json:"calTxSize"` // Calculated size of all transactions
+
+	Chain      string `json:"chain"`                // mvc/btc
+	CommitPsbt string `json:"commitPsbt,omitempty"` // BTC commit PSBT of the whole batch for the payer to sign (base64)
+	CommitTxId string `json:"commitTxId,omitempty"` // BTC commit transaction ID
+}
+
+// BatchCommitItem signed transaction of one file of a batch
+type BatchCommitItem struct {
+	FileId      string `json:"fileId"`      // File ID (from batch pre-upload response)
+	SignedRawTx string `json:"signedRawTx"` // Signed raw transaction (hex), may be empty in a BTC batch with signedCommit
+}
+
+// BatchCommitResponse batch commit response
+type BatchCommitResponse struct {
+	Files   []*UploadResponse `json:"files"`   // Commit result of every file, in request order
+	PinIds  map[string]string `json:"pinIds"`  // FileId -> PinId of the committed files
+	Success int               `json:"success"` // Number of committed files
+	Failed  int               `json:"failed"`  // Number of files that failed to commit
+}
+
+// BatchPreUpload pre-upload several files at once
+// The MetaID indexer only reads the first MetaID output of an MVC transaction, and numbers every PIN of a
+// BTC reveal i0, so each file keeps its own PIN transaction. On BTC one commit transaction funds the reveals
+// of all files, so the payer signs once. An MVC batch would still need a transaction and a signature per
+// file, so it holds one file until the indexer reads every MetaID output. If a file fails, the records and
+// reservations of the files before it are rolled back.
+func (s *UploadService) BatchPreUpload(req *BatchUploadRequest) (*BatchPreUploadResponse, error) {
+	if err := validateBatch(req); err != nil {
+		return nil, err
+	}
+
+	rollback := &batchRollback{files: s.fileDAO, reservations: s.utxoReservationDAO}
+	var (
+		resp *BatchPreUploadResponse
+		err  error
+	)
+	if req.Chain == model.ChainBtc {
+		resp, err = s.batchPreUploadBtc(req, rollback)
+	} else {
+		resp, err = preUploadBatch(req, rollback, s.PreUpload)
+	}
+	if err != nil {
+		rollback.undo()
+		return nil, err
+	}
+
+	log.Printf("Batch pre-upload: metaId=%s, chain=%s, files=%d, fee=%d", req.MetaId, resp.Chain, len(resp.Files), resp.CalTxFee)
+	return resp, nil
+}
+
+// validateBatch check the files of a batch before anything is built
+func validateBatch(req *BatchUploadRequest) error {
+	if len(req.Files) == 0 {
+		return fmt.Errorf("%w: no files", ErrInvalidBatch)
+	}
+	if int64(len(req.Files)) > conf.Cfg.Uploader.MaxBatchFiles {
+		return fmt.Errorf("%w: at most %d files per batch", ErrInvalidBatch, conf.Cfg.Uploader.MaxBatchFiles)
+	}
+	switch req.Chain {
+	case "":
+		req.Chain = model.ChainMvc
+	case model.ChainMvc, model.ChainBtc:
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedChain, req.Chain)
+	}
+	if req.Chain == model.ChainMvc && len(req.Files) > 1 {
+		return fmt.Errorf("%w: an MVC batch holds one file, each MVC file needs its own transaction; upload the files one by one or use chain btc", ErrInvalidBatch)
+	}
+	if req.Path == "" {
+		return fmt.Errorf("%w: path is required", ErrInvalidBatch)
+	}
+
+	seen := make(map[string]int, len(req.Files))
+	for i, f := range req.Files {
+		if len(f.Content) == 0 {
+			return fmt.Errorf("%w: file %d (%s) is empty", ErrInvalidBatch, i, f.FileName)
+		}
+		// Chunked files need a transaction per chunk, upload them with pre-upload
+		if int64(len(f.Content)) > conf.Cfg.Uploader.ChunkThreshold {
+			return fmt.Errorf("%w: file %d (%s) exceeds the chunk threshold of %d bytes", ErrInvalidBatch, i, f.FileName, conf.Cfg.Uploader.ChunkThreshold)
+		}
+		fileId, _, _ := fileHashes(req.MetaId, f.Content)
+		if j, ok := seen[fileId]; ok {
+			return fmt.Errorf("%w: file %d (%s) has the same content as file %d", ErrInvalidBatch, i, f.FileName, j)
+		}
+		seen[fileId] = i
+	}
+	return nil
+}
+
+// fileRequest upload request of one file of a batch
+func (req *BatchUploadRequest) fileRequest(f *BatchUploadFile) *UploadRequest {
+	return &UploadRequest{
+		MetaId:        req.MetaId,
+		Address:       req.Address,
+		FileName:      f.FileName,
+		Content:       f.Content,
+		Path:          req.Path,
+		Operation:     req.Operation,
+		ContentType:   f.ContentType,
+		ChangeAddress: req.ChangeAddress,
+		FeeRate:       req.FeeRate,
+		PayerAddress:  req.PayerAddress,
+		Chain:         req.Chain,
+		BtcInputs:     req.BtcInputs,
+	}
+}
+
+// preUploadBatch pre-upload the files of an MVC batch one by one
+// Files are pre-uploaded in order, so payer UTXOs selected for one file are not selected for the next.
+// validateBatch limits MVC batches to one file for now.
+func preUploadBatch(req *BatchUploadRequest, rollback *batchRollback, preUpload func(*UploadRequest) (*PreUploadResponse, error)) (*BatchPreUploadResponse, error) {
+	resp := &BatchPreUploadResponse{
+		Files: make([]*PreUploadResponse, 0, len(req.Files)),
+		Chain: model.ChainMvc,
+	}
+	for i, f := range req.Files {
+		fileId, _, _ := fileHashes(req.MetaId, f.Content)
+		if err := rollback.track(fileId); err != nil {
+			return nil, err
+		}
+		pre, err := preUpload(req.fileRequest(f))
+		if err != nil {
+			return nil, fmt.Errorf("file %d (%s): %w", i, f.FileName, err)
+		}
+		resp.Files = append(resp.Files, pre)
+		resp.CalTxFee += pre.CalTxFee
+		resp.CalTxSize += pre.CalTxSize
+	}
+	return resp, nil
+}
+
+// batchPreUploadBtc build one commit transaction for the files of a BTC batch and a reveal per file
+// Files already uploaded are returned as they are and left out of the commit.
+func (s *UploadService) batchPreUploadBtc(req *BatchUploadRequest, rollback *batchRollback) (*BatchPreUploadResponse, error) {
+	if err := checkBtcEnabled(); err != nil {
+		return nil, err
+	}
+	if req.Address == "" {
+		return nil, fmt.Errorf("%w: address (PIN owner) is required", ErrInvalidBtcUpload)
+	}
+	if len(req.BtcInputs) == 0 {
+		return nil, fmt.Errorf("%w: inputs are required", ErrInvalidBtcUpload)
+	}
+	if req.ChangeAddress == "" {
+		req.ChangeAddress = req.Address
+	}
+	if req.FeeRate == 0 {
+		req.FeeRate = conf.Cfg.Uploader.Btc.FeeRate
+	}
+	if req.Operation == "" {
+		req.Operation = "create"
+	}
+
+	resp := &BatchPreUploadResponse{
+		Files: make([]*PreUploadResponse, len(req.Files)),
+		Chain: model.ChainBtc,
+	}
+	type pendingPin struct {
+		index    int
+		req      *UploadRequest
+		existing *model.File
+		fileId   string
+		fileHash string
+		fileMd5  string
+	}
+	var (
+		pending []*pendingPin
+		pins    []*common.BtcInscriptionContent
+	)
+	for i, f := range req.Files {
+		fileReq := req.fileRequest(f)
+		if fileReq.ContentType == "" {
+			fileReq.ContentType = "application/octet-stream"
+		}
+		fileId, fileHash, fileMd5 := fileHashes(req.MetaId, f.Content)
+		existing, err := s.fileDAO.GetByFileID(fileId)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("failed to get file: %w", err)
+			}
+			existing = nil
+		}
+		if existing != nil && existing.Committed() {
+			resp.Files[i] = uploadedBtcResponse(existing)
+			continue
+		}
+		pending = append(pending, &pendingPin{index: i, req: fileReq, existing: existing, fileId: fileId, fileHash: fileHash, fileMd5: fileMd5})
+		pins = append(pins, &common.BtcInscriptionContent{
+			Operation:   fileReq.Operation,
+			Path:        fileReq.Path,
+			ContentType: fileReq.ContentType,
+			Content:     fileReq.Content,
+		})
+	}
+	if len(pending) == 0 {
+		return resp, nil
+	}
+
+	batch, err := common.BuildBtcMetaIdInscriptions(btcNetParams(), req.BtcInputs, req.Address, req.ChangeAddress,
+		pins, conf.Cfg.Uploader.Btc.Postage, req.FeeRate)
+	if err != nil {
+		return nil, btcBuildError(err)
+	}
+	commitPsbt, err := common.BtcCommitPsbt(batch.CommitTx, batch.CommitPrevOuts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create commit PSBT: %w", err)
+	}
+	commitRaw, err := common.BtcTxToHex(batch.CommitTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize commit transaction: %w", err)
+	}
+	resp.CommitPsbt = commitPsbt
+	resp.CommitTxId = batch.CommitTx.TxHash().String()
+	resp.CalTxFee = batch.CommitFee
+	resp.CalTxSize = batch.CommitVsize
+
+	for i, pin := range pending {
+		if err := rollback.track(pin.fileId); err != nil {
+			return nil, err
+		}
+		reveal := batch.Reveals[i]
+		file, err := s.saveBtcFile(pin.existing, pin.req, pin.fileId, pin.fileHash, pin.fileMd5, batch.CommitTx, commitPsbt, reveal)
+		if err != nil {
+			return nil, fmt.Errorf("file %d (%s): %w", pin.index, pin.req.FileName, err)
+		}
+		pre := builtBtcResponse(file, commitRaw)
+		pre.CalTxFee = reveal.Fee
+		pre.CalTxSize = reveal.Vsize
+		resp.Files[pin.index] = pre
+		resp.CalTxFee += reveal.Fee
+		resp.CalTxSize += reveal.Vsize
+	}
+	log.Printf("BTC batch inscription built: commit=%s, reveals=%d", resp.CommitTxId, len(pending))
+	return resp, nil
+}
+
+// batchFileStore file records a batch pre-upload writes
+type batchFileStore interface {
+	GetByFileID(fileID string) (*model.File, error)
+	Update(file *model.File) error
+	Delete(id int64) error
+}
+
+// batchReservations payer UTXO reservations a batch pre-upload makes
+type batchReservations interface {
+	ReleaseByFileID(fileId string) error
+}
+
+// batchRollback files a batch pre-upload touched, with their records from before the batch
+type batchRollback struct {
+	files        batchFileStore
+	reservations batchReservations
+	touched      []*batchTouchedFile
+}
+
+// batchTouchedFile file written by a batch pre-upload
+type batchTouchedFile struct {
+	fileId   string
+	previous *model.File // Record before the batch, nil if the batch created it
+}
+
+// track remember the record of a file before the batch writes it
+func (r *batchRollback) track(fileId string) error {
+	previous, err := r.files.GetByFileID(fileId)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get file: %w", err)
+		}
+		previous = nil
+	}
+	r.touched = append(r.touched, &batchTouchedFile{fileId: fileId, previous: previous})
+	return nil
+}
+
+// undo release the reservations of the touched files, delete the records the batch created and put the
+// others back as they were. Failures are only logged, reservations expire on their own.
+func (r *batchRollback) undo() {
+	for i := len(r.touched) - 1; i >= 0; i-- {
+		touched := r.touched[i]
+		if err := r.reservations.ReleaseByFileID(touched.fileId); err != nil {
+			log.Printf("Failed to release reservations of batch file: fileId=%s, error=%v", touched.fileId, err)
+		}
+		if touched.previous != nil {
+			if err := r.files.Update(touched.previous); err != nil {
+				log.Printf("Failed to restore batch file: fileId=%s, error=%v", touched.fileId, err)
+			}
+			continue
+		}
+		file, err := r.files.GetByFileID(touched.fileId)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Failed to get batch file: fileId=%s, error=%v", touched.fileId, err)
+			}
+			continue
+		}
+		if err := r.files.Delete(file.ID); err != nil {
+			log.Printf("Failed to delete batch file: fileId=%s, error=%v", touched.fileId, err)
+		}
+	}
+	r.touched = nil
+}
+
+// BatchCommitUpload commit the signed transactions of a batch
+// Each transaction is checked and broadcast like CommitUpload. A file that fails does not stop the
+// others, its result carries status failed and the error message. Files of a BTC batch share the
+// signed commit, it is used for every item without signedRawTx.
+func (s *UploadService) BatchCommitUpload(items []*BatchCommitItem, signedCommit string) (*BatchCommitResponse, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: no files", ErrInvalidBatch)
+	}
+	if int64(len(items)) > conf.Cfg.Uploader.MaxBatchFiles {
+		return nil, fmt.Errorf("%w: at most %d files per batch", ErrInvalidBatch, conf.Cfg.Uploader.MaxBatchFiles)
+	}
+	for i, item := range items {
+		if item.FileId == "" || (item.SignedRawTx == "" && signedCommit == "") {
+			return nil, fmt.Errorf("%w: file %d needs fileId and signedRawTx", ErrInvalidBatch, i)
+		}
+	}
+
+	resp := &BatchCommitResponse{
+		Files:  make([]*UploadResponse, 0, len(items)),
+		PinIds: make(map[string]string, len(items)),
+	}
+	for _, item := range items {
+		signed := item.SignedRawTx
+		if signed == "" {
+			signed = signedCommit
+		}
+		result, err := s.CommitUpload(item.FileId, signed)
+		if err != nil {
+			log.Printf("Batch commit failed: fileId=%s, error=%v", item.FileId, err)
+			resp.Files = append(resp.Files, &UploadResponse{
+				FileId:  item.FileId,
+				Status:  string(model.StatusFailed),
+				Message: err.Error(),
+			})
+			resp.Failed++
+			continue
+		}
+		resp.Files = append(resp.Files, result)
+		resp.PinIds[result.FileId] = result.PinId
+		resp.Success++
+	}
+	return resp, nil
+}
@@ -0,0 +1,196 @@
+package upload_service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"meta-media-service/model"
+)
+
+// fakeBatchFiles in-memory file records, keyed by file ID
+type fakeBatchFiles struct {
+	files  map[string]*model.File
+	nextID int64
+}
+
+func newFakeBatchFiles(files ...*model.File) *fakeBatchFiles {
+	f := &fakeBatchFiles{files: make(map[string]*model.File), nextID: 100}
+	for _, file := range files {
+		f.files[file.FileId] = file
+	}
+	return f
+}
+
+func (f *fakeBatchFiles) GetByFileID(fileID string) (*model.File, error) {
+	file, ok := f.files[fileID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	saved := *file
+	return &saved, nil
+}
+
+func (f *fakeBatchFiles) Update(file *model.File) error {
+	saved := *file
+	f.files[file.FileId] = &saved
+	return nil
+}
+
+func (f *fakeBatchFiles) Delete(id int64) error {
+	for fileId, file := range f.files {
+		if file.ID == id {
+			delete(f.files, fileId)
+		}
+	}
+	return nil
+}
+
+// save write the record a pre-upload writes, rebuilding an existing one in place
+func (f *fakeBatchFiles) save(req *UploadRequest) {
+	fileId, fileHash, _ := fileHashes(req.MetaId, req.Content)
+	file := &model.File{FileId: fileId, FileName: req.FileName, FileHash: fileHash, Status: model.StatusPending}
+	if existing, ok := f.files[fileId]; ok {
+		file.ID = existing.ID
+	} else {
+		f.nextID++
+		file.ID = f.nextID
+	}
+	f.files[fileId] = file
+}
+
+// fakeBatchReservations log of released file IDs
+type fakeBatchReservations struct {
+	released []string
+}
+
+func (f *fakeBatchReservations) ReleaseByFileID(fileId string) error {
+	f.released = append(f.released, fileId)
+	return nil
+}
+
+func testBatchRequest(names ...string) *BatchUploadRequest {
+	req := &BatchUploadRequest{MetaId: "metaid", Path: "/file"}
+	for _, name := range names {
+		req.Files = append(req.Files, &BatchUploadFile{FileName: name, Content: []byte("content of " + name)})
+	}
+	return req
+}
+
+func testBatchFileId(req *BatchUploadRequest, i int) string {
+	fileId, _, _ := fileHashes(req.MetaId, req.Files[i].Content)
+	return fileId
+}
+
+func TestPreUploadBatchRollback(t *testing.T) {
+	req := testBatchRequest("a.png", "b.png", "c.png")
+	failed := &model.File{ID: 7, FileId: testBatchFileId(req, 1), FileName: "old.png", Status: model.StatusFailed}
+	uploaded := &model.File{ID: 8, FileId: "metaid_other", Status: model.StatusSuccess}
+	files := newFakeBatchFiles(failed, uploaded)
+	reservations := &fakeBatchReservations{}
+	rollback := &batchRollback{files: files, reservations: reservations}
+
+	// The third file fails after the first was created and the failed record of the second was rebuilt
+	preUpload := func(fileReq *UploadRequest) (*PreUploadResponse, error) {
+		if fileReq.FileName == "c.png" {
+			return nil, ErrInsufficientFunds
+		}
+		files.save(fileReq)
+		fileId, _, _ := fileHashes(fileReq.MetaId, fileReq.Content)
+		return &PreUploadResponse{FileId: fileId, CalTxFee: 10}, nil
+	}
+	_, err := preUploadBatch(req, rollback, preUpload)
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("preUploadBatch() err = %v, want %v", err, ErrInsufficientFunds)
+	}
+	if files.files[testBatchFileId(req, 1)].Status != model.StatusPending {
+		t.Fatal("pre-upload of the second file should have rebuilt its record")
+	}
+	rollback.undo()
+
+	if _, ok := files.files[testBatchFileId(req, 0)]; ok {
+		t.Error("record created by the batch should be deleted")
+	}
+	if got := files.files[testBatchFileId(req, 1)]; !reflect.DeepEqual(got, failed) {
+		t.Errorf("record rebuilt by the batch = %+v, want it restored to %+v", got, failed)
+	}
+	if got := files.files["metaid_other"]; !reflect.DeepEqual(got, uploaded) {
+		t.Error("records outside the batch should not change")
+	}
+	want := []string{testBatchFileId(req, 2), testBatchFileId(req, 1), testBatchFileId(req, 0)}
+	if !reflect.DeepEqual(reservations.released, want) {
+		t.Errorf("released = %v, want %v", reservations.released, want)
+	}
+}
+
+func TestPreUploadBatch(t *testing.T) {
+	req := testBatchRequest("a.png", "b.png")
+	files := newFakeBatchFiles()
+	reservations := &fakeBatchReservations{}
+	rollback := &batchRollback{files: files, reservations: reservations}
+
+	var requests []*UploadRequest
+	preUpload := func(fileReq *UploadRequest) (*PreUploadResponse, error) {
+		requests = append(requests, fileReq)
+		files.save(fileReq)
+		return &PreUploadResponse{FileId: fileReq.FileName, CalTxFee: 10, CalTxSize: 100}, nil
+	}
+	resp, err := preUploadBatch(req, rollback, preUpload)
+	if err != nil {
+		t.Fatalf("preUploadBatch() failed, err: %v", err)
+	}
+	if len(resp.Files) != 2 || resp.Files[0].FileId != "a.png" || resp.Files[1].FileId != "b.png" {
+		t.Errorf("files should be pre-uploaded in request order, got %+v", resp.Files)
+	}
+	if resp.CalTxFee != 20 || resp.CalTxSize != 200 || resp.Chain != model.ChainMvc {
+		t.Errorf("batch totals = fee %d, size %d, chain %q", resp.CalTxFee, resp.CalTxSize, resp.Chain)
+	}
+	for _, fileReq := range requests {
+		if fileReq.Path != req.Path || fileReq.MetaId != req.MetaId {
+			t.Errorf("file request %+v should share the batch parameters", fileReq)
+		}
+	}
+	if len(files.files) != 2 || len(reservations.released) != 0 {
+		t.Error("a successful batch should keep its records and reservations")
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	setTestConfig(t)
+	tests := []struct {
+		name    string
+		modify  func(req *BatchUploadRequest)
+		wantErr error
+	}{
+		{"valid", func(req *BatchUploadRequest) {}, nil},
+		{"one mvc file", func(req *BatchUploadRequest) { req.Chain, req.Files = "", req.Files[:1] }, nil},
+		{"several mvc files", func(req *BatchUploadRequest) { req.Chain = model.ChainMvc }, ErrInvalidBatch},
+		{"no files", func(req *BatchUploadRequest) { req.Files = nil }, ErrInvalidBatch},
+		{"no path", func(req *BatchUploadRequest) { req.Path = "" }, ErrInvalidBatch},
+		{"empty file", func(req *BatchUploadRequest) { req.Files[1].Content = nil }, ErrInvalidBatch},
+		{"same content", func(req *BatchUploadRequest) { req.Files[1].Content = req.Files[0].Content }, ErrInvalidBatch},
+		{"unknown chain", func(req *BatchUploadRequest) { req.Chain = "eth" }, ErrUnsupportedChain},
+		{"too many files", func(req *BatchUploadRequest) {
+			for i := 0; i < 50; i++ {
+				req.Files = append(req.Files, &BatchUploadFile{FileName: "x", Content: []byte(fmt.Sprint(i))})
+			}
+		}, ErrInvalidBatch},
+		{"above chunk threshold", func(req *BatchUploadRequest) {
+			req.Files[0].Content = make([]byte, 5*1024*1024+1)
+		}, ErrInvalidBatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testBatchRequest("a.png", "b.png")
+			req.Chain = model.ChainBtc
+			tt.modify(req)
+			err := validateBatch(req)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("validateBatch() err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
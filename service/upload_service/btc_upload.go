@@ -0,0 +1,340 @@
+package upload_service
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"gorm.io/gorm"
+
+	"meta-media-service/common"
+	"meta-media-service/conf"
+	"meta-media-service/database"
+	"meta-media-service/model"
+)
+
+var (
+	ErrUnsupportedChain = errors.New("unsupported chain")
+	ErrBtcDisabled      = errors.New("BTC uploads are not enabled")
+	ErrInvalidBtcUpload = errors.New("invalid BTC upload")
+)
+
+// btcNetParams BTC network parameters of the configured network
+func btcNetParams() *chaincfg.Params {
+	if conf.Cfg.Net == "mainnet" {
+		return &chaincfg.MainNetParams
+	}
+	return &chaincfg.TestNet3Params
+}
+
+// checkBtcEnabled check BTC uploads are enabled and reveal keys can be kept
+func checkBtcEnabled() error {
+	if !conf.Cfg.Uploader.Btc.Enabled {
+		return ErrBtcDisabled
+	}
+	if conf.Cfg.Uploader.Btc.RevealKeySecret == "" {
+		return fmt.Errorf("%w: uploader.btc.reveal_key_secret is not set", ErrBtcDisabled)
+	}
+	return nil
+}
+
+// preUploadBtc pre-upload on BTC: build the commit and reveal transactions of the inscription
+// The reveal transaction is signed here with a one-time key, only the commit PSBT is left for the payer
+// to sign. Both are kept on the file record until the signed commit is submitted, with the reveal key
+// encrypted by uploader.btc.reveal_key_secret.
+func (s *UploadService) preUploadBtc(req *UploadRequest) (*PreUploadResponse, error) {
+	if err := checkBtcEnabled(); err != nil {
+		return nil, err
+	}
+	if req.Address == "" {
+		return nil, fmt.Errorf("%w: address (PIN owner) is required", ErrInvalidBtcUpload)
+	}
+	if len(req.BtcInputs) == 0 {
+		return nil, fmt.Errorf("%w: inputs are required", ErrInvalidBtcUpload)
+	}
+	if req.ChangeAddress == "" {
+		req.ChangeAddress = req.Address
+	}
+	if req.FeeRate == 0 {
+		req.FeeRate = conf.Cfg.Uploader.Btc.FeeRate
+	}
+
+	fileId, filehashStr, md5hashStr := fileHashes(req.MetaId, req.Content)
+	existingFile, err := s.fileDAO.GetByFileID(fileId)
+	if err != nil {
+		existingFile = nil
+	}
+	if existingFile != nil && existingFile.Committed() {
+		log.Printf("File already exists and uploaded successfully: FileId=%s", fileId)
+		return uploadedBtcResponse(existingFile), nil
+	}
+
+	inscription, err := common.BuildBtcMetaIdInscription(btcNetParams(), req.BtcInputs, req.Address, req.ChangeAddress,
+		req.Operation, req.Path, req.ContentType, req.Content, conf.Cfg.Uploader.Btc.Postage, req.FeeRate)
+	if err != nil {
+		return nil, btcBuildError(err)
+	}
+	commitPsbt, err := common.BtcCommitPsbt(inscription.CommitTx, inscription.CommitPrevOuts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create commit PSBT: %w", err)
+	}
+	commitRaw, err := common.BtcTxToHex(inscription.CommitTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize commit transaction: %w", err)
+	}
+
+	reveal := &common.BtcReveal{Tx: inscription.RevealTx, Key: inscription.RevealKey}
+	file, err := s.saveBtcFile(existingFile, req, fileId, filehashStr, md5hashStr, inscription.CommitTx, commitPsbt, reveal)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("BTC inscription built: FileId=%s, commit=%s, reveal=%s", file.FileId, file.CommitTxId, file.TxID)
+
+	resp := builtBtcResponse(file, commitRaw)
+	resp.CalTxFee = inscription.CommitFee + inscription.RevealFee
+	resp.CalTxSize = inscription.CommitVsize + inscription.RevealVsize
+	return resp, nil
+}
+
+// fileHashes file ID, sha256 and md5 (hex) of the content of a file
+func fileHashes(metaId string, content []byte) (fileId, fileHash, fileMd5 string) {
+	sha256hash := sha256.Sum256(content)
+	md5hash := md5.Sum(content)
+	fileHash = hex.EncodeToString(sha256hash[:])
+	return metaId + "_" + fileHash, fileHash, hex.EncodeToString(md5hash[:])
+}
+
+// btcBuildError error of a failed inscription build, insufficient funds and bad inputs are the caller's
+func btcBuildError(err error) error {
+	if errors.Is(err, common.ErrBtcInsufficientFunds) {
+		return fmt.Errorf("%w: %v", ErrInsufficientFunds, err)
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidBtcUpload, err)
+}
+
+// saveBtcFile save the pending record of a BTC file with its commit and reveal transactions
+// A pending or failed record of the file is rebuilt in place, its previous transactions are never broadcast.
+// The reveal key is kept encrypted, so the reveal can be signed again.
+func (s *UploadService) saveBtcFile(existingFile *model.File, req *UploadRequest, fileId, fileHash, fileMd5 string, commit *wire.MsgTx, commitPsbt string, reveal *common.BtcReveal) (*model.File, error) {
+	revealRaw, err := common.BtcTxToHex(reveal.Tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize reveal transaction: %w", err)
+	}
+	revealKey, err := encryptKeyWithSecret(conf.Cfg.Uploader.Btc.RevealKeySecret, hex.EncodeToString(reveal.Key.Serialize()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt reveal key: %w", err)
+	}
+	revealTxId := reveal.Tx.TxHash().String()
+
+	file := existingFile
+	if file == nil {
+		file = &model.File{FileId: fileId}
+	}
+	file.FileName = req.FileName
+	file.FileType = strings.ReplaceAll(req.ContentType, ";binary", "")
+	file.MetaId = req.MetaId
+	file.Address = req.Address
+	file.Path = req.Path
+	file.ContentType = req.ContentType
+	file.FileSize = int64(len(req.Content))
+	file.FileHash = fileHash
+	file.FileMd5 = fileMd5
+	file.FileContentType = strings.ReplaceAll(req.ContentType, ";binary", "")
+	file.ChunkType = model.ChunkTypeSingle
+	file.Operation = req.Operation
+	file.Chain = model.ChainBtc
+	file.TxID = revealTxId
+	file.PinId = revealTxId + "i0"
+	file.CommitTxId = commit.TxHash().String()
+	file.PreTxRaw = commitPsbt
+	file.RevealTxRaw = revealRaw
+	file.RevealKey = revealKey
+	file.Status = model.StatusPending
+	file.TxState = model.TxStateBuilt
+
+	if existingFile != nil {
+		err = s.fileDAO.Update(file)
+	} else {
+		err = s.fileDAO.Create(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save file metadata: %w", err)
+	}
+	logTxEvent(database.UploaderDB, file.FileId, revealTxId, model.TxStateBuilt, 0, "")
+	return file, nil
+}
+
+// builtBtcResponse pre-upload response of a BTC file whose inscription was just built
+func builtBtcResponse(file *model.File, commitRaw string) *PreUploadResponse {
+	return &PreUploadResponse{
+		FileId:      file.FileId,
+		FileMd5:     file.FileMd5,
+		FileHash:    file.FileHash,
+		TxId:        file.TxID,
+		PinId:       file.PinId,
+		PreTxRaw:    commitRaw,
+		Status:      string(file.Status),
+		Message:     "success",
+		ChunkType:   string(file.ChunkType),
+		Chain:       file.Chain,
+		CommitPsbt:  file.PreTxRaw,
+		CommitTxId:  file.CommitTxId,
+		RevealTxRaw: file.RevealTxRaw,
+	}
+}
+
+// uploadedBtcResponse pre-upload response of a BTC file that is already uploaded
+func uploadedBtcResponse(file *model.File) *PreUploadResponse {
+	return &PreUploadResponse{
+		TxId:      file.TxID,
+		PinId:     file.PinId,
+		FileId:    file.FileId,
+		FileMd5:   file.FileMd5,
+		FileHash:  file.FileHash,
+		ChunkType: string(file.ChunkType),
+		Chain:     file.Chain,
+		Status:    string(file.Status),
+		TxState:   string(file.TxState),
+		Message:   "file already exists and uploaded",
+	}
+}
+
+// commitBtcUpload verify the signed commit transaction of a BTC file and broadcast it, then the reveal
+// signed is a signed PSBT (base64 or hex) or the signed commit transaction (hex). A commit the wallet rebuilt
+// with other inputs is accepted as a signed PSBT, the reveal is signed again for it. A file stays pending
+// when a broadcast fails, committing again resubmits both transactions. It stays pending until the reveal
+// is confirmed.
+func (s *UploadService) commitBtcUpload(file *model.File, signed string) error {
+	commitTx, signedPrevOuts, err := common.ExtractBtcSignedTx(signed)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBtcUpload, err)
+	}
+	packet, err := common.ParseBtcPsbt(file.PreTxRaw)
+	if err != nil {
+		return fmt.Errorf("failed to parse stored commit PSBT: %w", err)
+	}
+	prevOuts := make([]*wire.TxOut, len(packet.Inputs))
+	for i, in := range packet.Inputs {
+		prevOuts[i] = in.WitnessUtxo
+	}
+	if txId := commitTx.TxHash().String(); txId != file.CommitTxId {
+		if signedPrevOuts == nil {
+			return fmt.Errorf("%w: signed transaction %s is not the commit transaction %s, submit a rebuilt commit as a signed PSBT",
+				ErrInvalidBtcUpload, txId, file.CommitTxId)
+		}
+		if err := s.resignBtcReveal(file, packet.UnsignedTx, commitTx); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBtcUpload, err)
+		}
+		prevOuts = signedPrevOuts
+	}
+	if err := common.VerifyBtcInputs(commitTx, prevOuts); err != nil {
+		return fmt.Errorf("%w: commit signature check failed: %v", ErrInvalidBtcUpload, err)
+	}
+	commitRaw, err := common.BtcTxToHex(commitTx)
+	if err != nil {
+		return fmt.Errorf("failed to serialize commit transaction: %w", err)
+	}
+
+	// The reveal spends the commit output, so the commit goes first. It is recorded on its own, so the tracker
+	// still follows it when the reveal broadcast fails. The commit of a batch is shared, its record belongs to
+	// the first file committed and the other files skip the broadcast.
+	shared, err := s.fileTxDAO.GetByTxID(file.CommitTxId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get commit transaction: %w", err)
+	}
+	if err == nil && shared.FileId != file.FileId && shared.State != model.TxStateFailed && shared.State != model.TxStateReplaced {
+		log.Printf("Commit transaction already broadcast with its batch: fileId=%s, txId=%s, with=%s", file.FileId, file.CommitTxId, shared.FileId)
+	} else {
+		err = broadcastFileTx(&model.FileTx{
+			FileId:     file.FileId,
+			TxId:       file.CommitTxId,
+			Chain:      model.ChainBtc,
+			Role:       model.TxRoleCommit,
+			ChunkIndex: fileTxIndex,
+			TxRaw:      commitRaw,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to broadcast commit transaction: %w", err)
+		}
+		log.Printf("Commit transaction broadcasted successfully: fileId=%s, txId=%s", file.FileId, file.CommitTxId)
+	}
+	err = broadcastFileTx(&model.FileTx{
+		FileId:     file.FileId,
+		TxId:       file.TxID,
+		Chain:      model.ChainBtc,
+		Role:       model.TxRoleFile,
+		ChunkIndex: fileTxIndex,
+		TxRaw:      file.RevealTxRaw,
+	}, func(tx *gorm.DB) error {
+		file.TxRaw = commitRaw
+		file.Status = model.StatusPending
+		file.TxState = model.TxStateBroadcast
+		if err := tx.Save(file).Error; err != nil {
+			return fmt.Errorf("failed to update file record: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to broadcast reveal transaction: %w", err)
+	}
+	log.Printf("Reveal transaction broadcasted successfully: fileId=%s, txId=%s", file.FileId, file.TxID)
+	return nil
+}
+
+// resignBtcReveal sign the reveal of a BTC file again for a rebuilt commit transaction
+// The rebuilt commit must pay the inscription output at least what the built one did, the reveal fee comes
+// from it. The file gets the new commit and reveal transaction IDs, the PIN ID follows the reveal.
+func (s *UploadService) resignBtcReveal(file *model.File, builtCommit, commitTx *wire.MsgTx) error {
+	if file.RevealKey == "" {
+		return errors.New("reveal key was not kept, upload the file again")
+	}
+	priHex, err := decryptKeyWithSecret(conf.Cfg.Uploader.Btc.RevealKeySecret, file.RevealKey)
+	if err != nil {
+		return err
+	}
+	priBytes, err := hex.DecodeString(priHex)
+	if err != nil {
+		return fmt.Errorf("invalid reveal key: %w", err)
+	}
+	revealKey, _ := btcec.PrivKeyFromBytes(priBytes)
+	reveal, _, err := common.ExtractBtcSignedTx(file.RevealTxRaw)
+	if err != nil {
+		return fmt.Errorf("failed to parse stored reveal transaction: %w", err)
+	}
+	builtOut := reveal.TxIn[0].PreviousOutPoint
+	if builtOut.Hash != builtCommit.TxHash() || int(builtOut.Index) >= len(builtCommit.TxOut) {
+		return errors.New("stored reveal does not spend the built commit")
+	}
+	built := builtCommit.TxOut[builtOut.Index].Value
+	if err := common.SignBtcReveal(reveal, commitTx, revealKey); err != nil {
+		return err
+	}
+	if paid := commitTx.TxOut[reveal.TxIn[0].PreviousOutPoint.Index].Value; paid < built {
+		return fmt.Errorf("rebuilt commit pays the inscription %d satoshis, the built one %d", paid, built)
+	}
+	revealRaw, err := common.BtcTxToHex(reveal)
+	if err != nil {
+		return fmt.Errorf("failed to serialize reveal transaction: %w", err)
+	}
+
+	log.Printf("BTC reveal signed again: FileId=%s, commit=%s -> %s", file.FileId, file.CommitTxId, commitTx.TxHash().String())
+	file.CommitTxId = commitTx.TxHash().String()
+	file.TxID = reveal.TxHash().String()
+	file.PinId = file.TxID + "i0"
+	file.RevealTxRaw = revealRaw
+	return nil
+}
+
+// isAlreadyBroadcast whether a broadcast failed because the node already has the transaction
+func isAlreadyBroadcast(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already in block chain") || strings.Contains(msg, "txn-already-in-mempool") ||
+		strings.Contains(msg, "txn-already-known")
+}
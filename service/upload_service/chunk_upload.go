@@ -0,0 +1,655 @@
+package upload_service
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+	"gorm.io/gorm"
+
+	"meta-media-service/common"
+	"meta-media-service/conf"
+	"meta-media-service/database"
+	"meta-media-service/indexer"
+	"meta-media-service/model"
+)
+
+// Content types of the PINs a chunked file is inscribed as
+const (
+	ChunkContentType = "metafile/chunk;binary" // Chunk PIN, raw slice of the file
+	IndexContentType = "metafile/index;utf-8"  // Index PIN, JSON listing the chunk PINs
+)
+
+var (
+	ErrFileNotFound  = errors.New("file not found")
+	ErrChunkNotFound = errors.New("chunk not found")
+)
+
+// PreUploadChunk unsigned transaction of one chunk PIN
+type PreUploadChunk struct {
+	ChunkIndex int64  `json:"chunkIndex"` // Chunk index (0-based)
+	ChunkSize  int64  `json:"chunkSize"`  // Chunk size (bytes)
+	ChunkHash  string `json:"chunkHash"`  // Chunk SHA256
+	PreTxRaw   string `json:"preTxRaw"`   // Pre-transaction raw data (empty once committed)
+	Status     string `json:"status"`     // pending/success
+	CalTxFee   int64  `json:"calTxFee"`   // Calculated transaction fee
+	CalTxSize  int64  `json:"calTxSize"`  // Calculated transaction size
+
+	Inputs []*FundedInput `json:"inputs,omitempty"` // Payer inputs of PreTxRaw to sign (with payer address)
+}
+
+// ChunkProgress broadcast state of one chunk PIN
+type ChunkProgress struct {
+	ChunkIndex int64  `json:"chunkIndex"` // Chunk index (0-based)
+	ChunkSize  int64  `json:"chunkSize"`  // Chunk size (bytes)
+	ChunkHash  string `json:"chunkHash"`  // Chunk SHA256
+	Status     string `json:"status"`     // pending/success
+	TxId       string `json:"txId"`       // Chunk transaction ID (once committed)
+	PinId      string `json:"pinId"`      // Chunk Pin ID (once committed)
+}
+
+// UploadProgressResponse upload progress response
+type UploadProgressResponse struct {
+	FileId          string           `json:"fileId"`                // File ID
+	ChunkType       string           `json:"chunkType"`             // single/multi
+	Status          string           `json:"status"`                // File status, success once the index transaction is broadcast
+	TotalTxCount    int              `json:"totalTxCount"`          // Transactions to broadcast (chunks + index)
+	BroadcastTxs    int              `json:"broadcastTxs"`          // Transactions broadcast so far
+	Progress        int              `json:"progress"`              // Broadcast progress (0-100)
+	ChunkNumber     int              `json:"chunkNumber"`           // Number of chunks
+	CommittedChunks int              `json:"committedChunks"`       // Chunks broadcast so far
+	Chunks          []*ChunkProgress `json:"chunks"`                // Chunk states ordered by index
+	IndexPreTxRaw   string           `json:"indexPreTxRaw"`         // Index pre-transaction, set once all chunks are broadcast
+	IndexInputs     []*FundedInput   `json:"indexInputs,omitempty"` // Payer inputs of IndexPreTxRaw to sign (with payer address)
+	TxId            string           `json:"txId"`                  // Index transaction ID (once committed)
+	PinId           string           `json:"pinId"`                 // Index Pin ID (once committed)
+	TxState         string           `json:"txState"`               // Broadcast state of the file (or index) transaction
+	BlockHeight     int64            `json:"blockHeight"`           // Block height of the file transaction, once confirmed
+}
+
+// metaFileIndex content of the index PIN of a chunked file
+type metaFileIndex struct {
+	Sha256      string               `json:"sha256"`
+	FileSize    int64                `json:"fileSize"`
+	ChunkNumber int                  `json:"chunkNumber"`
+	ChunkSize   int64                `json:"chunkSize"`
+	DataType    string               `json:"dataType"`
+	Name        string               `json:"name"`
+	ChunkList   []metaFileIndexChunk `json:"chunkList"`
+}
+
+// metaFileIndexChunk chunk entry of the index PIN
+type metaFileIndexChunk struct {
+	Sha256 string `json:"sha256"`
+	PinId  string `json:"pinId"`
+}
+
+// placeholderPinId stands in for chunk Pin IDs in the index template, it has the length of a real Pin ID
+var placeholderPinId = strings.Repeat("0", 64) + "i0"
+
+// preUploadChunked pre-upload a file above the chunk threshold
+// Every chunk gets its own unsigned transaction. The index transaction is kept as a template in
+// File.PreTxRaw and returned by GetUploadProgress once all chunk transactions are broadcast.
+func (s *UploadService) preUploadChunked(req *UploadRequest, netParam *chaincfg2.Params) (*PreUploadResponse, error) {
+	sha256hash := sha256.Sum256(req.Content)
+	md5hash := md5.Sum(req.Content)
+	filehashStr := hex.EncodeToString(sha256hash[:])
+	md5hashStr := hex.EncodeToString(md5hash[:])
+	fileId := req.MetaId + "_" + filehashStr
+
+	existingFile, err := s.fileDAO.GetByFileID(fileId)
+	if err == nil && existingFile != nil {
+		if existingFile.Committed() {
+			log.Printf("File already exists and uploaded successfully: FileId=%s", fileId)
+			return &PreUploadResponse{
+				TxId:      existingFile.TxID,
+				PinId:     existingFile.PinId,
+				FileId:    existingFile.FileId,
+				FileMd5:   existingFile.FileMd5,
+				FileHash:  existingFile.FileHash,
+				ChunkType: string(existingFile.ChunkType),
+				Status:    string(existingFile.Status),
+				TxState:   string(existingFile.TxState),
+				Message:   "file already exists and uploaded",
+			}, nil
+		} else if existingFile.Status == model.StatusPending && existingFile.ChunkType == model.ChunkTypeMulti {
+			// Chunks are being committed, return the transactions of the remaining ones
+			log.Printf("Chunked file already exists in pending status: FileId=%s", fileId)
+			return s.resumeChunked(req, existingFile, netParam)
+		}
+		log.Printf("File exists but is not a pending chunked upload, re-uploading as chunks: FileId=%s", fileId)
+	}
+
+	chunkSize := conf.Cfg.Uploader.ChunkSize
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("invalid chunk size: %d", chunkSize)
+	}
+	chunkPath := path.Join(req.Path, "_chunk")
+	storageType := uploadStorageType()
+
+	var (
+		chunks     []*model.FileChunk
+		preChunks  []*PreUploadChunk
+		fundingTxs []*fundingTx
+		totalFee   int64
+		totalSize  int64
+		indexList  []metaFileIndexChunk
+	)
+	for index := int64(0); index*chunkSize < int64(len(req.Content)); index++ {
+		end := (index + 1) * chunkSize
+		if end > int64(len(req.Content)) {
+			end = int64(len(req.Content))
+		}
+		content := req.Content[index*chunkSize : end]
+
+		chunkSha256 := sha256.Sum256(content)
+		chunkMd5 := md5.Sum(content)
+		chunkHash := hex.EncodeToString(chunkSha256[:])
+
+		// Chunk content is kept in storage so that transactions of pending chunks can be rebuilt
+		key := chunkStorageKey(chunkHash)
+		if !s.storage.Exists(key) {
+			if err := s.storage.Save(key, content); err != nil {
+				return nil, fmt.Errorf("failed to save chunk %d: %w", index, err)
+			}
+		}
+
+		preChunk, chunkTx, err := buildChunkTx(netParam, req, chunkPath, index, chunkHash, content)
+		if err != nil {
+			return nil, err
+		}
+
+		chunks = append(chunks, &model.FileChunk{
+			FileId:      fileId,
+			ChunkHash:   chunkHash,
+			ChunkSize:   int64(len(content)),
+			ChunkMd5:    hex.EncodeToString(chunkMd5[:]),
+			ChunkIndex:  index,
+			FileHash:    filehashStr,
+			TxID:        pendingChunkTxID(fileId, index),
+			Path:        chunkPath,
+			ContentType: ChunkContentType,
+			Size:        int64(len(content)),
+			StorageType: storageType,
+			StoragePath: key,
+			Operation:   "create",
+			Status:      model.StatusPending,
+		})
+		preChunks = append(preChunks, preChunk)
+		fundingTxs = append(fundingTxs, &fundingTx{tx: chunkTx, txIndex: index})
+		indexList = append(indexList, metaFileIndexChunk{Sha256: chunkHash, PinId: placeholderPinId})
+	}
+
+	// Index transaction template, placeholder Pin IDs keep its size equal to the final one
+	fileContentType := strings.ReplaceAll(req.ContentType, ";binary", "")
+	indexContent, err := json.Marshal(&metaFileIndex{
+		Sha256:      filehashStr,
+		FileSize:    int64(len(req.Content)),
+		ChunkNumber: len(chunks),
+		ChunkSize:   chunkSize,
+		DataType:    fileContentType,
+		Name:        req.FileName,
+		ChunkList:   indexList,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode index: %w", err)
+	}
+	indexTx, err := common.BuildMvcCommonMetaIdTxForUnkwonInput(
+		netParam,
+		req.Inputs,
+		req.Outputs,
+		req.OtherOutputs,
+		req.Operation,
+		req.Path,
+		indexContent,
+		IndexContentType,
+		req.templateChangeAddress(),
+		req.FeeRate,
+		true, // No signature needed
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build index transaction: %w", err)
+	}
+	indexFunding := &fundingTx{tx: indexTx, txIndex: fileTxIndex, fee: int64(indexTx.SerializeSize()) * req.FeeRate}
+
+	if req.PayerAddress != "" {
+		if err := s.utxoReservationDAO.ReleaseByFileID(fileId); err != nil {
+			return nil, fmt.Errorf("failed to release previous reservations: %w", err)
+		}
+		if err := s.fundTxs(netParam, req, fileId, append(fundingTxs, indexFunding)); err != nil {
+			return nil, err
+		}
+		for i, ftx := range fundingTxs {
+			if err := applyChunkFunding(preChunks[i], ftx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, preChunk := range preChunks {
+		totalFee += preChunk.CalTxFee
+		totalSize += preChunk.CalTxSize
+	}
+
+	indexTxRaw, err := indexer.TxToHex(indexTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize index transaction: %w", err)
+	}
+	totalFee += indexFunding.fee
+	totalSize += int64(signedTxSize(indexTx))
+
+	file := &model.File{
+		FileId:          fileId,
+		FileName:        req.FileName,
+		FileType:        fileContentType,
+		MetaId:          req.MetaId,
+		Address:         req.Address,
+		Path:            req.Path,
+		ContentType:     req.ContentType,
+		FileSize:        int64(len(req.Content)),
+		FileHash:        filehashStr,
+		FileMd5:         md5hashStr,
+		FileContentType: fileContentType,
+		ChunkType:       model.ChunkTypeMulti,
+		Operation:       req.Operation,
+		PreTxRaw:        indexTxRaw,
+		Status:          model.StatusPending,
+		TxState:         model.TxStateBuilt,
+	}
+	if existingFile != nil {
+		file.ID = existingFile.ID
+		file.CreatedAt = existingFile.CreatedAt
+	}
+
+	err = database.UploaderDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("file_id = ?", fileId).Delete(&model.FileChunk{}).Error; err != nil {
+			return fmt.Errorf("failed to clear previous chunks: %w", err)
+		}
+		if err := tx.Save(file).Error; err != nil {
+			return fmt.Errorf("failed to save file metadata: %w", err)
+		}
+		if err := tx.Create(&chunks).Error; err != nil {
+			return fmt.Errorf("failed to save chunk metadata: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logTxEvent(database.UploaderDB, fileId, "", model.TxStateBuilt, 0, "")
+
+	log.Printf("Chunked file metadata saved successfully: FileId=%s, chunks=%d, status=pending", fileId, len(chunks))
+
+	return &PreUploadResponse{
+		FileId:    fileId,
+		FileMd5:   md5hashStr,
+		FileHash:  filehashStr,
+		ChunkType: string(model.ChunkTypeMulti),
+		Chunks:    preChunks,
+		Status:    string(file.Status),
+		CalTxFee:  totalFee,
+		CalTxSize: totalSize,
+		Message:   "success",
+	}, nil
+}
+
+// resumeChunked rebuild the transactions of the chunks of a pending upload that are not committed yet
+func (s *UploadService) resumeChunked(req *UploadRequest, file *model.File, netParam *chaincfg2.Params) (*PreUploadResponse, error) {
+	chunks, err := s.fileChunkDAO.ListByFileID(file.FileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+
+	var (
+		preChunks  []*PreUploadChunk
+		pending    []*PreUploadChunk
+		fundingTxs []*fundingTx
+		totalFee   int64
+		totalSize  int64
+	)
+	for _, chunk := range chunks {
+		if chunk.Status == model.StatusSuccess {
+			preChunks = append(preChunks, &PreUploadChunk{
+				ChunkIndex: chunk.ChunkIndex,
+				ChunkSize:  chunk.ChunkSize,
+				ChunkHash:  chunk.ChunkHash,
+				Status:     string(chunk.Status),
+			})
+			continue
+		}
+
+		content, err := s.storage.Get(chunk.StoragePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk %d: %w", chunk.ChunkIndex, err)
+		}
+		preChunk, chunkTx, err := buildChunkTx(netParam, req, chunk.Path, chunk.ChunkIndex, chunk.ChunkHash, content)
+		if err != nil {
+			return nil, err
+		}
+		preChunks = append(preChunks, preChunk)
+		pending = append(pending, preChunk)
+		fundingTxs = append(fundingTxs, &fundingTx{tx: chunkTx, txIndex: chunk.ChunkIndex})
+	}
+
+	// Pending chunks are funded again, the index transaction keeps its inputs
+	if req.PayerAddress != "" && len(fundingTxs) > 0 {
+		for _, ftx := range fundingTxs {
+			if err := s.utxoReservationDAO.ReleaseByFileTx(file.FileId, ftx.txIndex); err != nil {
+				return nil, fmt.Errorf("failed to release previous reservations: %w", err)
+			}
+		}
+		if err := s.fundTxs(netParam, req, file.FileId, fundingTxs); err != nil {
+			return nil, err
+		}
+		for i, ftx := range fundingTxs {
+			if err := applyChunkFunding(pending[i], ftx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, preChunk := range pending {
+		totalFee += preChunk.CalTxFee
+		totalSize += preChunk.CalTxSize
+	}
+
+	return &PreUploadResponse{
+		FileId:    file.FileId,
+		FileMd5:   file.FileMd5,
+		FileHash:  file.FileHash,
+		ChunkType: string(file.ChunkType),
+		Chunks:    preChunks,
+		Status:    string(file.Status),
+		CalTxFee:  totalFee,
+		CalTxSize: totalSize,
+		Message:   "file already in pending, please commit remaining chunks",
+	}, nil
+}
+
+// CommitChunk commit one chunk of a chunked upload: broadcast its transaction and record its Pin ID
+func (s *UploadService) CommitChunk(fileId string, chunkIndex int64, signedRawTx string) (*UploadProgressResponse, error) {
+	file, err := s.fileDAO.GetByFileID(fileId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to find file record: %w", err)
+	}
+	if file.ChunkType != model.ChunkTypeMulti {
+		return nil, fmt.Errorf("file is not a chunked upload: fileId=%s", fileId)
+	}
+	if file.Committed() {
+		return nil, fmt.Errorf("file already committed: fileId=%s", fileId)
+	}
+
+	var chunk model.FileChunk
+	if err := database.UploaderDB.Where("file_id = ? AND chunk_index = ?", fileId, chunkIndex).First(&chunk).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChunkNotFound
+		}
+		return nil, fmt.Errorf("failed to find chunk record: %w", err)
+	}
+	if chunk.Status == model.StatusSuccess {
+		return nil, fmt.Errorf("chunk already committed: fileId=%s, chunkIndex=%d", fileId, chunkIndex)
+	}
+
+	verified, err := verifySignedTx(signedRawTx, &expectedPin{
+		path:        chunk.Path,
+		contentType: ChunkContentType,
+		contentHash: chunk.ChunkHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Broadcast failure leaves the chunk pending so it can be committed again
+	err = broadcastFileTx(&model.FileTx{
+		FileId:     fileId,
+		TxId:       verified.txId,
+		Chain:      model.ChainMvc,
+		Role:       model.TxRoleChunk,
+		ChunkIndex: chunkIndex,
+		TxRaw:      signedRawTx,
+	}, func(tx *gorm.DB) error {
+		chunk.TxID = verified.txId
+		chunk.PinId = verified.pinId
+		chunk.Status = model.StatusSuccess
+		if err := tx.Save(&chunk).Error; err != nil {
+			return fmt.Errorf("failed to update chunk record: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to broadcast chunk transaction: %w", err)
+	}
+	log.Printf("Chunk transaction broadcasted successfully: fileId=%s, chunkIndex=%d, txId=%s", fileId, chunkIndex, verified.txId)
+
+	// Spent UTXOs are no longer listed, the rest stay reserved while chunks keep coming
+	if err := s.utxoReservationDAO.ReleaseByFileTx(fileId, chunkIndex); err != nil {
+		log.Printf("Failed to release reservations of chunk: fileId=%s, chunkIndex=%d, err=%v", fileId, chunkIndex, err)
+	}
+	if err := s.utxoReservationDAO.ExtendByFileID(fileId, reservationExpiresAt()); err != nil {
+		log.Printf("Failed to extend reservations: fileId=%s, err=%v", fileId, err)
+	}
+
+	return s.GetUploadProgress(fileId)
+}
+
+// GetUploadProgress get broadcast progress of an upload
+// For chunked uploads the index pre-transaction is returned once every chunk transaction is broadcast.
+func (s *UploadService) GetUploadProgress(fileId string) (*UploadProgressResponse, error) {
+	file, err := s.fileDAO.GetByFileID(fileId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	resp := &UploadProgressResponse{
+		FileId:       file.FileId,
+		ChunkType:    string(file.ChunkType),
+		Status:       string(file.Status),
+		TxState:      string(file.TxState),
+		BlockHeight:  file.BlockHeight,
+		TotalTxCount: 1,
+		Chunks:       []*ChunkProgress{},
+	}
+	if file.Committed() {
+		resp.TxId = file.TxID
+		resp.PinId = file.PinId
+	}
+
+	if file.ChunkType == model.ChunkTypeMulti {
+		chunks, err := s.fileChunkDAO.ListByFileID(fileId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get chunks: %w", err)
+		}
+
+		resp.ChunkNumber = len(chunks)
+		resp.TotalTxCount += len(chunks)
+		for _, chunk := range chunks {
+			progress := &ChunkProgress{
+				ChunkIndex: chunk.ChunkIndex,
+				ChunkSize:  chunk.ChunkSize,
+				ChunkHash:  chunk.ChunkHash,
+				Status:     string(chunk.Status),
+			}
+			if chunk.Status == model.StatusSuccess {
+				progress.TxId = chunk.TxID
+				progress.PinId = chunk.PinId
+				resp.CommittedChunks++
+			}
+			resp.Chunks = append(resp.Chunks, progress)
+		}
+		resp.BroadcastTxs = resp.CommittedChunks
+
+		if resp.CommittedChunks == len(chunks) && !file.Committed() {
+			indexTx, err := buildIndexTx(file, chunks)
+			if err != nil {
+				return nil, err
+			}
+			if resp.IndexPreTxRaw, err = indexer.TxToHex(indexTx); err != nil {
+				return nil, fmt.Errorf("failed to serialize index transaction: %w", err)
+			}
+			if resp.IndexInputs, err = s.reservedSigHashInputs(indexTx, fileId, fileTxIndex); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if file.Committed() {
+		resp.BroadcastTxs++
+	}
+	resp.Progress = resp.BroadcastTxs * 100 / resp.TotalTxCount
+
+	return resp, nil
+}
+
+// countPendingChunks count chunks of a file whose transaction is not broadcast yet
+func countPendingChunks(tx *gorm.DB, fileId string) (int64, error) {
+	var count int64
+	err := tx.Model(&model.FileChunk{}).
+		Where("file_id = ? AND status <> ?", fileId, model.StatusSuccess).
+		Count(&count).Error
+	return count, err
+}
+
+// buildChunkTx build the unsigned transaction of a chunk PIN
+// Chunk transactions only carry the chunk output and change, payment outputs go into the index transaction.
+func buildChunkTx(netParam *chaincfg2.Params, req *UploadRequest, chunkPath string, index int64, chunkHash string, content []byte) (*PreUploadChunk, *wire2.MsgTx, error) {
+	tx, err := common.BuildMvcCommonMetaIdTxForUnkwonInput(
+		netParam,
+		nil,
+		nil,
+		nil,
+		"create",
+		chunkPath,
+		content,
+		ChunkContentType,
+		req.templateChangeAddress(),
+		req.FeeRate,
+		true, // No signature needed
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build chunk %d transaction: %w", index, err)
+	}
+	preTxRaw, err := indexer.TxToHex(tx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to serialize chunk %d transaction: %w", index, err)
+	}
+
+	txSize := tx.SerializeSize()
+	return &PreUploadChunk{
+		ChunkIndex: index,
+		ChunkSize:  int64(len(content)),
+		ChunkHash:  chunkHash,
+		PreTxRaw:   preTxRaw,
+		Status:     string(model.StatusPending),
+		CalTxFee:   int64(txSize) * req.FeeRate,
+		CalTxSize:  int64(txSize),
+	}, tx, nil
+}
+
+// applyChunkFunding update a chunk pre-transaction after payer inputs were added to it
+func applyChunkFunding(preChunk *PreUploadChunk, ftx *fundingTx) error {
+	preTxRaw, err := indexer.TxToHex(ftx.tx)
+	if err != nil {
+		return fmt.Errorf("failed to serialize chunk %d transaction: %w", preChunk.ChunkIndex, err)
+	}
+	preChunk.PreTxRaw = preTxRaw
+	preChunk.CalTxFee = ftx.fee
+	preChunk.CalTxSize = int64(signedTxSize(ftx.tx))
+	preChunk.Inputs = ftx.inputs
+	return nil
+}
+
+// buildIndexTx fill the index transaction template with the committed chunk Pin IDs
+func buildIndexTx(file *model.File, chunks []*model.FileChunk) (*wire2.MsgTx, error) {
+	templateBytes, err := hex.DecodeString(file.PreTxRaw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode index template: %w", err)
+	}
+	tx := wire2.NewMsgTx(10)
+	if err := tx.Deserialize(bytes.NewReader(templateBytes)); err != nil {
+		return nil, fmt.Errorf("failed to deserialize index template: %w", err)
+	}
+
+	content, err := buildIndexContent(file, chunks)
+	if err != nil {
+		return nil, err
+	}
+	script, err := buildMetaIdScript(file.Operation, file.Path, IndexContentType, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build index script: %w", err)
+	}
+	replaced := false
+	for _, out := range tx.TxOut {
+		if isMetaIdScript(out.PkScript) {
+			out.PkScript = script
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		return nil, fmt.Errorf("index template has no MetaID output")
+	}
+
+	return tx, nil
+}
+
+// buildIndexContent content of the index PIN listing the committed chunk Pin IDs
+func buildIndexContent(file *model.File, chunks []*model.FileChunk) ([]byte, error) {
+	index := &metaFileIndex{
+		Sha256:      file.FileHash,
+		FileSize:    file.FileSize,
+		ChunkNumber: len(chunks),
+		DataType:    file.FileContentType,
+		Name:        file.FileName,
+	}
+	if len(chunks) > 0 {
+		index.ChunkSize = chunks[0].ChunkSize
+	}
+	for _, chunk := range chunks {
+		index.ChunkList = append(index.ChunkList, metaFileIndexChunk{Sha256: chunk.ChunkHash, PinId: chunk.PinId})
+	}
+	content, err := json.Marshal(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode index: %w", err)
+	}
+	return content, nil
+}
+
+// isMetaIdScript check if an output script is a MetaID OP_RETURN output (OP_0 OP_RETURN "metaid" ...)
+func isMetaIdScript(script []byte) bool {
+	return len(script) > 9 && script[0] == 0x00 && script[1] == 0x6a && string(script[3:9]) == "metaid"
+}
+
+// pendingChunkTxID placeholder transaction ID of a chunk that is not committed yet
+// tx_id is unique and not null in tb_file_chunk.
+func pendingChunkTxID(fileId string, index int64) string {
+	hash := sha256.Sum256([]byte(fileId))
+	return fmt.Sprintf("pending_%s_%d", hex.EncodeToString(hash[:20]), index)
+}
+
+// chunkStorageKey deterministic storage key of chunk content
+// uploads/chunks/{chunk sha256}
+func chunkStorageKey(chunkHash string) string {
+	return "uploads/chunks/" + chunkHash
+}
+
+// uploadStorageType storage type recorded for uploaded content
+func uploadStorageType() string {
+	if conf.Cfg.Storage.Type == "oss" || conf.Cfg.Storage.Type == "s3" {
+		return conf.Cfg.Storage.Type
+	}
+	return "local"
+}
@@ -0,0 +1,138 @@
+package upload_service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+
+	"meta-media-service/common"
+	"meta-media-service/indexer"
+	"meta-media-service/model"
+)
+
+// testIndexTemplate index transaction template of a chunked file, as preUploadChunked builds it
+func testIndexTemplate(t *testing.T, file *model.File, chunks []*model.FileChunk, changeAddress string) string {
+	t.Helper()
+	placeholders := make([]*model.FileChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		placeholders = append(placeholders, &model.FileChunk{ChunkHash: chunk.ChunkHash, ChunkSize: chunk.ChunkSize, PinId: placeholderPinId})
+	}
+	content, err := buildIndexContent(file, placeholders)
+	if err != nil {
+		t.Fatalf("buildIndexContent() failed, err: %v", err)
+	}
+	tx, err := common.BuildMvcCommonMetaIdTxForUnkwonInput(netParams(), nil, nil, nil, file.Operation, file.Path,
+		content, IndexContentType, changeAddress, 1, true)
+	if err != nil {
+		t.Fatalf("BuildMvcCommonMetaIdTxForUnkwonInput() failed, err: %v", err)
+	}
+	// Payer input, as fundTxs adds it
+	prevHash := chainhash.DoubleHashH([]byte("payer"))
+	tx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(&prevHash, 0), nil))
+	raw, err := indexer.TxToHex(tx)
+	if err != nil {
+		t.Fatalf("TxToHex() failed, err: %v", err)
+	}
+	return raw
+}
+
+func TestBuildIndexTx(t *testing.T) {
+	setTestConfig(t)
+	_, changeAddress, _ := newTestKey(t)
+
+	for _, chunkNumber := range []int{1, 3, 40} {
+		t.Run(fmt.Sprintf("%d chunks", chunkNumber), func(t *testing.T) {
+			file := &model.File{
+				FileName:        "video.mp4",
+				FileHash:        strings.Repeat("ab", 32),
+				FileSize:        int64(chunkNumber) * 1024,
+				FileContentType: "video/mp4",
+				Operation:       "create",
+				Path:            "/file",
+			}
+			var chunks []*model.FileChunk
+			want := &metaFileIndex{
+				Sha256:      file.FileHash,
+				FileSize:    file.FileSize,
+				ChunkNumber: chunkNumber,
+				ChunkSize:   1024,
+				DataType:    file.FileContentType,
+				Name:        file.FileName,
+			}
+			for i := 0; i < chunkNumber; i++ {
+				chunkHash := chainhash.HashH([]byte(fmt.Sprintf("chunk-%d", i))).String()
+				pinId := chainhash.DoubleHashH([]byte(fmt.Sprintf("tx-%d", i))).String() + "i0"
+				chunks = append(chunks, &model.FileChunk{ChunkHash: chunkHash, ChunkSize: 1024, PinId: pinId})
+				want.ChunkList = append(want.ChunkList, metaFileIndexChunk{Sha256: chunkHash, PinId: pinId})
+			}
+			file.PreTxRaw = testIndexTemplate(t, file, chunks, changeAddress)
+			template, err := decodeMvcTx(file.PreTxRaw)
+			if err != nil {
+				t.Fatalf("decodeMvcTx() failed, err: %v", err)
+			}
+
+			tx, err := buildIndexTx(file, chunks)
+			if err != nil {
+				t.Fatalf("buildIndexTx() failed, err: %v", err)
+			}
+			if tx.SerializeSize() != template.SerializeSize() {
+				t.Errorf("index transaction size = %d, template size %d", tx.SerializeSize(), template.SerializeSize())
+			}
+			if len(tx.TxOut) != len(template.TxOut) {
+				t.Fatalf("index transaction has %d outputs, template %d", len(tx.TxOut), len(template.TxOut))
+			}
+			for i := 1; i < len(tx.TxOut); i++ {
+				if tx.TxOut[i].Value != template.TxOut[i].Value || !bytes.Equal(tx.TxOut[i].PkScript, template.TxOut[i].PkScript) {
+					t.Errorf("output %d should be kept from the template", i)
+				}
+			}
+
+			parsed, err := indexer.NewMetaIDParser("").ParseAllPINs(tx, indexer.ChainTypeMVC)
+			if err != nil || parsed == nil || len(parsed.MetaIDData) != 1 {
+				t.Fatalf("ParseAllPINs() = %+v, err: %v", parsed, err)
+			}
+			pin := parsed.MetaIDData[0]
+			if pin.OriginalPath != file.Path || pin.ContentType != IndexContentType {
+				t.Errorf("index PIN path %q, content type %q", pin.OriginalPath, pin.ContentType)
+			}
+			got := &metaFileIndex{}
+			if err := json.Unmarshal(pin.Content, got); err != nil {
+				t.Fatalf("index content is not JSON, err: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("index = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestBuildIndexTxInvalidTemplate(t *testing.T) {
+	setTestConfig(t)
+	_, _, payerScript := newTestKey(t)
+	noMetaId := wire2.NewMsgTx(10)
+	noMetaId.AddTxOut(wire2.NewTxOut(1000, payerScript))
+	noMetaIdRaw, _ := indexer.TxToHex(noMetaId)
+
+	tests := []struct {
+		name     string
+		preTxRaw string
+	}{
+		{"not hex", "zz"},
+		{"not a transaction", "0a0b"},
+		{"no MetaID output", noMetaIdRaw},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &model.File{PreTxRaw: tt.preTxRaw, Operation: "create", Path: "/file"}
+			if _, err := buildIndexTx(file, nil); err == nil {
+				t.Error("buildIndexTx() should fail")
+			}
+		})
+	}
+}
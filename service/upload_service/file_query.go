@@ -0,0 +1,134 @@
+package upload_service
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"meta-media-service/model"
+)
+
+var ErrInvalidFileQuery = errors.New("invalid file query")
+
+// FileInfo upload record of a file
+type FileInfo struct {
+	Id          int64  `json:"id"`          // Record ID, the cursor of file lists
+	FileId      string `json:"fileId"`      // File ID (metaid_filehash)
+	FileName    string `json:"fileName"`    // File name
+	FileHash    string `json:"fileHash"`    // File SHA256
+	FileMd5     string `json:"fileMd5"`     // File MD5
+	FileSize    int64  `json:"fileSize"`    // File size (bytes)
+	ContentType string `json:"contentType"` // Content type
+	ChunkType   string `json:"chunkType"`   // single/multi
+	MetaId      string `json:"metaId"`      // MetaID
+	Address     string `json:"address"`     // Owner address
+	Path        string `json:"path"`        // MetaID path
+	Operation   string `json:"operation"`   // create/modify/revoke
+	Chain       string `json:"chain"`       // mvc/btc
+	TxId        string `json:"txId"`        // File (or index, reveal) transaction ID, once committed
+	PinId       string `json:"pinId"`       // Pin ID, once committed
+	CommitTxId  string `json:"commitTxId"`  // BTC commit transaction ID
+	Status      string `json:"status"`      // pending/success/failed
+	TxState     string `json:"txState"`     // Broadcast state of the file transaction
+	BlockHeight int64  `json:"blockHeight"` // Block height, once confirmed
+	CreatedAt   int64  `json:"createdAt"`   // Creation time (Unix seconds)
+	UpdatedAt   int64  `json:"updatedAt"`   // Update time (Unix seconds)
+}
+
+// FileListResponse file list response with cursor pagination
+type FileListResponse struct {
+	Files      []*FileInfo `json:"files"`      // Files, newest first
+	NextCursor int64       `json:"nextCursor"` // Cursor of the next page
+	HasMore    bool        `json:"hasMore"`    // Whether more files may follow
+}
+
+// GetFile get the upload record of a file by file ID
+func (s *UploadService) GetFile(fileId string) (*FileInfo, error) {
+	file, err := s.fileDAO.GetByFileID(fileId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	return toFileInfo(file), nil
+}
+
+// GetFileByTxID get the upload record of a file by the ID of one of its transactions
+// Besides the file transaction, chunk, merge and BTC commit transactions lead to their file.
+func (s *UploadService) GetFileByTxID(txId string) (*FileInfo, error) {
+	file, err := s.fileDAO.GetByTxID(txId)
+	if err == nil {
+		return toFileInfo(file), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	ftx, err := s.fileTxDAO.GetByTxID(txId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return s.GetFile(ftx.FileId)
+}
+
+// ListFiles list upload records with cursor pagination, newest first
+// address, metaId and status filter the list when set. cursor is the nextCursor of the previous page (0 for the first page).
+func (s *UploadService) ListFiles(address, metaId, status string, cursor int64, size int) (*FileListResponse, error) {
+	switch model.Status(status) {
+	case "", model.StatusPending, model.StatusSuccess, model.StatusFailed:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %s", ErrInvalidFileQuery, status)
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	files, err := s.fileDAO.List(address, metaId, model.Status(status), cursor, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	resp := &FileListResponse{
+		Files: make([]*FileInfo, 0, len(files)),
+	}
+	for _, file := range files {
+		resp.Files = append(resp.Files, toFileInfo(file))
+	}
+	if len(files) > 0 {
+		resp.NextCursor = files[len(files)-1].ID
+		resp.HasMore = len(files) == size
+	}
+	return resp, nil
+}
+
+// toFileInfo convert a file record to its response, transaction data and content are left out
+func toFileInfo(file *model.File) *FileInfo {
+	return &FileInfo{
+		Id:          file.ID,
+		FileId:      file.FileId,
+		FileName:    file.FileName,
+		FileHash:    file.FileHash,
+		FileMd5:     file.FileMd5,
+		FileSize:    file.FileSize,
+		ContentType: file.ContentType,
+		ChunkType:   string(file.ChunkType),
+		MetaId:      file.MetaId,
+		Address:     file.Address,
+		Path:        file.Path,
+		Operation:   file.Operation,
+		Chain:       file.Chain,
+		TxId:        file.TxID,
+		PinId:       file.PinId,
+		CommitTxId:  file.CommitTxId,
+		Status:      string(file.Status),
+		TxState:     string(file.TxState),
+		BlockHeight: file.BlockHeight,
+		CreatedAt:   file.CreatedAt.Unix(),
+		UpdatedAt:   file.UpdatedAt.Unix(),
+	}
+}
@@ -0,0 +1,362 @@
+package upload_service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"meta-media-service/common"
+	"meta-media-service/conf"
+	"meta-media-service/model"
+	"meta-media-service/model/dao"
+)
+
+const (
+	// sponsorDayLayout budget day format (UTC)
+	sponsorDayLayout = "2006-01-02"
+	// sponsorAuthWindow max clock difference of a sponsored upload signature timestamp
+	sponsorAuthWindow = 5 * time.Minute
+)
+
+var (
+	ErrSponsorDisabled       = errors.New("fee sponsorship is disabled")
+	ErrSponsorUnauthorized   = errors.New("sponsored upload authorization failed")
+	ErrSponsorNotEligible    = errors.New("upload is not eligible for sponsorship")
+	ErrSponsorBudgetExceeded = errors.New("daily sponsorship budget exceeded")
+	ErrInvalidReportRange    = errors.New("invalid report range")
+)
+
+// SponsoredUploadRequest upload paid by the sponsor wallet
+// Signature is the wallet message signature (base64) of SponsorAuthMessage by Upload.Address.
+type SponsoredUploadRequest struct {
+	Upload    *UploadRequest // File, path, operation, content type and user address
+	Timestamp int64          // Unix seconds, must be within 5 minutes
+	Signature string         // Base64 compact signature
+}
+
+// SponsoredUploadResponse sponsored upload result
+type SponsoredUploadResponse struct {
+	FileId  string `json:"fileId"`  // File ID
+	Status  string `json:"status"`  // Status
+	TxId    string `json:"txId"`    // Transaction ID
+	PinId   string `json:"pinId"`   // Pin ID
+	TxCount int64  `json:"txCount"` // Transactions paid by the sponsor in this request
+	Fee     int64  `json:"fee"`     // Satoshis paid by the sponsor in this request (fees and owner outputs)
+	Message string `json:"message"` // Message
+}
+
+// SponsoredUploadRecord sponsored upload with its cost
+type SponsoredUploadRecord struct {
+	Id          int64  `json:"id"`          // Record ID
+	FileId      string `json:"fileId"`      // File ID
+	MetaId      string `json:"metaId"`      // MetaID of the user
+	Address     string `json:"address"`     // User address, owner of the PINs
+	Day         string `json:"day"`         // Budget day (UTC)
+	ContentType string `json:"contentType"` // Content type
+	FileSize    int64  `json:"fileSize"`    // File size (bytes)
+	TxCount     int64  `json:"txCount"`     // Transactions paid
+	Fee         int64  `json:"fee"`         // Satoshis paid
+	TxId        string `json:"txId"`        // File (or index) transaction ID
+	PinId       string `json:"pinId"`       // File Pin ID
+	Status      string `json:"status"`      // pending/success/failed
+	Message     string `json:"message"`     // Failure reason
+	CreatedAt   int64  `json:"createdAt"`   // Creation time (Unix seconds)
+}
+
+// SponsorReportResponse sponsorship cost report
+type SponsorReportResponse struct {
+	Uploads    int64                    `json:"uploads"`    // Sponsored upload requests matching the filters
+	Bytes      int64                    `json:"bytes"`      // File bytes of successful uploads
+	TxCount    int64                    `json:"txCount"`    // Transactions paid
+	Fee        int64                    `json:"fee"`        // Satoshis paid
+	Records    []*SponsoredUploadRecord `json:"records"`    // Page of records, newest first
+	NextCursor int64                    `json:"nextCursor"` // Cursor of the next page
+	HasMore    bool                     `json:"hasMore"`    // Whether there are more records
+}
+
+// SponsorService fee sponsorship
+// Uploads allowed by the policy are funded and signed by the sponsor wallet. The user authorizes
+// the MetaID output with a wallet signature and receives an owner output, so the PIN is theirs.
+type SponsorService struct {
+	sponsorDAO    *dao.SponsorDAO
+	uploadService *UploadService
+}
+
+// NewSponsorService create sponsor service instance
+func NewSponsorService(uploadService *UploadService) *SponsorService {
+	return &SponsorService{
+		sponsorDAO:    dao.NewSponsorDAO(),
+		uploadService: uploadService,
+	}
+}
+
+// SponsorAuthMessage message the user signs to authorize a sponsored MetaID output
+func SponsorAuthMessage(metaId, operation, path, contentType, contentHash string, timestamp int64) string {
+	return fmt.Sprintf("meta-media sponsored upload\nmetaId: %s\noperation: %s\npath: %s\ncontentType: %s\nsha256: %s\ntimestamp: %d",
+		metaId, operation, path, contentType, contentHash, timestamp)
+}
+
+// Upload upload a file funded and signed by the sponsor wallet
+// The file size is charged to the byte budget of the user first, then every transaction to the fee budget.
+func (s *SponsorService) Upload(sreq *SponsoredUploadRequest) (*SponsoredUploadResponse, error) {
+	cfg := conf.Cfg.Uploader.Sponsor
+	if !cfg.Enabled || cfg.PrivateKey == "" || conf.Cfg.Uploader.Assistant.MasterKey == "" {
+		return nil, ErrSponsorDisabled
+	}
+	req := sreq.Upload
+	if len(req.Content) == 0 {
+		return nil, fmt.Errorf("file content is empty")
+	}
+	if req.Operation == "" {
+		req.Operation = "create"
+	}
+	if req.ContentType == "" {
+		req.ContentType = "application/octet-stream"
+	}
+	if _, err := addressScript(netParams(), req.Address); err != nil {
+		return nil, fmt.Errorf("%w: invalid address: %v", ErrSponsorUnauthorized, err)
+	}
+	metaId := CalculateMetaID(req.Address)
+	if err := verifySponsorAuth(metaId, sreq); err != nil {
+		return nil, err
+	}
+	if err := checkSponsorPolicy(metaId, req); err != nil {
+		return nil, err
+	}
+	priHex, err := decryptPrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sponsor private key: %w", err)
+	}
+	sponsorAddress, err := common.MvcAddressFromPriHex(netParams(), priHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sponsor private key: %w", err)
+	}
+	changeScript, err := addressScript(netParams(), sponsorAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	day := time.Now().UTC().Format(sponsorDayLayout)
+	fileSize := int64(len(req.Content))
+	charged, err := s.sponsorDAO.Charge(metaId, day, fileSize, 0, cfg.DailyByteBudget, cfg.DailyFeeBudget)
+	if err != nil {
+		return nil, fmt.Errorf("failed to charge sponsorship budget: %w", err)
+	}
+	if !charged {
+		return nil, fmt.Errorf("%w: %d bytes per day", ErrSponsorBudgetExceeded, cfg.DailyByteBudget)
+	}
+
+	// The owner output comes first, the PIN owner is the first address output
+	req.MetaId = metaId
+	req.Outputs = []*common.TxOutput{{Address: req.Address, Amount: cfg.OwnerOutputAmount}}
+	req.OtherOutputs = nil
+	req.Inputs = nil
+	req.FeeRate = conf.Cfg.Uploader.FeeRate
+	req.PayerAddress = sponsorAddress
+	req.ChangeAddress = sponsorAddress
+	pre, err := s.uploadService.PreUpload(req)
+	if err != nil || pre.committed() {
+		s.refund(metaId, day, fileSize, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &SponsoredUploadResponse{FileId: pre.FileId, Status: pre.Status, TxId: pre.TxId, PinId: pre.PinId, Message: pre.Message}, nil
+	}
+
+	record := &model.SponsoredUpload{
+		FileId:      pre.FileId,
+		MetaId:      metaId,
+		Address:     req.Address,
+		Day:         day,
+		ContentType: req.ContentType,
+		FileSize:    fileSize,
+		Status:      model.StatusPending,
+	}
+	if err := s.sponsorDAO.CreateUpload(record); err != nil {
+		s.refund(metaId, day, fileSize, 0)
+		return nil, fmt.Errorf("failed to save sponsored upload: %w", err)
+	}
+
+	resp, err := s.uploadService.commitFunded(pre, s.signer(record, priHex, changeScript).commit)
+	if err != nil {
+		if errors.Is(err, ErrSponsorBudgetExceeded) {
+			s.uploadService.releaseReservations(record.FileId)
+		}
+		record.Status = model.StatusFailed
+		record.Message = truncateMessage(err.Error(), 500)
+		if record.TxCount == 0 {
+			// Nothing was broadcast, the file does not count against the byte budget
+			s.refund(metaId, day, fileSize, 0)
+		}
+	} else {
+		record.Status = model.StatusSuccess
+		record.TxId = resp.TxId
+		record.PinId = resp.PinId
+	}
+	if updateErr := s.sponsorDAO.UpdateUpload(record); updateErr != nil {
+		log.Printf("Failed to save sponsored upload: fileId=%s, err=%v", record.FileId, updateErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Sponsored upload committed: metaId=%s, fileId=%s, pinId=%s, txs=%d, fee=%d",
+		metaId, resp.FileId, resp.PinId, record.TxCount, record.Fee)
+	return &SponsoredUploadResponse{
+		FileId:  resp.FileId,
+		Status:  resp.Status,
+		TxId:    resp.TxId,
+		PinId:   resp.PinId,
+		TxCount: record.TxCount,
+		Fee:     record.Fee,
+		Message: resp.Message,
+	}, nil
+}
+
+// Report get sponsorship totals and a page of sponsored uploads
+// metaId, from and to (inclusive days, 2006-01-02) are optional filters.
+func (s *SponsorService) Report(metaId, from, to string, cursor int64, size int) (*SponsorReportResponse, error) {
+	for _, day := range []string{from, to} {
+		if day == "" {
+			continue
+		}
+		if _, err := time.Parse(sponsorDayLayout, day); err != nil {
+			return nil, fmt.Errorf("%w: day %q is not YYYY-MM-DD", ErrInvalidReportRange, day)
+		}
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	totals, err := s.sponsorDAO.SumUploads(metaId, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum sponsored uploads: %w", err)
+	}
+	uploads, err := s.sponsorDAO.ListUploadsWithCursor(metaId, from, to, cursor, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sponsored uploads: %w", err)
+	}
+
+	resp := &SponsorReportResponse{
+		Uploads: totals.Uploads,
+		Bytes:   totals.Bytes,
+		TxCount: totals.TxCount,
+		Fee:     totals.Fee,
+		Records: make([]*SponsoredUploadRecord, 0, len(uploads)),
+	}
+	for _, upload := range uploads {
+		resp.Records = append(resp.Records, toSponsoredUploadRecord(upload))
+	}
+	if len(uploads) > 0 {
+		resp.NextCursor = uploads[len(uploads)-1].ID
+		resp.HasMore = len(uploads) == size
+	}
+	return resp, nil
+}
+
+// signer signs the transactions of a sponsored upload and charges them to the fee budget of its MetaID
+func (s *SponsorService) signer(record *model.SponsoredUpload, priHex string, changeScript []byte) *chargedSigner {
+	cfg := conf.Cfg.Uploader.Sponsor
+	return &chargedSigner{
+		priHex:       priHex,
+		changeScript: changeScript,
+		charge: func(cost int64) error {
+			charged, err := s.sponsorDAO.Charge(record.MetaId, record.Day, 0, cost, cfg.DailyByteBudget, cfg.DailyFeeBudget)
+			if err != nil {
+				return fmt.Errorf("failed to charge sponsorship budget: %w", err)
+			}
+			if !charged {
+				return fmt.Errorf("%w: %d satoshis per day, transaction costs %d", ErrSponsorBudgetExceeded, cfg.DailyFeeBudget, cost)
+			}
+			return nil
+		},
+		refund: func(cost int64) {
+			s.refund(record.MetaId, record.Day, 0, cost)
+		},
+		committed: func(cost int64) {
+			record.TxCount++
+			record.Fee += cost
+		},
+	}
+}
+
+// refund return an unspent charge to the budget of a MetaID
+func (s *SponsorService) refund(metaId, day string, bytes, fee int64) {
+	if err := s.sponsorDAO.Refund(metaId, day, bytes, fee); err != nil {
+		log.Printf("Failed to refund sponsorship budget: metaId=%s, day=%s, bytes=%d, fee=%d, err=%v", metaId, day, bytes, fee, err)
+	}
+}
+
+// verifySponsorAuth verify the user signature authorizing the MetaID output
+func verifySponsorAuth(metaId string, sreq *SponsoredUploadRequest) error {
+	now := time.Now().Unix()
+	if sreq.Timestamp < now-int64(sponsorAuthWindow.Seconds()) || sreq.Timestamp > now+int64(sponsorAuthWindow.Seconds()) {
+		return fmt.Errorf("%w: timestamp is not within 5 minutes", ErrSponsorUnauthorized)
+	}
+	req := sreq.Upload
+	hash := sha256.Sum256(req.Content)
+	message := SponsorAuthMessage(metaId, req.Operation, req.Path, req.ContentType, hex.EncodeToString(hash[:]), sreq.Timestamp)
+	if err := common.VerifyMessageSignature(netParams(), req.Address, message, sreq.Signature); err != nil {
+		return fmt.Errorf("%w: %v", ErrSponsorUnauthorized, err)
+	}
+	return nil
+}
+
+// checkSponsorPolicy check the allowlist and the allowed content types
+func checkSponsorPolicy(metaId string, req *UploadRequest) error {
+	cfg := conf.Cfg.Uploader.Sponsor
+	if len(cfg.Allowlist) > 0 {
+		allowed := false
+		for _, entry := range cfg.Allowlist {
+			if entry == req.Address || strings.EqualFold(entry, metaId) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: address is not on the allowlist", ErrSponsorNotEligible)
+		}
+	}
+	if len(cfg.AllowedContentTypes) > 0 {
+		contentType := strings.ToLower(req.ContentType)
+		for _, prefix := range cfg.AllowedContentTypes {
+			if strings.HasPrefix(contentType, strings.ToLower(prefix)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: content type %s is not sponsored", ErrSponsorNotEligible, req.ContentType)
+	}
+	return nil
+}
+
+// toSponsoredUploadRecord convert a sponsored upload to its report record
+func toSponsoredUploadRecord(upload *model.SponsoredUpload) *SponsoredUploadRecord {
+	return &SponsoredUploadRecord{
+		Id:          upload.ID,
+		FileId:      upload.FileId,
+		MetaId:      upload.MetaId,
+		Address:     upload.Address,
+		Day:         upload.Day,
+		ContentType: upload.ContentType,
+		FileSize:    upload.FileSize,
+		TxCount:     upload.TxCount,
+		Fee:         upload.Fee,
+		TxId:        upload.TxId,
+		PinId:       upload.PinId,
+		Status:      string(upload.Status),
+		Message:     upload.Message,
+		CreatedAt:   upload.CreatedAt.Unix(),
+	}
+}
+
+// truncateMessage limit a message to the size of its column
+func truncateMessage(message string, max int) string {
+	if len(message) <= max {
+		return message
+	}
+	return message[:max]
+}
@@ -0,0 +1,356 @@
+package upload_service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"meta-media-service/conf"
+	"meta-media-service/model"
+	"meta-media-service/model/dao"
+	"meta-media-service/storage"
+)
+
+// tus protocol support
+const (
+	TusVersion    = "1.0.0"
+	TusExtensions = "creation,expiration,termination"
+)
+
+const (
+	tusPartSize     = 4 * 1024 * 1024 // Max bytes per staged part, a dropped PATCH keeps all completed parts
+	tusCleanupBatch = 100             // Expired uploads removed per cleanup query
+)
+
+var (
+	ErrUploadNotFound   = errors.New("upload not found")
+	ErrUploadExpired    = errors.New("upload expired")
+	ErrUploadIncomplete = errors.New("upload is not complete")
+	ErrUploadTooLarge   = errors.New("upload exceeds max file size")
+	ErrOffsetMismatch   = errors.New("upload offset mismatch")
+	ErrUploadLocked     = errors.New("upload is being written by another request")
+	ErrInvalidMetadata  = errors.New("invalid upload metadata")
+)
+
+// TusService resumable uploads (tus protocol)
+// Bytes are staged in storage with the offsets of their parts. A completed upload can be passed to
+// pre-upload or direct-upload by its ID instead of re-sending the content.
+type TusService struct {
+	tusUploadDAO *dao.TusUploadDAO
+	storage      storage.Storage
+	writing      sync.Map // Upload IDs with a write or termination in progress
+}
+
+// NewTusService create resumable upload service instance
+func NewTusService(storage storage.Storage) *TusService {
+	return &TusService{
+		tusUploadDAO: dao.NewTusUploadDAO(),
+		storage:      storage,
+	}
+}
+
+// Create create a resumable upload
+// length: total size in bytes
+// metadata: raw Upload-Metadata header (may be empty)
+func (s *TusService) Create(length int64, metadata string) (*model.TusUpload, error) {
+	if length < 0 {
+		return nil, fmt.Errorf("invalid upload length: %d", length)
+	}
+	if length > conf.Cfg.Uploader.MaxFileSize {
+		return nil, ErrUploadTooLarge
+	}
+	values, err := ParseTusMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadId, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &model.TusUpload{
+		UploadId:     uploadId,
+		UploadLength: length,
+		Parts:        "[]",
+		Metadata:     metadata,
+		FileName:     firstValue(values, "filename", "name"),
+		ContentType:  firstValue(values, "filetype", "type", "contentType"),
+		ExpiresAt:    s.expiresAt(),
+	}
+	if err := s.tusUploadDAO.Create(upload); err != nil {
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+
+	log.Printf("Resumable upload created: uploadId=%s, length=%d", uploadId, length)
+	return upload, nil
+}
+
+// Get get a resumable upload that has not expired
+func (s *TusService) Get(uploadId string) (*model.TusUpload, error) {
+	upload, err := s.tusUploadDAO.GetByUploadID(uploadId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, fmt.Errorf("failed to get upload: %w", err)
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return nil, ErrUploadExpired
+	}
+	return upload, nil
+}
+
+// Write append the request body to an upload at offset
+// The body is staged in parts of at most tusPartSize, so an interrupted request keeps every part
+// received in full. The upload is returned with its new offset even if reading the body failed.
+func (s *TusService) Write(uploadId string, offset int64, body io.Reader) (*model.TusUpload, error) {
+	if _, busy := s.writing.LoadOrStore(uploadId, true); busy {
+		return nil, ErrUploadLocked
+	}
+	defer s.writing.Delete(uploadId)
+
+	upload, err := s.Get(uploadId)
+	if err != nil {
+		return nil, err
+	}
+	if offset != upload.UploadOffset {
+		return upload, ErrOffsetMismatch
+	}
+	parts, err := decodeParts(upload.Parts)
+	if err != nil {
+		return nil, err
+	}
+
+	for upload.UploadOffset < upload.UploadLength {
+		size := upload.UploadLength - upload.UploadOffset
+		if size > tusPartSize {
+			size = tusPartSize
+		}
+		part := make([]byte, size)
+		n, readErr := io.ReadFull(body, part)
+
+		if n > 0 {
+			start := upload.UploadOffset
+			if err := s.storage.Save(tusPartKey(uploadId, start), part[:n]); err != nil {
+				return upload, fmt.Errorf("failed to stage upload part: %w", err)
+			}
+
+			parts = append(parts, start)
+			encoded, _ := json.Marshal(parts)
+			upload.Parts = string(encoded)
+			upload.UploadOffset += int64(n)
+			upload.ExpiresAt = s.expiresAt()
+
+			updated, err := s.tusUploadDAO.UpdateOffset(upload, start)
+			if err != nil {
+				return upload, fmt.Errorf("failed to update upload offset: %w", err)
+			}
+			if !updated {
+				return upload, ErrOffsetMismatch
+			}
+		}
+
+		if readErr != nil {
+			if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+				// Request body ended, early if the client stopped or the connection dropped
+				break
+			}
+			log.Printf("Resumable upload interrupted: uploadId=%s, offset=%d, err=%v", uploadId, upload.UploadOffset, readErr)
+			return upload, fmt.Errorf("failed to read upload body: %w", readErr)
+		}
+	}
+
+	if upload.Complete() {
+		log.Printf("Resumable upload complete: uploadId=%s, length=%d", uploadId, upload.UploadLength)
+	}
+	return upload, nil
+}
+
+// Terminate delete an upload and its staged bytes
+func (s *TusService) Terminate(uploadId string) error {
+	if _, busy := s.writing.LoadOrStore(uploadId, true); busy {
+		return ErrUploadLocked
+	}
+	defer s.writing.Delete(uploadId)
+
+	upload, err := s.Get(uploadId)
+	if err != nil {
+		return err
+	}
+	return s.remove(upload)
+}
+
+// Content read the content of a completed upload
+func (s *TusService) Content(uploadId string) ([]byte, *model.TusUpload, error) {
+	upload, err := s.Get(uploadId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !upload.Complete() {
+		return nil, nil, fmt.Errorf("%w: %d of %d bytes received", ErrUploadIncomplete, upload.UploadOffset, upload.UploadLength)
+	}
+	parts, err := decodeParts(upload.Parts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content := make([]byte, 0, upload.UploadLength)
+	for _, start := range parts {
+		if start != int64(len(content)) {
+			return nil, nil, fmt.Errorf("upload part at offset %d does not follow offset %d", start, len(content))
+		}
+		data, err := s.storage.Get(tusPartKey(uploadId, start))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read upload part at offset %d: %w", start, err)
+		}
+		content = append(content, data...)
+	}
+	if int64(len(content)) != upload.UploadLength {
+		return nil, nil, fmt.Errorf("upload content size %d does not match length %d", len(content), upload.UploadLength)
+	}
+
+	return content, upload, nil
+}
+
+// Start remove expired uploads periodically (blocking)
+func (s *TusService) Start(interval time.Duration) {
+	log.Printf("Resumable upload cleanup started (interval: %s, expiration: %dh)", interval, conf.Cfg.Uploader.StagingExpiration)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		removed, err := s.CleanupExpired()
+		if err != nil {
+			log.Printf("Resumable upload cleanup failed: %v", err)
+			continue
+		}
+		if removed > 0 {
+			log.Printf("Resumable upload cleanup finished: removed=%d", removed)
+		}
+	}
+}
+
+// CleanupExpired delete expired uploads and their staged bytes
+// Returns: number of uploads removed
+func (s *TusService) CleanupExpired() (int, error) {
+	removed := 0
+	for {
+		uploads, err := s.tusUploadDAO.ListExpired(time.Now(), tusCleanupBatch)
+		if err != nil {
+			return removed, fmt.Errorf("failed to list expired uploads: %w", err)
+		}
+		for _, upload := range uploads {
+			if err := s.remove(upload); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if len(uploads) < tusCleanupBatch {
+			return removed, nil
+		}
+	}
+}
+
+// remove delete the staged parts and the record of an upload
+func (s *TusService) remove(upload *model.TusUpload) error {
+	parts, err := decodeParts(upload.Parts)
+	if err != nil {
+		log.Printf("Failed to decode parts of upload %s, staged bytes are left in storage: %v", upload.UploadId, err)
+	}
+	for _, start := range parts {
+		if err := s.storage.Delete(tusPartKey(upload.UploadId, start)); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Failed to delete upload part %s at offset %d: %v", upload.UploadId, start, err)
+		}
+	}
+
+	if err := s.tusUploadDAO.Delete(upload.ID); err != nil {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+	log.Printf("Resumable upload removed: uploadId=%s", upload.UploadId)
+	return nil
+}
+
+// expiresAt expiry of an upload written now
+func (s *TusService) expiresAt() time.Time {
+	return time.Now().Add(time.Duration(conf.Cfg.Uploader.StagingExpiration) * time.Hour)
+}
+
+// ParseTusMetadata parse an Upload-Metadata header
+// Format: comma separated "key base64(value)" pairs, the value may be omitted.
+func ParseTusMetadata(header string) (map[string]string, error) {
+	values := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return values, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMetadata, pair)
+		}
+		key := fields[0]
+		if _, exists := values[key]; exists {
+			return nil, fmt.Errorf("%w: duplicate key %q", ErrInvalidMetadata, key)
+		}
+
+		value := ""
+		if len(fields) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: value of %q is not base64", ErrInvalidMetadata, key)
+			}
+			value = string(decoded)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// firstValue get the first non-empty metadata value among keys
+func firstValue(values map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := values[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// decodeParts decode the part offsets of an upload
+func decodeParts(parts string) ([]int64, error) {
+	var offsets []int64
+	if parts == "" {
+		return offsets, nil
+	}
+	if err := json.Unmarshal([]byte(parts), &offsets); err != nil {
+		return nil, fmt.Errorf("failed to decode upload parts: %w", err)
+	}
+	return offsets, nil
+}
+
+// tusPartKey storage key of a staged upload part
+// uploads/tus/{upload_id}/{start offset}
+func tusPartKey(uploadId string, start int64) string {
+	return fmt.Sprintf("uploads/tus/%s/%020d", uploadId, start)
+}
+
+// newUploadID generate a random upload ID
+func newUploadID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate upload ID: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
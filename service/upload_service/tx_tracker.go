@@ -0,0 +1,537 @@
+package upload_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"meta-media-service/conf"
+	"meta-media-service/database"
+	"meta-media-service/model"
+	"meta-media-service/model/dao"
+	"meta-media-service/node"
+)
+
+// trackerBatch transactions loaded per query while tracking
+const trackerBatch = 100
+
+// trackedTxStates states the tracker moves forward, the others are final
+var trackedTxStates = []model.TxState{model.TxStateBroadcast, model.TxStateMempool}
+
+// TxTracker follow broadcast upload transactions until they are mined
+// The node is polled for each transaction. Transactions it no longer knows are looked up in the recent
+// blocks, then broadcast again, and the state of the file (or chunk) a transaction belongs to follows it.
+// Transactions confirmed in the recent blocks are polled again, so a reorg moves them back.
+type TxTracker struct {
+	fileTxDAO *dao.FileTxDAO
+}
+
+// NewTxTracker create transaction tracker instance
+func NewTxTracker() *TxTracker {
+	return &TxTracker{
+		fileTxDAO: dao.NewFileTxDAO(),
+	}
+}
+
+// Start track transactions periodically (blocking)
+func (t *TxTracker) Start(interval time.Duration) {
+	log.Printf("Transaction tracker started (interval: %s, max rebroadcasts: %d, confirm depth: %d)", interval,
+		conf.Cfg.Uploader.TxTracker.MaxRebroadcasts, conf.Cfg.Uploader.TxTracker.ConfirmDepth)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		changed, err := t.Track()
+		if err != nil {
+			log.Printf("Transaction tracking failed: %v", err)
+			continue
+		}
+		if changed > 0 {
+			log.Printf("Transaction tracking finished: changed=%d", changed)
+		}
+	}
+}
+
+// Track query the node for every broadcast or in-mempool transaction and advance its state
+// Transactions confirmed in the last ConfirmDepth blocks are queried again to follow reorgs.
+// Returns: number of transactions whose state changed
+func (t *TxTracker) Track() (int, error) {
+	pass := newTrackPass()
+	changed := 0
+	for _, chain := range trackedChains() {
+		tip, err := pass.tip(rpcChain(chain))
+		if err != nil {
+			log.Printf("Failed to get block height, reorgs not checked: chain=%s, err=%v", chain, err)
+			continue
+		}
+		minHeight := tip - conf.Cfg.Uploader.TxTracker.ConfirmDepth + 1
+		n, err := t.trackAll(pass, func(afterID int64) ([]*model.FileTx, error) {
+			return t.fileTxDAO.ListConfirmedSince(chain, minHeight, afterID, trackerBatch)
+		})
+		changed += n
+		if err != nil {
+			return changed, err
+		}
+	}
+
+	n, err := t.trackAll(pass, func(afterID int64) ([]*model.FileTx, error) {
+		return t.fileTxDAO.ListByStates(trackedTxStates, afterID, trackerBatch)
+	})
+	return changed + n, err
+}
+
+// trackAll track the transactions a listing returns, page by page
+func (t *TxTracker) trackAll(pass *trackPass, list func(afterID int64) ([]*model.FileTx, error)) (int, error) {
+	changed := 0
+	afterID := int64(0)
+	for {
+		txs, err := list(afterID)
+		if err != nil {
+			return changed, fmt.Errorf("failed to list transactions: %w", err)
+		}
+		for _, ftx := range txs {
+			afterID = ftx.ID
+			ok, err := t.track(ftx, pass)
+			if err != nil {
+				log.Printf("Failed to track transaction: fileId=%s, txId=%s, err=%v", ftx.FileId, ftx.TxId, err)
+				continue
+			}
+			if ok {
+				changed++
+			}
+		}
+		if len(txs) < trackerBatch {
+			return changed, nil
+		}
+	}
+}
+
+// track move one transaction to the state the node reports
+// Returns whether the state changed.
+func (t *TxTracker) track(ftx *model.FileTx, pass *trackPass) (bool, error) {
+	chain := rpcChain(ftx.Chain)
+	detail, err := node.GetTxDetail(chain, ftx.TxId)
+	if err != nil {
+		if !isTxNotFound(err) {
+			return false, fmt.Errorf("failed to get transaction: %w", err)
+		}
+		// Nodes without txindex return mempool transactions only
+		height, found, err := pass.minedHeight(chain, ftx.TxId)
+		if err != nil {
+			return false, fmt.Errorf("failed to search recent blocks: %w", err)
+		}
+		if found {
+			return t.confirm(ftx, height, "")
+		}
+		if ftx.State != model.TxStateConfirmed {
+			return t.rebroadcast(ftx)
+		}
+		// Its block was orphaned and the node dropped it
+		ftx.BlockHeight = 0
+		if err := setTxState(database.UploaderDB, ftx, model.TxStateBroadcast, "block orphaned, not in the chain any more"); err != nil {
+			return false, err
+		}
+		_, err = t.rebroadcast(ftx)
+		return true, err
+	}
+
+	if detail.Confirmations == 0 {
+		switch ftx.State {
+		case model.TxStateMempool:
+			return false, nil
+		case model.TxStateConfirmed:
+			ftx.BlockHeight = 0
+			return true, setTxState(database.UploaderDB, ftx, model.TxStateMempool, "block orphaned, back in the mempool")
+		}
+		return true, setTxState(database.UploaderDB, ftx, model.TxStateMempool, "")
+	}
+
+	// Nodes without blockheight in getrawtransaction report confirmations only
+	height := int64(detail.BlockHeight)
+	if height == 0 {
+		if detail.Confirmations <= uint64(conf.Cfg.Uploader.TxTracker.ConfirmDepth) {
+			// Recent blocks are searched, so a tip that moved during the pass does not shift the height
+			mined, found, err := pass.minedHeight(chain, ftx.TxId)
+			if err != nil {
+				return false, fmt.Errorf("failed to search recent blocks: %w", err)
+			}
+			if found {
+				return t.confirm(ftx, mined, "")
+			}
+		}
+		tip, err := pass.tip(chain)
+		if err != nil {
+			return false, fmt.Errorf("failed to get block height: %w", err)
+		}
+		height = tip - int64(detail.Confirmations) + 1
+	}
+	return t.confirm(ftx, height, "")
+}
+
+// confirm record a transaction mined at a block height, 0 if the height is not known
+// A confirmed transaction found at another height was moved by a reorg.
+func (t *TxTracker) confirm(ftx *model.FileTx, height int64, message string) (bool, error) {
+	if ftx.State == model.TxStateConfirmed {
+		if ftx.BlockHeight == height {
+			return false, nil
+		}
+		message = fmt.Sprintf("reorg, moved from block %d", ftx.BlockHeight)
+	}
+	ftx.BlockHeight = height
+	return true, setTxState(database.UploaderDB, ftx, model.TxStateConfirmed, message)
+}
+
+// rebroadcast broadcast a transaction the node dropped from its mempool
+// A transaction the node reports in the block chain was mined before the searched blocks. One whose
+// inputs were spent meanwhile is replaced, one still not accepted after the configured number of
+// rebroadcasts has failed.
+func (t *TxTracker) rebroadcast(ftx *model.FileTx) (bool, error) {
+	if ftx.Rebroadcasts >= conf.Cfg.Uploader.TxTracker.MaxRebroadcasts {
+		message := fmt.Sprintf("dropped by the node, not accepted after %d rebroadcasts", ftx.Rebroadcasts)
+		return true, setTxState(database.UploaderDB, ftx, model.TxStateFailed, message)
+	}
+
+	_, err := broadcastRaw(ftx.Chain, ftx.TxRaw)
+	if err != nil && isInBlockChain(err) {
+		return t.confirm(ftx, 0, "in the block chain, block older than the searched ones")
+	}
+	ftx.Rebroadcasts++
+	if err != nil && !isAlreadyBroadcast(err) {
+		if isInputConflict(err) {
+			return true, setTxState(database.UploaderDB, ftx, model.TxStateReplaced, err.Error())
+		}
+		// Still broadcast, the next pass tries again
+		ftx.Message = truncateMessage(err.Error(), 500)
+		if err := database.UploaderDB.Save(ftx).Error; err != nil {
+			return false, fmt.Errorf("failed to update transaction: %w", err)
+		}
+		return false, nil
+	}
+
+	log.Printf("Transaction rebroadcast: fileId=%s, txId=%s, rebroadcasts=%d", ftx.FileId, ftx.TxId, ftx.Rebroadcasts)
+	return true, setTxState(database.UploaderDB, ftx, model.TxStateBroadcast, fmt.Sprintf("rebroadcast %d", ftx.Rebroadcasts))
+}
+
+// trackPass node state queried once per tracking pass
+type trackPass struct {
+	tips  map[string]int64            // Chain tip per node
+	mined map[string]map[string]int64 // Transactions of the last ConfirmDepth blocks per node, txid -> height
+}
+
+func newTrackPass() *trackPass {
+	return &trackPass{
+		tips:  make(map[string]int64),
+		mined: make(map[string]map[string]int64),
+	}
+}
+
+// tip height of the chain tip of a node
+func (p *trackPass) tip(chain string) (int64, error) {
+	if tip, ok := p.tips[chain]; ok {
+		return tip, nil
+	}
+	current, err := node.CurrentBlockHeight(chain)
+	if err != nil {
+		return 0, err
+	}
+	p.tips[chain] = int64(current)
+	return int64(current), nil
+}
+
+// minedHeight height of the block that holds a transaction, among the last ConfirmDepth blocks
+func (p *trackPass) minedHeight(chain, txId string) (int64, bool, error) {
+	mined, ok := p.mined[chain]
+	if !ok {
+		tip, err := p.tip(chain)
+		if err != nil {
+			return 0, false, err
+		}
+		mined = make(map[string]int64)
+		for height := tip; height > tip-conf.Cfg.Uploader.TxTracker.ConfirmDepth && height >= 0; height-- {
+			hash, err := node.GetBlockhash(chain, uint64(height))
+			if err != nil {
+				return 0, false, err
+			}
+			txIds, err := node.GetBlockTxIds(chain, hash)
+			if err != nil {
+				return 0, false, err
+			}
+			for _, id := range txIds {
+				mined[id] = height
+			}
+		}
+		p.mined[chain] = mined
+	}
+	height, ok := mined[txId]
+	return height, ok, nil
+}
+
+// trackedChains chains whose transactions are re-checked for reorgs
+func trackedChains() []string {
+	chains := []string{model.ChainMvc}
+	if conf.Cfg.Uploader.Btc.Enabled {
+		chains = append(chains, model.ChainBtc)
+	}
+	return chains
+}
+
+// setTxState record a state of a tracked transaction and carry it to the file or chunk it belongs to
+// A file is successful once its transaction is confirmed and pending again if a reorg moves it back.
+// A file whose transaction failed or was replaced is marked failed, so it can be uploaded again.
+func setTxState(db *gorm.DB, ftx *model.FileTx, state model.TxState, message string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := recordTx(tx, ftx, state, message); err != nil {
+			return err
+		}
+
+		final := state == model.TxStateFailed || state == model.TxStateReplaced
+		switch ftx.Role {
+		case model.TxRoleFile:
+			// Block height is cleared when a reorg moves the transaction back
+			updates := map[string]interface{}{"tx_state": state, "block_height": ftx.BlockHeight}
+			switch {
+			case final:
+				updates["status"] = model.StatusFailed
+			case state == model.TxStateConfirmed:
+				updates["status"] = model.StatusSuccess
+			default:
+				updates["status"] = model.StatusPending
+			}
+			err := tx.Model(&model.File{}).Where("file_id = ? AND tx_id = ?", ftx.FileId, ftx.TxId).Updates(updates).Error
+			if err != nil {
+				return fmt.Errorf("failed to update file state: %w", err)
+			}
+		case model.TxRoleChunk:
+			updates := map[string]interface{}{"block_height": ftx.BlockHeight}
+			if final {
+				updates["status"] = model.StatusFailed
+			}
+			err := tx.Model(&model.FileChunk{}).Where("file_id = ? AND tx_id = ?", ftx.FileId, ftx.TxId).Updates(updates).Error
+			if err != nil {
+				return fmt.Errorf("failed to update chunk state: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// broadcastFileTx broadcast a verified upload transaction, then record it for the tracker
+// The broadcast runs outside any database transaction, so a failed write cannot roll back the record of a
+// transaction the node accepted. The record and the update of the file or chunk it belongs to (apply, may be
+// nil) are written together afterwards. A transaction the node already has is recorded the same way, so
+// committing again after a failed write records it.
+func broadcastFileTx(ftx *model.FileTx, apply func(tx *gorm.DB) error) error {
+	logTxEvent(database.UploaderDB, ftx.FileId, ftx.TxId, model.TxStateSigned, 0, "")
+	if _, err := broadcastRaw(ftx.Chain, ftx.TxRaw); err != nil && !isAlreadyBroadcast(err) && !isInBlockChain(err) {
+		logTxEvent(database.UploaderDB, ftx.FileId, ftx.TxId, model.TxStateFailed, 0, err.Error())
+		return err
+	}
+	err := database.UploaderDB.Transaction(func(tx *gorm.DB) error {
+		if err := recordTx(tx, ftx, model.TxStateBroadcast, ""); err != nil {
+			return err
+		}
+		if apply != nil {
+			return apply(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Transaction broadcast but not recorded: fileId=%s, txId=%s, err=%v", ftx.FileId, ftx.TxId, err)
+		return fmt.Errorf("transaction %s broadcast but not recorded, commit again to record it: %w", ftx.TxId, err)
+	}
+	return nil
+}
+
+// recordTx save a transaction in a new state and append the state to the history of its file
+// A transaction has one record. A commit transaction shared by the files of a batch belongs to the file that
+// broadcast it first, the others find it by their CommitTxId. The history entry goes to the calling file.
+func recordTx(db *gorm.DB, ftx *model.FileTx, state model.TxState, message string) error {
+	fileId := ftx.FileId
+	if ftx.ID == 0 {
+		// A transaction committed again after it failed, or by another file of its batch, keeps its record
+		var existing model.FileTx
+		if err := db.Where("tx_id = ?", ftx.TxId).Limit(1).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to find transaction record: %w", err)
+		}
+		if existing.ID != 0 {
+			ftx.ID = existing.ID
+			ftx.CreatedAt = existing.CreatedAt
+			ftx.FileId = existing.FileId
+			ftx.Role = existing.Role
+			ftx.ChunkIndex = existing.ChunkIndex
+		}
+	}
+	ftx.State = state
+	if message != "" {
+		ftx.Message = truncateMessage(message, 500)
+	}
+	if err := db.Save(ftx).Error; err != nil {
+		return fmt.Errorf("failed to save transaction record: %w", err)
+	}
+	err := db.Create(&model.FileTxEvent{
+		FileId:      fileId,
+		TxId:        ftx.TxId,
+		State:       state,
+		BlockHeight: ftx.BlockHeight,
+		Message:     truncateMessage(message, 500),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save transaction history: %w", err)
+	}
+	return nil
+}
+
+// logTxEvent append a state to the history of a file, failures are only logged
+func logTxEvent(db *gorm.DB, fileId, txId string, state model.TxState, blockHeight int64, message string) {
+	err := db.Create(&model.FileTxEvent{
+		FileId:      fileId,
+		TxId:        txId,
+		State:       state,
+		BlockHeight: blockHeight,
+		Message:     truncateMessage(message, 500),
+	}).Error
+	if err != nil {
+		log.Printf("Failed to save transaction history: fileId=%s, txId=%s, state=%s, err=%v", fileId, txId, state, err)
+	}
+}
+
+// broadcastRaw broadcast a signed transaction to the node of its chain
+func broadcastRaw(chain, rawTx string) (string, error) {
+	if chain == model.ChainBtc {
+		return node.BroadcastRawTx(model.ChainBtc, rawTx)
+	}
+	return node.BroadcastTx(conf.Cfg.Net, rawTx)
+}
+
+// rpcChain node transactions of a chain are queried on
+func rpcChain(chain string) string {
+	if chain == model.ChainBtc {
+		return model.ChainBtc
+	}
+	return conf.Cfg.Net
+}
+
+// isTxNotFound whether a node query failed because the node does not know the transaction
+func isTxNotFound(err error) bool {
+	return strings.HasPrefix(err.Error(), "[-5]")
+}
+
+// isInBlockChain whether a broadcast failed because the transaction is already mined
+func isInBlockChain(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "already in block chain")
+}
+
+// isInputConflict whether a broadcast failed because an input was spent by another transaction
+func isInputConflict(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "txn-mempool-conflict") || strings.Contains(msg, "missing inputs") ||
+		strings.Contains(msg, "missingorspent") || strings.Contains(msg, "inputs-spent")
+}
+
+// TxRecord transaction of an upload and its broadcast state
+type TxRecord struct {
+	TxId         string `json:"txId"`         // Transaction ID
+	Chain        string `json:"chain"`        // mvc/btc
+	Role         string `json:"role"`         // file/chunk/merge/commit
+	ChunkIndex   int64  `json:"chunkIndex"`   // Chunk index, -1 unless the role is chunk
+	State        string `json:"state"`        // broadcast/in_mempool/confirmed/failed/replaced
+	BlockHeight  int64  `json:"blockHeight"`  // Block height, once confirmed
+	Rebroadcasts int64  `json:"rebroadcasts"` // Times the tracker broadcast it again
+	Message      string `json:"message"`      // Last broadcast error
+	UpdatedAt    int64  `json:"updatedAt"`    // Time of the last state change (Unix seconds)
+}
+
+// TxEvent state change in the history of an upload
+type TxEvent struct {
+	TxId        string `json:"txId"`        // Transaction ID, empty while it is not signed
+	State       string `json:"state"`       // built/signed/broadcast/in_mempool/confirmed/failed/replaced
+	BlockHeight int64  `json:"blockHeight"` // Block height (confirmed)
+	Message     string `json:"message"`     // Detail, e.g. the broadcast error
+	CreatedAt   int64  `json:"createdAt"`   // Time of the change (Unix seconds)
+}
+
+// TxHistoryResponse broadcast history of an upload
+type TxHistoryResponse struct {
+	FileId      string      `json:"fileId"`      // File ID
+	Status      string      `json:"status"`      // pending/success/failed
+	TxState     string      `json:"txState"`     // State of the file (or index, reveal) transaction
+	BlockHeight int64       `json:"blockHeight"` // Block height of the file transaction, once confirmed
+	Txs         []*TxRecord `json:"txs"`         // Transactions in broadcast order
+	Events      []*TxEvent  `json:"events"`      // State changes, oldest first
+}
+
+// GetTxHistory get the transactions of an upload and the history of their states
+func (s *UploadService) GetTxHistory(fileId string) (*TxHistoryResponse, error) {
+	file, err := s.fileDAO.GetByFileID(fileId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	txs, err := s.fileTxDAO.ListByFileID(fileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	if file.CommitTxId != "" && !hasTx(txs, file.CommitTxId) {
+		// A commit transaction shared by a batch is recorded under the file that broadcast it first
+		commit, err := s.fileTxDAO.GetByTxID(file.CommitTxId)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get commit transaction: %w", err)
+		}
+		if commit != nil {
+			txs = append([]*model.FileTx{commit}, txs...)
+		}
+	}
+	events, err := s.fileTxDAO.ListEventsByFileID(fileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction history: %w", err)
+	}
+
+	resp := &TxHistoryResponse{
+		FileId:      file.FileId,
+		Status:      string(file.Status),
+		TxState:     string(file.TxState),
+		BlockHeight: file.BlockHeight,
+		Txs:         make([]*TxRecord, 0, len(txs)),
+		Events:      make([]*TxEvent, 0, len(events)),
+	}
+	for _, ftx := range txs {
+		resp.Txs = append(resp.Txs, &TxRecord{
+			TxId:         ftx.TxId,
+			Chain:        ftx.Chain,
+			Role:         ftx.Role,
+			ChunkIndex:   ftx.ChunkIndex,
+			State:        string(ftx.State),
+			BlockHeight:  ftx.BlockHeight,
+			Rebroadcasts: ftx.Rebroadcasts,
+			Message:      ftx.Message,
+			UpdatedAt:    ftx.UpdatedAt.Unix(),
+		})
+	}
+	for _, event := range events {
+		resp.Events = append(resp.Events, &TxEvent{
+			TxId:        event.TxId,
+			State:       string(event.State),
+			BlockHeight: event.BlockHeight,
+			Message:     event.Message,
+			CreatedAt:   event.CreatedAt.Unix(),
+		})
+	}
+	return resp, nil
+}
+
+// hasTx whether a transaction is among the records
+func hasTx(txs []*model.FileTx, txId string) bool {
+	for _, ftx := range txs {
+		if ftx.TxId == txId {
+			return true
+		}
+	}
+	return false
+}
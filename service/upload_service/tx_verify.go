@@ -0,0 +1,142 @@
+package upload_service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	txscript2 "github.com/bitcoinsv/bsvd/txscript"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+
+	"meta-media-service/common"
+	"meta-media-service/conf"
+	"meta-media-service/indexer"
+	"meta-media-service/node"
+)
+
+var ErrInvalidSignedTx = errors.New("invalid signed transaction")
+
+// expectedPin MetaID output a submitted transaction must carry
+type expectedPin struct {
+	path        string
+	contentType string
+	contentHash string // Content SHA256 (hex)
+}
+
+// verifiedTx submitted transaction that passed verification
+type verifiedTx struct {
+	txId  string
+	pinId string // Pin ID as the indexer derives it (owner output)
+}
+
+// verifySignedTx decode a signed MVC transaction and check it before broadcast
+// The transaction must carry the expected MetaID output, and pass verifyTxInputs. unconfirmed are
+// transactions broadcast just before it whose outputs it may spend (e.g. a merge transaction).
+func verifySignedTx(signedRawTx string, expected *expectedPin, unconfirmed ...*wire2.MsgTx) (*verifiedTx, error) {
+	tx, err := decodeMvcTx(signedRawTx)
+	if err != nil {
+		return nil, err
+	}
+	txId := common.GetMvcTxhashFromRaw(signedRawTx)
+
+	parsed, err := indexer.NewMetaIDParser("").ParseAllPINs(tx, indexer.ChainTypeMVC)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignedTx, err)
+	}
+	pinId := ""
+	if parsed != nil {
+		for _, pin := range parsed.MetaIDData {
+			hash := sha256.Sum256(pin.Content)
+			if pin.OriginalPath == expected.path &&
+				strings.EqualFold(pin.ContentType, strings.TrimSpace(expected.contentType)) &&
+				hex.EncodeToString(hash[:]) == expected.contentHash {
+				pinId = pin.PinID
+				break
+			}
+		}
+	}
+	if pinId == "" {
+		return nil, fmt.Errorf("%w: no MetaID output with path %s, content type %s and content sha256 %s",
+			ErrInvalidSignedTx, expected.path, expected.contentType, expected.contentHash)
+	}
+
+	if err := verifyTxInputs(tx, unconfirmed...); err != nil {
+		return nil, err
+	}
+	return &verifiedTx{txId: txId, pinId: pinId}, nil
+}
+
+// verifyTxInputs run the scripts of every input against the output it spends and check the fee
+// Spent outputs are taken from unconfirmed, then from the node. The fee must meet the configured fee rate,
+// the lowest rate pre-upload builds with (see mvcFeeRate).
+func verifyTxInputs(tx *wire2.MsgTx, unconfirmed ...*wire2.MsgTx) error {
+	if len(tx.TxIn) == 0 {
+		return fmt.Errorf("%w: transaction has no inputs", ErrInvalidSignedTx)
+	}
+	prevTxs := make(map[string]*wire2.MsgTx, len(unconfirmed))
+	for _, utx := range unconfirmed {
+		raw, err := indexer.TxToHex(utx)
+		if err != nil {
+			return fmt.Errorf("failed to serialize transaction: %w", err)
+		}
+		prevTxs[common.GetMvcTxhashFromRaw(raw)] = utx
+	}
+
+	sigHashes := txscript2.NewTxSigHashes(tx)
+	inAmount := int64(0)
+	for i, in := range tx.TxIn {
+		prevTxId := in.PreviousOutPoint.Hash.String()
+		prevTx, ok := prevTxs[prevTxId]
+		if !ok {
+			prevRaw, err := node.GetTxRaw(conf.Cfg.Net, prevTxId)
+			if err != nil {
+				return fmt.Errorf("failed to get transaction %s spent by input %d: %w", prevTxId, i, err)
+			}
+			if prevTx, err = decodeMvcTx(prevRaw); err != nil {
+				return fmt.Errorf("transaction %s spent by input %d: %w", prevTxId, i, err)
+			}
+			prevTxs[prevTxId] = prevTx
+		}
+		if int(in.PreviousOutPoint.Index) >= len(prevTx.TxOut) {
+			return fmt.Errorf("%w: input %d spends missing output %s", ErrInvalidSignedTx, i, in.PreviousOutPoint.String())
+		}
+		prevOut := prevTx.TxOut[in.PreviousOutPoint.Index]
+
+		vm, err := txscript2.NewEngine(prevOut.PkScript, tx, i, txscript2.StandardVerifyFlags, nil, sigHashes, prevOut.Value)
+		if err != nil {
+			return fmt.Errorf("%w: input %d: %v", ErrInvalidSignedTx, i, err)
+		}
+		if err := vm.Execute(); err != nil {
+			return fmt.Errorf("%w: input %d: %v", ErrInvalidSignedTx, i, err)
+		}
+		inAmount += prevOut.Value
+	}
+
+	outAmount := int64(0)
+	for _, out := range tx.TxOut {
+		outAmount += out.Value
+	}
+	fee := inAmount - outAmount
+	minFee := int64(tx.SerializeSize()) * conf.Cfg.Uploader.FeeRate
+	if fee < minFee {
+		return fmt.Errorf("%w: fee %d is below %d (%d bytes at %d satoshis per byte)",
+			ErrInvalidSignedTx, fee, minFee, tx.SerializeSize(), conf.Cfg.Uploader.FeeRate)
+	}
+	return nil
+}
+
+// decodeMvcTx deserialize a raw MVC transaction (hex)
+func decodeMvcTx(rawTx string) (*wire2.MsgTx, error) {
+	txBytes, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode hex: %v", ErrInvalidSignedTx, err)
+	}
+	tx := wire2.NewMsgTx(10)
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, fmt.Errorf("%w: failed to deserialize: %v", ErrInvalidSignedTx, err)
+	}
+	return tx, nil
+}
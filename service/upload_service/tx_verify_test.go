@@ -0,0 +1,178 @@
+package upload_service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+
+	"meta-media-service/common"
+	"meta-media-service/indexer"
+)
+
+// testSignedTx signed transaction spending output 0 of prevTx, with a MetaID output and change
+type testSignedTx struct {
+	prevTx  *wire2.MsgTx
+	tx      *wire2.MsgTx
+	priHex  string
+	script  []byte
+	content []byte
+}
+
+// newTestSignedTx pay a MetaID output of content from a prevAmount output, leaving fee to the miner
+func newTestSignedTx(t *testing.T, prevAmount, fee int64) *testSignedTx {
+	t.Helper()
+	priHex, _, script := newTestKey(t)
+	prevTx := wire2.NewMsgTx(10)
+	prevTx.AddTxIn(wire2.NewTxIn(&wire2.OutPoint{}, nil))
+	prevTx.AddTxOut(wire2.NewTxOut(prevAmount, script))
+
+	content := []byte("verified content")
+	metaIdScript, err := buildMetaIdScript("create", "/file", "text/plain", content)
+	if err != nil {
+		t.Fatalf("buildMetaIdScript() failed, err: %v", err)
+	}
+	prevRaw, err := indexer.TxToHex(prevTx)
+	if err != nil {
+		t.Fatalf("TxToHex() failed, err: %v", err)
+	}
+	// MVC transaction IDs are not the double SHA256 of the serialized transaction
+	prevHash, err := chainhash.NewHashFromStr(common.GetMvcTxhashFromRaw(prevRaw))
+	if err != nil {
+		t.Fatalf("NewHashFromStr() failed, err: %v", err)
+	}
+	tx := wire2.NewMsgTx(10)
+	tx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(prevHash, 0), nil))
+	tx.AddTxOut(wire2.NewTxOut(0, metaIdScript))
+	tx.AddTxOut(wire2.NewTxOut(prevAmount-fee, script))
+	ts := &testSignedTx{prevTx: prevTx, tx: tx, priHex: priHex, script: script, content: content}
+	ts.sign(t)
+	return ts
+}
+
+// sign sign the payer input again
+func (ts *testSignedTx) sign(t *testing.T) {
+	t.Helper()
+	err := common.SignMvcP2PKHInputs(ts.tx, []*common.TxInputUtxo{{
+		TxId:     ts.tx.TxIn[0].PreviousOutPoint.Hash.String(),
+		TxIndex:  0,
+		PkScript: hex.EncodeToString(ts.script),
+		Amount:   uint64(ts.prevTx.TxOut[0].Value),
+		PriHex:   ts.priHex,
+		SignMode: common.SignModeLegacy,
+	}})
+	if err != nil {
+		t.Fatalf("SignMvcP2PKHInputs() failed, err: %v", err)
+	}
+}
+
+func (ts *testSignedTx) raw(t *testing.T) string {
+	t.Helper()
+	raw, err := indexer.TxToHex(ts.tx)
+	if err != nil {
+		t.Fatalf("TxToHex() failed, err: %v", err)
+	}
+	return raw
+}
+
+func (ts *testSignedTx) expected() *expectedPin {
+	hash := sha256.Sum256(ts.content)
+	return &expectedPin{path: "/file", contentType: "text/plain", contentHash: hex.EncodeToString(hash[:])}
+}
+
+func TestVerifySignedTx(t *testing.T) {
+	setTestConfig(t)
+	ts := newTestSignedTx(t, 10000, 1000)
+	raw := ts.raw(t)
+
+	verified, err := verifySignedTx(raw, ts.expected(), ts.prevTx)
+	if err != nil {
+		t.Fatalf("verifySignedTx() failed, err: %v", err)
+	}
+	// The Pin ID is on the owner output, the first output after the OP_RETURN
+	txId := common.GetMvcTxhashFromRaw(raw)
+	if verified.txId != txId || verified.pinId != txId+"i1" {
+		t.Errorf("verified = %+v, want txid %s and Pin ID %si1", verified, txId, txId)
+	}
+
+	// Content type is matched case-insensitively, ignoring surrounding spaces
+	expected := ts.expected()
+	expected.contentType = " Text/Plain "
+	if _, err := verifySignedTx(raw, expected, ts.prevTx); err != nil {
+		t.Errorf("verifySignedTx() with content type %q failed, err: %v", expected.contentType, err)
+	}
+}
+
+func TestVerifySignedTxRejects(t *testing.T) {
+	setTestConfig(t)
+	_, _, otherScript := newTestKey(t)
+
+	tests := []struct {
+		name   string
+		fee    int64
+		modify func(t *testing.T, ts *testSignedTx, expected *expectedPin)
+	}{
+		{"other content", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			expected.contentHash = strings.Repeat("00", 32)
+		}},
+		{"other path", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			expected.path = "/file/other"
+		}},
+		{"other content type", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			expected.contentType = "image/png"
+		}},
+		{"no MetaID output", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			ts.tx.TxOut = ts.tx.TxOut[1:]
+			ts.sign(t)
+		}},
+		{"tampered output value", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			ts.tx.TxOut[1].Value -= 100
+		}},
+		{"tampered output script", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			ts.tx.TxOut[1].PkScript = otherScript
+		}},
+		{"tampered MetaID content", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			ts.content = []byte("verified contenT")
+			ts.tx.TxOut[0].PkScript, _ = buildMetaIdScript("create", "/file", "text/plain", ts.content)
+			hash := sha256.Sum256(ts.content)
+			expected.contentHash = hex.EncodeToString(hash[:])
+		}},
+		{"tampered signature script", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			sigScript := ts.tx.TxIn[0].SignatureScript
+			sigScript[10] ^= 0x01
+		}},
+		{"unsigned input", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			ts.tx.TxIn[0].SignatureScript = nil
+		}},
+		{"signed by another key", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			ts.priHex, _, _ = newTestKey(t)
+			ts.sign(t)
+		}},
+		{"fee below fee rate", 100, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {}},
+		{"outputs above inputs", -1, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {}},
+		{"spends missing output", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			ts.tx.TxIn[0].PreviousOutPoint.Index = 1
+		}},
+		{"no inputs", 1000, func(t *testing.T, ts *testSignedTx, expected *expectedPin) {
+			ts.tx.TxIn = nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestSignedTx(t, 10000, tt.fee)
+			expected := ts.expected()
+			tt.modify(t, ts, expected)
+			if _, err := verifySignedTx(ts.raw(t), expected, ts.prevTx); !errors.Is(err, ErrInvalidSignedTx) {
+				t.Errorf("verifySignedTx() err = %v, want %v", err, ErrInvalidSignedTx)
+			}
+		})
+	}
+
+	if _, err := verifySignedTx("not hex", &expectedPin{}); !errors.Is(err, ErrInvalidSignedTx) {
+		t.Errorf("verifySignedTx() of invalid hex err = %v, want %v", err, ErrInvalidSignedTx)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/md5"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -22,21 +23,26 @@ import (
 	"meta-media-service/indexer"
 	"meta-media-service/model"
 	"meta-media-service/model/dao"
-	"meta-media-service/node"
 	"meta-media-service/storage"
 )
 
 // UploadService upload service
 type UploadService struct {
-	fileDAO *dao.FileDAO
-	storage storage.Storage
+	fileDAO            *dao.FileDAO
+	fileChunkDAO       *dao.FileChunkDAO
+	utxoReservationDAO *dao.UtxoReservationDAO
+	fileTxDAO          *dao.FileTxDAO
+	storage            storage.Storage
 }
 
 // NewUploadService create upload service instance
 func NewUploadService(storage storage.Storage) *UploadService {
 	return &UploadService{
-		fileDAO: dao.NewFileDAO(),
-		storage: storage,
+		fileDAO:            dao.NewFileDAO(),
+		fileChunkDAO:       dao.NewFileChunkDAO(),
+		utxoReservationDAO: dao.NewUtxoReservationDAO(),
+		fileTxDAO:          dao.NewFileTxDAO(),
+		storage:            storage,
 	}
 }
 
@@ -54,6 +60,18 @@ type UploadRequest struct {
 	Outputs       []*common.TxOutput    // Outputs
 	OtherOutputs  []*common.TxOutput    // Other outputs
 	FeeRate       int64                 // Fee rate
+	PayerAddress  string                // Payer address, if set the uploader selects its UTXOs as inputs
+	Chain         string                // mvc (default)/btc
+	BtcInputs     []*common.BtcUtxo     // Payer UTXOs of the BTC commit transaction (P2WPKH or P2TR)
+}
+
+// templateChangeAddress change address put in built transactions
+// Funded transactions get their change output from coin selection instead.
+func (req *UploadRequest) templateChangeAddress() string {
+	if req.PayerAddress != "" {
+		return ""
+	}
+	return req.ChangeAddress
 }
 
 // DirectUploadRequest direct upload request (one-step upload with PreTxHex)
@@ -68,7 +86,7 @@ type DirectUploadRequest struct {
 	MergeTxHex       string // Merge transaction hex (signed, with inputs and outputs)
 	PreTxHex         string // Pre-transaction hex (signed, with inputs and outputs)
 	ChangeAddress    string // Change address (optional, defaults to Address)
-	FeeRate          int64  // Fee rate (satoshis per byte, optional, defaults to config)
+	FeeRate          int64  // Fee rate (satoshis per byte, optional, at least and by default the configured rate)
 	TotalInputAmount int64  // Total input amount in satoshis (optional, for change calculation)
 }
 
@@ -80,21 +98,46 @@ type PreUploadResponse struct {
 	TxId      string `json:"txId"`      // Transaction ID
 	PinId     string `json:"pinId"`     // Pin ID
 	PreTxRaw  string `json:"preTxRaw"`  // Pre-transaction raw data
-	Status    string `json:"status"`    // Status
+	Status    string `json:"status"`    // pending/success (transaction confirmed)/failed
+	TxState   string `json:"txState"`   // State of the file transaction, broadcast once the node accepted it
 	Message   string `json:"message"`   // Message (e.g., exists, success, etc.)
-	CalTxFee  int64  `json:"calTxFee"`  // Calculated transaction fee
-	CalTxSize int64  `json:"calTxSize"` // Calculated transaction size
+	CalTxFee  int64  `json:"calTxFee"`  // Calculated transaction fee (all transactions for chunked files)
+	CalTxSize int64  `json:"calTxSize"` // Calculated transaction size (all transactions for chunked files)
+
+	ChunkType string            `json:"chunkType"`        // single/multi
+	Chunks    []*PreUploadChunk `json:"chunks,omitempty"` // Chunk transactions to sign and commit (multi only)
+	Inputs    []*FundedInput    `json:"inputs,omitempty"` // Payer inputs of PreTxRaw to sign (with payer address)
+
+	Chain       string `json:"chain,omitempty"`       // mvc/btc
+	CommitPsbt  string `json:"commitPsbt,omitempty"`  // BTC commit PSBT for the payer to sign (base64)
+	CommitTxId  string `json:"commitTxId,omitempty"`  // BTC commit transaction ID
+	RevealTxRaw string `json:"revealTxRaw,omitempty"` // BTC signed reveal transaction, broadcast after the commit
+}
+
+// committed whether the file of a pre-upload was committed already, its transaction need not be signed
+func (r *PreUploadResponse) committed() bool {
+	return r.Status == string(model.StatusSuccess) || model.TxState(r.TxState).Accepted()
 }
 
 // UploadResponse upload response
 type UploadResponse struct {
 	FileId  string `json:"fileId"`  // File ID
-	Status  string `json:"status"`  // Status
+	Status  string `json:"status"`  // pending until the transaction is confirmed, then success
+	TxState string `json:"txState"` // State of the file transaction
 	TxId    string `json:"txId"`    // Transaction ID
 	PinId   string `json:"pinId"`   // Pin ID
 	Message string `json:"message"` // Message
 }
 
+// mvcFeeRate fee rate an MVC upload is built with: the requested rate, but at least the configured one
+// Signed transactions are verified against the configured rate, a lower rate would always be rejected.
+func mvcFeeRate(requested int64) int64 {
+	if requested < conf.Cfg.Uploader.FeeRate {
+		return conf.Cfg.Uploader.FeeRate
+	}
+	return requested
+}
+
 // PreUpload pre-upload: build transaction and save file metadata
 func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error) {
 	// Parameter validation
@@ -112,9 +155,14 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 	if req.ContentType == "" {
 		req.ContentType = "application/octet-stream"
 	}
-	if req.FeeRate == 0 {
-		req.FeeRate = conf.Cfg.Uploader.FeeRate
+	switch req.Chain {
+	case "", model.ChainMvc:
+	case model.ChainBtc:
+		return s.preUploadBtc(req)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedChain, req.Chain)
 	}
+	req.FeeRate = mvcFeeRate(req.FeeRate)
 
 	// Get network parameters
 	var netParam *chaincfg2.Params
@@ -124,6 +172,20 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 		netParam = &chaincfg2.TestNet3Params
 	}
 
+	if req.PayerAddress != "" {
+		if _, err := addressScript(netParam, req.PayerAddress); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPayer, err)
+		}
+		if req.ChangeAddress == "" {
+			req.ChangeAddress = req.PayerAddress
+		}
+	}
+
+	// Files above the threshold are inscribed as chunk PINs plus an index PIN
+	if int64(len(req.Content)) > conf.Cfg.Uploader.ChunkThreshold {
+		return s.preUploadChunked(req, netParam)
+	}
+
 	// Build transaction
 	tx, err := common.BuildMvcCommonMetaIdTxForUnkwonInput(
 		netParam,
@@ -134,7 +196,7 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 		req.Path,
 		req.Content,
 		req.ContentType,
-		req.ChangeAddress,
+		req.templateChangeAddress(),
 		req.FeeRate,
 		true, // No signature needed
 	)
@@ -142,16 +204,6 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 		return nil, fmt.Errorf("failed to build transaction: %w", err)
 	}
 
-	txSize := tx.SerializeSize()
-	txFee := int64(txSize) * req.FeeRate
-
-	// Get transaction ID and raw transaction
-	// txID := tx.Txhash().String()
-	preTxRaw, err := indexer.TxToHex(tx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to serialize transaction: %w", err)
-	}
-
 	// Calculate file hash
 	sha256hash := sha256.Sum256(req.Content)
 	md5hash := md5.Sum(req.Content)
@@ -163,31 +215,64 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 
 	// Check if FileId already exists
 	existingFile, err := s.fileDAO.GetByFileID(fileId)
-	if err == nil && existingFile != nil {
+	alreadyUploaded := err == nil && existingFile != nil && existingFile.Committed()
+
+	txSize := tx.SerializeSize()
+	txFee := int64(txSize) * req.FeeRate
+	var inputs []*FundedInput
+	if req.PayerAddress != "" && !alreadyUploaded {
+		// UTXOs selected by an earlier pre-upload of the file are selectable again
+		if err := s.utxoReservationDAO.ReleaseByFileID(fileId); err != nil {
+			return nil, fmt.Errorf("failed to release previous reservations: %w", err)
+		}
+		ftx := &fundingTx{tx: tx, txIndex: fileTxIndex}
+		if err := s.fundTxs(netParam, req, fileId, []*fundingTx{ftx}); err != nil {
+			return nil, err
+		}
+		inputs = ftx.inputs
+		txSize = signedTxSize(tx)
+		txFee = ftx.fee
+	}
+
+	// Get transaction ID and raw transaction
+	// txID := tx.Txhash().String()
+	preTxRaw, err := indexer.TxToHex(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+
+	if existingFile != nil {
 		// File already exists, return different info based on status
-		if existingFile.Status == model.StatusSuccess {
-			// File already successfully uploaded to chain
+		if existingFile.Committed() {
+			// File transaction already accepted by the node
 			log.Printf("File already exists and uploaded successfully: FileId=%s", fileId)
 			return &PreUploadResponse{
-				TxId:     existingFile.TxID,
-				PinId:    existingFile.PinId,
-				FileId:   existingFile.FileId,
-				FileMd5:  existingFile.FileMd5,
-				FileHash: existingFile.FileHash,
-				PreTxRaw: preTxRaw,
-				Status:   string(existingFile.Status),
-				Message:  "file already exists and uploaded",
+				TxId:      existingFile.TxID,
+				PinId:     existingFile.PinId,
+				FileId:    existingFile.FileId,
+				FileMd5:   existingFile.FileMd5,
+				FileHash:  existingFile.FileHash,
+				PreTxRaw:  preTxRaw,
+				ChunkType: string(existingFile.ChunkType),
+				Status:    string(existingFile.Status),
+				TxState:   string(existingFile.TxState),
+				Message:   "file already exists and uploaded",
 			}, nil
 		} else if existingFile.Status == model.StatusPending {
 			// File is being processed, return existing PreTxRaw
 			log.Printf("File already exists in pending status: FileId=%s", fileId)
 			return &PreUploadResponse{
-				FileId:   existingFile.FileId,
-				FileMd5:  existingFile.FileMd5,
-				FileHash: existingFile.FileHash,
-				PreTxRaw: preTxRaw,
-				Status:   string(existingFile.Status),
-				Message:  "file already in pending, please commit",
+				FileId:    existingFile.FileId,
+				FileMd5:   existingFile.FileMd5,
+				FileHash:  existingFile.FileHash,
+				PreTxRaw:  preTxRaw,
+				CalTxFee:  txFee,
+				CalTxSize: int64(txSize),
+				ChunkType: string(existingFile.ChunkType),
+				Inputs:    inputs,
+				Status:    string(existingFile.Status),
+				TxState:   string(existingFile.TxState),
+				Message:   "file already in pending, please commit",
 			}, nil
 		}
 		// If status is failed, allow re-upload
@@ -210,12 +295,22 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 		ChunkType:       model.ChunkTypeSingle,
 		Operation:       req.Operation,
 		// PreTxRaw:        preTxRaw,
-		Status: model.StatusPending, // Set status to pending
+		Status:  model.StatusPending, // Set status to pending
+		TxState: model.TxStateBuilt,
 	}
 
-	if err := s.fileDAO.Create(file); err != nil {
+	// A failed record is rebuilt in place, file_id is unique
+	if existingFile != nil {
+		file.ID = existingFile.ID
+		file.CreatedAt = existingFile.CreatedAt
+		err = s.fileDAO.Update(file)
+	} else {
+		err = s.fileDAO.Create(file)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to save file metadata: %w", err)
 	}
+	logTxEvent(database.UploaderDB, file.FileId, "", model.TxStateBuilt, 0, "")
 
 	log.Printf("File metadata saved successfully: FileId=%s, status=pending", file.FileId)
 
@@ -225,76 +320,107 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 		FileHash:  filehashStr,
 		PreTxRaw:  preTxRaw,
 		Status:    string(file.Status),
+		TxState:   string(file.TxState),
 		TxId:      file.TxID,
 		PinId:     file.PinId,
 		CalTxFee:  txFee,
 		CalTxSize: int64(txSize),
+		ChunkType: string(file.ChunkType),
+		Inputs:    inputs,
 		Message:   "success",
 	}, nil
 }
 
-// CommitUpload commit upload: broadcast transaction and update file status
-// Use database transaction to ensure data consistency
+// CommitUpload commit upload: broadcast transaction and record it for the file
+// For chunked files this commits the index transaction, which requires all chunks to be committed.
+// For BTC files signedRawTx is the signed commit PSBT (or transaction), the reveal is broadcast after it.
+// The file stays pending until the tracker sees its transaction confirmed.
 func (s *UploadService) CommitUpload(fileId string, signedRawTx string) (*UploadResponse, error) {
+	// 1. Query file record
+	file, err := s.fileDAO.GetByFileID(fileId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find file record: %w", err)
+	}
 
-	var (
-		txId   string
-		status string
-	)
-	// Use database transaction
-	err := database.UploaderDB.Transaction(func(tx *gorm.DB) error {
-		// 1. Query file record
-		var file model.File
-		if err := tx.Where("file_id = ?", fileId).First(&file).Error; err != nil {
-			return fmt.Errorf("failed to find file record: %w", err)
+	// Check file status
+	if file.Committed() {
+		log.Printf("File already committed: fileId=%s", fileId)
+		return nil, fmt.Errorf("file already committed: fileId=%s", fileId)
+	}
+	if file.Chain == model.ChainBtc {
+		if err := s.commitBtcUpload(file, signedRawTx); err != nil {
+			return nil, err
 		}
+	} else if err := s.commitMvcUpload(file, signedRawTx); err != nil {
+		return nil, err
+	}
 
-		// Check file status
-		if file.Status == model.StatusSuccess {
-			log.Printf("File already committed: fileId=%s", fileId)
-			return fmt.Errorf("file already committed: fileId=%s", fileId)
-		}
-		txhash := common.GetMvcTxhashFromRaw(signedRawTx)
+	if err := s.utxoReservationDAO.ReleaseByFileID(fileId); err != nil {
+		log.Printf("Failed to release reservations: fileId=%s, err=%v", fileId, err)
+	}
 
-		// 2. Update file record
-		// file.TxRaw = signedRawTx
-		file.TxID = txhash
-		file.PinId = fmt.Sprintf("%si0", txhash)
-		file.Status = model.StatusSuccess
-		if err := tx.Save(&file).Error; err != nil {
-			return fmt.Errorf("failed to update file record: %w", err)
-		}
-		status = string(file.Status)
-		txId = file.TxID
+	return &UploadResponse{
+		FileId:  fileId,
+		Status:  string(file.Status),
+		TxState: string(file.TxState),
+		TxId:    file.TxID,
+		PinId:   file.PinId,
+		Message: "success",
+	}, nil
+}
 
-		// 3. Broadcast transaction to blockchain network
-		chain := conf.Cfg.Net // Use network type from configuration
-		broadcastTxID, err := node.BroadcastTx(chain, signedRawTx)
+// commitMvcUpload verify the signed transaction of an MVC file (or its index) and broadcast it
+func (s *UploadService) commitMvcUpload(file *model.File, signedRawTx string) error {
+	expected := &expectedPin{path: file.Path, contentType: file.ContentType, contentHash: file.FileHash}
+	if file.ChunkType == model.ChunkTypeMulti {
+		pending, err := countPendingChunks(database.UploaderDB, file.FileId)
+		if err != nil {
+			return fmt.Errorf("failed to count pending chunks: %w", err)
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d chunk transactions not committed yet: fileId=%s", pending, file.FileId)
+		}
+		chunks, err := s.fileChunkDAO.ListByFileID(file.FileId)
+		if err != nil {
+			return fmt.Errorf("failed to get chunks: %w", err)
+		}
+		indexContent, err := buildIndexContent(file, chunks)
 		if err != nil {
-			// Broadcast failed, update status to failed
-			file.Status = model.StatusFailed
-			if updateErr := tx.Save(&file).Error; updateErr != nil {
-				return fmt.Errorf("failed to update file status to failed: %w", updateErr)
-			}
-			return fmt.Errorf("failed to broadcast transaction: %w", err)
+			return err
 		}
+		indexHash := sha256.Sum256(indexContent)
+		expected = &expectedPin{path: file.Path, contentType: IndexContentType, contentHash: hex.EncodeToString(indexHash[:])}
+	}
 
-		log.Printf("Transaction broadcasted successfully: fileId=%s, broadcastTxID=%s", fileId, broadcastTxID)
+	// 2. Verify the transaction carries the PIN of the file, is signed and pays the fee
+	verified, err := verifySignedTx(signedRawTx, expected)
+	if err != nil {
+		return err
+	}
 
-		log.Printf("File status updated to success: fileId=%s", fileId)
+	// 3. Broadcast transaction to blockchain network, then record it with the file
+	err = broadcastFileTx(&model.FileTx{
+		FileId:     file.FileId,
+		TxId:       verified.txId,
+		Chain:      model.ChainMvc,
+		Role:       model.TxRoleFile,
+		ChunkIndex: fileTxIndex,
+		TxRaw:      signedRawTx,
+	}, func(tx *gorm.DB) error {
+		file.TxID = verified.txId
+		file.PinId = verified.pinId
+		file.Status = model.StatusPending
+		file.TxState = model.TxStateBroadcast
+		if err := tx.Save(file).Error; err != nil {
+			return fmt.Errorf("failed to update file record: %w", err)
+		}
 		return nil
 	})
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to broadcast transaction: %w", err)
 	}
-
-	return &UploadResponse{
-		FileId:  fileId,
-		Status:  status,
-		TxId:    txId,
-		PinId:   fmt.Sprintf("%si0", txId),
-		Message: "success",
-	}, nil
+	log.Printf("Transaction broadcasted successfully: fileId=%s, txId=%s", file.FileId, verified.txId)
+	return nil
 }
 
 // DirectUpload direct upload: one-step upload with PreTxHex (add MetaID output and broadcast)
@@ -312,6 +438,9 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 	if req.PreTxHex == "" {
 		return nil, fmt.Errorf("PreTxHex is required")
 	}
+	if int64(len(req.Content)) > conf.Cfg.Uploader.ChunkThreshold {
+		return nil, fmt.Errorf("file size %d exceeds chunk threshold %d, use pre-upload for chunked upload", len(req.Content), conf.Cfg.Uploader.ChunkThreshold)
+	}
 
 	// Set default values
 	if req.Operation == "" {
@@ -323,9 +452,7 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 	if req.ChangeAddress == "" && req.Address != "" {
 		req.ChangeAddress = req.Address
 	}
-	if req.FeeRate == 0 {
-		req.FeeRate = conf.Cfg.Uploader.FeeRate
-	}
+	req.FeeRate = mvcFeeRate(req.FeeRate)
 
 	// Get network parameters
 	var netParam *chaincfg2.Params
@@ -354,28 +481,7 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 	}
 
 	// Build MetaID OP_RETURN output
-	inscriptionBuilder := txscript.NewScriptBuilder().
-		AddOp(txscript.OP_0).
-		AddOp(txscript.OP_RETURN).
-		AddData([]byte("metaid")).       // <metaid_flag>
-		AddData([]byte(req.Operation)).  // <operation>
-		AddData([]byte(req.Path)).       // <path>
-		AddData([]byte("0")).            // <Encryption>
-		AddData([]byte("1.0.0")).        // <version>
-		AddData([]byte(req.ContentType)) // <content-type>
-
-	// Split content into chunks (max 520 bytes per chunk)
-	maxChunkSize := 520
-	bodySize := len(req.Content)
-	for i := 0; i < bodySize; i += maxChunkSize {
-		end := i + maxChunkSize
-		if end > bodySize {
-			end = bodySize
-		}
-		inscriptionBuilder.AddFullData(req.Content[i:end]) // <payload>
-	}
-
-	inscriptionScript, err := inscriptionBuilder.Script()
+	inscriptionScript, err := buildMetaIdScript(req.Operation, req.Path, req.ContentType, req.Content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build inscription script: %w", err)
 	}
@@ -427,85 +533,64 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 		return nil, fmt.Errorf("failed to serialize transaction: %w", err)
 	}
 
-	// Get transaction hash
-	txhash := common.GetMvcTxhashFromRaw(signedRawTx)
-
 	// Calculate file hash
 	sha256hash := sha256.Sum256(req.Content)
 	md5hash := md5.Sum(req.Content)
 	filehashStr := hex.EncodeToString(sha256hash[:])
 	md5hashStr := hex.EncodeToString(md5hash[:])
 
+	// Verify both transactions before anything is broadcast, the upload transaction may spend merge outputs
+	var unconfirmed []*wire2.MsgTx
+	if req.MergeTxHex != "" {
+		mergeTx, err := decodeMvcTx(req.MergeTxHex)
+		if err != nil {
+			return nil, fmt.Errorf("merge transaction: %w", err)
+		}
+		if err := verifyTxInputs(mergeTx); err != nil {
+			return nil, fmt.Errorf("merge transaction: %w", err)
+		}
+		unconfirmed = append(unconfirmed, mergeTx)
+	}
+	verified, err := verifySignedTx(signedRawTx, &expectedPin{
+		path:        req.Path,
+		contentType: req.ContentType,
+		contentHash: filehashStr,
+	}, unconfirmed...)
+	if err != nil {
+		return nil, err
+	}
+	txhash := verified.txId
+
 	// Generate FileId (ensure uniqueness)
 	fileId := req.MetaId + "_" + filehashStr
 
-	var (
-		finalTxId string
-		pinId     string
-		status    string
-	)
-
-	// Use database transaction to ensure data consistency
-	err = database.UploaderDB.Transaction(func(dbTx *gorm.DB) error {
-		// Check if FileId already exists
-		var existingFile model.File
-		err := dbTx.Where("file_id = ?", fileId).First(&existingFile).Error
-
-		if err == nil {
-			// File already exists
-			if existingFile.Status == model.StatusSuccess {
-				// File already successfully uploaded to chain
-				log.Printf("File already exists and uploaded successfully: FileId=%s", fileId)
-				finalTxId = existingFile.TxID
-				pinId = existingFile.PinId
-				status = string(existingFile.Status)
-				return nil
-			} else if existingFile.Status == model.StatusPending {
-				// File is pending, update and broadcast
-				log.Printf("File exists in pending status, updating and broadcasting: FileId=%s", fileId)
-				existingFile.TxID = txhash
-				existingFile.PinId = fmt.Sprintf("%si0", txhash)
-				existingFile.Status = model.StatusSuccess
-				if err := dbTx.Save(&existingFile).Error; err != nil {
-					return fmt.Errorf("failed to update file record: %w", err)
-				}
-				finalTxId = existingFile.TxID
-				pinId = existingFile.PinId
-				status = string(existingFile.Status)
-
-				// Broadcast transaction
-				chain := conf.Cfg.Net
-				if req.MergeTxHex != "" {
-					broadcastMergeTxID, err := node.BroadcastTx(chain, req.MergeTxHex)
-					if err != nil {
-						// // Broadcast failed, update status to failed
-						// existingFile.Status = model.StatusFailed
-						// if updateErr := dbTx.Save(&existingFile).Error; updateErr != nil {
-						// 	return fmt.Errorf("failed to update file status to failed: %w", updateErr)
-						// }
-						return fmt.Errorf("failed to broadcast merge transaction: %w", err)
-					}
-					log.Printf("Transaction broadcasted successfully: fileId=%s, broadcastMergeTxID=%s", fileId, broadcastMergeTxID)
-				}
-
-				broadcastTxID, err := node.BroadcastTx(chain, signedRawTx)
-				if err != nil {
-					// Broadcast failed, update status to failed
-					// existingFile.Status = model.StatusFailed
-					// if updateErr := dbTx.Save(&existingFile).Error; updateErr != nil {
-					// 	return fmt.Errorf("failed to update file status to failed: %w", updateErr)
-					// }
-					return fmt.Errorf("failed to broadcast transaction: %w", err)
-				}
-				log.Printf("Transaction broadcasted successfully: fileId=%s, broadcastTxID=%s", fileId, broadcastTxID)
-				return nil
-			}
-			// If status is failed, allow re-upload (continue to create new record)
+	// Check if FileId already exists
+	existingFile, err := s.fileDAO.GetByFileID(fileId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to query file record: %w", err)
+	}
+	file := existingFile
+	switch {
+	case existingFile != nil && existingFile.Committed():
+		// File transaction already accepted by the node
+		log.Printf("File already exists and uploaded successfully: FileId=%s", fileId)
+		return &UploadResponse{
+			FileId:  fileId,
+			Status:  string(existingFile.Status),
+			TxState: string(existingFile.TxState),
+			TxId:    existingFile.TxID,
+			PinId:   existingFile.PinId,
+			Message: "success",
+		}, nil
+	case existingFile != nil && existingFile.Status == model.StatusPending:
+		// File is pending, broadcast and update
+		log.Printf("File exists in pending status, updating and broadcasting: FileId=%s", fileId)
+	default:
+		if existingFile != nil {
+			// If status is failed, allow re-upload (the record is rebuilt in place)
 			log.Printf("File exists but failed, allow re-upload: FileId=%s", fileId)
 		}
-
-		// File does not exist, create new record
-		file := &model.File{
+		file = &model.File{
 			FileId:          fileId,
 			FileName:        req.FileName,
 			FileType:        strings.ReplaceAll(req.ContentType, ";binary", ""),
@@ -519,52 +604,84 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 			FileContentType: strings.ReplaceAll(req.ContentType, ";binary", ""),
 			ChunkType:       model.ChunkTypeSingle,
 			Operation:       req.Operation,
-			TxID:            txhash,
-			PinId:           fmt.Sprintf("%si0", txhash),
-			Status:          model.StatusSuccess,
-		}
-
-		if err := dbTx.Create(file).Error; err != nil {
-			return fmt.Errorf("failed to create file metadata: %w", err)
 		}
-
-		finalTxId = file.TxID
-		pinId = file.PinId
-		status = string(file.Status)
-
-		// Broadcast transaction
-		chain := conf.Cfg.Net
-		if req.MergeTxHex != "" {
-			broadcastMergeTxID, err := node.BroadcastTx(chain, req.MergeTxHex)
-			if err != nil {
-				return fmt.Errorf("failed to broadcast merge transaction: %w", err)
-			}
-			log.Printf("Transaction broadcasted successfully: fileId=%s, broadcastMergeTxID=%s", fileId, broadcastMergeTxID)
+		if existingFile != nil {
+			file.ID = existingFile.ID
+			file.CreatedAt = existingFile.CreatedAt
 		}
+	}
 
-		broadcastTxID, err := node.BroadcastTx(chain, signedRawTx)
+	// Broadcast the merge transaction (if any), then the upload transaction. The file is saved with the
+	// upload transaction once the node accepted it, and stays pending until the transaction is confirmed.
+	if req.MergeTxHex != "" {
+		mergeTxId := common.GetMvcTxhashFromRaw(req.MergeTxHex)
+		err := broadcastFileTx(&model.FileTx{
+			FileId:     fileId,
+			TxId:       mergeTxId,
+			Chain:      model.ChainMvc,
+			Role:       model.TxRoleMerge,
+			ChunkIndex: fileTxIndex,
+			TxRaw:      req.MergeTxHex,
+		}, nil)
 		if err != nil {
-			// Broadcast failed, update status to failed
-			// file.Status = model.StatusFailed
-			// if updateErr := dbTx.Save(file).Error; updateErr != nil {
-			// 	return fmt.Errorf("failed to update file status to failed: %w", updateErr)
-			// }
-			return fmt.Errorf("failed to broadcast transaction: %w", err)
+			return nil, fmt.Errorf("failed to broadcast merge transaction: %w", err)
+		}
+		log.Printf("Transaction broadcasted successfully: fileId=%s, mergeTxId=%s", fileId, mergeTxId)
+	}
+	err = broadcastFileTx(&model.FileTx{
+		FileId:     fileId,
+		TxId:       txhash,
+		Chain:      model.ChainMvc,
+		Role:       model.TxRoleFile,
+		ChunkIndex: fileTxIndex,
+		TxRaw:      signedRawTx,
+	}, func(tx *gorm.DB) error {
+		file.TxID = txhash
+		file.PinId = verified.pinId
+		file.Status = model.StatusPending
+		file.TxState = model.TxStateBroadcast
+		if err := tx.Save(file).Error; err != nil {
+			return fmt.Errorf("failed to save file metadata: %w", err)
 		}
-
-		log.Printf("File created and transaction broadcasted successfully: fileId=%s, broadcastTxID=%s", fileId, broadcastTxID)
 		return nil
 	})
-
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to broadcast transaction: %w", err)
 	}
+	log.Printf("File saved and transaction broadcasted successfully: fileId=%s, txId=%s", fileId, txhash)
 
 	return &UploadResponse{
 		FileId:  fileId,
-		Status:  status,
-		TxId:    finalTxId,
-		PinId:   pinId,
+		Status:  string(file.Status),
+		TxState: string(file.TxState),
+		TxId:    file.TxID,
+		PinId:   file.PinId,
 		Message: "success",
 	}, nil
 }
+
+// buildMetaIdScript build a MetaID OP_RETURN output script
+func buildMetaIdScript(operation, path, contentType string, content []byte) ([]byte, error) {
+	inscriptionBuilder := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).
+		AddOp(txscript.OP_RETURN).
+		AddData([]byte("metaid")).   // <metaid_flag>
+		AddData([]byte(operation)).  // <operation>
+		AddData([]byte(path)).       // <path>
+		AddData([]byte("0")).        // <Encryption>
+		AddData([]byte("1.0.0")).    // <version>
+		AddData([]byte(contentType)) // <content-type>
+
+	// Split content into chunks (max 520 bytes per chunk)
+	maxChunkSize := 520
+	bodySize := len(content)
+	for i := 0; i < bodySize; i += maxChunkSize {
+		end := i + maxChunkSize
+		if end > bodySize {
+			end = bodySize
+		}
+		inscriptionBuilder.AddFullData(content[i:end]) // <payload>
+	}
+
+	return inscriptionBuilder.Script()
+}
@@ -0,0 +1,394 @@
+package upload_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	"github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	txscript2 "github.com/bitcoinsv/bsvd/txscript"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+	bsvutil2 "github.com/bitcoinsv/bsvutil"
+
+	"meta-media-service/common"
+	"meta-media-service/conf"
+	"meta-media-service/indexer"
+	"meta-media-service/model"
+	"meta-media-service/node"
+)
+
+const (
+	p2pkhInputSize  = 148 // Signed P2PKH input: outpoint 36, script length 1, signature and public key 107, sequence 4
+	unsignedInput   = 41  // Input with an empty script
+	p2pkhOutputSize = 34  // P2PKH output: value 8, script length 1, script 25
+	changeDustLimit = 600 // Smaller change is left to the fee
+
+	// fileTxIndex reservation transaction index of the file transaction (index transaction for chunked files)
+	fileTxIndex = int64(-1)
+)
+
+// sigHashType sighash type the wallet signs payer inputs with
+const sigHashType = txscript2.SigHashAll | txscript2.SigHashForkID
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrInvalidPayer      = errors.New("invalid payer address")
+)
+
+// FundedInput payer UTXO spent by a pre-upload transaction and the data its signature commits to
+type FundedInput struct {
+	Index           int    `json:"index"`           // Input index in the transaction
+	TxId            string `json:"txId"`            // Transaction ID of the spent output
+	Vout            uint32 `json:"vout"`            // Output index of the spent output
+	Address         string `json:"address"`         // Payer address
+	Amount          int64  `json:"amount"`          // Output amount (satoshis)
+	ScriptPubKey    string `json:"scriptPubKey"`    // Output locking script (hex)
+	SigHashType     uint32 `json:"sigHashType"`     // Sighash type to sign with (0x41, SIGHASH_ALL|FORKID)
+	SigHashPreimage string `json:"sigHashPreimage"` // Sighash preimage (hex)
+	SigHash         string `json:"sigHash"`         // Double SHA256 of the preimage, the digest to sign (hex)
+}
+
+// fundingTx unsigned transaction to fund from the payer UTXOs
+type fundingTx struct {
+	tx      *wire2.MsgTx
+	txIndex int64 // Chunk index, fileTxIndex for the file (or index) transaction
+
+	inputs []*FundedInput // Set by fundTxs
+	fee    int64          // Set by fundTxs
+}
+
+// payerUtxo spendable output of the payer
+type payerUtxo struct {
+	txId         string
+	vout         uint32
+	amount       int64
+	scriptPubKey []byte
+}
+
+// fundTxs add payer inputs and change to transactions and reserve the selected UTXOs for the file
+// Transactions get disjoint inputs. UTXOs reserved by other pending uploads are skipped, the
+// caller releases the previous reservations of the file first.
+func (s *UploadService) fundTxs(netParam *chaincfg2.Params, req *UploadRequest, fileId string, txs []*fundingTx) error {
+	changeScript, err := addressScript(netParam, req.ChangeAddress)
+	if err != nil {
+		return fmt.Errorf("failed to decode change address: %w", err)
+	}
+	pool, err := s.payerUtxos(req.PayerAddress)
+	if err != nil {
+		return err
+	}
+
+	var reservations []*model.UtxoReservation
+	expiresAt := reservationExpiresAt()
+	for _, ftx := range txs {
+		selected, fee, err := selectCoins(ftx.tx, &pool, changeScript, req.FeeRate)
+		if err != nil {
+			return err
+		}
+		ftx.fee = fee
+		for _, utxo := range selected {
+			reservations = append(reservations, &model.UtxoReservation{
+				TxId:         utxo.txId,
+				Vout:         utxo.vout,
+				Address:      req.PayerAddress,
+				Amount:       utxo.amount,
+				ScriptPubKey: hex.EncodeToString(utxo.scriptPubKey),
+				FileId:       fileId,
+				TxIndex:      ftx.txIndex,
+				ExpiresAt:    expiresAt,
+			})
+		}
+		if ftx.inputs, err = sigHashInputs(ftx.tx, selected, req.PayerAddress); err != nil {
+			return err
+		}
+	}
+
+	if err := s.utxoReservationDAO.Reserve(reservations, time.Now()); err != nil {
+		return fmt.Errorf("failed to reserve payer UTXOs, retry pre-upload: %w", err)
+	}
+	log.Printf("Payer UTXOs reserved: fileId=%s, payer=%s, utxos=%d", fileId, req.PayerAddress, len(reservations))
+	return nil
+}
+
+// payerUtxos spendable outputs of the payer that are not reserved, largest first
+func (s *UploadService) payerUtxos(address string) ([]*payerUtxo, error) {
+	unspents, err := node.ListUnspent(conf.Cfg.Net, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payer UTXOs: %w", err)
+	}
+	reserved, err := s.utxoReservationDAO.ListActiveByAddress(address, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reserved UTXOs: %w", err)
+	}
+	reservedSet := make(map[string]bool, len(reserved))
+	for _, r := range reserved {
+		reservedSet[fmt.Sprintf("%s:%d", r.TxId, r.Vout)] = true
+	}
+
+	var utxos []*payerUtxo
+	for _, unspent := range unspents {
+		if !unspent.Spendable || reservedSet[fmt.Sprintf("%s:%d", unspent.TxID, unspent.Vout)] {
+			continue
+		}
+		amount, err := unspent.Satoshis()
+		if err != nil {
+			return nil, err
+		}
+		script, err := hex.DecodeString(unspent.ScriptPubKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid script of UTXO %s:%d: %w", unspent.TxID, unspent.Vout, err)
+		}
+		utxos = append(utxos, &payerUtxo{
+			txId:         unspent.TxID,
+			vout:         uint32(unspent.Vout),
+			amount:       amount,
+			scriptPubKey: script,
+		})
+	}
+	sort.SliceStable(utxos, func(i, j int) bool {
+		return utxos[i].amount > utxos[j].amount
+	})
+	return utxos, nil
+}
+
+// selectCoins take UTXOs from the pool until they pay the outputs and the fee of the signed transaction
+// Inputs and a change output (if not dust) are added to tx. Returns the selected UTXOs and the fee.
+func selectCoins(tx *wire2.MsgTx, pool *[]*payerUtxo, changeScript []byte, feeRate int64) ([]*payerUtxo, int64, error) {
+	outAmount := int64(0)
+	for _, out := range tx.TxOut {
+		outAmount += out.Value
+	}
+	baseSize := int64(tx.SerializeSize())
+
+	var (
+		selected []*payerUtxo
+		total    int64
+	)
+	for len(*pool) > 0 {
+		utxo := (*pool)[0]
+		*pool = (*pool)[1:]
+		selected = append(selected, utxo)
+		total += utxo.amount
+
+		size := baseSize + int64(len(selected))*p2pkhInputSize
+		fee := size * feeRate
+		if total < outAmount+fee {
+			continue
+		}
+
+		for _, u := range selected {
+			hash, err := chainhash.NewHashFromStr(u.txId)
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid UTXO txid %s: %w", u.txId, err)
+			}
+			tx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(hash, u.vout), nil))
+		}
+		feeWithChange := (size + p2pkhOutputSize) * feeRate
+		if change := total - outAmount - feeWithChange; change >= changeDustLimit {
+			tx.AddTxOut(wire2.NewTxOut(change, changeScript))
+			return selected, feeWithChange, nil
+		}
+		return selected, total - outAmount, nil
+	}
+
+	// Return the UTXOs to the pool untouched
+	*pool = append(selected, *pool...)
+	return nil, 0, fmt.Errorf("%w: outputs need %d satoshis plus fee, %d satoshis unreserved", ErrInsufficientFunds, outAmount, total)
+}
+
+// sigHashInputs compute the sighash preimages of the inputs of a funded transaction
+func sigHashInputs(tx *wire2.MsgTx, utxos []*payerUtxo, address string) ([]*FundedInput, error) {
+	inputs := make([]*FundedInput, 0, len(utxos))
+	for i, utxo := range utxos {
+		preimage, err := common.CalcMvcSigHashPreimage(tx, i, utxo.scriptPubKey, utxo.amount, sigHashType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute sighash preimage of input %d: %w", i, err)
+		}
+		inputs = append(inputs, &FundedInput{
+			Index:           i,
+			TxId:            utxo.txId,
+			Vout:            utxo.vout,
+			Address:         address,
+			Amount:          utxo.amount,
+			ScriptPubKey:    hex.EncodeToString(utxo.scriptPubKey),
+			SigHashType:     uint32(sigHashType),
+			SigHashPreimage: hex.EncodeToString(preimage),
+			SigHash:         hex.EncodeToString(chainhash.DoubleHashB(preimage)),
+		})
+	}
+	return inputs, nil
+}
+
+// reservedSigHashInputs compute the sighash preimages of a transaction funded earlier, using its reservations
+func (s *UploadService) reservedSigHashInputs(tx *wire2.MsgTx, fileId string, txIndex int64) ([]*FundedInput, error) {
+	if len(tx.TxIn) == 0 {
+		return nil, nil
+	}
+	reservations, err := s.utxoReservationDAO.ListByFileTx(fileId, txIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reserved UTXOs: %w", err)
+	}
+	byOutpoint := make(map[string]*model.UtxoReservation, len(reservations))
+	for _, r := range reservations {
+		byOutpoint[fmt.Sprintf("%s:%d", r.TxId, r.Vout)] = r
+	}
+
+	utxos := make([]*payerUtxo, 0, len(tx.TxIn))
+	address := ""
+	for i, in := range tx.TxIn {
+		r, ok := byOutpoint[in.PreviousOutPoint.String()]
+		if !ok {
+			return nil, fmt.Errorf("reservation of input %d (%s) was released, pre-upload again", i, in.PreviousOutPoint.String())
+		}
+		script, err := hex.DecodeString(r.ScriptPubKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reserved script of input %d: %w", i, err)
+		}
+		utxos = append(utxos, &payerUtxo{txId: r.TxId, vout: r.Vout, amount: r.Amount, scriptPubKey: script})
+		address = r.Address
+	}
+	return sigHashInputs(tx, utxos, address)
+}
+
+// fundedTxCommitter sign a funded pre-transaction and pass the signed transaction to commit
+type fundedTxCommitter func(preTxRaw string, inputs []*FundedInput, commit func(signedRawTx string) error) error
+
+// commitFunded sign and commit every transaction of a pre-upload funded by a server-held key
+// Chunk transactions committed by an earlier request are skipped, the file (or index)
+// transaction is committed last.
+func (s *UploadService) commitFunded(pre *PreUploadResponse, commitTx fundedTxCommitter) (*UploadResponse, error) {
+	preTxRaw, inputs := pre.PreTxRaw, pre.Inputs
+	if pre.ChunkType == string(model.ChunkTypeMulti) {
+		for _, chunk := range pre.Chunks {
+			if chunk.PreTxRaw == "" {
+				continue // Committed by an earlier request
+			}
+			err := commitTx(chunk.PreTxRaw, chunk.Inputs, func(signedRawTx string) error {
+				_, err := s.CommitChunk(pre.FileId, chunk.ChunkIndex, signedRawTx)
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("chunk %d: %w", chunk.ChunkIndex, err)
+			}
+		}
+		progress, err := s.GetUploadProgress(pre.FileId)
+		if err != nil {
+			return nil, err
+		}
+		preTxRaw, inputs = progress.IndexPreTxRaw, progress.IndexInputs
+	}
+
+	var resp *UploadResponse
+	err := commitTx(preTxRaw, inputs, func(signedRawTx string) error {
+		var err error
+		resp, err = s.CommitUpload(pre.FileId, signedRawTx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// chargedSigner signs funded pre-transactions with a server-held key and charges their cost to a
+// budget before committing them. The charge is refunded if the commit fails.
+type chargedSigner struct {
+	priHex       string
+	changeScript []byte
+	charge       func(cost int64) error // Fails if the budget would be exceeded
+	refund       func(cost int64)
+	committed    func(cost int64) // Optional, called after each committed transaction
+}
+
+// commit sign a funded pre-transaction, charge its cost and commit it, a fundedTxCommitter
+func (c *chargedSigner) commit(preTxRaw string, inputs []*FundedInput, commit func(signedRawTx string) error) error {
+	signedRawTx, cost, err := signFundedTx(preTxRaw, inputs, c.priHex, c.changeScript)
+	if err != nil {
+		return err
+	}
+	if err := c.charge(cost); err != nil {
+		return err
+	}
+	if err := commit(signedRawTx); err != nil {
+		c.refund(cost)
+		return err
+	}
+	if c.committed != nil {
+		c.committed(cost)
+	}
+	return nil
+}
+
+// releaseReservations release the payer UTXOs reserved for a file that will not be committed
+func (s *UploadService) releaseReservations(fileId string) {
+	if err := s.utxoReservationDAO.ReleaseByFileID(fileId); err != nil {
+		log.Printf("Failed to release reservations: fileId=%s, err=%v", fileId, err)
+	}
+}
+
+// signFundedTx sign the payer inputs of a funded pre-transaction with a server-held key
+// Returns the signed transaction and its cost to the payer: inputs minus the outputs paying changeScript.
+func signFundedTx(preTxRaw string, inputs []*FundedInput, priHex string, changeScript []byte) (string, int64, error) {
+	if len(inputs) == 0 {
+		return "", 0, fmt.Errorf("pre-transaction is not funded by the payer")
+	}
+	txBytes, err := hex.DecodeString(preTxRaw)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to decode pre-transaction: %w", err)
+	}
+	tx := wire2.NewMsgTx(10)
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return "", 0, fmt.Errorf("failed to deserialize pre-transaction: %w", err)
+	}
+
+	ins := make([]*common.TxInputUtxo, 0, len(inputs))
+	cost := int64(0)
+	for _, in := range inputs {
+		ins = append(ins, &common.TxInputUtxo{
+			TxId:     in.TxId,
+			TxIndex:  int64(in.Vout),
+			PkScript: in.ScriptPubKey,
+			Amount:   uint64(in.Amount),
+			PriHex:   priHex,
+			SignMode: common.SignModeLegacy,
+		})
+		cost += in.Amount
+	}
+	for _, out := range tx.TxOut {
+		if bytes.Equal(out.PkScript, changeScript) {
+			cost -= out.Value
+		}
+	}
+	if err := common.SignMvcP2PKHInputs(tx, ins); err != nil {
+		return "", 0, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	signedRawTx, err := indexer.TxToHex(tx)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	return signedRawTx, cost, nil
+}
+
+// signedTxSize estimated size of a funded transaction once its P2PKH inputs are signed
+func signedTxSize(tx *wire2.MsgTx) int {
+	return tx.SerializeSize() + len(tx.TxIn)*(p2pkhInputSize-unsignedInput)
+}
+
+// addressScript P2PKH locking script of an address
+func addressScript(netParam *chaincfg2.Params, address string) ([]byte, error) {
+	addr, err := bsvutil2.DecodeAddress(address, netParam)
+	if err != nil {
+		return nil, err
+	}
+	return txscript2.PayToAddrScript(addr)
+}
+
+// reservationExpiresAt expiry of a reservation made or extended now
+func reservationExpiresAt() time.Time {
+	return time.Now().Add(time.Duration(conf.Cfg.Uploader.UtxoReservationTTL) * time.Minute)
+}
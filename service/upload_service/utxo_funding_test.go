@@ -0,0 +1,212 @@
+package upload_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	txscript2 "github.com/bitcoinsv/bsvd/txscript"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+
+	"meta-media-service/common"
+)
+
+// testPayerUtxos pool of payer UTXOs with the given amounts, all locked by script
+func testPayerUtxos(script []byte, amounts ...int64) []*payerUtxo {
+	pool := make([]*payerUtxo, 0, len(amounts))
+	for i, amount := range amounts {
+		hash := chainhash.DoubleHashH([]byte(fmt.Sprintf("utxo-%d", i)))
+		pool = append(pool, &payerUtxo{txId: hash.String(), vout: uint32(i), amount: amount, scriptPubKey: script})
+	}
+	return pool
+}
+
+func TestSelectCoins(t *testing.T) {
+	setTestConfig(t)
+	_, _, payerScript := newTestKey(t)
+	_, _, changeScript := newTestKey(t)
+	const outAmount = int64(1000)
+
+	// Amounts are given relative to the fee of the transaction with one signed input (fee1)
+	tests := []struct {
+		name       string
+		amounts    func(fee1 int64) []int64
+		wantInputs int
+		wantChange func(fee1 int64) int64 // 0 when the change is left to the fee
+		wantErr    error
+	}{
+		{
+			name:       "change",
+			amounts:    func(fee1 int64) []int64 { return []int64{100000} },
+			wantInputs: 1,
+			wantChange: func(fee1 int64) int64 { return 100000 - outAmount - fee1 - p2pkhOutputSize },
+		},
+		{
+			name:       "change at dust limit",
+			amounts:    func(fee1 int64) []int64 { return []int64{outAmount + fee1 + p2pkhOutputSize + changeDustLimit} },
+			wantInputs: 1,
+			wantChange: func(fee1 int64) int64 { return changeDustLimit },
+		},
+		{
+			name:       "dust change left to fee",
+			amounts:    func(fee1 int64) []int64 { return []int64{outAmount + fee1 + p2pkhOutputSize + changeDustLimit - 1} },
+			wantInputs: 1,
+			wantChange: func(fee1 int64) int64 { return 0 },
+		},
+		{
+			name:       "exact amount",
+			amounts:    func(fee1 int64) []int64 { return []int64{outAmount + fee1, 50000} },
+			wantInputs: 1,
+			wantChange: func(fee1 int64) int64 { return 0 },
+		},
+		{
+			name:       "two inputs",
+			amounts:    func(fee1 int64) []int64 { return []int64{outAmount, fee1 + p2pkhInputSize} },
+			wantInputs: 2,
+			wantChange: func(fee1 int64) int64 { return 0 },
+		},
+		{
+			name:       "insufficient funds",
+			amounts:    func(fee1 int64) []int64 { return []int64{outAmount, fee1 - 1} },
+			wantErr:    ErrInsufficientFunds,
+			wantChange: func(fee1 int64) int64 { return 0 },
+		},
+		{
+			name:       "empty pool",
+			amounts:    func(fee1 int64) []int64 { return nil },
+			wantErr:    ErrInsufficientFunds,
+			wantChange: func(fee1 int64) int64 { return 0 },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opReturn, _ := txscript2.NullDataScript([]byte("metaid"))
+			tx := wire2.NewMsgTx(10)
+			tx.AddTxOut(wire2.NewTxOut(0, opReturn))
+			tx.AddTxOut(wire2.NewTxOut(outAmount, payerScript))
+			baseSize := int64(tx.SerializeSize())
+			fee1 := baseSize + p2pkhInputSize
+
+			amounts := tt.amounts(fee1)
+			pool := testPayerUtxos(payerScript, amounts...)
+			initial := append([]*payerUtxo(nil), pool...)
+			selected, fee, err := selectCoins(tx, &pool, changeScript, 1)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("selectCoins() err = %v, want %v", err, tt.wantErr)
+				}
+				if len(pool) != len(initial) || len(tx.TxIn) != 0 || len(tx.TxOut) != 2 {
+					t.Error("a failed selection should leave the pool and the transaction untouched")
+				}
+				for i := range pool {
+					if pool[i] != initial[i] {
+						t.Errorf("pool[%d] changed order", i)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectCoins() failed, err: %v", err)
+			}
+			if len(selected) != tt.wantInputs || len(tx.TxIn) != tt.wantInputs {
+				t.Fatalf("selected %d UTXOs, %d inputs, want %d", len(selected), len(tx.TxIn), tt.wantInputs)
+			}
+			if len(pool) != len(amounts)-tt.wantInputs {
+				t.Errorf("pool has %d UTXOs left, want %d", len(pool), len(amounts)-tt.wantInputs)
+			}
+			for i, utxo := range selected {
+				if tx.TxIn[i].PreviousOutPoint.Hash.String() != utxo.txId || tx.TxIn[i].PreviousOutPoint.Index != utxo.vout {
+					t.Errorf("input %d spends %s, want %s:%d", i, tx.TxIn[i].PreviousOutPoint.String(), utxo.txId, utxo.vout)
+				}
+			}
+
+			change := int64(0)
+			if len(tx.TxOut) == 3 {
+				if !bytes.Equal(tx.TxOut[2].PkScript, changeScript) {
+					t.Fatal("third output should pay the change address")
+				}
+				change = tx.TxOut[2].Value
+			}
+			if want := tt.wantChange(fee1); change != want {
+				t.Errorf("change = %d, want %d", change, want)
+			}
+
+			total := int64(0)
+			for _, utxo := range selected {
+				total += utxo.amount
+			}
+			if fee != total-outAmount-change {
+				t.Errorf("fee = %d, want inputs minus outputs %d", fee, total-outAmount-change)
+			}
+			if minFee := int64(signedTxSize(tx)); fee < minFee {
+				t.Errorf("fee %d is below the signed size %d at 1 satoshi per byte", fee, minFee)
+			}
+		})
+	}
+}
+
+// TestCalcMvcSigHashPreimage the digest of the preimage must be the sighash bsvd signs and verifies
+func TestCalcMvcSigHashPreimage(t *testing.T) {
+	setTestConfig(t)
+	_, _, payerScript := newTestKey(t)
+	_, _, changeScript := newTestKey(t)
+
+	tx := wire2.NewMsgTx(10)
+	opReturn, _ := buildMetaIdScript("create", "/file", "text/plain", []byte("content"))
+	tx.AddTxOut(wire2.NewTxOut(0, opReturn))
+	tx.AddTxOut(wire2.NewTxOut(1000, payerScript))
+	pool := testPayerUtxos(payerScript, 700, 900, 1200)
+	selected, _, err := selectCoins(tx, &pool, changeScript, 1)
+	if err != nil {
+		t.Fatalf("selectCoins() failed, err: %v", err)
+	}
+	tx.TxIn[1].Sequence = 7
+	tx.LockTime = 800000
+
+	inputs, err := sigHashInputs(tx, selected, "payer")
+	if err != nil {
+		t.Fatalf("sigHashInputs() failed, err: %v", err)
+	}
+	if len(inputs) != len(tx.TxIn) {
+		t.Fatalf("got %d inputs, want %d", len(inputs), len(tx.TxIn))
+	}
+	sigHashes := txscript2.NewTxSigHashes(tx)
+	for i, in := range inputs {
+		want, err := txscript2.CalcSignatureHash(payerScript, sigHashes, sigHashType, tx, i, selected[i].amount, true)
+		if err != nil {
+			t.Fatalf("CalcSignatureHash() failed, err: %v", err)
+		}
+		if in.SigHash != hex.EncodeToString(want) {
+			t.Errorf("input %d: sighash = %s, bsvd = %x", i, in.SigHash, want)
+		}
+		preimage, _ := hex.DecodeString(in.SigHashPreimage)
+		if !bytes.Equal(chainhash.DoubleHashB(preimage), want) {
+			t.Errorf("input %d: double SHA256 of the preimage is not the bsvd sighash", i)
+		}
+		if in.SigHashType != 0x41 || in.Index != i || in.Amount != selected[i].amount {
+			t.Errorf("input %d = %+v", i, in)
+		}
+	}
+
+	unsupported := []struct {
+		name     string
+		hashType txscript2.SigHashType
+	}{
+		{"without fork id", txscript2.SigHashAll},
+		{"anyone can pay", txscript2.SigHashAll | txscript2.SigHashForkID | txscript2.SigHashAnyOneCanPay},
+		{"single", txscript2.SigHashSingle | txscript2.SigHashForkID},
+	}
+	for _, tt := range unsupported {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := common.CalcMvcSigHashPreimage(tx, 0, payerScript, 700, tt.hashType); err == nil {
+				t.Errorf("CalcMvcSigHashPreimage() should reject sighash type %#x", uint32(tt.hashType))
+			}
+		})
+	}
+	if _, err := common.CalcMvcSigHashPreimage(tx, len(tx.TxIn), payerScript, 700, sigHashType); err == nil {
+		t.Error("CalcMvcSigHashPreimage() should reject an input index out of range")
+	}
+}
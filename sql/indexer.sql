@@ -2,7 +2,7 @@
 -- MetaID Indexer Database Schema
 -- ============================================
 -- This file contains all table definitions for the Indexer service
--- Tables: tb_indexer_file, tb_indexer_file_chunk, tb_indexer_user_avatar, tb_indexer_sync_status
+-- Tables: tb_indexer_file, tb_indexer_file_chunk, tb_indexer_user_avatar, tb_indexer_sync_status, tb_indexer_blob, tb_indexer_media_metadata, tb_indexer_image_hash, tb_indexer_block, tb_indexer_block_audit
 -- ============================================
 
 -- --------------------------------------------
@@ -21,7 +21,11 @@ CREATE TABLE IF NOT EXISTS `tb_indexer_file` (
     `parent_path` VARCHAR(500) DEFAULT '' COMMENT 'Parent path',
     `encryption` VARCHAR(50) DEFAULT '0' COMMENT 'Encryption method',
     `version` VARCHAR(50) DEFAULT '0' COMMENT 'Version',
-    `content_type` VARCHAR(100) DEFAULT '' COMMENT 'Content type',
+    `content_type` VARCHAR(100) DEFAULT '' COMMENT 'Content type (declared in PIN)',
+    
+    -- Content type detection fields
+    `detected_content_type` VARCHAR(100) DEFAULT '' COMMENT 'Content type detected from file signature',
+    `content_type_mismatch` TINYINT(1) DEFAULT 0 COMMENT 'Detected content contradicts declared type: 0=no, 1=yes',
     
     -- File related fields
     `file_type` VARCHAR(20) DEFAULT '' COMMENT 'File type: image/video/audio/document/text/archive/data/other',
@@ -31,6 +35,11 @@ CREATE TABLE IF NOT EXISTS `tb_indexer_file` (
     `file_md5` VARCHAR(64) DEFAULT '' COMMENT 'File MD5 hash',
     `file_hash` VARCHAR(64) DEFAULT '' COMMENT 'File SHA256 hash',
     
+    -- Image placeholder fields
+    `blur_hash` VARCHAR(64) DEFAULT '' COMMENT 'BlurHash string (images only)',
+    `dominant_color` VARCHAR(7) DEFAULT '' COMMENT 'Dominant color #rrggbb (images only)',
+    `image_decode_failed` TINYINT(1) DEFAULT 0 COMMENT 'Image content failed to decode, placeholder and hash are not computed: 0=no, 1=yes',
+    
     -- Storage related fields
     `storage_type` VARCHAR(20) DEFAULT 'local' COMMENT 'Storage type: local/oss',
     `storage_path` VARCHAR(500) DEFAULT '' COMMENT 'Storage path',
@@ -61,7 +70,8 @@ CREATE TABLE IF NOT EXISTS `tb_indexer_file` (
     KEY `idx_creator_meta_id` (`creator_meta_id`),
     KEY `idx_owner_address` (`owner_address`),
     KEY `idx_chain_name` (`chain_name`),
-    KEY `idx_timestamp` (`timestamp`)
+    KEY `idx_timestamp` (`timestamp`),
+    KEY `idx_content_type_mismatch` (`content_type_mismatch`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer file metadata table';
 
 -- --------------------------------------------
@@ -174,6 +184,148 @@ CREATE TABLE IF NOT EXISTS `tb_indexer_sync_status` (
     UNIQUE KEY `uk_chain_name` (`chain_name`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer synchronization status table';
 
+-- --------------------------------------------
+-- Table: tb_indexer_blob
+-- Description: Stores content-addressed blobs shared by records with the same SHA256 (storage.layout = cas)
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_blob` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    
+    -- Blob information
+    `file_hash` VARCHAR(64) NOT NULL COMMENT 'File SHA256 hash',
+    `storage_path` VARCHAR(500) NOT NULL COMMENT 'Blob storage path',
+    `file_size` BIGINT DEFAULT 0 COMMENT 'File size (bytes)',
+    `ref_count` BIGINT NOT NULL DEFAULT 0 COMMENT 'Number of records referencing this blob',
+    
+    -- Timestamps
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_file_hash` (`file_hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer content-addressed blob table';
+
+-- --------------------------------------------
+-- Table: tb_indexer_media_metadata
+-- Description: Stores image/video/audio metadata extracted at index time (GPS coordinates are never stored)
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_media_metadata` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    
+    -- PIN reference
+    `pin_id` VARCHAR(255) NOT NULL COMMENT 'PIN ID',
+    `file_hash` VARCHAR(64) DEFAULT '' COMMENT 'File SHA256 hash',
+    `media_type` VARCHAR(20) DEFAULT '' COMMENT 'Media type: image/video/audio',
+    `format` VARCHAR(20) DEFAULT '' COMMENT 'Container format: jpeg/png/gif/webp/mp4/mov/webm/mp3/flac/wav',
+    
+    -- Visual
+    `width` INT DEFAULT 0 COMMENT 'Width in pixels',
+    `height` INT DEFAULT 0 COMMENT 'Height in pixels',
+    `orientation` INT DEFAULT 0 COMMENT 'EXIF orientation 1-8 (0 = unknown)',
+    `frame_count` INT DEFAULT 0 COMMENT 'Number of frames (>1 = animated)',
+    
+    -- Timing
+    `duration_ms` BIGINT DEFAULT 0 COMMENT 'Duration in milliseconds',
+    `bitrate` BIGINT DEFAULT 0 COMMENT 'Average bitrate (bits per second)',
+    
+    -- Codecs
+    `video_codec` VARCHAR(50) DEFAULT '' COMMENT 'Video codec',
+    `audio_codec` VARCHAR(50) DEFAULT '' COMMENT 'Audio codec',
+    `sample_rate` INT DEFAULT 0 COMMENT 'Audio sample rate (Hz)',
+    `channels` INT DEFAULT 0 COMMENT 'Audio channels',
+    
+    -- EXIF
+    `camera_make` VARCHAR(100) DEFAULT '' COMMENT 'Camera manufacturer',
+    `camera_model` VARCHAR(100) DEFAULT '' COMMENT 'Camera model',
+    `capture_time` VARCHAR(32) DEFAULT '' COMMENT 'Capture time (EXIF DateTimeOriginal)',
+    `has_gps` TINYINT(1) DEFAULT 0 COMMENT 'GPS data present in source (coordinates not stored)',
+    
+    -- Tags
+    `title` VARCHAR(255) DEFAULT '' COMMENT 'Title tag',
+    `artist` VARCHAR(255) DEFAULT '' COMMENT 'Artist tag',
+    `album` VARCHAR(255) DEFAULT '' COMMENT 'Album tag',
+    
+    -- Timestamps
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_pin_id` (`pin_id`),
+    KEY `idx_file_hash` (`file_hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer media metadata table';
+
+-- --------------------------------------------
+-- Table: tb_indexer_image_hash
+-- Description: Stores perceptual hashes (dHash) of image PINs for near-duplicate lookup
+--              The 64-bit hash is split into four 16-bit bands; two hashes within Hamming
+--              distance d differ by at most d/4 bits in some band, so lookups probe band values.
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_image_hash` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `pin_id` VARCHAR(255) NOT NULL COMMENT 'PIN ID',
+    `file_hash` VARCHAR(64) DEFAULT '' COMMENT 'File SHA256 hash',
+    `hash` VARCHAR(16) NOT NULL COMMENT '64-bit dHash (hex)',
+    
+    -- Lookup bands
+    `band0` INT NOT NULL COMMENT 'Hash bits 63-48',
+    `band1` INT NOT NULL COMMENT 'Hash bits 47-32',
+    `band2` INT NOT NULL COMMENT 'Hash bits 31-16',
+    `band3` INT NOT NULL COMMENT 'Hash bits 15-0',
+    
+    -- Timestamps
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_pin_id` (`pin_id`),
+    KEY `idx_file_hash` (`file_hash`),
+    KEY `idx_band0` (`band0`),
+    KEY `idx_band1` (`band1`),
+    KEY `idx_band2` (`band2`),
+    KEY `idx_band3` (`band3`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer image perceptual hash table';
+
+-- --------------------------------------------
+-- Table: tb_indexer_block
+-- Description: Moderation blocklist. Blocked PINs, content hashes, creator addresses and
+--              creator MetaIDs are not served (HTTP 451) and hidden from list results.
+--              Hash blocks also apply to PINs indexed later with the same content.
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_block` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `block_type` VARCHAR(16) NOT NULL COMMENT 'Block type: pin/hash/address/metaid',
+    `value` VARCHAR(255) NOT NULL COMMENT 'Blocked PIN ID, content SHA256, address or MetaID',
+    `reason` VARCHAR(1024) DEFAULT '' COMMENT 'Reason, e.g. complaint reference',
+    `operator` VARCHAR(255) DEFAULT '' COMMENT 'Who added the entry',
+    
+    -- Timestamps
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_type_value` (`block_type`, `value`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer moderation blocklist table';
+
+-- --------------------------------------------
+-- Table: tb_indexer_block_audit
+-- Description: Append-only moderation audit log (block, unblock and hash match events)
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_block_audit` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `block_id` BIGINT NOT NULL COMMENT 'Blocklist entry ID',
+    `action` VARCHAR(16) NOT NULL COMMENT 'Action: block/unblock/match',
+    `block_type` VARCHAR(16) NOT NULL COMMENT 'Block type: pin/hash/address/metaid',
+    `value` VARCHAR(255) NOT NULL COMMENT 'Blocked PIN ID, content SHA256, address or MetaID',
+    `pin_id` VARCHAR(255) DEFAULT '' COMMENT 'PIN a hash block was applied to (match only)',
+    `reason` VARCHAR(1024) DEFAULT '' COMMENT 'Reason given for the action',
+    `operator` VARCHAR(255) DEFAULT '' COMMENT 'Who performed the action (indexer for matches)',
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Action time',
+    
+    PRIMARY KEY (`id`),
+    KEY `idx_block_id` (`block_id`),
+    KEY `idx_pin_id` (`pin_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer moderation audit log table';
+
 -- --------------------------------------------
 -- Initialize default sync status records
 -- --------------------------------------------
@@ -188,6 +340,23 @@ INSERT INTO `tb_indexer_sync_status` (`chain_name`, `current_sync_height`)
 VALUES ('btc', 0)
 ON DUPLICATE KEY UPDATE `chain_name` = `chain_name`;
 
+-- --------------------------------------------
+-- Migrations for existing databases
+-- --------------------------------------------
+
+-- Image placeholder columns (run once on tables created before they were added,
+-- then fill existing rows with: storagectl -cmd backfill)
+-- ALTER TABLE tb_indexer_file ADD COLUMN `blur_hash` VARCHAR(64) DEFAULT '' COMMENT 'BlurHash string (images only)' AFTER `file_hash`;
+-- ALTER TABLE tb_indexer_file ADD COLUMN `dominant_color` VARCHAR(7) DEFAULT '' COMMENT 'Dominant color #rrggbb (images only)' AFTER `blur_hash`;
+
+-- Content type detection columns (run once, then fill existing rows with: storagectl -cmd backfill)
+-- ALTER TABLE tb_indexer_file ADD COLUMN `detected_content_type` VARCHAR(100) DEFAULT '' COMMENT 'Content type detected from file signature' AFTER `content_type`;
+-- ALTER TABLE tb_indexer_file ADD COLUMN `content_type_mismatch` TINYINT(1) DEFAULT 0 COMMENT 'Detected content contradicts declared type: 0=no, 1=yes' AFTER `detected_content_type`;
+-- ALTER TABLE tb_indexer_file ADD INDEX idx_content_type_mismatch (content_type_mismatch);
+
+-- Undecodable image marker (run once, backfill then skips images that failed to decode)
+-- ALTER TABLE tb_indexer_file ADD COLUMN `image_decode_failed` TINYINT(1) DEFAULT 0 COMMENT 'Image content failed to decode, placeholder and hash are not computed: 0=no, 1=yes' AFTER `dominant_color`;
+
 -- ============================================
 -- End of Indexer Database Schema
 -- ============================================
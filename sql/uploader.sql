@@ -31,6 +31,7 @@ CREATE TABLE IF NOT EXISTS `tb_file` (
     `path` VARCHAR(191) NOT NULL COMMENT 'MetaID path',
     `content_type` VARCHAR(100) DEFAULT NULL COMMENT 'Content type',
     `operation` VARCHAR(20) DEFAULT NULL COMMENT 'Operation type (create/modify/revoke)',
+    `chain` VARCHAR(10) NOT NULL DEFAULT 'mvc' COMMENT 'Chain the file is inscribed on (mvc/btc)',
     
     -- Storage information
     `storage_type` VARCHAR(20) DEFAULT NULL COMMENT 'Storage type (local/oss)',
@@ -39,7 +40,11 @@ CREATE TABLE IF NOT EXISTS `tb_file` (
     -- Transaction data
     `pre_tx_raw` TEXT COMMENT 'Pre-transaction raw data',
     `tx_raw` TEXT COMMENT 'Transaction raw data',
+    `commit_tx_id` VARCHAR(64) DEFAULT NULL COMMENT 'BTC commit transaction ID',
+    `reveal_tx_raw` MEDIUMTEXT COMMENT 'BTC signed reveal transaction (hex)',
+    `reveal_key` VARCHAR(255) DEFAULT NULL COMMENT 'BTC one-time reveal key (encrypted), kept to sign the reveal again',
     `status` VARCHAR(20) DEFAULT NULL COMMENT 'Status (pending/success/failed)',
+    `tx_state` VARCHAR(20) DEFAULT NULL COMMENT 'Broadcast state of the file transaction (built/signed/broadcast/in_mempool/confirmed/failed/replaced)',
     
     -- Block information
     `block_height` BIGINT DEFAULT NULL COMMENT 'Block height',
@@ -55,6 +60,7 @@ CREATE TABLE IF NOT EXISTS `tb_file` (
     KEY `idx_meta_id` (`meta_id`),
     KEY `idx_address` (`address`),
     KEY `idx_status` (`status`),
+    KEY `idx_tx_state` (`tx_state`),
     KEY `idx_created_at` (`created_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='File metadata table';
 
@@ -63,6 +69,7 @@ CREATE TABLE IF NOT EXISTS `tb_file` (
 -- =============================================
 CREATE TABLE IF NOT EXISTS `tb_file_chunk` (
     `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `file_id` VARCHAR(150) DEFAULT NULL COMMENT 'Belonging file ID (metaid_fileHash)',
     
     -- Chunk information
     `chunk_hash` VARCHAR(80) DEFAULT NULL COMMENT 'Chunk hash',
@@ -75,7 +82,7 @@ CREATE TABLE IF NOT EXISTS `tb_file_chunk` (
     `content_hex` TEXT COMMENT 'Content hexadecimal',
     
     -- Transaction information
-    `tx_id` VARCHAR(64) NOT NULL COMMENT 'On-chain transaction ID',
+    `tx_id` VARCHAR(64) NOT NULL COMMENT 'On-chain transaction ID (placeholder until committed)',
     `pin_id` VARCHAR(80) NOT NULL COMMENT 'Pin ID',
     `path` VARCHAR(191) NOT NULL COMMENT 'MetaID path',
     `content_type` VARCHAR(100) DEFAULT NULL COMMENT 'Content type',
@@ -100,6 +107,7 @@ CREATE TABLE IF NOT EXISTS `tb_file_chunk` (
     
     PRIMARY KEY (`id`),
     UNIQUE KEY `uk_tx_id` (`tx_id`),
+    KEY `idx_file_id` (`file_id`),
     KEY `idx_pin_id` (`pin_id`),
     KEY `idx_file_hash` (`file_hash`),
     KEY `idx_chunk_index` (`chunk_index`),
@@ -117,9 +125,15 @@ CREATE TABLE IF NOT EXISTS `tb_assistant` (
     `address` VARCHAR(80) NOT NULL COMMENT 'Address',
     
     -- Assistant information
-    `assistant_private_key` VARCHAR(80) NOT NULL COMMENT 'Assistant private key',
+    `assistant_private_key` VARCHAR(80) NOT NULL COMMENT 'Assistant private key (AES-GCM encrypted with the master key)',
     `assistant_address` VARCHAR(80) NOT NULL COMMENT 'Assistant address',
     `assistant_meta_id` VARCHAR(80) NOT NULL COMMENT 'Assistant MetaID',
+    `prev_private_key` VARCHAR(80) DEFAULT NULL COMMENT 'Key replaced by a rotation (encrypted), kept until its balance is swept',
+    `prev_address` VARCHAR(80) DEFAULT NULL COMMENT 'Address of the replaced key',
+    `spend_limit` BIGINT NOT NULL DEFAULT 0 COMMENT 'Spend limit (satoshis)',
+    `spent_amount` BIGINT NOT NULL DEFAULT 0 COMMENT 'Spent amount (satoshis)',
+    `token_hash` VARCHAR(64) DEFAULT NULL COMMENT 'SHA256 of the access token',
+    `auth_timestamp` BIGINT NOT NULL DEFAULT 0 COMMENT 'Timestamp of the last owner authorization',
     
     -- Timestamps
     `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
@@ -133,6 +147,146 @@ CREATE TABLE IF NOT EXISTS `tb_assistant` (
     KEY `idx_assistant_meta_id` (`assistant_meta_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Assistant table';
 
+-- =============================================
+-- Resumable upload table (tb_tus_upload)
+-- =============================================
+CREATE TABLE IF NOT EXISTS `tb_tus_upload` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    
+    -- Upload information
+    `upload_id` VARCHAR(64) NOT NULL COMMENT 'Upload ID used in the upload URL',
+    `upload_length` BIGINT DEFAULT NULL COMMENT 'Total size (bytes)',
+    `upload_offset` BIGINT DEFAULT NULL COMMENT 'Bytes received so far',
+    `parts` TEXT COMMENT 'JSON array of staged part start offsets',
+    `metadata` TEXT COMMENT 'Raw Upload-Metadata header',
+    `file_name` VARCHAR(255) DEFAULT NULL COMMENT 'File name from metadata',
+    `content_type` VARCHAR(100) DEFAULT NULL COMMENT 'Content type from metadata',
+    
+    -- Timestamps
+    `expires_at` DATETIME(3) DEFAULT NULL COMMENT 'Staged bytes are deleted after this time',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_upload_id` (`upload_id`),
+    KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Resumable upload table';
+
+-- =============================================
+-- Payer UTXO reservation table (tb_utxo_reservation)
+-- =============================================
+CREATE TABLE IF NOT EXISTS `tb_utxo_reservation` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    
+    -- Outpoint information
+    `tx_id` VARCHAR(64) NOT NULL COMMENT 'Transaction ID of the output',
+    `vout` INT UNSIGNED NOT NULL COMMENT 'Output index',
+    `address` VARCHAR(100) DEFAULT NULL COMMENT 'Payer address',
+    `amount` BIGINT DEFAULT NULL COMMENT 'Output amount (satoshis)',
+    `script_pub_key` VARCHAR(255) DEFAULT NULL COMMENT 'Output locking script (hex)',
+    
+    -- Reserving transaction
+    `file_id` VARCHAR(255) DEFAULT NULL COMMENT 'File the reserving transaction belongs to',
+    `tx_index` BIGINT DEFAULT NULL COMMENT 'Chunk index, -1 for the file (or index) transaction',
+    
+    -- Timestamps
+    `expires_at` DATETIME(3) DEFAULT NULL COMMENT 'Reservation is ignored after this time',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_outpoint` (`tx_id`, `vout`),
+    KEY `idx_file_id` (`file_id`),
+    KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Payer UTXO reservation table';
+
+-- =============================================
+-- Sponsored upload table (tb_sponsored_upload)
+-- =============================================
+CREATE TABLE IF NOT EXISTS `tb_sponsored_upload` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    
+    -- Upload information
+    `file_id` VARCHAR(255) DEFAULT NULL COMMENT 'File ID',
+    `meta_id` VARCHAR(64) DEFAULT NULL COMMENT 'MetaID of the user',
+    `address` VARCHAR(100) DEFAULT NULL COMMENT 'User address, owner of the PINs',
+    `day` VARCHAR(10) DEFAULT NULL COMMENT 'Budget day (UTC, 2006-01-02)',
+    `content_type` VARCHAR(100) DEFAULT NULL COMMENT 'Content type',
+    `file_size` BIGINT DEFAULT NULL COMMENT 'File size charged to the byte budget',
+    
+    -- Cost accounting
+    `tx_count` BIGINT DEFAULT NULL COMMENT 'Transactions paid (chunks and index)',
+    `fee` BIGINT DEFAULT NULL COMMENT 'Satoshis paid: fees and owner outputs',
+    `tx_id` VARCHAR(64) DEFAULT NULL COMMENT 'File (or index) transaction ID',
+    `pin_id` VARCHAR(100) DEFAULT NULL COMMENT 'File Pin ID',
+    `status` VARCHAR(20) DEFAULT NULL COMMENT 'Status (pending/success/failed)',
+    `message` VARCHAR(500) DEFAULT NULL COMMENT 'Failure reason',
+    
+    -- Timestamps
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    KEY `idx_file_id` (`file_id`),
+    KEY `idx_meta_day` (`meta_id`, `day`),
+    KEY `idx_day` (`day`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Sponsored upload table';
+
+-- =============================================
+-- Sponsor budget table (tb_sponsor_budget)
+-- =============================================
+CREATE TABLE IF NOT EXISTS `tb_sponsor_budget` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `meta_id` VARCHAR(64) NOT NULL COMMENT 'MetaID of the user',
+    `day` VARCHAR(10) NOT NULL COMMENT 'Budget day (UTC, 2006-01-02)',
+    `bytes` BIGINT DEFAULT 0 COMMENT 'File bytes sponsored',
+    `fee` BIGINT DEFAULT 0 COMMENT 'Satoshis spent',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_meta_day` (`meta_id`, `day`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Sponsor budget table';
+
+-- =============================================
+-- Upload transaction table (tb_file_tx)
+-- =============================================
+CREATE TABLE IF NOT EXISTS `tb_file_tx` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `file_id` VARCHAR(150) DEFAULT NULL COMMENT 'Belonging file ID (metaid_fileHash)',
+    `tx_id` VARCHAR(64) NOT NULL COMMENT 'Transaction ID',
+    `chain` VARCHAR(10) NOT NULL DEFAULT 'mvc' COMMENT 'Chain (mvc/btc)',
+    `role` VARCHAR(20) DEFAULT NULL COMMENT 'Role (file/chunk/merge/commit)',
+    `chunk_index` BIGINT DEFAULT -1 COMMENT 'Chunk index, -1 unless the role is chunk',
+    `tx_raw` MEDIUMTEXT COMMENT 'Signed transaction (hex), kept for rebroadcast',
+    `state` VARCHAR(20) DEFAULT NULL COMMENT 'State (broadcast/in_mempool/confirmed/failed/replaced)',
+    `block_height` BIGINT DEFAULT 0 COMMENT 'Block height, once confirmed',
+    `rebroadcasts` BIGINT DEFAULT 0 COMMENT 'Times the tracker broadcast it again',
+    `message` VARCHAR(500) DEFAULT NULL COMMENT 'Last broadcast error',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_tx_id` (`tx_id`),
+    KEY `idx_file_id` (`file_id`),
+    KEY `idx_state` (`state`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Upload transaction table';
+
+-- =============================================
+-- Upload transaction history table (tb_file_tx_event)
+-- =============================================
+CREATE TABLE IF NOT EXISTS `tb_file_tx_event` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `file_id` VARCHAR(150) DEFAULT NULL COMMENT 'Belonging file ID (metaid_fileHash)',
+    `tx_id` VARCHAR(64) DEFAULT NULL COMMENT 'Transaction ID, empty while it is not signed',
+    `state` VARCHAR(20) DEFAULT NULL COMMENT 'State entered',
+    `block_height` BIGINT DEFAULT 0 COMMENT 'Block height (confirmed)',
+    `message` VARCHAR(500) DEFAULT NULL COMMENT 'Detail, e.g. the broadcast error',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    
+    PRIMARY KEY (`id`),
+    KEY `idx_file_id` (`file_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Upload transaction history table';
+
 -- =============================================
 -- Index notes
 -- =============================================
@@ -156,3 +310,24 @@ CREATE TABLE IF NOT EXISTS `tb_assistant` (
 -- query by path and status
 -- ALTER TABLE tb_file ADD INDEX idx_path_status (path, status);
 
+-- =============================================
+-- Migrations for existing databases
+-- =============================================
+-- Chunked upload file reference (run once on tables created before it was added)
+-- ALTER TABLE tb_file_chunk ADD COLUMN `file_id` VARCHAR(150) DEFAULT NULL COMMENT 'Belonging file ID (metaid_fileHash)' AFTER `id`;
+-- ALTER TABLE tb_file_chunk ADD INDEX idx_file_id (file_id);
+-- Assistant spend limits and access tokens
+-- ALTER TABLE tb_assistant ADD COLUMN `spend_limit` BIGINT NOT NULL DEFAULT 0 COMMENT 'Spend limit (satoshis)' AFTER `assistant_meta_id`;
+-- ALTER TABLE tb_assistant ADD COLUMN `spent_amount` BIGINT NOT NULL DEFAULT 0 COMMENT 'Spent amount (satoshis)' AFTER `spend_limit`;
+-- ALTER TABLE tb_assistant ADD COLUMN `token_hash` VARCHAR(64) DEFAULT NULL COMMENT 'SHA256 of the access token' AFTER `spent_amount`;
+-- ALTER TABLE tb_assistant ADD COLUMN `auth_timestamp` BIGINT NOT NULL DEFAULT 0 COMMENT 'Timestamp of the last owner authorization' AFTER `token_hash`;
+-- ALTER TABLE tb_assistant ADD COLUMN `prev_private_key` VARCHAR(80) DEFAULT NULL COMMENT 'Key replaced by a rotation (encrypted), kept until its balance is swept' AFTER `assistant_meta_id`;
+-- ALTER TABLE tb_assistant ADD COLUMN `prev_address` VARCHAR(80) DEFAULT NULL COMMENT 'Address of the replaced key' AFTER `prev_private_key`;
+-- BTC inscription uploads
+-- ALTER TABLE tb_file ADD COLUMN `chain` VARCHAR(10) NOT NULL DEFAULT 'mvc' COMMENT 'Chain the file is inscribed on (mvc/btc)' AFTER `operation`;
+-- ALTER TABLE tb_file ADD COLUMN `commit_tx_id` VARCHAR(64) DEFAULT NULL COMMENT 'BTC commit transaction ID' AFTER `tx_raw`;
+-- ALTER TABLE tb_file ADD COLUMN `reveal_tx_raw` MEDIUMTEXT COMMENT 'BTC signed reveal transaction (hex)' AFTER `commit_tx_id`;
+-- ALTER TABLE tb_file ADD COLUMN `reveal_key` VARCHAR(255) DEFAULT NULL COMMENT 'BTC one-time reveal key (encrypted), kept to sign the reveal again' AFTER `reveal_tx_raw`;
+-- Broadcast state tracking
+-- ALTER TABLE tb_file ADD COLUMN `tx_state` VARCHAR(20) DEFAULT NULL COMMENT 'Broadcast state of the file transaction' AFTER `status`;
+-- ALTER TABLE tb_file ADD INDEX idx_tx_state (tx_state);
@@ -0,0 +1,418 @@
+package storage
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CacheStorage local disk LRU cache in front of a (remote) backing storage
+// Reads are served from disk when possible, concurrent misses for the same key
+// are collapsed into one backend fetch, and writes go through to the backend.
+// Streaming and presigned URLs are passed through to the backend, so they keep working with the cache enabled.
+type CacheStorage struct {
+	backend  Storage
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	lru   *list.List               // Front is most recently used
+	items map[string]*list.Element // Cache file name -> LRU element
+	size  int64                    // Total bytes cached
+
+	group    singleflight.Group
+	fetching map[string]bool // Cache file name -> stale, for keys being fetched from backend
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+// cacheEntry cached object
+type cacheEntry struct {
+	name string
+	size int64
+}
+
+// CacheStats cache statistics
+type CacheStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Entries   int64
+	Bytes     int64
+	MaxBytes  int64
+}
+
+// NewCacheStorage create cache storage instance
+// maxBytes: cache size budget, least recently used objects are evicted beyond it
+func NewCacheStorage(backend Storage, dir string, maxBytes int64) (*CacheStorage, error) {
+	if backend == nil {
+		return nil, ErrInvalid
+	}
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("%w: cache size must be positive", ErrInvalid)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	s := &CacheStorage{
+		backend:  backend,
+		dir:      dir,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		items:    make(map[string]*list.Element),
+		fetching: make(map[string]bool),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// load rebuild LRU state from files left by a previous run (oldest first)
+func (s *CacheStorage) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	type cachedFile struct {
+		name    string
+		size    int64
+		modTime int64
+	}
+	files := make([]cachedFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		// Remove partially written files
+		if filepath.Ext(entry.Name()) == ".tmp" {
+			os.Remove(filepath.Join(s.dir, entry.Name()))
+			continue
+		}
+		files = append(files, cachedFile{name: entry.Name(), size: info.Size(), modTime: info.ModTime().UnixNano()})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime < files[j].modTime
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range files {
+		s.items[f.name] = s.lru.PushFront(&cacheEntry{name: f.name, size: f.size})
+		s.size += f.size
+	}
+	s.evictLocked()
+
+	return nil
+}
+
+// Save save file to backend and cache it
+func (s *CacheStorage) Save(key string, data []byte) error {
+	if err := s.backend.Save(key, data); err != nil {
+		return err
+	}
+	s.put(key, data, false)
+	return nil
+}
+
+// Get get file from cache, fetching it from backend on miss
+func (s *CacheStorage) Get(key string) ([]byte, error) {
+	if data, ok := s.get(key); ok {
+		s.hits.Add(1)
+		return data, nil
+	}
+	s.misses.Add(1)
+
+	v, err, _ := s.group.Do(key, func() (interface{}, error) {
+		name := cacheFileName(key)
+		s.mu.Lock()
+		s.fetching[name] = false
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			delete(s.fetching, name)
+			s.mu.Unlock()
+		}()
+
+		data, err := s.backend.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		s.put(key, data, true)
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]byte), nil
+}
+
+// SaveReader save file from reader to backend, dropping any cached copy
+// Streamed objects are usually large, so they are not written to the cache.
+func (s *CacheStorage) SaveReader(key string, reader io.Reader, size int64) error {
+	streamStorage, ok := s.backend.(StreamStorage)
+	if !ok {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read content: %w", err)
+		}
+		return s.Save(key, data)
+	}
+
+	err := streamStorage.SaveReader(key, reader, size)
+	s.remove(key)
+	return err
+}
+
+// GetReader get file reader from cache, or stream it from backend on miss without caching it
+// Caller must close the reader.
+func (s *CacheStorage) GetReader(key string) (io.ReadCloser, int64, error) {
+	name := cacheFileName(key)
+
+	s.mu.Lock()
+	elem, ok := s.items[name]
+	if ok {
+		s.lru.MoveToFront(elem)
+	}
+	s.mu.Unlock()
+	if ok {
+		if f, err := os.Open(filepath.Join(s.dir, name)); err == nil {
+			if info, err := f.Stat(); err == nil {
+				s.hits.Add(1)
+				return f, info.Size(), nil
+			}
+			f.Close()
+		}
+		// File evicted concurrently or removed externally
+		s.remove(key)
+	}
+	s.misses.Add(1)
+
+	streamStorage, ok := s.backend.(StreamStorage)
+	if !ok {
+		data, err := s.backend.Get(key)
+		if err != nil {
+			return nil, 0, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+	}
+	return streamStorage.GetReader(key)
+}
+
+// PresignedURL get presigned download URL from backend (requires backend to implement URLSigner)
+func (s *CacheStorage) PresignedURL(key string, expires time.Duration) (string, error) {
+	signer, ok := s.backend.(URLSigner)
+	if !ok {
+		return "", fmt.Errorf("%w: backend storage does not support presigned URLs", ErrInvalid)
+	}
+	return signer.PresignedURL(key, expires)
+}
+
+// Delete delete file from backend and cache
+// The cache is cleared after the backend, and a fetch still in flight does not cache the old content.
+func (s *CacheStorage) Delete(key string) error {
+	err := s.backend.Delete(key)
+	s.group.Forget(key)
+	s.remove(key)
+	return err
+}
+
+// Exists check if file exists
+func (s *CacheStorage) Exists(key string) bool {
+	s.mu.Lock()
+	_, ok := s.items[cacheFileName(key)]
+	s.mu.Unlock()
+	if ok {
+		return true
+	}
+	return s.backend.Exists(key)
+}
+
+// List list keys from backend (requires backend to implement Lister)
+func (s *CacheStorage) List(prefix string, fn func(key string) error) error {
+	lister, ok := s.backend.(Lister)
+	if !ok {
+		return fmt.Errorf("%w: backend storage does not support listing", ErrInvalid)
+	}
+	return lister.List(prefix, fn)
+}
+
+// Stats get cache statistics
+func (s *CacheStorage) Stats() CacheStats {
+	s.mu.Lock()
+	entries := int64(s.lru.Len())
+	size := s.size
+	s.mu.Unlock()
+
+	return CacheStats{
+		Hits:      s.hits.Load(),
+		Misses:    s.misses.Load(),
+		Evictions: s.evictions.Load(),
+		Entries:   entries,
+		Bytes:     size,
+		MaxBytes:  s.maxBytes,
+	}
+}
+
+// get read cached file and mark it as recently used
+func (s *CacheStorage) get(key string) ([]byte, bool) {
+	name := cacheFileName(key)
+
+	s.mu.Lock()
+	elem, ok := s.items[name]
+	if ok {
+		s.lru.MoveToFront(elem)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		// File evicted concurrently or removed externally
+		s.remove(key)
+		return nil, false
+	}
+
+	return data, true
+}
+
+// put write file to cache, evicting least recently used files beyond budget
+// Cache write failures are not fatal, the backend remains the source of truth.
+// fetched: data was read from backend, it is dropped if the key was saved or deleted meanwhile.
+func (s *CacheStorage) put(key string, data []byte, fetched bool) {
+	name := cacheFileName(key)
+	filePath := filepath.Join(s.dir, name)
+
+	size := int64(len(data))
+	if size > s.maxBytes {
+		// An older cached copy must not outlive a save
+		s.remove(key)
+		return
+	}
+
+	// Write to temporary file first so readers never see partial content
+	tmp, err := os.CreateTemp(s.dir, name+"-*.tmp")
+	if err != nil {
+		s.remove(key)
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		s.remove(key)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stale, ok := s.fetching[name]; ok {
+		if fetched && stale {
+			// Saved or deleted while fetching, the fetched content is outdated
+			os.Remove(tmp.Name())
+			return
+		}
+		if !fetched {
+			s.fetching[name] = true
+		}
+	}
+
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		os.Remove(tmp.Name())
+		if elem, ok := s.items[name]; ok {
+			s.removeElementLocked(elem)
+		}
+		return
+	}
+
+	if elem, ok := s.items[name]; ok {
+		entry := elem.Value.(*cacheEntry)
+		s.size += size - entry.size
+		entry.size = size
+		s.lru.MoveToFront(elem)
+	} else {
+		s.items[name] = s.lru.PushFront(&cacheEntry{name: name, size: size})
+		s.size += size
+	}
+	s.evictLocked()
+}
+
+// remove remove file from cache, a fetch of the key in flight is marked stale
+func (s *CacheStorage) remove(key string) {
+	name := cacheFileName(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.fetching[name]; ok {
+		s.fetching[name] = true
+	}
+	if elem, ok := s.items[name]; ok {
+		s.removeElementLocked(elem)
+	}
+}
+
+// evictLocked evict least recently used files until cache fits in budget
+func (s *CacheStorage) evictLocked() {
+	for s.size > s.maxBytes {
+		elem := s.lru.Back()
+		if elem == nil {
+			return
+		}
+		s.removeElementLocked(elem)
+		s.evictions.Add(1)
+	}
+}
+
+// removeElementLocked remove LRU element and its file
+func (s *CacheStorage) removeElementLocked(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	s.lru.Remove(elem)
+	delete(s.items, entry.name)
+	s.size -= entry.size
+	os.Remove(filepath.Join(s.dir, entry.name))
+}
+
+// Unwrap get the backing storage of a cache storage, or the storage itself
+// Useful when reads must bypass the cache (e.g. integrity checks).
+func Unwrap(s Storage) Storage {
+	if cacheStorage, ok := s.(*CacheStorage); ok {
+		return cacheStorage.backend
+	}
+	return s
+}
+
+// cacheFileName map storage key to a flat cache file name
+func cacheFileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,311 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryStorage in-memory backend that counts Get calls
+type memoryStorage struct {
+	mu    sync.Mutex
+	data  map[string][]byte
+	gets  atomic.Int64
+	delay time.Duration
+	hold  chan struct{} // When set, Get signals after reading and waits to be released
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{data: make(map[string][]byte)}
+}
+
+func (m *memoryStorage) Save(key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *memoryStorage) Get(key string) ([]byte, error) {
+	m.gets.Add(1)
+	time.Sleep(m.delay)
+	m.mu.Lock()
+	data, ok := m.data[key]
+	m.mu.Unlock()
+	if hold := m.hold; hold != nil {
+		hold <- struct{}{}
+		<-hold
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+func (m *memoryStorage) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memoryStorage) Exists(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.data[key]
+	return ok
+}
+
+// streamMemoryStorage in-memory backend that also streams and signs URLs, counting streamed reads
+type streamMemoryStorage struct {
+	*memoryStorage
+	readers atomic.Int64
+}
+
+func (m *streamMemoryStorage) SaveReader(key string, reader io.Reader, size int64) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	return m.Save(key, data)
+}
+
+func (m *streamMemoryStorage) GetReader(key string) (io.ReadCloser, int64, error) {
+	m.readers.Add(1)
+	m.mu.Lock()
+	data, ok := m.data[key]
+	m.mu.Unlock()
+	if !ok {
+		return nil, 0, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
+
+func (m *streamMemoryStorage) PresignedURL(key string, expires time.Duration) (string, error) {
+	return "https://bucket.example.com/" + key, nil
+}
+
+func TestCacheStorageHitMissAndWriteThrough(t *testing.T) {
+	backend := newMemoryStorage()
+	backend.Save("a", []byte("aaaa"))
+
+	cache, err := NewCacheStorage(backend, t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("NewCacheStorage: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		data, err := cache.Get("a")
+		if err != nil || !bytes.Equal(data, []byte("aaaa")) {
+			t.Fatalf("Get: %q, %v", data, err)
+		}
+	}
+	if got := backend.gets.Load(); got != 1 {
+		t.Fatalf("backend gets = %d, want 1", got)
+	}
+
+	if err := cache.Save("b", []byte("bb")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if !backend.Exists("b") {
+		t.Fatal("Save did not write through to backend")
+	}
+	if _, err := cache.Get("b"); err != nil {
+		t.Fatalf("Get after Save: %v", err)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 3 || stats.Misses != 1 {
+		t.Fatalf("stats = %+v, want 3 hits and 1 miss", stats)
+	}
+
+	if err := cache.Delete("b"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if cache.Exists("b") {
+		t.Fatal("Exists after Delete")
+	}
+}
+
+func TestCacheStorageEvictsLeastRecentlyUsed(t *testing.T) {
+	backend := newMemoryStorage()
+	cache, err := NewCacheStorage(backend, t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("NewCacheStorage: %v", err)
+	}
+
+	cache.Save("a", []byte("aaaa"))
+	cache.Save("b", []byte("bbbb"))
+	cache.Get("a") // a is now most recently used
+	cache.Save("c", []byte("cccc"))
+
+	stats := cache.Stats()
+	if stats.Bytes > 10 || stats.Evictions != 1 {
+		t.Fatalf("stats = %+v, want <= 10 bytes and 1 eviction", stats)
+	}
+
+	backend.gets.Store(0)
+	cache.Get("a")
+	cache.Get("c")
+	if got := backend.gets.Load(); got != 0 {
+		t.Fatalf("recently used keys were evicted (backend gets = %d)", got)
+	}
+	cache.Get("b")
+	if got := backend.gets.Load(); got != 1 {
+		t.Fatalf("least recently used key was not evicted (backend gets = %d)", got)
+	}
+}
+
+func TestCacheStorageCollapsesConcurrentMisses(t *testing.T) {
+	backend := newMemoryStorage()
+	backend.delay = 50 * time.Millisecond
+	backend.Save("a", []byte("aaaa"))
+
+	cache, err := NewCacheStorage(backend, t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("NewCacheStorage: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.Get("a"); err != nil {
+				t.Errorf("Get: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := backend.gets.Load(); got != 1 {
+		t.Fatalf("backend gets = %d, want 1", got)
+	}
+}
+
+func TestCacheStorageReloadsExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	backend := newMemoryStorage()
+
+	cache, err := NewCacheStorage(backend, dir, 1024)
+	if err != nil {
+		t.Fatalf("NewCacheStorage: %v", err)
+	}
+	cache.Save("a", []byte("aaaa"))
+
+	reloaded, err := NewCacheStorage(backend, dir, 1024)
+	if err != nil {
+		t.Fatalf("NewCacheStorage: %v", err)
+	}
+	if stats := reloaded.Stats(); stats.Entries != 1 || stats.Bytes != 4 {
+		t.Fatalf("stats = %+v, want 1 entry of 4 bytes", stats)
+	}
+}
+
+func TestCacheStorageDropsFetchOutdatedByWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		write   func(cache *CacheStorage) error
+		want    []byte // Content after the write, nil if deleted
+		wantErr error
+	}{
+		{"delete", func(cache *CacheStorage) error { return cache.Delete("a") }, nil, ErrNotFound},
+		{"save", func(cache *CacheStorage) error { return cache.Save("a", []byte("new")) }, []byte("new"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newMemoryStorage()
+			backend.Save("a", []byte("old"))
+			cache, err := NewCacheStorage(backend, t.TempDir(), 1024)
+			if err != nil {
+				t.Fatalf("NewCacheStorage: %v", err)
+			}
+
+			// Fetch the old content and hold it until the write is done
+			hold := make(chan struct{})
+			backend.hold = hold
+			done := make(chan []byte)
+			go func() {
+				data, _ := cache.Get("a")
+				done <- data
+			}()
+			<-hold
+			backend.hold = nil
+			if err := tt.write(cache); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			hold <- struct{}{}
+			if got := <-done; !bytes.Equal(got, []byte("old")) {
+				t.Fatalf("fetch in flight returned %q, want the old content", got)
+			}
+
+			got, err := cache.Get("a")
+			if err != tt.wantErr || !bytes.Equal(got, tt.want) {
+				t.Errorf("Get after write = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCacheStoragePassesThroughStreamingAndURLs(t *testing.T) {
+	backend := &streamMemoryStorage{memoryStorage: newMemoryStorage()}
+	backend.Save("a", []byte("aaaa"))
+	cache, err := NewCacheStorage(backend, t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("NewCacheStorage: %v", err)
+	}
+
+	readAll := func(key string) []byte {
+		t.Helper()
+		reader, size, err := cache.GetReader(key)
+		if err != nil {
+			t.Fatalf("GetReader: %v", err)
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil || int64(len(data)) != size {
+			t.Fatalf("read %q of size %d, err: %v", data, size, err)
+		}
+		return data
+	}
+
+	// Misses stream from the backend without filling the cache
+	if got := readAll("a"); !bytes.Equal(got, []byte("aaaa")) {
+		t.Fatalf("GetReader = %q", got)
+	}
+	if _, err := cache.Get("a"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := backend.gets.Load(); got != 1 {
+		t.Fatalf("backend gets = %d, want 1", got)
+	}
+	// Cached objects are read from disk
+	readAll("a")
+	if got := backend.readers.Load(); got != 1 {
+		t.Errorf("backend streamed reads = %d, want 1", got)
+	}
+
+	// A streamed save replaces the cached copy
+	if err := cache.SaveReader("a", bytes.NewReader([]byte("new")), 3); err != nil {
+		t.Fatalf("SaveReader: %v", err)
+	}
+	if got, err := cache.Get("a"); err != nil || !bytes.Equal(got, []byte("new")) {
+		t.Errorf("Get after SaveReader = %q, %v", got, err)
+	}
+
+	if url, err := cache.PresignedURL("a", time.Minute); err != nil || url != "https://bucket.example.com/a" {
+		t.Errorf("PresignedURL = %q, %v", url, err)
+	}
+
+	plain, err := NewCacheStorage(newMemoryStorage(), t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("NewCacheStorage: %v", err)
+	}
+	if _, err := plain.PresignedURL("a", time.Minute); !errors.Is(err, ErrInvalid) {
+		t.Errorf("PresignedURL without signing backend err = %v, want %v", err, ErrInvalid)
+	}
+}
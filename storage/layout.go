@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Storage layouts
+const (
+	LayoutPath             = "path" // One object per PIN: indexer/{chain}/{pinid}{ext}
+	LayoutContentAddressed = "cas"  // One object per content hash: blobs/sha256/{aa}/{bb}/{hash}
+)
+
+// contentAddressedPrefix key prefix of content-addressed blobs
+const contentAddressedPrefix = "blobs/sha256/"
+
+// ContentAddressedKey build storage key for content-addressed blob from SHA256 hex hash
+func ContentAddressedKey(fileHash string) string {
+	fileHash = strings.ToLower(fileHash)
+	if len(fileHash) < 4 {
+		return contentAddressedPrefix + fileHash
+	}
+	return fmt.Sprintf("%s%s/%s/%s", contentAddressedPrefix, fileHash[0:2], fileHash[2:4], fileHash)
+}
+
+// IsContentAddressedKey check if storage key points at a content-addressed blob
+func IsContentAddressedKey(key string) bool {
+	return strings.HasPrefix(key, contentAddressedPrefix)
+}
@@ -1,10 +1,21 @@
 package storage
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
+)
+
+const (
+	// localStagingDir directory under the base path for files being written, never listed
+	localStagingDir = ".staging"
+	// localStagingMaxAge staging files older than this are left over from a crash
+	localStagingMaxAge = 24 * time.Hour
 )
 
 // LocalStorage local file system storage
@@ -22,14 +33,59 @@ func NewLocalStorage(basePath string) (*LocalStorage, error) {
 	if err := os.MkdirAll(basePath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create base path: %w", err)
 	}
+	if err := os.MkdirAll(filepath.Join(basePath, localStagingDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging path: %w", err)
+	}
 
-	return &LocalStorage{
+	s := &LocalStorage{
 		basePath: basePath,
-	}, nil
+	}
+	s.cleanStaging()
+
+	return s, nil
+}
+
+// cleanStaging remove staging files left by writes that never completed
+// Recent files are kept, another process sharing the base path may still be writing them.
+func (s *LocalStorage) cleanStaging() {
+	stagingPath := filepath.Join(s.basePath, localStagingDir)
+	entries, err := os.ReadDir(stagingPath)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < localStagingMaxAge {
+			continue
+		}
+		os.Remove(filepath.Join(stagingPath, entry.Name()))
+	}
 }
 
 // Save save file
 func (s *LocalStorage) Save(key string, data []byte) error {
+	return s.SaveReader(key, bytes.NewReader(data), int64(len(data)))
+}
+
+// Get get file
+func (s *LocalStorage) Get(key string) ([]byte, error) {
+	filePath := filepath.Join(s.basePath, key)
+
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return data, nil
+}
+
+// SaveReader save file from reader
+// Content is written to a temporary file in the staging directory and renamed into place once
+// complete, so a failed or short write never leaves a truncated file under key. size may be -1 if unknown.
+func (s *LocalStorage) SaveReader(key string, reader io.Reader, size int64) error {
 	filePath := filepath.Join(s.basePath, key)
 
 	// Ensure parent directory exists
@@ -38,27 +94,57 @@ func (s *LocalStorage) Save(key string, data []byte) error {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Write file
-	if err := ioutil.WriteFile(filePath, data, 0644); err != nil {
+	tmp, err := os.CreateTemp(filepath.Join(s.basePath, localStagingDir), filepath.Base(filePath)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	written, err := io.Copy(tmp, reader)
+	if err == nil && size >= 0 && written != size {
+		err = fmt.Errorf("wrote %d of %d bytes", written, size)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, 0644)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move file into place: %w", err)
+	}
+
 	return nil
 }
 
-// Get get file
-func (s *LocalStorage) Get(key string) ([]byte, error) {
+// GetReader get file reader, caller must close the reader
+func (s *LocalStorage) GetReader(key string) (io.ReadCloser, int64, error) {
 	filePath := filepath.Join(s.basePath, key)
 
-	data, err := ioutil.ReadFile(filePath)
+	f, err := os.Open(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, ErrNotFound
+			return nil, 0, ErrNotFound
 		}
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, 0, fmt.Errorf("failed to open file: %w", err)
 	}
 
-	return data, nil
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	return f, info.Size(), nil
 }
 
 // Delete delete file
@@ -80,3 +166,33 @@ func (s *LocalStorage) Exists(key string) bool {
 	_, err := os.Stat(filePath)
 	return err == nil
 }
+
+// List walk files under prefix and call fn with each key
+// Files being written (the staging directory) are not listed.
+func (s *LocalStorage) List(prefix string, fn func(key string) error) error {
+	root := filepath.Join(s.basePath, prefix)
+	stagingPath := filepath.Join(s.basePath, localStagingDir)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path == stagingPath {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(s.basePath, path)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failingReader returns data, then fails
+type failingReader struct {
+	data []byte
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, errors.New("connection reset")
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestLocalStorageSaveReader(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalStorage(dir)
+	if err != nil {
+		t.Fatalf("NewLocalStorage() failed: %v", err)
+	}
+	key := "indexer/mvc/file.bin"
+	original := []byte("original content")
+	if err := s.Save(key, original); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		reader io.Reader
+		size   int64
+	}{
+		{"failed copy", &failingReader{data: []byte("partial")}, -1},
+		{"short copy", bytes.NewReader([]byte("short")), 100},
+		{"long copy", bytes.NewReader([]byte("longer than announced")), 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.SaveReader(key, tt.reader, tt.size); err == nil {
+				t.Fatal("SaveReader() should fail")
+			}
+			got, err := s.Get(key)
+			if err != nil || !bytes.Equal(got, original) {
+				t.Errorf("Get() = %q, %v, want the previous content kept", got, err)
+			}
+		})
+	}
+
+	content := []byte("new content")
+	if err := s.SaveReader(key, bytes.NewReader(content), -1); err != nil {
+		t.Fatalf("SaveReader() failed: %v", err)
+	}
+	got, err := s.Get(key)
+	if err != nil || !bytes.Equal(got, content) {
+		t.Errorf("Get() = %q, %v, want %q", got, err, content)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, localStagingDir))
+	if err != nil {
+		t.Fatalf("ReadDir() failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("staging directory has %d entries, temporary files should be removed", len(entries))
+	}
+	info, err := os.Stat(filepath.Join(dir, key))
+	if err != nil {
+		t.Fatalf("Stat() failed: %v", err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("file mode = %v, want 0644", info.Mode().Perm())
+	}
+}
+
+func TestLocalStorageListSkipsStaging(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalStorage(dir)
+	if err != nil {
+		t.Fatalf("NewLocalStorage() failed: %v", err)
+	}
+	key := "indexer/mvc/file.bin"
+	if err := s.Save(key, []byte("content")); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	// A write in progress, or left over by a crash
+	if err := os.WriteFile(filepath.Join(dir, localStagingDir, "file.bin-123.tmp"), []byte("partial"), 0644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	var keys []string
+	err = s.List("", func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != key {
+		t.Errorf("List() = %v, want [%s]", keys, key)
+	}
+}
@@ -3,7 +3,10 @@ package storage
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"strconv"
+	"time"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
@@ -64,6 +67,43 @@ func (s *OSSStorage) Get(key string) ([]byte, error) {
 	return data, nil
 }
 
+// SaveReader save file to OSS from reader
+func (s *OSSStorage) SaveReader(key string, reader io.Reader, size int64) error {
+	err := s.bucket.PutObject(key, reader)
+	if err != nil {
+		return fmt.Errorf("failed to upload to oss: %w", err)
+	}
+	return nil
+}
+
+// GetReader get file reader from OSS, caller must close the reader
+func (s *OSSStorage) GetReader(key string) (io.ReadCloser, int64, error) {
+	meta, err := s.bucket.GetObjectDetailedMeta(key)
+	if err != nil {
+		if ossErr, ok := err.(oss.ServiceError); ok && ossErr.StatusCode == 404 {
+			return nil, 0, ErrNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to get from oss: %w", err)
+	}
+	size, _ := strconv.ParseInt(meta.Get("Content-Length"), 10, 64)
+
+	body, err := s.bucket.GetObject(key)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get from oss: %w", err)
+	}
+
+	return body, size, nil
+}
+
+// PresignedURL generate presigned download URL
+func (s *OSSStorage) PresignedURL(key string, expires time.Duration) (string, error) {
+	signedURL, err := s.bucket.SignURL(key, oss.HTTPGet, int64(expires.Seconds()))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign oss url: %w", err)
+	}
+	return signedURL, nil
+}
+
 // Delete delete file from OSS
 func (s *OSSStorage) Delete(key string) error {
 	err := s.bucket.DeleteObject(key)
@@ -81,3 +121,30 @@ func (s *OSSStorage) Exists(key string) bool {
 	}
 	return exists
 }
+
+// List list objects under prefix and call fn with each key
+func (s *OSSStorage) List(prefix string, fn func(key string) error) error {
+	token := ""
+	for {
+		options := []oss.Option{oss.Prefix(prefix), oss.MaxKeys(1000)}
+		if token != "" {
+			options = append(options, oss.ContinuationToken(token))
+		}
+
+		result, err := s.bucket.ListObjectsV2(options...)
+		if err != nil {
+			return fmt.Errorf("failed to list oss objects: %w", err)
+		}
+
+		for _, object := range result.Objects {
+			if err := fn(object.Key); err != nil {
+				return err
+			}
+		}
+
+		if !result.IsTruncated {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Default multipart part size for S3 uploads (16MB)
+const defaultS3PartSize = 16 * 1024 * 1024
+
+// S3Config S3-compatible storage configuration
+type S3Config struct {
+	Endpoint  string // Endpoint host[:port], e.g. "127.0.0.1:9000", "s3.amazonaws.com"
+	Region    string // Region, e.g. "us-east-1"
+	Bucket    string // Bucket name
+	AccessKey string // Access key
+	SecretKey string // Secret key
+	UseSSL    bool   // Use HTTPS
+	PathStyle bool   // Use path-style addressing (required by MinIO/Ceph)
+	PartSize  uint64 // Multipart part size in bytes (0 for default)
+}
+
+// S3Storage S3-compatible object storage (AWS S3, MinIO, Ceph, R2)
+type S3Storage struct {
+	client   *minio.Client
+	bucket   string
+	partSize uint64
+}
+
+// NewS3Storage create S3 storage instance
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, ErrInvalid
+	}
+
+	lookup := minio.BucketLookupDNS
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	// Create S3 client instance
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       cfg.UseSSL,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	// Check bucket
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("bucket %s does not exist", cfg.Bucket)
+	}
+
+	partSize := cfg.PartSize
+	if partSize == 0 {
+		partSize = defaultS3PartSize
+	}
+
+	return &S3Storage{
+		client:   client,
+		bucket:   cfg.Bucket,
+		partSize: partSize,
+	}, nil
+}
+
+// Save save file to S3
+func (s *S3Storage) Save(key string, data []byte) error {
+	return s.SaveReader(key, bytes.NewReader(data), int64(len(data)))
+}
+
+// SaveReader save file to S3 from reader
+// Objects larger than the part size are uploaded with multipart upload.
+// size may be -1 if unknown.
+func (s *S3Storage) SaveReader(key string, reader io.Reader, size int64) error {
+	_, err := s.client.PutObject(context.Background(), s.bucket, key, reader, size, minio.PutObjectOptions{
+		PartSize: s.partSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload to s3: %w", err)
+	}
+	return nil
+}
+
+// Get get file from S3
+func (s *S3Storage) Get(key string) ([]byte, error) {
+	body, _, err := s.GetReader(key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read s3 object: %w", err)
+	}
+
+	return data, nil
+}
+
+// GetReader get file reader from S3, caller must close the reader
+func (s *S3Storage) GetReader(key string) (io.ReadCloser, int64, error) {
+	obj, err := s.client.GetObject(context.Background(), s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get from s3: %w", err)
+	}
+
+	// GetObject is lazy, Stat triggers the request
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		if isS3NotFound(err) {
+			return nil, 0, ErrNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to get from s3: %w", err)
+	}
+
+	return obj, info.Size, nil
+}
+
+// Delete delete file from S3
+func (s *S3Storage) Delete(key string) error {
+	err := s.client.RemoveObject(context.Background(), s.bucket, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to delete from s3: %w", err)
+	}
+	return nil
+}
+
+// Exists check if file exists in S3
+func (s *S3Storage) Exists(key string) bool {
+	_, err := s.client.StatObject(context.Background(), s.bucket, key, minio.StatObjectOptions{})
+	return err == nil
+}
+
+// List list objects under prefix and call fn with each key
+func (s *S3Storage) List(prefix string, fn func(key string) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return fmt.Errorf("failed to list s3 objects: %w", object.Err)
+		}
+		if err := fn(object.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PresignedURL generate presigned download URL
+func (s *S3Storage) PresignedURL(key string, expires time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(context.Background(), s.bucket, key, expires, url.Values{})
+	if err != nil {
+		return "", fmt.Errorf("failed to presign s3 url: %w", err)
+	}
+	return u.String(), nil
+}
+
+// isS3NotFound check if error is a not found error
+func isS3NotFound(err error) bool {
+	resp := minio.ToErrorResponse(err)
+	return resp.StatusCode == 404 || resp.Code == "NoSuchKey"
+}
@@ -2,6 +2,9 @@ package storage
 
 import (
 	"errors"
+	"io"
+	"time"
+
 	"meta-media-service/conf"
 )
 
@@ -13,13 +16,45 @@ type Storage interface {
 	Exists(key string) bool
 }
 
+// StreamStorage storage that supports streaming reads and writes
+type StreamStorage interface {
+	SaveReader(key string, reader io.Reader, size int64) error
+	GetReader(key string) (io.ReadCloser, int64, error)
+}
+
+// URLSigner storage that can generate presigned download URLs
+type URLSigner interface {
+	PresignedURL(key string, expires time.Duration) (string, error)
+}
+
+// Lister storage that can enumerate stored keys under a prefix
+type Lister interface {
+	List(prefix string, fn func(key string) error) error
+}
+
 var (
 	ErrNotFound = errors.New("file not found")
 	ErrInvalid  = errors.New("invalid storage configuration")
 )
 
 // NewStorage create storage instance by configuration
+// Remote storage (oss/s3) is wrapped with a local disk cache when storage.cache is enabled.
 func NewStorage() (Storage, error) {
+	backend, err := newBackendStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	cacheConf := conf.Cfg.Storage.Cache
+	if !cacheConf.Enabled || conf.Cfg.Storage.Type == "local" || conf.Cfg.Storage.Type == "" {
+		return backend, nil
+	}
+
+	return NewCacheStorage(backend, cacheConf.Dir, cacheConf.MaxSize*1024*1024) // MB to bytes
+}
+
+// newBackendStorage create backing storage instance by configured type
+func newBackendStorage() (Storage, error) {
 	storageType := conf.Cfg.Storage.Type
 
 	switch storageType {
@@ -28,6 +63,17 @@ func NewStorage() (Storage, error) {
 	case "oss":
 		return NewOSSStorage(conf.Cfg.Storage.OSS.Endpoint, conf.Cfg.Storage.OSS.AccessKey,
 			conf.Cfg.Storage.OSS.SecretKey, conf.Cfg.Storage.OSS.Bucket)
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:  conf.Cfg.Storage.S3.Endpoint,
+			Region:    conf.Cfg.Storage.S3.Region,
+			Bucket:    conf.Cfg.Storage.S3.Bucket,
+			AccessKey: conf.Cfg.Storage.S3.AccessKey,
+			SecretKey: conf.Cfg.Storage.S3.SecretKey,
+			UseSSL:    conf.Cfg.Storage.S3.UseSSL,
+			PathStyle: conf.Cfg.Storage.S3.PathStyle,
+			PartSize:  uint64(conf.Cfg.Storage.S3.PartSize) * 1024 * 1024, // MB to bytes
+		})
 	default:
 		// Default to local storage
 		return NewLocalStorage(conf.Cfg.Storage.Local.BasePath)
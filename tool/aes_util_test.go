@@ -0,0 +1,29 @@
+package tool
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAESGCMEncrypt(t *testing.T) {
+	plaintext := []byte("8170940a65bda743704be89096ce6d292f052dbb897f4b7aa5d92aa1d0e64531")
+	data, err := AESGCMEncrypt("master-key", plaintext)
+	if err != nil {
+		t.Fatalf("AESGCMEncrypt() failed, err: %v", err)
+	}
+
+	decrypted, err := AESGCMDecrypt("master-key", data)
+	if err != nil {
+		t.Fatalf("AESGCMDecrypt() failed, err: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("AESGCMDecrypt() = %q, want %q", decrypted, plaintext)
+	}
+
+	if _, err := AESGCMDecrypt("other-key", data); err == nil {
+		t.Error("AESGCMDecrypt() with wrong key should fail")
+	}
+	if _, err := AESGCMDecrypt("master-key", data[:10]); err == nil {
+		t.Error("AESGCMDecrypt() of truncated data should fail")
+	}
+}
@@ -8,6 +8,7 @@ let walletConnected = false;
 let currentAddress = null;
 let maxFileSize = 10485760; // Default 10MB, will be fetched from server
 let swaggerBaseUrl = ''; // Swagger base URL from server config
+let chunkThreshold = 5242880; // Default 5MB, larger files are uploaded in chunks
 
 // Helper function to get wallet object
 function getWallet() {
@@ -137,6 +138,7 @@ async function loadConfig() {
         if (result.code === 0 && result.data) {
             maxFileSize = result.data.maxFileSize;
             swaggerBaseUrl = result.data.swaggerBaseUrl || '';
+            chunkThreshold = result.data.chunkThreshold || chunkThreshold;
             
             const sizeText = formatFileSize(maxFileSize);
             maxFileSizeText.textContent = sizeText;
@@ -736,6 +738,12 @@ async function calculateMetaID(address) {
     }
 }
 
+// check if the file transaction was already accepted by the node (status stays pending until it is confirmed)
+function isCommitted(preUploadResult) {
+    return preUploadResult.status === 'success' ||
+        ['broadcast', 'in_mempool', 'confirmed'].includes(preUploadResult.txState);
+}
+
 // Start uploadflow
 async function startUpload() {
     // validate wallet connection
@@ -767,7 +775,7 @@ async function startUpload() {
         const preUploadResult = await preUpload();
         
         // check if file is alreadyupload to chain successful
-        if (preUploadResult.status === 'success') {
+        if (isCommitted(preUploadResult)) {
             updateProgress(100, 'file already exists!');
             addLog(`✅ this file has been uploaded to chain successfully!`, 'success');
             
@@ -848,6 +856,13 @@ async function startUpload2() {
     }
     
     console.log('✅ Validation passed: wallet connected, file selected');
+
+    // Direct upload only takes a single transaction, large files go through chunked upload
+    if (selectedFile.size > chunkThreshold) {
+        addLog(`📦 File is larger than ${formatFileSize(chunkThreshold)}, using chunked upload`, 'info');
+        return startChunkedUpload();
+    }
+
     addLog('Starting Direct Upload flow...', 'info');
 
     try {
@@ -936,6 +951,80 @@ async function startUpload2() {
     }
 }
 
+// Start upload flow (chunked upload - one transaction per chunk plus an index transaction)
+async function startChunkedUpload() {
+    try {
+        uploadBtn.disabled = true;
+        uploadBtn.textContent = 'Uploading...';
+        progress.classList.add('show');
+        
+        showNotification('Starting Chunked Upload...', 'info');
+        
+        // Step 1: pre-upload, server splits the file and builds chunk transactions
+        updateProgress(5, 'Step 1/4: Building chunk transactions...');
+        const preUploadResult = await preUpload();
+        
+        if (isCommitted(preUploadResult)) {
+            updateProgress(100, 'File already exists!');
+            addLog(`✅ This file has been uploaded to chain successfully!`, 'success');
+            showUploadSuccessLinks(preUploadResult.txId, preUploadResult.pinId);
+            showBuzzSection(preUploadResult.pinId);
+            return;
+        }
+        
+        // Step 2: verify balance for all transactions
+        updateProgress(10, 'Step 2/4: Verifying balance...');
+        await prepareUTXO(preUploadResult);
+        
+        // Step 3: sign and commit chunks one by one
+        const chunks = preUploadResult.chunks || [];
+        const pendingChunks = chunks.filter(chunk => chunk.status !== 'success');
+        addLog(`📦 ${chunks.length} chunk(s), ${pendingChunks.length} to upload`, 'info');
+        
+        let uploadProgress = null;
+        for (const chunk of pendingChunks) {
+            updateProgress(10 + Math.floor(80 * chunk.chunkIndex / chunks.length), `Step 3/4: Uploading chunk ${chunk.chunkIndex + 1}/${chunks.length}...`);
+            showNotification(`Please confirm chunk ${chunk.chunkIndex + 1}/${chunks.length} in wallet...`, 'info');
+            const signedChunkTx = await signTransaction(chunk.preTxRaw);
+            uploadProgress = await commitChunk(preUploadResult.fileId, chunk.chunkIndex, signedChunkTx);
+            addLog(`✅ Chunk ${chunk.chunkIndex + 1}/${chunks.length} broadcast (${uploadProgress.progress}%)`, 'success');
+        }
+        if (!uploadProgress) {
+            uploadProgress = await getUploadProgress(preUploadResult.fileId);
+        }
+        if (!uploadProgress.indexPreTxRaw) {
+            throw new Error(`${uploadProgress.chunkNumber - uploadProgress.committedChunks} chunk(s) not broadcast yet`);
+        }
+        
+        // Step 4: sign and commit the index transaction
+        updateProgress(90, 'Step 4/4: Uploading index...');
+        showNotification('Please confirm index transaction in wallet...', 'info');
+        const signedIndexTx = await signTransaction(uploadProgress.indexPreTxRaw);
+        const commitResult = await commitUpload(preUploadResult.fileId, signedIndexTx);
+        
+        updateProgress(100, 'Upload completed!');
+        addLog(`✅ File uploaded successfully! Index TxID: ${commitResult.txId}`, 'success');
+        showNotification(`🎉 File uploaded successfully!`, 'success');
+        
+        showUploadSuccessLinks(commitResult.txId, commitResult.pinId);
+        showBuzzSection(commitResult.pinId);
+        
+    } catch (error) {
+        console.error('❌ Chunked upload failed:', error);
+        addLog(`❌ Chunked upload failed: ${error.message}`, 'error');
+        
+        if (error.message && error.message.includes('user cancelled')) {
+            showNotification('Upload operation cancelled', 'warning');
+        } else {
+            showNotification('Upload failed: ' + error.message, 'error');
+        }
+    } finally {
+        uploadBtn.disabled = false;
+        uploadBtn.textContent = '🚀 Start Upload to Chain';
+        progress.classList.remove('show');
+    }
+}
+
 // Estimate file upload fee
 async function estimateUploadFee() {
     try {
@@ -1619,6 +1708,50 @@ async function commitUpload(fileId, signedRawTx) {
     return result.data;
 }
 
+// commit one chunk of a chunked upload
+async function commitChunk(fileId, chunkIndex, signedRawTx) {
+    const response = await fetch(`${API_BASE}/api/v1/files/commit-chunk`, {
+        method: 'POST',
+        headers: {
+            'Content-Type': 'application/json'
+        },
+        body: JSON.stringify({
+            fileId: fileId,
+            chunkIndex: chunkIndex,
+            signedRawTx: signedRawTx
+        })
+    });
+    
+    if (!response.ok) {
+        throw new Error(`HTTP Error: ${response.status}`);
+    }
+    
+    const result = await response.json();
+    
+    if (result.code !== 0) {
+        throw new Error(result.message);
+    }
+    
+    return result.data;
+}
+
+// get upload progress
+async function getUploadProgress(fileId) {
+    const response = await fetch(`${API_BASE}/api/v1/files/${encodeURIComponent(fileId)}/progress`);
+    
+    if (!response.ok) {
+        throw new Error(`HTTP Error: ${response.status}`);
+    }
+    
+    const result = await response.json();
+    
+    if (result.code !== 0) {
+        throw new Error(result.message);
+    }
+    
+    return result.data;
+}
+
 // Update progress
 function updateProgress(percent, text) {
     progressFill.style.width = percent + '%';